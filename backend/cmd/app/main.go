@@ -4,14 +4,25 @@ import (
 	"log"
 	"net/http"
 	"wbrost-go/internal/config"
+	"wbrost-go/internal/crypto"
 	"wbrost-go/internal/handler"
 	"wbrost-go/internal/middleware"
+	"wbrost-go/internal/repository/account"
 	"wbrost-go/internal/repository/article"
+	auditrepo "wbrost-go/internal/repository/audit"
 	"wbrost-go/internal/repository/database/postgres"
+	"wbrost-go/internal/repository/export"
+	"wbrost-go/internal/repository/organization"
+	"wbrost-go/internal/repository/ozon"
 	"wbrost-go/internal/repository/stat"
 	"wbrost-go/internal/repository/user"
+	"wbrost-go/internal/repository/yandex"
 	"wbrost-go/internal/server"
+	auditservice "wbrost-go/internal/service/audit"
 	"wbrost-go/internal/service/auth"
+	"wbrost-go/internal/service/card"
+	orgservice "wbrost-go/internal/service/organization"
+	"wbrost-go/internal/service/profile"
 )
 
 func main() {
@@ -22,6 +33,7 @@ func main() {
 	log.Printf("  DB: %s@%s:%s/%s", cfg.DBUser, cfg.DBHost, cfg.DBPort, cfg.DBName)
 	log.Printf("  Server port: %s", cfg.ServerPort)
 	log.Printf("  Allowed origins: %v", cfg.AllowedOrigins)
+	log.Printf("  Trusted proxies: %v", cfg.TrustedProxies)
 
 	// Формируем строку подключения к БД
 	connectionString := "host=" + cfg.DBHost +
@@ -38,27 +50,54 @@ func main() {
 	}
 	defer db.Close()
 
+	// Загружаем мастер-ключи для шифрования API ключей маркетплейсов
+	keyring, err := crypto.NewKeyring(cfg.Encryption.Keys, cfg.Encryption.CurrentVersion)
+	if err != nil {
+		log.Fatal("Failed to load encryption keys:", err)
+	}
+
 	// Инициализируем репозитории
-	userRepo := user.NewUserRepository(db)
+	userRepo := user.NewUserRepository(db, keyring)
+	accountRepo := account.NewSellerAccountRepository(db, keyring)
+	orgRepo := organization.NewOrganizationRepository(db)
 	wbStatsGetRepo := stat.NewWBStatsGetRepository(db)
 	statsRepo := stat.NewStatRepository(db)
 	analyticsRepo := stat.NewAnalyticsRepository(db, userRepo)
 	dashboardRepo := stat.NewDashboardRepository(db, userRepo)
 	articlesGetRepo := article.NewWBArticlesGetRepository(db)
 	articleRepo := article.NewWBArticlesRepository(db)
+	cardUpdateRepo := article.NewWBCardUpdateRepository(db)
+	ozonGetRepo := ozon.NewOzonGetRepository(db)
+	ozonTransactionRepo := ozon.NewTransactionRepository(db)
+	ozonProductRepo := ozon.NewProductRepository(db)
+	yandexGetRepo := yandex.NewYandexGetRepository(db)
+	exportRepo := export.NewExportRepository(db)
+	auditRepo := auditrepo.NewAuditRepository(db)
 
 	// Инициализируем сервис
-	authService := auth.NewAuthService(userRepo)
+	auditService := auditservice.NewAuditService(auditRepo)
+	authService := auth.NewAuthService(userRepo, accountRepo, auditService)
+	orgService := orgservice.NewOrganizationService(orgRepo, userRepo)
+	cardService := card.NewCardService(articleRepo, cardUpdateRepo)
+	profileService := profile.NewProfileService(userRepo, accountRepo, exportRepo, cfg.DeletionGraceDays)
 
 	// Создаем обработчики
 	authHandler := handler.NewAuthHandler(authService, userRepo, cfg.JWTSecret)
-	wbStatsHandler := handler.NewWBStatsHandler(userRepo, wbStatsGetRepo, statsRepo, analyticsRepo, dashboardRepo, cfg.JWTSecret)
-	wbArticlesHandler := handler.NewWBArticlesHandler(userRepo, articlesGetRepo, articleRepo, cfg.JWTSecret)
+	wbStatsHandler := handler.NewWBStatsHandler(userRepo, accountRepo, wbStatsGetRepo, statsRepo, analyticsRepo, dashboardRepo, orgService, auditService, cfg.JWTSecret)
+	wbArticlesHandler := handler.NewWBArticlesHandler(userRepo, accountRepo, articlesGetRepo, articleRepo, orgService, auditService, cardService, cfg.JWTSecret)
+	sellerAccountsHandler := handler.NewSellerAccountsHandler(userRepo, accountRepo, orgService, cfg.JWTSecret)
+	organizationsHandler := handler.NewOrganizationsHandler(userRepo, orgService, cfg.JWTSecret)
+	ozonHandler := handler.NewOzonHandler(userRepo, ozonGetRepo, ozonTransactionRepo, ozonProductRepo, orgService, auditService, cfg.JWTSecret)
+	yandexHandler := handler.NewYandexHandler(userRepo, accountRepo, yandexGetRepo, orgService, auditService, cfg.JWTSecret)
+	profileHandler := handler.NewProfileHandler(userRepo, profileService, auditService, cfg.JWTSecret)
+	auditHandler := handler.NewAuditHandler(userRepo, auditService, cfg.JWTSecret)
 
 	// Настраиваем маршруты
-	httpHandler := server.SetupRoutes(authHandler, wbStatsHandler, wbArticlesHandler)
+	httpHandler := server.SetupRoutes(authHandler, wbStatsHandler, wbArticlesHandler, sellerAccountsHandler, organizationsHandler, ozonHandler, yandexHandler, profileHandler, auditHandler)
 	// Обертываем в CORS middleware
 	handlerWithCORS := middleware.CORS(cfg)(httpHandler)
+	// Адрес клиента из заголовков доверенного прокси
+	handlerWithCORS = middleware.RealIP(cfg)(handlerWithCORS)
 
 	serverAddr := ":" + cfg.ServerPort
 	log.Printf("Server starting on %s", serverAddr)
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"wbrost-go/internal/config"
+	"wbrost-go/internal/repository/database/postgres"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratepg "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+)
+
+const usage = `Использование: migrate [-path migrations] <команда> [аргумент]
+
+Команды:
+  up [N]       применить все новые миграции или N следующих
+  down [N]     откатить N последних миграций (по умолчанию одну)
+  goto V       перейти на версию V (вверх или вниз)
+  status       текущая версия и список миграций
+  force V      записать версию V без выполнения миграций и снять флаг dirty
+  create name  создать пару файлов NNN_name.up.sql / NNN_name.down.sql
+`
+
+// migrationNameRe - допустимое имя новой миграции
+var migrationNameRe = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Управление миграциями БД. Подключение берется из того же конфига, что и у приложения
+func main() {
+	var path string
+
+	flag.StringVar(&path, "path", "migrations", "Каталог с файлами миграций")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	command, arg := args[0], ""
+	if len(args) > 1 {
+		arg = args[1]
+	}
+
+	// create не требует подключения к БД
+	if command == "create" {
+		if err := createMigration(path, arg); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		return
+	}
+
+	// Загружаем конфиг
+	cfg := config.Load()
+
+	db, err := postgres.NewPostgresDB(cfg.GetDBConnectionString())
+	if err != nil {
+		log.Fatalf("Ошибка подключения к БД: %v", err)
+	}
+	defer db.Close()
+
+	fmt.Printf("✓ Подключение к БД %s@%s:%s/%s установлено\n", cfg.DBUser, cfg.DBHost, cfg.DBPort, cfg.DBName)
+
+	driver, err := migratepg.WithInstance(db.DB, &migratepg.Config{})
+	if err != nil {
+		log.Fatalf("Ошибка создания драйвера миграций: %v", err)
+	}
+
+	m, err := migrate.NewWithDatabaseInstance("file://"+path, "postgres", driver)
+	if err != nil {
+		log.Fatalf("Ошибка загрузки миграций из %s: %v", path, err)
+	}
+	m.Log = &migrateLogger{}
+
+	switch command {
+	case "up":
+		if arg == "" {
+			err = m.Up()
+		} else {
+			var steps int
+			if steps, err = parsePositive(arg); err == nil {
+				err = m.Steps(steps)
+			}
+		}
+	case "down":
+		// Без аргумента откатываем одну миграцию: полный откат удаляет все данные
+		steps := 1
+		if arg != "" {
+			steps, err = parsePositive(arg)
+		}
+		if err == nil {
+			err = m.Steps(-steps)
+		}
+	case "goto":
+		var version int
+		if version, err = parsePositive(arg); err == nil {
+			err = m.Migrate(uint(version))
+		}
+	case "force":
+		var version int
+		if version, err = strconv.Atoi(arg); err != nil {
+			err = fmt.Errorf("invalid version %q", arg)
+		} else {
+			// -1 - база без примененных миграций
+			err = m.Force(version)
+		}
+	case "status":
+		err = printStatus(m, path)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if errors.Is(err, migrate.ErrNoChange) {
+		fmt.Println("ℹ️  Нет изменений")
+		return
+	}
+	if err != nil {
+		log.Fatalf("❌ Ошибка выполнения %s: %v", command, err)
+	}
+
+	if command != "status" {
+		printVersion(m)
+		fmt.Println("✅ Готово")
+	}
+}
+
+// printVersion выводит текущую версию схемы
+func printVersion(m *migrate.Migrate) {
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		fmt.Println("Версия: миграции не применены")
+		return
+	}
+	if err != nil {
+		fmt.Printf("Не удалось получить версию: %v\n", err)
+		return
+	}
+
+	if dirty {
+		fmt.Printf("Версия: %d (dirty - миграция завершилась ошибкой, исправьте БД и выполните force)\n", version)
+		return
+	}
+	fmt.Printf("Версия: %d\n", version)
+}
+
+// printStatus выводит текущую версию и все миграции каталога с отметкой о применении
+func printStatus(m *migrate.Migrate, path string) error {
+	printVersion(m)
+
+	current, _, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return err
+	}
+	applied := err == nil
+
+	src, err := source.Open("file://" + path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	for err == nil {
+		mark := " "
+		if applied && version <= current {
+			mark = "✓"
+		}
+
+		name := ""
+		if r, identifier, readErr := src.ReadUp(version); readErr == nil {
+			name = identifier
+			r.Close()
+		}
+
+		fmt.Printf("  [%s] %03d %s\n", mark, version, name)
+		version, err = src.Next(version)
+	}
+
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// createMigration создает пустые up/down файлы со следующим номером
+func createMigration(path, name string) error {
+	if !migrationNameRe.MatchString(name) {
+		return fmt.Errorf("invalid migration name %q: use lowercase letters, digits and _", name)
+	}
+
+	files, err := filepath.Glob(filepath.Join(path, "*.sql"))
+	if err != nil {
+		return err
+	}
+
+	next := 1
+	for _, file := range files {
+		var version int
+		if _, err := fmt.Sscanf(filepath.Base(file), "%d_", &version); err == nil && version >= next {
+			next = version + 1
+		}
+	}
+
+	for _, direction := range []string{"up", "down"} {
+		file := filepath.Join(path, fmt.Sprintf("%03d_%s.%s.sql", next, name, direction))
+		if err := os.WriteFile(file, nil, 0644); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Создан %s\n", file)
+	}
+
+	return nil
+}
+
+func parsePositive(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid number %q", value)
+	}
+	return n, nil
+}
+
+// migrateLogger выводит ход выполнения миграций
+type migrateLogger struct{}
+
+func (l *migrateLogger) Printf(format string, v ...interface{}) {
+	fmt.Printf(format, v...)
+}
+
+func (l *migrateLogger) Verbose() bool {
+	return false
+}
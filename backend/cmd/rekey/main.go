@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"wbrost-go/internal/config"
+	"wbrost-go/internal/crypto"
+	"wbrost-go/internal/repository/account"
+	"wbrost-go/internal/repository/database/postgres"
+	"wbrost-go/internal/repository/user"
+)
+
+// Перешифрование API ключей маркетплейсов текущим мастер-ключом.
+// Ротация: добавить новый ключ в ENCRYPTION_KEYS ("1:<старый>,2:<новый>"),
+// запустить эту команду, после чего старый ключ можно убрать из конфига
+func main() {
+	var dryRun bool
+
+	flag.BoolVar(&dryRun, "dry-run", false, "Только посчитать пользователей, чьи ключи нужно перешифровать")
+	flag.Parse()
+
+	// Загружаем конфиг
+	cfg := config.Load()
+
+	keyring, err := crypto.NewKeyring(cfg.Encryption.Keys, cfg.Encryption.CurrentVersion)
+	if err != nil {
+		log.Fatalf("Ошибка загрузки ключей шифрования: %v", err)
+	}
+
+	// Инициализируем БД
+	db, err := postgres.NewPostgresDB(cfg.GetDBConnectionString())
+	if err != nil {
+		log.Fatalf("Ошибка подключения к БД: %v", err)
+	}
+	defer db.Close()
+
+	fmt.Printf("🔑 Загружены ключи версий %v, текущая версия: %d\n", keyring.Versions(), keyring.Current())
+
+	userRepo := user.NewUserRepository(db, keyring)
+
+	count, err := userRepo.ReencryptKeys(dryRun)
+	if err != nil {
+		log.Fatalf("❌ Ошибка перешифрования (обработано пользователей: %d): %v", count, err)
+	}
+
+	accountRepo := account.NewSellerAccountRepository(db, keyring)
+
+	accountsCount, err := accountRepo.ReencryptKeys(dryRun)
+	if err != nil {
+		log.Fatalf("❌ Ошибка перешифрования кабинетов (обработано кабинетов: %d): %v", accountsCount, err)
+	}
+
+	if dryRun {
+		fmt.Printf("ℹ️  Нужно перешифровать ключи у %d пользователей и %d кабинетов\n", count, accountsCount)
+		return
+	}
+
+	fmt.Printf("✅ Ключи перешифрованы у %d пользователей и %d кабинетов\n", count, accountsCount)
+}
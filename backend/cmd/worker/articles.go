@@ -9,8 +9,11 @@ import (
 	"syscall"
 	"time"
 	"wbrost-go/internal/config"
+	"wbrost-go/internal/crypto"
+	"wbrost-go/internal/repository/account"
 	"wbrost-go/internal/repository/article"
 	"wbrost-go/internal/repository/database/postgres"
+	"wbrost-go/internal/repository/operation"
 	"wbrost-go/internal/repository/stat"
 	"wbrost-go/internal/repository/user"
 	"wbrost-go/internal/service/wb"
@@ -37,15 +40,24 @@ func main() {
 
 	fmt.Println("✓ Подключение к БД установлено")
 
+	// Загружаем мастер-ключи для шифрования API ключей маркетплейсов
+	keyring, err := crypto.NewKeyring(cfg.Encryption.Keys, cfg.Encryption.CurrentVersion)
+	if err != nil {
+		log.Fatalf("Ошибка загрузки ключей шифрования: %v", err)
+	}
+
 	// Инициализируем репозитории
-	userRepo := user.NewUserRepository(db)
+	userRepo := user.NewUserRepository(db, keyring)
+	accountRepo := account.NewSellerAccountRepository(db, keyring)
 	statsGetRepo := stat.NewWBStatsGetRepository(db)
 	statRepo := stat.NewStatRepository(db)
 	articlesGetRepo := article.NewWBArticlesGetRepository(db)
 	articleRepo := article.NewWBArticlesRepository(db)
+	cardUpdateRepo := article.NewWBCardUpdateRepository(db)
+	operationRepo := operation.NewOperationRepository(db)
 
 	// Инициализируем сервис
-	articlesService := wb.NewWBService(userRepo, statsGetRepo, statRepo, articlesGetRepo, articleRepo)
+	articlesService := wb.NewWBService(userRepo, accountRepo, statsGetRepo, statRepo, articlesGetRepo, articleRepo, cardUpdateRepo, operationRepo)
 
 	// Определяем интервал
 	if interval == 0 {
@@ -55,7 +67,7 @@ func main() {
 	if runOnce {
 		// Запускаем один раз
 		fmt.Println("🚀 Запуск обработки карточек товаров...")
-		if err := articlesService.ProcessPendingArticles(); err != nil {
+		if err := processArticles(articlesService); err != nil {
 			log.Printf("❌ Ошибка обработки: %v", err)
 			os.Exit(1)
 		}
@@ -76,7 +88,7 @@ func main() {
 
 	// Первый запуск сразу
 	fmt.Println("🎯 Первоначальная обработка карточек...")
-	if err := articlesService.ProcessPendingArticles(); err != nil {
+	if err := processArticles(articlesService); err != nil {
 		log.Printf("⚠️ Ошибка при первоначальной обработке: %v", err)
 	}
 
@@ -85,7 +97,7 @@ func main() {
 		select {
 		case <-ticker.C:
 			fmt.Printf("\n⏰ Запуск обработки карточек в %s\n", time.Now().Format("2006-01-02 15:04:05"))
-			if err := articlesService.ProcessPendingArticles(); err != nil {
+			if err := processArticles(articlesService); err != nil {
 				log.Printf("⚠️ Ошибка обработки карточек: %v", err)
 			}
 			fmt.Printf("💤 Следующий запуск через %d секунд...\n", interval)
@@ -96,3 +108,11 @@ func main() {
 		}
 	}
 }
+
+// processArticles - загрузка карточек и отправка изменений карточек в WB
+func processArticles(service *wb.WBService) error {
+	if err := service.ProcessPendingArticles(); err != nil {
+		return err
+	}
+	return service.ProcessPendingCardUpdates()
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"wbrost-go/internal/config"
+	"wbrost-go/internal/crypto"
+	"wbrost-go/internal/repository/account"
+	"wbrost-go/internal/repository/database/postgres"
+	"wbrost-go/internal/repository/export"
+	"wbrost-go/internal/repository/user"
+	"wbrost-go/internal/service/profile"
+)
+
+// Удаление аккаунтов, у которых истек льготный период после запроса удаления
+func main() {
+	// Флаги командной строки
+	var runOnce bool
+	var interval int
+
+	flag.BoolVar(&runOnce, "once", false, "Запустить один раз и выйти")
+	flag.IntVar(&interval, "interval", 3600, "Интервал в секундах между запусками")
+	flag.Parse()
+
+	// Загружаем конфиг
+	cfg := config.Load()
+
+	// Инициализируем БД
+	db, err := postgres.NewPostgresDB(cfg.GetDBConnectionString())
+	if err != nil {
+		log.Fatalf("Ошибка подключения к БД: %v", err)
+	}
+	defer db.Close()
+
+	fmt.Println("✓ Подключение к БД установлено")
+
+	// Ключи нужны репозиториям для чтения пользователей и кабинетов
+	keyring, err := crypto.NewKeyring(cfg.Encryption.Keys, cfg.Encryption.CurrentVersion)
+	if err != nil {
+		log.Fatalf("Ошибка загрузки ключей шифрования: %v", err)
+	}
+
+	// Инициализируем репозитории
+	userRepo := user.NewUserRepository(db, keyring)
+	accountRepo := account.NewSellerAccountRepository(db, keyring)
+	exportRepo := export.NewExportRepository(db)
+
+	// Инициализируем сервис
+	profileService := profile.NewProfileService(userRepo, accountRepo, exportRepo, cfg.DeletionGraceDays)
+
+	run := func() {
+		start := time.Now()
+		purged, err := profileService.PurgeDueDeletions()
+		if err != nil {
+			log.Printf("⚠️ Ошибка удаления аккаунтов: %v", err)
+			return
+		}
+		fmt.Printf("✅ Удалено аккаунтов: %d за %v\n", purged, time.Since(start))
+	}
+
+	if runOnce {
+		fmt.Println("🚀 Удаление аккаунтов с истекшим льготным периодом...")
+		run()
+		os.Exit(0)
+	}
+
+	// Запускаем как демон
+	fmt.Printf("🔄 Запуск воркера удаления аккаунтов с интервалом %d секунд...\n", interval)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	// Первый запуск сразу
+	fmt.Println("🎯 Первоначальная обработка...")
+	run()
+
+	for {
+		select {
+		case <-ticker.C:
+			fmt.Printf("\n⏰ Запуск обработки в %s\n", time.Now().Format("2006-01-02 15:04:05"))
+			run()
+			fmt.Printf("💤 Следующий запуск через %d секунд...\n", interval)
+
+		case sig := <-sigChan:
+			fmt.Printf("\n🛑 Получен сигнал: %v. Завершение работы...\n", sig)
+			return
+		}
+	}
+}
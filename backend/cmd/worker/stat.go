@@ -9,8 +9,11 @@ import (
 	"syscall"
 	"time"
 	"wbrost-go/internal/config"
+	"wbrost-go/internal/crypto"
+	"wbrost-go/internal/repository/account"
 	"wbrost-go/internal/repository/article"
 	"wbrost-go/internal/repository/database/postgres"
+	"wbrost-go/internal/repository/operation"
 	"wbrost-go/internal/repository/stat"
 	"wbrost-go/internal/repository/user"
 	"wbrost-go/internal/service/wb"
@@ -42,15 +45,24 @@ func main() {
 
 	fmt.Println("✓ Подключение к БД установлено")
 
+	// Загружаем мастер-ключи для шифрования API ключей маркетплейсов
+	keyring, err := crypto.NewKeyring(cfg.Encryption.Keys, cfg.Encryption.CurrentVersion)
+	if err != nil {
+		log.Fatalf("Ошибка загрузки ключей шифрования: %v", err)
+	}
+
 	// Инициализируем репозитории
-	userRepo := user.NewUserRepository(db)
+	userRepo := user.NewUserRepository(db, keyring)
+	accountRepo := account.NewSellerAccountRepository(db, keyring)
 	statsGetRepo := stat.NewWBStatsGetRepository(db)
 	statRepo := stat.NewStatRepository(db)
 	articlesGetRepo := article.NewWBArticlesGetRepository(db)
 	articleRepo := article.NewWBArticlesRepository(db)
+	cardUpdateRepo := article.NewWBCardUpdateRepository(db)
+	operationRepo := operation.NewOperationRepository(db)
 
 	// Инициализируем сервис
-	wbService := wb.NewWBService(userRepo, statsGetRepo, statRepo, articlesGetRepo, articleRepo)
+	wbService := wb.NewWBService(userRepo, accountRepo, statsGetRepo, statRepo, articlesGetRepo, articleRepo, cardUpdateRepo, operationRepo)
 
 	// Определяем интервал
 	if interval == 0 {
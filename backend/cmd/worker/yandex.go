@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"wbrost-go/internal/config"
+	"wbrost-go/internal/crypto"
+	"wbrost-go/internal/repository/account"
+	"wbrost-go/internal/repository/article"
+	"wbrost-go/internal/repository/database/postgres"
+	"wbrost-go/internal/repository/operation"
+	yandexrepo "wbrost-go/internal/repository/yandex"
+	"wbrost-go/internal/service/yandex"
+)
+
+func main() {
+	// Флаги командной строки
+	var runOnce bool
+	var interval int
+
+	flag.BoolVar(&runOnce, "once", false, "Запустить один раз и выйти")
+	flag.IntVar(&interval, "interval", 60, "Интервал в секундах между запусками")
+	flag.Parse()
+
+	// Загружаем конфиг
+	cfg := config.Load()
+
+	// Инициализируем БД
+	db, err := postgres.NewPostgresDB(cfg.GetDBConnectionString())
+	if err != nil {
+		log.Fatalf("Ошибка подключения к БД: %v", err)
+	}
+	defer db.Close()
+
+	fmt.Println("✓ Подключение к БД установлено")
+
+	// Загружаем мастер-ключи для шифрования API ключей маркетплейсов
+	keyring, err := crypto.NewKeyring(cfg.Encryption.Keys, cfg.Encryption.CurrentVersion)
+	if err != nil {
+		log.Fatalf("Ошибка загрузки ключей шифрования: %v", err)
+	}
+
+	// Инициализируем репозитории
+	accountRepo := account.NewSellerAccountRepository(db, keyring)
+	jobRepo := yandexrepo.NewYandexGetRepository(db)
+	articleRepo := article.NewWBArticlesRepository(db)
+	operationRepo := operation.NewOperationRepository(db)
+
+	// Инициализируем сервис
+	yandexService := yandex.NewYandexService(accountRepo, jobRepo, articleRepo, operationRepo)
+
+	if interval == 0 {
+		interval = cfg.Worker.Interval
+	}
+
+	if runOnce {
+		start := time.Now()
+		fmt.Println("🚀 Запуск обработки заданий Яндекс Маркета...")
+		if err := yandexService.ProcessPendingJobs(); err != nil {
+			log.Printf("❌ Ошибка обработки: %v", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Обработка завершена - заняло по времени: %v\n", time.Since(start))
+		os.Exit(0)
+	}
+
+	// Запускаем как демон
+	fmt.Printf("🔄 Запуск воркера Яндекс Маркета с интервалом %d секунд...\n", interval)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	// Первый запуск сразу
+	fmt.Println("🎯 Первоначальная обработка...")
+	if err := yandexService.ProcessPendingJobs(); err != nil {
+		log.Printf("⚠️ Ошибка при первоначальной обработке: %v", err)
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			fmt.Printf("\n⏰ Запуск обработки в %s\n", time.Now().Format("2006-01-02 15:04:05"))
+			start := time.Now()
+			if err := yandexService.ProcessPendingJobs(); err != nil {
+				log.Printf("⚠️ Ошибка обработки: %v", err)
+			}
+			fmt.Printf("✅ Обработка завершена за %v\n", time.Since(start))
+			fmt.Printf("💤 Следующий запуск через %d секунд...\n", interval)
+
+		case sig := <-sigChan:
+			fmt.Printf("\n🛑 Получен сигнал: %v. Завершение работы...\n", sig)
+			return
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package ozon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// BaseURL - Ozon Seller API
+const BaseURL = "https://api-seller.ozon.ru"
+
+// Эндпоинты Ozon Seller API
+const (
+	EndpointProductList     = "/v3/product/list"
+	EndpointProductInfoList = "/v3/product/info/list"
+	EndpointTransactionList = "/v3/finance/transaction/list"
+)
+
+// Лимиты страниц
+const (
+	ProductListLimit     = 1000
+	ProductInfoLimit     = 1000
+	TransactionPageLimit = 1000
+)
+
+// TransactionDateFormat - формат дат в фильтре финансовых операций
+const TransactionDateFormat = "2006-01-02T15:04:05.000Z"
+
+// Client клиент для работы с Ozon Seller API (авторизация парой Client-Id + Api-Key)
+type Client struct {
+	ClientID string
+	APIKey   string
+	BaseURL  string
+	Client   *http.Client
+}
+
+// NewOzonClient создает новый клиент
+func NewOzonClient(clientID, apiKey string) *Client {
+	return &Client{
+		ClientID: clientID,
+		APIKey:   apiKey,
+		BaseURL:  BaseURL,
+		Client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// CheckKey проверяет пару Client-Id + Api-Key запросом одной страницы товаров.
+// 200 - ключ рабочий, 401/403 - неверный ключ, остальное - ошибка
+func (c *Client) CheckKey() (bool, error) {
+	req := ProductListRequest{Filter: ProductListFilter{Visibility: "ALL"}, Limit: 1}
+
+	status, body, err := c.post(EndpointProductList, req)
+	if err != nil {
+		return false, err
+	}
+
+	switch status {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return false, nil
+	default:
+		return false, fmt.Errorf("ozon API вернул статус %d: %s", status, errorMessage(body))
+	}
+}
+
+// ProductList возвращает страницу списка товаров (lastID = "" - первая страница)
+func (c *Client) ProductList(lastID string, limit int) (*ProductListResponse, error) {
+	req := ProductListRequest{Filter: ProductListFilter{Visibility: "ALL"}, LastID: lastID, Limit: limit}
+
+	var resp ProductListResponse
+	if err := c.call(EndpointProductList, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ProductInfoList возвращает карточки товаров по их product_id
+func (c *Client) ProductInfoList(productIDs []int64) ([]ProductInfo, error) {
+	var resp ProductInfoResponse
+	if err := c.call(EndpointProductInfoList, ProductInfoRequest{ProductID: productIDs}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// TransactionList возвращает страницу финансовых операций за период (не больше месяца)
+func (c *Client) TransactionList(from, to time.Time, page, pageSize int) (*TransactionListResponse, error) {
+	req := TransactionListRequest{Page: page, PageSize: pageSize}
+	req.Filter.Date.From = from.UTC().Format(TransactionDateFormat)
+	req.Filter.Date.To = to.UTC().Format(TransactionDateFormat)
+	req.Filter.OperationType = []string{}
+	req.Filter.TransactionType = "all"
+
+	var resp TransactionListResponse
+	if err := c.call(EndpointTransactionList, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// call выполняет запрос и разбирает успешный ответ в out
+func (c *Client) call(endpoint string, payload interface{}, out interface{}) error {
+	status, body, err := c.post(endpoint, payload)
+	if err != nil {
+		return err
+	}
+
+	if status != http.StatusOK {
+		return fmt.Errorf("ozon API %s вернул статус %d: %s", endpoint, status, errorMessage(body))
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("ошибка разбора ответа %s: %v", endpoint, err)
+	}
+
+	return nil
+}
+
+// post отправляет POST запрос с заголовками авторизации Ozon
+func (c *Client) post(endpoint string, payload interface{}) (int, []byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0, nil, fmt.Errorf("ошибка формирования запроса: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", c.BaseURL+endpoint, bytes.NewReader(data))
+	if err != nil {
+		return 0, nil, fmt.Errorf("ошибка создания запроса: %v", err)
+	}
+
+	req.Header.Set("Client-Id", c.ClientID)
+	req.Header.Set("Api-Key", c.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("ошибка сети: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, fmt.Errorf("ошибка чтения ответа: %v", err)
+	}
+
+	return resp.StatusCode, body, nil
+}
+
+// errorMessage достает текст ошибки из ответа Ozon
+func errorMessage(body []byte) string {
+	var e ErrorResponse
+	if err := json.Unmarshal(body, &e); err == nil && e.Message != "" {
+		return e.Message
+	}
+	if len(body) > 200 {
+		return string(body[:200])
+	}
+	return string(body)
+}
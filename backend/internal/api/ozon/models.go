@@ -0,0 +1,118 @@
+package ozon
+
+// ProductListRequest - запрос /v3/product/list
+type ProductListRequest struct {
+	Filter ProductListFilter `json:"filter"`
+	LastID string            `json:"last_id"`
+	Limit  int               `json:"limit"`
+}
+
+// ProductListFilter - фильтр списка товаров (ALL - включая архивные)
+type ProductListFilter struct {
+	Visibility string `json:"visibility"`
+}
+
+// ProductListResponse - ответ /v3/product/list
+type ProductListResponse struct {
+	Result struct {
+		Items []struct {
+			ProductID int64  `json:"product_id"`
+			OfferID   string `json:"offer_id"`
+			Archived  bool   `json:"archived"`
+		} `json:"items"`
+		Total  int    `json:"total"`
+		LastID string `json:"last_id"`
+	} `json:"result"`
+}
+
+// ProductInfo - карточка товара из /v3/product/info/list
+type ProductInfo struct {
+	ID            int64    `json:"id"`
+	Name          string   `json:"name"`
+	OfferID       string   `json:"offer_id"`
+	Barcodes      []string `json:"barcodes"`
+	PrimaryImage  []string `json:"primary_image"`
+	Images        []string `json:"images"`
+	Price         string   `json:"price"`
+	OldPrice      string   `json:"old_price"`
+	CurrencyCode  string   `json:"currency_code"`
+	IsArchived    bool     `json:"is_archived"`
+	IsAutoarchive bool     `json:"is_autoarchived"`
+	Sources       []struct {
+		Sku    int64  `json:"sku"`
+		Source string `json:"source"`
+	} `json:"sources"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// ProductInfoRequest - запрос /v3/product/info/list
+type ProductInfoRequest struct {
+	ProductID []int64 `json:"product_id"`
+}
+
+// ProductInfoResponse - ответ /v3/product/info/list
+type ProductInfoResponse struct {
+	Items []ProductInfo `json:"items"`
+}
+
+// TransactionListRequest - запрос /v3/finance/transaction/list
+type TransactionListRequest struct {
+	Filter   TransactionFilter `json:"filter"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+}
+
+// TransactionFilter - фильтр финансовых операций. Период - не больше одного месяца
+type TransactionFilter struct {
+	Date struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	} `json:"date"`
+	OperationType   []string `json:"operation_type"`
+	PostingNumber   string   `json:"posting_number"`
+	TransactionType string   `json:"transaction_type"`
+}
+
+// Transaction - финансовая операция Ozon
+type Transaction struct {
+	OperationID          int64   `json:"operation_id"`
+	OperationType        string  `json:"operation_type"`
+	OperationDate        string  `json:"operation_date"`
+	OperationTypeName    string  `json:"operation_type_name"`
+	DeliveryCharge       float64 `json:"delivery_charge"`
+	ReturnDeliveryCharge float64 `json:"return_delivery_charge"`
+	AccrualsForSale      float64 `json:"accruals_for_sale"`
+	SaleCommission       float64 `json:"sale_commission"`
+	Amount               float64 `json:"amount"`
+	Type                 string  `json:"type"`
+	Posting              struct {
+		DeliverySchema string `json:"delivery_schema"`
+		OrderDate      string `json:"order_date"`
+		PostingNumber  string `json:"posting_number"`
+		WarehouseID    int64  `json:"warehouse_id"`
+	} `json:"posting"`
+	Items []struct {
+		Name string `json:"name"`
+		Sku  int64  `json:"sku"`
+	} `json:"items"`
+	Services []struct {
+		Name  string  `json:"name"`
+		Price float64 `json:"price"`
+	} `json:"services"`
+}
+
+// TransactionListResponse - ответ /v3/finance/transaction/list
+type TransactionListResponse struct {
+	Result struct {
+		Operations []Transaction `json:"operations"`
+		PageCount  int           `json:"page_count"`
+		RowCount   int           `json:"row_count"`
+	} `json:"result"`
+}
+
+// ErrorResponse - ответ Ozon с ошибкой
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
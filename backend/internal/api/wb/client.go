@@ -24,11 +24,37 @@ func NewWBClient(token string) *Client {
 	}
 }
 
-// CheckToken проверяет валидность токена через API WB
+// CheckToken проверяет валидность токена через API WB.
+// Запрос идет в /ping первой категории, доступной токену, поэтому токены
+// без категории "Маркетплейс" тоже проходят проверку
 func (c *Client) CheckToken() (bool, error) {
-	// Используем конструктор URL!
-	url := URLFor(Passes)
+	info, err := ParseToken(c.Token)
+	if err != nil {
+		return false, err
+	}
+
+	for _, scope := range info.GrantedScopes() {
+		if PingURL(scope) != "" {
+			return c.CheckTokenFor(scope)
+		}
+	}
+
+	// Нет категорий с /ping - проверяем по-старому через пропуска
+	return c.check(URLFor(Passes))
+}
+
+// CheckTokenFor проверяет, что токен работает для указанной категории WB API
+func (c *Client) CheckTokenFor(scope Scope) (bool, error) {
+	url := PingURL(scope)
+	if url == "" {
+		return false, fmt.Errorf("для категории %d нет эндпоинта проверки", scope)
+	}
 
+	return c.check(url)
+}
+
+// check выполняет GET запрос к url с токеном и интерпретирует статус ответа
+func (c *Client) check(url string) (bool, error) {
 	// Создаем запрос
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -55,12 +81,6 @@ func (c *Client) CheckToken() (bool, error) {
 	// Если 429 - лимит запросов (но токен валиден!)
 	switch resp.StatusCode {
 	case 200:
-		// Проверяем что это действительно массив пропусков
-		var passes []Pass
-		if err := json.Unmarshal(body, &passes); err != nil {
-			// Если не массив пропусков, но статус 200 - всё равно валиден
-			return true, nil
-		}
 		return true, nil
 
 	case 401, 403:
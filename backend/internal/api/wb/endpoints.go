@@ -13,12 +13,18 @@ const (
 	EndpointDetailsV1     = "api/v1/supplier/reportDetailByPeriod"
 	EndpointDetailsV5     = "api/v5/supplier/reportDetailByPeriod"
 	EndpointOrders        = "api/v1/supplier/orders"
+	EndpointStocks        = "api/v1/supplier/stocks"
 	EndpointTaskCreate    = "api/v1/delayed-gen/tasks/create"
 	EndpointTaskStatus    = "api/v1/delayed-gen/tasks"
 	EndpointTaskDownload  = "api/v1/delayed-gen/tasks/download"
 	EndpointCardsList     = "content/v2/get/cards/list"
+	EndpointCardsTrash    = "content/v2/get/cards/trash"
+	EndpointCardsUpdate   = "content/v2/cards/update"
+	EndpointCardsErrors   = "content/v2/cards/error/list"
+	EndpointSubjectCharcs = "content/v2/object/charcs" // + /{subjectId}
 	EndpointDetailHistory = "api/v2/nm-report/detail/history"
 	EndpointPasses        = "api/v3/passes"
+	EndpointPing          = "ping"
 )
 
 // Endpoint тип для эндпоинтов API Wildberries
@@ -29,22 +35,28 @@ const (
 	DetailsV1     Endpoint = EndpointDetailsV1
 	DetailsV5     Endpoint = EndpointDetailsV5
 	Orders        Endpoint = EndpointOrders
+	Stocks        Endpoint = EndpointStocks
 	TaskCreate    Endpoint = EndpointTaskCreate
 	TaskStatus    Endpoint = EndpointTaskStatus
 	TaskDownload  Endpoint = EndpointTaskDownload
 	CardsList     Endpoint = EndpointCardsList
+	CardsTrash    Endpoint = EndpointCardsTrash
+	CardsUpdate   Endpoint = EndpointCardsUpdate
+	CardsErrors   Endpoint = EndpointCardsErrors
+	SubjectCharcs Endpoint = EndpointSubjectCharcs
 	DetailHistory Endpoint = EndpointDetailHistory
 	Passes        Endpoint = EndpointPasses
+	Ping          Endpoint = EndpointPing
 )
 
 // URLFor возвращает полный URL для указанного эндпоинта
 func URLFor(endpoint Endpoint) string {
 	switch endpoint {
-	case Incomes, DetailsV1, Orders, TaskCreate, TaskStatus, TaskDownload:
+	case Incomes, DetailsV1, TaskCreate, TaskStatus, TaskDownload:
 		return BaseURLStats + string(endpoint)
-	case DetailsV5:
+	case DetailsV5, Orders, Stocks:
 		return BaseURLStatsNew + string(endpoint)
-	case CardsList, DetailHistory:
+	case CardsList, CardsTrash, CardsUpdate, CardsErrors, SubjectCharcs, DetailHistory:
 		return BaseURLCard + string(endpoint)
 	case Passes:
 		return BaseURLMarketplace + string(endpoint)
@@ -54,6 +66,23 @@ func URLFor(endpoint Endpoint) string {
 	}
 }
 
+// PingURL возвращает URL проверки подключения для категории токена
+// (у каждой категории WB API свой домен и свой /ping)
+func PingURL(scope Scope) string {
+	switch scope {
+	case ScopeContent:
+		return BaseURLCard + string(Ping)
+	case ScopeAnalytics:
+		return BaseURLContent + string(Ping)
+	case ScopeMarketplace:
+		return BaseURLMarketplace + string(Ping)
+	case ScopeStatistics:
+		return BaseURLStatsNew + string(Ping)
+	default:
+		return ""
+	}
+}
+
 // BaseURLs возвращает все базовые URL в виде map
 func BaseURLs() map[string]string {
 	return map[string]string{
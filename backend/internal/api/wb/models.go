@@ -1,5 +1,7 @@
 package wb
 
+import "encoding/json"
+
 // Article - структура для ответа API карточек WB
 type Article struct {
 	NmID        int    `json:"nmID"`
@@ -24,8 +26,26 @@ type Article struct {
 		Skus     []string `json:"skus"`
 		WbSize   string   `json:"wbSize"`
 	} `json:"sizes"`
-	CreatedAt string `json:"createdAt"`
-	UpdatedAt string `json:"updatedAt"`
+	Characteristics []ArticleCharacteristic `json:"characteristics"`
+	Dimensions      ArticleDimensions       `json:"dimensions"`
+	CreatedAt       string                  `json:"createdAt"`
+	UpdatedAt       string                  `json:"updatedAt"`
+}
+
+// ArticleCharacteristic - характеристика карточки. Value - строка, число или массив строк
+type ArticleCharacteristic struct {
+	ID    int             `json:"id"`
+	Name  string          `json:"name"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ArticleDimensions - габариты упаковки (см) и вес с упаковкой (кг)
+type ArticleDimensions struct {
+	Length       float64 `json:"length"`
+	Width        float64 `json:"width"`
+	Height       float64 `json:"height"`
+	WeightBrutto float64 `json:"weightBrutto"`
+	IsValid      bool    `json:"isValid"`
 }
 
 // ArticleResponse - структура полного ответа API
@@ -45,19 +65,129 @@ type ArticleRequestCursor struct {
 	NmID      int    `json:"nmID,omitempty"`
 }
 
+// ArticleRequestSort - сортировка по updatedAt (ascending - от старых изменений к новым)
+type ArticleRequestSort struct {
+	Ascending bool `json:"ascending"`
+}
+
 // ArticleRequestSettings - настройки запроса
 type ArticleRequestSettings struct {
+	Sort   *ArticleRequestSort  `json:"sort,omitempty"`
 	Cursor ArticleRequestCursor `json:"cursor"`
 	Filter struct {
 		WithPhoto int `json:"withPhoto"`
 	} `json:"filter"`
 }
 
+// TrashRequest - запрос карточек в корзине
+type TrashRequest struct {
+	Settings struct {
+		Cursor TrashCursor `json:"cursor"`
+	} `json:"settings"`
+}
+
+// TrashCursor - курсор пагинации корзины
+type TrashCursor struct {
+	Limit     int    `json:"limit,omitempty"`
+	TrashedAt string `json:"trashedAt,omitempty"`
+	NmID      int    `json:"nmID,omitempty"`
+	Total     int    `json:"total,omitempty"`
+}
+
+// TrashResponse - карточки в корзине (поля карточки как в списке)
+type TrashResponse struct {
+	Cards []struct {
+		NmID       int    `json:"nmID"`
+		VendorCode string `json:"vendorCode"`
+		TrashedAt  string `json:"trashedAt"`
+	} `json:"cards"`
+	Cursor TrashCursor `json:"cursor"`
+}
+
 // ArticleRequest - полный запрос
 type ArticleRequest struct {
 	Settings ArticleRequestSettings `json:"settings"`
 }
 
+// CardUpdate - карточка для content/v2/cards/update. WB заменяет карточку целиком:
+// характеристики и размеры, которых нет в запросе, удаляются
+type CardUpdate struct {
+	NmID            int                        `json:"nmID"`
+	VendorCode      string                     `json:"vendorCode"`
+	Brand           string                     `json:"brand,omitempty"`
+	Title           string                     `json:"title"`
+	Description     string                     `json:"description"`
+	Dimensions      CardUpdateDimensions       `json:"dimensions"`
+	Characteristics []CardUpdateCharacteristic `json:"characteristics"`
+	Sizes           []CardUpdateSize           `json:"sizes"`
+}
+
+// CardUpdateDimensions - габариты упаковки (см) и вес с упаковкой (кг)
+type CardUpdateDimensions struct {
+	Length       float64 `json:"length"`
+	Width        float64 `json:"width"`
+	Height       float64 `json:"height"`
+	WeightBrutto float64 `json:"weightBrutto"`
+}
+
+// CardUpdateCharacteristic - значение характеристики (строка, число или массив строк)
+type CardUpdateCharacteristic struct {
+	ID    int             `json:"id"`
+	Value json.RawMessage `json:"value"`
+}
+
+// CardUpdateSize - размер карточки (chrtID обязателен для существующих размеров)
+type CardUpdateSize struct {
+	ChrtID   int      `json:"chrtID"`
+	TechSize string   `json:"techSize"`
+	WbSize   string   `json:"wbSize"`
+	Skus     []string `json:"skus"`
+}
+
+// ContentResponse - общий ответ методов изменения API контента
+type ContentResponse struct {
+	Data             json.RawMessage `json:"data"`
+	Error            bool            `json:"error"`
+	ErrorText        string          `json:"errorText"`
+	AdditionalErrors json.RawMessage `json:"additionalErrors"`
+}
+
+// CardError - ошибка WB при асинхронной обработке карточки (content/v2/cards/error/list)
+type CardError struct {
+	Object     string   `json:"object"`
+	VendorCode string   `json:"vendorCode"`
+	UpdatedAt  string   `json:"updatedAt"`
+	Errors     []string `json:"errors"`
+	ObjectID   int      `json:"objectID"`
+}
+
+// CardErrorsResponse - список несозданных и необновленных карточек с ошибками
+type CardErrorsResponse struct {
+	Data      []CardError `json:"data"`
+	Error     bool        `json:"error"`
+	ErrorText string      `json:"errorText"`
+}
+
+// SubjectCharacteristic - характеристика предмета (content/v2/object/charcs/{subjectId})
+type SubjectCharacteristic struct {
+	CharcID     int    `json:"charcID"`
+	SubjectName string `json:"subjectName"`
+	SubjectID   int    `json:"subjectID"`
+	Name        string `json:"name"`
+	Required    bool   `json:"required"`
+	UnitName    string `json:"unitName"`
+	MaxCount    int    `json:"maxCount"`
+	Popular     bool   `json:"popular"`
+	CharcType   int    `json:"charcType"`
+}
+
+// SubjectCharacteristicsResponse - характеристики предмета
+type SubjectCharacteristicsResponse struct {
+	Data      []SubjectCharacteristic `json:"data"`
+	Error     bool                    `json:"error"`
+	ErrorText string                  `json:"errorText"`
+}
+
 // PassesResponse структура для ответа проверки токена
 type PassesResponse []struct {
 	Status int         `json:"status"`
@@ -86,3 +216,34 @@ type Pass struct {
 	OfficeID      int    `json:"officeId"`
 	DateEnd       string `json:"dateEnd"`
 }
+
+// Order - заказ из /api/v1/supplier/orders (статистика)
+type Order struct {
+	Date            string  `json:"date"`
+	LastChangeDate  string  `json:"lastChangeDate"`
+	WarehouseName   string  `json:"warehouseName"`
+	RegionName      string  `json:"regionName"`
+	SupplierArticle string  `json:"supplierArticle"`
+	NmID            int64   `json:"nmId"`
+	Barcode         string  `json:"barcode"`
+	TechSize        string  `json:"techSize"`
+	TotalPrice      float64 `json:"totalPrice"`
+	DiscountPercent float64 `json:"discountPercent"`
+	PriceWithDisc   float64 `json:"priceWithDisc"`
+	IsCancel        bool    `json:"isCancel"`
+	Srid            string  `json:"srid"`
+}
+
+// Stock - остаток на складе WB из /api/v1/supplier/stocks (статистика)
+type Stock struct {
+	LastChangeDate  string `json:"lastChangeDate"`
+	WarehouseName   string `json:"warehouseName"`
+	SupplierArticle string `json:"supplierArticle"`
+	NmID            int64  `json:"nmId"`
+	Barcode         string `json:"barcode"`
+	TechSize        string `json:"techSize"`
+	Quantity        int    `json:"quantity"`
+	InWayToClient   int    `json:"inWayToClient"`
+	InWayFromClient int    `json:"inWayFromClient"`
+	QuantityFull    int    `json:"quantityFull"`
+}
@@ -0,0 +1,153 @@
+package wb
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Scope - номер бита категории в маске "s" токена WB
+type Scope int
+
+// Категории доступа токена WB (номер бита в маске "s")
+const (
+	ScopeContent     Scope = 1  // Контент
+	ScopeAnalytics   Scope = 2  // Аналитика
+	ScopePrices      Scope = 3  // Цены и скидки
+	ScopeMarketplace Scope = 4  // Маркетплейс
+	ScopeStatistics  Scope = 5  // Статистика
+	ScopePromotion   Scope = 6  // Продвижение
+	ScopeFeedbacks   Scope = 7  // Вопросы и отзывы
+	ScopeChat        Scope = 9  // Чат с покупателями
+	ScopeSupplies    Scope = 10 // Поставки
+	ScopeReturns     Scope = 11 // Возвраты покупателями
+	ScopeDocuments   Scope = 12 // Документы
+	ScopeFinance     Scope = 13 // Финансы
+	ScopeReadOnly    Scope = 30 // Токен только на чтение
+)
+
+// ScopeNames - человекочитаемые названия категорий
+var ScopeNames = map[Scope]string{
+	ScopeContent:     "Контент",
+	ScopeAnalytics:   "Аналитика",
+	ScopePrices:      "Цены и скидки",
+	ScopeMarketplace: "Маркетплейс",
+	ScopeStatistics:  "Статистика",
+	ScopePromotion:   "Продвижение",
+	ScopeFeedbacks:   "Вопросы и отзывы",
+	ScopeChat:        "Чат с покупателями",
+	ScopeSupplies:    "Поставки",
+	ScopeReturns:     "Возвраты покупателями",
+	ScopeDocuments:   "Документы",
+	ScopeFinance:     "Финансы",
+}
+
+// scopeOrder - порядок вывода категорий
+var scopeOrder = []Scope{
+	ScopeContent, ScopeAnalytics, ScopePrices, ScopeMarketplace, ScopeStatistics, ScopePromotion,
+	ScopeFeedbacks, ScopeChat, ScopeSupplies, ScopeReturns, ScopeDocuments, ScopeFinance,
+}
+
+// Feature - функция приложения, которой нужен доступ к определенной категории WB API
+type Feature struct {
+	Code  string
+	Name  string
+	Scope Scope
+}
+
+// Features - функции приложения и категории токена, которые им нужны
+var Features = []Feature{
+	{Code: "stats", Name: "Отчеты о продажах (детализация реализации)", Scope: ScopeStatistics},
+	{Code: "articles", Name: "Карточки товаров", Scope: ScopeContent},
+	{Code: "analytics", Name: "Воронка продаж и аналитика карточек", Scope: ScopeAnalytics},
+}
+
+// TokenInfo - данные, извлеченные из payload токена WB
+type TokenInfo struct {
+	ExpiresAt  time.Time
+	Scopes     int64  // Битовая маска категорий ("s")
+	SellerID   int64  // ID продавца ("oid")
+	SellerUUID string // UUID продавца ("sid")
+	Test       bool   // Токен тестового контура ("t")
+}
+
+// tokenPayload - payload JWT токена WB
+type tokenPayload struct {
+	Exp int64  `json:"exp"`
+	S   int64  `json:"s"`
+	Oid int64  `json:"oid"`
+	Sid string `json:"sid"`
+	T   bool   `json:"t"`
+}
+
+// ParseToken декодирует payload токена WB (без проверки подписи - ее проверяет сам WB)
+func ParseToken(token string) (*TokenInfo, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("неверный формат токена: ожидается JWT из трех частей")
+	}
+	for _, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("неверный формат токена: пустая часть JWT")
+		}
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось декодировать payload токена: %w", err)
+	}
+
+	var payload tokenPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, fmt.Errorf("не удалось разобрать payload токена: %w", err)
+	}
+
+	info := &TokenInfo{
+		Scopes:     payload.S,
+		SellerID:   payload.Oid,
+		SellerUUID: payload.Sid,
+		Test:       payload.T,
+	}
+	if payload.Exp > 0 {
+		info.ExpiresAt = time.Unix(payload.Exp, 0)
+	}
+
+	return info, nil
+}
+
+// HasScope проверяет, выдан ли токену доступ к категории
+func (t *TokenInfo) HasScope(scope Scope) bool {
+	return t.Scopes&(1<<uint(scope)) != 0
+}
+
+// ReadOnly - токен выпущен только на чтение
+func (t *TokenInfo) ReadOnly() bool {
+	return t.HasScope(ScopeReadOnly)
+}
+
+// GrantedScopes возвращает список категорий, доступных токену
+func (t *TokenInfo) GrantedScopes() []Scope {
+	var scopes []Scope
+	for _, scope := range scopeOrder {
+		if t.HasScope(scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// Expired - истек ли срок действия токена
+func (t *TokenInfo) Expired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && !now.Before(t.ExpiresAt)
+}
+
+// DaysLeft - сколько полных дней осталось до истечения токена (0, если истек)
+func (t *TokenInfo) DaysLeft(now time.Time) int {
+	if t.ExpiresAt.IsZero() || t.Expired(now) {
+		return 0
+	}
+	return int(math.Floor(t.ExpiresAt.Sub(now).Hours() / 24))
+}
@@ -0,0 +1,140 @@
+package wb
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testToken собирает JWT с заданным payload (подпись не проверяется)
+func testToken(payload string) string {
+	return "eyJhbGciOiJFUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".sig"
+}
+
+func TestParseToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+		want    TokenInfo
+	}{
+		{
+			name:  "full payload",
+			token: testToken(`{"exp":1767225600,"s":1073741856,"oid":12345,"sid":"abc-uuid","t":true}`),
+			want: TokenInfo{
+				ExpiresAt:  time.Unix(1767225600, 0),
+				Scopes:     1073741856,
+				SellerID:   12345,
+				SellerUUID: "abc-uuid",
+				Test:       true,
+			},
+		},
+		{
+			name:  "without exp",
+			token: testToken(`{"s":2}`),
+			want:  TokenInfo{Scopes: 2},
+		},
+		{
+			name:  "padded payload and spaces",
+			token: "  " + strings.Replace(testToken(`{"s":4}`), ".sig", "", 1) + "==.sig  ",
+			want:  TokenInfo{Scopes: 4},
+		},
+		{name: "two parts", token: "a.b", wantErr: true},
+		{name: "empty part", token: "a..c", wantErr: true},
+		{name: "bad base64", token: "a.!!!.c", wantErr: true},
+		{name: "bad json", token: testToken(`not json`), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ParseToken(tt.token)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseToken() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseToken() error = %v", err)
+			}
+			if !info.ExpiresAt.Equal(tt.want.ExpiresAt) || info.Scopes != tt.want.Scopes || info.SellerID != tt.want.SellerID ||
+				info.SellerUUID != tt.want.SellerUUID || info.Test != tt.want.Test {
+				t.Errorf("ParseToken() = %+v, want %+v", *info, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenInfoScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		scopes   int64
+		granted  []Scope
+		readOnly bool
+	}{
+		{name: "no scopes", scopes: 0},
+		{name: "content", scopes: 1 << 1, granted: []Scope{ScopeContent}},
+		{name: "bit 0 is not a scope", scopes: 1, granted: nil},
+		{
+			name:     "statistics and analytics read only",
+			scopes:   1<<5 | 1<<2 | 1<<30,
+			granted:  []Scope{ScopeAnalytics, ScopeStatistics},
+			readOnly: true,
+		},
+		{
+			name:    "finance and chat",
+			scopes:  1<<13 | 1<<9,
+			granted: []Scope{ScopeChat, ScopeFinance},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &TokenInfo{Scopes: tt.scopes}
+			granted := info.GrantedScopes()
+			if len(granted) != len(tt.granted) {
+				t.Fatalf("GrantedScopes() = %v, want %v", granted, tt.granted)
+			}
+			for i := range granted {
+				if granted[i] != tt.granted[i] {
+					t.Fatalf("GrantedScopes() = %v, want %v", granted, tt.granted)
+				}
+				if !info.HasScope(granted[i]) {
+					t.Errorf("HasScope(%d) = false, want true", granted[i])
+				}
+			}
+			if info.ReadOnly() != tt.readOnly {
+				t.Errorf("ReadOnly() = %v, want %v", info.ReadOnly(), tt.readOnly)
+			}
+		})
+	}
+}
+
+func TestTokenInfoExpiry(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		expiresAt time.Time
+		expired   bool
+		daysLeft  int
+	}{
+		{name: "no expiry", expiresAt: time.Time{}, expired: false, daysLeft: 0},
+		{name: "expired", expiresAt: now.Add(-time.Hour), expired: true, daysLeft: 0},
+		{name: "expires now", expiresAt: now, expired: true, daysLeft: 0},
+		{name: "less than a day", expiresAt: now.Add(23 * time.Hour), expired: false, daysLeft: 0},
+		{name: "ten and a half days", expiresAt: now.Add(10*24*time.Hour + 12*time.Hour), expired: false, daysLeft: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &TokenInfo{ExpiresAt: tt.expiresAt}
+			if got := info.Expired(now); got != tt.expired {
+				t.Errorf("Expired() = %v, want %v", got, tt.expired)
+			}
+			if got := info.DaysLeft(now); got != tt.daysLeft {
+				t.Errorf("DaysLeft() = %d, want %d", got, tt.daysLeft)
+			}
+		})
+	}
+}
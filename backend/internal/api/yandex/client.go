@@ -0,0 +1,175 @@
+package yandex
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// BaseURL - API Яндекс Маркета для партнеров
+const BaseURL = "https://api.partner.market.yandex.ru"
+
+// Эндпоинты API Яндекс Маркета (%d - businessId или campaignId)
+const (
+	EndpointCampaign      = "/campaigns/%d"
+	EndpointOfferMappings = "/businesses/%d/offer-mappings"
+	EndpointStatsOrders   = "/campaigns/%d/stats/orders"
+)
+
+// Лимиты страниц
+const (
+	OfferMappingsPageLimit = 200
+	StatsOrdersPageLimit   = 200
+)
+
+// StatusEnhanceYourCalm - Яндекс Маркет отвечает 420 при превышении лимита запросов
+const StatusEnhanceYourCalm = 420
+
+// DateFormat - формат дат в фильтрах отчетов
+const DateFormat = "2006-01-02"
+
+// ErrRateLimited - превышен лимит запросов (HTTP 420/429)
+var ErrRateLimited = fmt.Errorf("yandex market: too many requests")
+
+// Client клиент для работы с API Яндекс Маркета (авторизация заголовком Api-Key)
+type Client struct {
+	APIKey  string
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewYandexClient создает новый клиент
+func NewYandexClient(apiKey string) *Client {
+	return &Client{
+		APIKey:  apiKey,
+		BaseURL: BaseURL,
+		Client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// CheckKey проверяет ключ запросом информации о магазине.
+// 200 - ключ рабочий, 401/403 - неверный ключ или нет доступа к магазину, остальное - ошибка
+func (c *Client) CheckKey(campaignID int64) (bool, error) {
+	status, body, err := c.do("GET", fmt.Sprintf(EndpointCampaign, campaignID), nil)
+	if err != nil {
+		return false, err
+	}
+
+	switch status {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return false, nil
+	default:
+		return false, fmt.Errorf("yandex market API вернул статус %d: %s", status, errorMessage(body))
+	}
+}
+
+// OfferMappings возвращает страницу товаров кабинета (pageToken = "" - первая страница)
+func (c *Client) OfferMappings(businessID int64, pageToken string) (*OfferMappingsResponse, error) {
+	endpoint := fmt.Sprintf(EndpointOfferMappings, businessID) + pageQuery(OfferMappingsPageLimit, pageToken)
+
+	var resp OfferMappingsResponse
+	if err := c.call("POST", endpoint, OfferMappingsRequest{}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// StatsOrders возвращает страницу отчета по заказам магазина с комиссиями и услугами
+func (c *Client) StatsOrders(campaignID int64, from, to time.Time, pageToken string) (*StatsOrdersResponse, error) {
+	endpoint := fmt.Sprintf(EndpointStatsOrders, campaignID) + pageQuery(StatsOrdersPageLimit, pageToken)
+	req := StatsOrdersRequest{
+		DateFrom: from.Format(DateFormat),
+		DateTo:   to.Format(DateFormat),
+	}
+
+	var resp StatsOrdersResponse
+	if err := c.call("POST", endpoint, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// call выполняет запрос и разбирает успешный ответ в out
+func (c *Client) call(method, endpoint string, payload interface{}, out interface{}) error {
+	status, body, err := c.do(method, endpoint, payload)
+	if err != nil {
+		return err
+	}
+
+	if status == StatusEnhanceYourCalm || status == http.StatusTooManyRequests {
+		return ErrRateLimited
+	}
+
+	if status != http.StatusOK {
+		return fmt.Errorf("yandex market API %s вернул статус %d: %s", endpoint, status, errorMessage(body))
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("ошибка разбора ответа %s: %v", endpoint, err)
+	}
+
+	return nil
+}
+
+// do отправляет запрос с заголовком авторизации Яндекс Маркета
+func (c *Client) do(method, endpoint string, payload interface{}) (int, []byte, error) {
+	var reader io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return 0, nil, fmt.Errorf("ошибка формирования запроса: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.BaseURL+endpoint, reader)
+	if err != nil {
+		return 0, nil, fmt.Errorf("ошибка создания запроса: %v", err)
+	}
+
+	req.Header.Set("Api-Key", c.APIKey)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("ошибка сети: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, fmt.Errorf("ошибка чтения ответа: %v", err)
+	}
+
+	return resp.StatusCode, body, nil
+}
+
+func pageQuery(limit int, pageToken string) string {
+	query := "?limit=" + strconv.Itoa(limit)
+	if pageToken != "" {
+		query += "&page_token=" + pageToken
+	}
+	return query
+}
+
+// errorMessage достает текст ошибки из ответа Яндекс Маркета
+func errorMessage(body []byte) string {
+	var e ErrorResponse
+	if err := json.Unmarshal(body, &e); err == nil && len(e.Errors) > 0 {
+		return e.Errors[0].Code + ": " + e.Errors[0].Message
+	}
+	if len(body) > 200 {
+		return string(body[:200])
+	}
+	return string(body)
+}
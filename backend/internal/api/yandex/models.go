@@ -0,0 +1,123 @@
+package yandex
+
+// ErrorResponse - ответ Яндекс Маркета с ошибкой
+type ErrorResponse struct {
+	Status string `json:"status"`
+	Errors []struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// Paging - курсор постраничной выдачи
+type Paging struct {
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// OfferMappingsRequest - фильтр списка товаров (пустой - все товары кабинета)
+type OfferMappingsRequest struct {
+	Archived *bool `json:"archived,omitempty"`
+}
+
+// OfferMappingsResponse - ответ /businesses/{businessId}/offer-mappings
+type OfferMappingsResponse struct {
+	Status string `json:"status"`
+	Result struct {
+		Paging        Paging         `json:"paging"`
+		OfferMappings []OfferMapping `json:"offerMappings"`
+	} `json:"result"`
+}
+
+// OfferMapping - товар продавца и его карточка на Маркете
+type OfferMapping struct {
+	Offer   Offer `json:"offer"`
+	Mapping struct {
+		MarketSku     int64  `json:"marketSku"`
+		MarketSkuName string `json:"marketSkuName"`
+		MarketModelID int64  `json:"marketModelId"`
+	} `json:"mapping"`
+}
+
+// Offer - товар продавца
+type Offer struct {
+	OfferID    string   `json:"offerId"`
+	Name       string   `json:"name"`
+	Vendor     string   `json:"vendor"`
+	Pictures   []string `json:"pictures"`
+	Barcodes   []string `json:"barcodes"`
+	Archived   bool     `json:"archived"`
+	BasicPrice *struct {
+		Value      float64 `json:"value"`
+		CurrencyID string  `json:"currencyId"`
+	} `json:"basicPrice"`
+}
+
+// StatsOrdersRequest - период отчета по заказам
+type StatsOrdersRequest struct {
+	DateFrom string `json:"dateFrom"`
+	DateTo   string `json:"dateTo"`
+}
+
+// StatsOrdersResponse - ответ /campaigns/{campaignId}/stats/orders
+type StatsOrdersResponse struct {
+	Status string `json:"status"`
+	Result struct {
+		Paging Paging       `json:"paging"`
+		Orders []StatsOrder `json:"orders"`
+	} `json:"result"`
+}
+
+// StatsOrder - заказ с товарами, комиссиями и услугами Маркета
+type StatsOrder struct {
+	ID               int64             `json:"id"`
+	CreationDate     string            `json:"creationDate"`
+	StatusUpdateDate string            `json:"statusUpdateDate"`
+	Status           string            `json:"status"`
+	PaymentType      string            `json:"paymentType"`
+	DeliveryRegion   *StatsRegion      `json:"deliveryRegion"`
+	Items            []StatsOrderItem  `json:"items"`
+	Commissions      []StatsCommission `json:"commissions"`
+}
+
+// StatsRegion - регион доставки
+type StatsRegion struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// StatsOrderItem - товар в заказе
+type StatsOrderItem struct {
+	OfferName string            `json:"offerName"`
+	MarketSku int64             `json:"marketSku"`
+	ShopSku   string            `json:"shopSku"`
+	Count     int               `json:"count"`
+	Prices    []StatsItemPrice  `json:"prices"`
+	Warehouse *StatsWarehouse   `json:"warehouse"`
+	Details   []StatsItemDetail `json:"details"`
+}
+
+// StatsItemPrice - цена товара (BUYER - цена для покупателя, MARKETPLACE - скидки Маркета)
+type StatsItemPrice struct {
+	Type        string  `json:"type"`
+	CostPerItem float64 `json:"costPerItem"`
+	Total       float64 `json:"total"`
+}
+
+// StatsWarehouse - склад отгрузки
+type StatsWarehouse struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// StatsItemDetail - статус части товаров (DELIVERED, RETURNED, ...)
+type StatsItemDetail struct {
+	ItemStatus string `json:"itemStatus"`
+	ItemCount  int    `json:"itemCount"`
+	UpdateDate string `json:"updateDate"`
+}
+
+// StatsCommission - комиссия или услуга Маркета по заказу
+type StatsCommission struct {
+	Type   string  `json:"type"`
+	Actual float64 `json:"actual"`
+}
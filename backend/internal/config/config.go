@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strings"
 )
 
 type Config struct {
@@ -14,6 +15,11 @@ type Config struct {
 	JWTSecret      string
 	Worker         WorkerConfig
 	AllowedOrigins []string
+	// Адреса или подсети (CIDR) обратных прокси, которым доверяются X-Real-IP и X-Forwarded-For
+	TrustedProxies []string
+	Encryption     EncryptionConfig
+	// Льготный период в днях между запросом удаления аккаунта и удалением данных
+	DeletionGraceDays int
 }
 
 type WorkerConfig struct {
@@ -21,6 +27,11 @@ type WorkerConfig struct {
 	ArticlesInterval int // Интервал опроса на новые события в секундах для карточек товаров
 }
 
+type EncryptionConfig struct {
+	Keys           string // Мастер-ключи AES-256 в формате "1:<base64>,2:<base64>"
+	CurrentVersion int    // Версия ключа для шифрования новых значений (0 = наибольшая)
+}
+
 func Load() *Config {
 	dbPort := os.Getenv("DB_PORT")
 	serverPort := os.Getenv("SERVER_PORT")
@@ -37,6 +48,13 @@ func Load() *Config {
 		allowedOrigins = append(allowedOrigins, extraOrigins)
 	}
 
+	var trustedProxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trustedProxies = append(trustedProxies, proxy)
+		}
+	}
+
 	return &Config{
 		DBHost:         os.Getenv("DB_HOST"),
 		DBPort:         dbPort,
@@ -46,9 +64,15 @@ func Load() *Config {
 		ServerPort:     serverPort,
 		JWTSecret:      os.Getenv("JWT_SECRET"),
 		AllowedOrigins: allowedOrigins,
+		TrustedProxies: trustedProxies,
 		Worker: WorkerConfig{
 			Interval:         getEnvAsInt("WORKER_INTERVAL", 60),
 			ArticlesInterval: getEnvAsInt("WORKER_ARTICLES_INTERVAL", 60),
 		},
+		Encryption: EncryptionConfig{
+			Keys:           os.Getenv("ENCRYPTION_KEYS"),
+			CurrentVersion: getEnvAsInt("ENCRYPTION_KEY_VERSION", 0),
+		},
+		DeletionGraceDays: getEnvAsInt("DELETION_GRACE_DAYS", 30),
 	}
 }
@@ -0,0 +1,189 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// encryptedPattern - формат зашифрованного значения: v<версия ключа>:<base64(nonce|ciphertext)>
+var encryptedPattern = regexp.MustCompile(`^v(\d+):([A-Za-z0-9+/=]+)$`)
+
+// Keyring - набор версионированных мастер-ключей AES-256-GCM.
+// Шифрование всегда идет текущим ключом, расшифровка - ключом из префикса значения,
+// поэтому после ротации старые шифртексты читаются до перешифрования
+type Keyring struct {
+	keys    map[int]cipher.AEAD
+	current int
+}
+
+// NewKeyring создает набор ключей из строки вида "1:<base64>,2:<base64>".
+// Каждый ключ - 32 байта в base64. Если current = 0, текущим считается ключ с наибольшей версией
+func NewKeyring(spec string, current int) (*Keyring, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, fmt.Errorf("ключи шифрования не заданы (ENCRYPTION_KEYS)")
+	}
+
+	k := &Keyring{keys: make(map[int]cipher.AEAD)}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		versionStr, encoded, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("неверный формат ключа %q: ожидается <версия>:<base64>", part)
+		}
+
+		version, err := strconv.Atoi(versionStr)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("неверная версия ключа %q", versionStr)
+		}
+
+		raw, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("ключ версии %d: неверный base64: %w", version, err)
+		}
+		if len(raw) != 32 {
+			return nil, fmt.Errorf("ключ версии %d: ожидается 32 байта, получено %d", version, len(raw))
+		}
+
+		block, err := aes.NewCipher(raw)
+		if err != nil {
+			return nil, fmt.Errorf("ключ версии %d: %w", version, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("ключ версии %d: %w", version, err)
+		}
+
+		k.keys[version] = aead
+	}
+
+	if len(k.keys) == 0 {
+		return nil, fmt.Errorf("ключи шифрования не заданы (ENCRYPTION_KEYS)")
+	}
+
+	if current == 0 {
+		current = k.Versions()[len(k.keys)-1]
+	}
+	if _, ok := k.keys[current]; !ok {
+		return nil, fmt.Errorf("текущая версия ключа %d отсутствует в ENCRYPTION_KEYS", current)
+	}
+	k.current = current
+
+	return k, nil
+}
+
+// Current возвращает версию ключа, которым шифруются новые значения
+func (k *Keyring) Current() int {
+	return k.current
+}
+
+// Versions возвращает все загруженные версии ключей по возрастанию
+func (k *Keyring) Versions() []int {
+	versions := make([]int, 0, len(k.keys))
+	for v := range k.keys {
+		versions = append(versions, v)
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// Encrypt шифрует значение текущим ключом. Пустая строка не шифруется
+func (k *Keyring) Encrypt(plain string) (string, error) {
+	if plain == "" {
+		return "", nil
+	}
+
+	aead := k.keys[k.current]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plain), nil)
+	return fmt.Sprintf("v%d:%s", k.current, base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// Decrypt расшифровывает значение ключом его версии.
+// Значения без префикса версии считаются открытым текстом (записаны до включения шифрования)
+func (k *Keyring) Decrypt(value string) (string, error) {
+	version, payload, ok := parseEncrypted(value)
+	if !ok {
+		return value, nil
+	}
+
+	aead, found := k.keys[version]
+	if !found {
+		return "", fmt.Errorf("ключ шифрования версии %d не загружен", version)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode ciphertext: %w", err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("ciphertext too short")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value (key v%d): %w", version, err)
+	}
+
+	return string(plain), nil
+}
+
+// IsCurrent проверяет, зашифровано ли значение текущим ключом
+func (k *Keyring) IsCurrent(value string) bool {
+	version, _, ok := parseEncrypted(value)
+	return ok && version == k.current
+}
+
+// IsEncrypted проверяет, что значение имеет формат шифртекста
+func IsEncrypted(value string) bool {
+	_, _, ok := parseEncrypted(value)
+	return ok
+}
+
+func parseEncrypted(value string) (int, string, bool) {
+	m := encryptedPattern.FindStringSubmatch(value)
+	if m == nil {
+		return 0, "", false
+	}
+
+	version, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, "", false
+	}
+
+	return version, m[2], true
+}
+
+// Mask скрывает секрет для вывода в API: первые 3 и последние 4 символа, например "eyJ…a9f3"
+func Mask(secret string) string {
+	if secret == "" {
+		return ""
+	}
+
+	runes := []rune(secret)
+	if len(runes) <= 8 {
+		return "…" + string(runes[len(runes)-min(2, len(runes)):])
+	}
+
+	return string(runes[:3]) + "…" + string(runes[len(runes)-4:])
+}
+
+// IsMasked проверяет, что value - маска секрета secret (фронтенд прислал значение обратно без изменений)
+func IsMasked(value, secret string) bool {
+	return secret != "" && value == Mask(secret)
+}
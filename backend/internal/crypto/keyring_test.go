@@ -0,0 +1,204 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+// testKey - ключ из 32 одинаковых байт в base64
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(b), 32)))
+}
+
+func TestNewKeyring(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        string
+		current     int
+		wantErr     bool
+		wantCurrent int
+	}{
+		{name: "single key", spec: "1:" + testKey('a'), wantCurrent: 1},
+		{name: "highest version by default", spec: "2:" + testKey('b') + ", 1:" + testKey('a'), wantCurrent: 2},
+		{name: "explicit current", spec: "1:" + testKey('a') + ",2:" + testKey('b'), current: 1, wantCurrent: 1},
+		{name: "empty spec", spec: " ", wantErr: true},
+		{name: "only separators", spec: ",,", wantErr: true},
+		{name: "missing version", spec: testKey('a'), wantErr: true},
+		{name: "zero version", spec: "0:" + testKey('a'), wantErr: true},
+		{name: "bad base64", spec: "1:not-base64!", wantErr: true},
+		{name: "short key", spec: "1:" + base64.StdEncoding.EncodeToString([]byte("short")), wantErr: true},
+		{name: "unknown current", spec: "1:" + testKey('a'), current: 3, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, err := NewKeyring(tt.spec, tt.current)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("NewKeyring() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewKeyring() error = %v", err)
+			}
+			if k.Current() != tt.wantCurrent {
+				t.Errorf("Current() = %d, want %d", k.Current(), tt.wantCurrent)
+			}
+		})
+	}
+}
+
+func TestKeyringRoundTrip(t *testing.T) {
+	k, err := NewKeyring("1:"+testKey('a'), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []string{"", "token", "eyJhbGciOiJFUzI1NiJ9.payload.signature", "пароль с пробелами"}
+	for _, plain := range tests {
+		encrypted, err := k.Encrypt(plain)
+		if err != nil {
+			t.Fatalf("Encrypt(%q) error = %v", plain, err)
+		}
+		if plain == "" {
+			if encrypted != "" {
+				t.Errorf("Encrypt(\"\") = %q, want empty", encrypted)
+			}
+			continue
+		}
+		if !strings.HasPrefix(encrypted, "v1:") || !IsEncrypted(encrypted) || !k.IsCurrent(encrypted) {
+			t.Errorf("Encrypt(%q) = %q, want v1 ciphertext", plain, encrypted)
+		}
+
+		decrypted, err := k.Decrypt(encrypted)
+		if err != nil {
+			t.Fatalf("Decrypt() error = %v", err)
+		}
+		if decrypted != plain {
+			t.Errorf("Decrypt() = %q, want %q", decrypted, plain)
+		}
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	old, err := NewKeyring("1:"+testKey('a'), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := old.Encrypt("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := NewKeyring("1:"+testKey('a')+",2:"+testKey('b'), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated.IsCurrent(encrypted) {
+		t.Errorf("IsCurrent() = true for value of the previous key")
+	}
+
+	decrypted, err := rotated.Decrypt(encrypted)
+	if err != nil || decrypted != "secret" {
+		t.Fatalf("Decrypt() = %q, %v, want old value readable after rotation", decrypted, err)
+	}
+
+	reencrypted, err := rotated.Encrypt(decrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(reencrypted, "v2:") || !rotated.IsCurrent(reencrypted) {
+		t.Errorf("Encrypt() = %q, want v2 ciphertext", reencrypted)
+	}
+
+	// Без старого ключа значение прочитать нельзя
+	withoutOld, err := NewKeyring("2:"+testKey('b'), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := withoutOld.Decrypt(encrypted); err == nil {
+		t.Errorf("Decrypt() error = nil for unloaded key version")
+	}
+}
+
+func TestKeyringDecrypt(t *testing.T) {
+	k, err := NewKeyring("1:"+testKey('a'), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := k.Encrypt("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(encrypted, "v1:"))
+	sealed[len(sealed)-1] ^= 0xff
+	tampered := "v1:" + base64.StdEncoding.EncodeToString(sealed)
+
+	otherKey, err := NewKeyring("1:"+testKey('z'), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		k       *Keyring
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "plaintext passthrough", k: k, value: "legacy-token", want: "legacy-token"},
+		{name: "empty", k: k, value: "", want: ""},
+		{name: "tampered ciphertext", k: k, value: tampered, wantErr: true},
+		{name: "same version other key", k: otherKey, value: encrypted, wantErr: true},
+		{name: "too short", k: k, value: "v1:AAAA", wantErr: true},
+		{name: "unknown version", k: k, value: "v9:" + strings.TrimPrefix(encrypted, "v1:"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.k.Decrypt(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Decrypt() = %q, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decrypt() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Decrypt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		secret string
+		want   string
+	}{
+		{secret: "", want: ""},
+		{secret: "a", want: "…a"},
+		{secret: "abcdefgh", want: "…gh"},
+		{secret: "abcdefghi", want: "abc…fghi"},
+		{secret: "токенпродавца", want: "ток…авца"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			if got := Mask(tt.secret); got != tt.want {
+				t.Errorf("Mask(%q) = %q, want %q", tt.secret, got, tt.want)
+			}
+			if tt.secret != "" && !IsMasked(tt.want, tt.secret) {
+				t.Errorf("IsMasked(%q, %q) = false", tt.want, tt.secret)
+			}
+		})
+	}
+
+	if IsMasked("", "") {
+		t.Errorf("IsMasked() = true for empty secret")
+	}
+}
@@ -10,14 +10,16 @@ type LoginResponse struct {
 }
 
 type UserResponse struct {
-	ID        int       `json:"id"`
-	Name      *string   `json:"name"`
-	Username  string    `json:"username"`
-	Email     *string   `json:"email,omitempty"`
-	Pro       int       `json:"pro"`
-	WbKey     *string   `json:"wb_key,omitempty"`
-	Taxes     int       `json:"taxes,omitempty"`
-	Phone     *string   `json:"phone,omitempty"`
-	Admin     int       `json:"admin"`
-	CreatedAt time.Time `json:"created_at"`
+	ID           int       `json:"id"`
+	Name         *string   `json:"name"`
+	Username     string    `json:"username"`
+	Email        *string   `json:"email,omitempty"`
+	Pro          int       `json:"pro"`
+	WbKey        *string   `json:"wb_key,omitempty"`
+	OzonKey      *string   `json:"ozon_key,omitempty"`
+	OzonClientID *string   `json:"ozon_client_id,omitempty"`
+	Taxes        int       `json:"taxes,omitempty"`
+	Phone        *string   `json:"phone,omitempty"`
+	Admin        int       `json:"admin"`
+	CreatedAt    time.Time `json:"created_at"`
 }
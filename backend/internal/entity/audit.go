@@ -0,0 +1,66 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// AuditLog - соответствует таблице audit_log (журнал действий пользователей и админов)
+type AuditLog struct {
+	ID           int64                  `json:"id" db:"id"`
+	ActorID      sql.NullInt64          `json:"actor_id" db:"actor_id"`
+	TargetUserID sql.NullInt64          `json:"target_user_id" db:"target_user_id"`
+	TargetType   string                 `json:"target_type" db:"target_type"`
+	TargetID     sql.NullString         `json:"target_id" db:"target_id"`
+	Action       string                 `json:"action" db:"action"`
+	Before       map[string]interface{} `json:"before" db:"before"`
+	After        map[string]interface{} `json:"after" db:"after"`
+	IP           sql.NullString         `json:"ip" db:"ip"`
+	UserAgent    sql.NullString         `json:"user_agent" db:"user_agent"`
+	CreatedAt    time.Time              `json:"created_at" db:"created_at"`
+}
+
+// AuditMeta - кто и откуда выполнил действие
+type AuditMeta struct {
+	ActorID   int
+	IP        string
+	UserAgent string
+}
+
+// AuditFilter - фильтры журнала для админки (нулевые значения - без фильтра)
+type AuditFilter struct {
+	ActorID      int
+	TargetUserID int
+	TargetType   string
+	Action       string
+	DateFrom     string
+	DateTo       string
+	Page         int
+	PageSize     int
+}
+
+// Действия журнала
+const (
+	AuditUserAdmin       = "user.admin"
+	AuditUserPro         = "user.pro"
+	AuditUserBlock       = "user.block"
+	AuditUserDel         = "user.del"
+	AuditProfileUpdate   = "profile.update"
+	AuditProfileDelete   = "profile.delete_request"
+	AuditProfileRestore  = "profile.delete_cancel"
+	AuditCostPriceUpdate = "article.cost_price"
+	AuditCardDraftSave   = "article.draft_save"
+	AuditCardDraftDelete = "article.draft_delete"
+	AuditTaxProfileSave  = "tax_profile.save"
+	AuditTaxProfileDel   = "tax_profile.delete"
+	AuditReportPayout    = "report.expected_payout"
+	AuditJobCreate       = "job.create"
+)
+
+// Типы объектов журнала
+const (
+	AuditTargetUser    = "user"
+	AuditTargetArticle = "article"
+	AuditTargetTax     = "tax_profile"
+	AuditTargetReport  = "realization_report"
+)
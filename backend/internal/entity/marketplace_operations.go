@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// MarketplaceOperation - соответствует таблице marketplace_operations
+// (финансовая операция маркетплейса в общем для всех площадок виде)
+type MarketplaceOperation struct {
+	ID            int64          `json:"id" db:"id"`
+	UserID        int            `json:"user_id" db:"user_id"`
+	AccountID     sql.NullInt64  `json:"account_id" db:"account_id"`
+	Marketplace   string         `json:"marketplace" db:"marketplace"`
+	ExternalID    string         `json:"external_id" db:"external_id"`
+	OperationType string         `json:"operation_type" db:"operation_type"`
+	OperationName sql.NullString `json:"operation_name" db:"operation_name"`
+	OperationDate sql.NullTime   `json:"operation_date" db:"operation_date"`
+	ProductID     sql.NullString `json:"product_id" db:"product_id"`
+	OfferID       sql.NullString `json:"offer_id" db:"offer_id"`
+	ProductName   sql.NullString `json:"product_name" db:"product_name"`
+	Quantity      int            `json:"quantity" db:"quantity"`
+	Revenue       float64        `json:"revenue" db:"revenue"`
+	Payout        float64        `json:"payout" db:"payout"`
+	Commission    float64        `json:"commission" db:"commission"`
+	Logistics     float64        `json:"logistics" db:"logistics"`
+	Storage       float64        `json:"storage" db:"storage"`
+	Penalty       float64        `json:"penalty" db:"penalty"`
+	OtherCharges  float64        `json:"other_charges" db:"other_charges"`
+	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
+}
+
+// Типы нормализованных операций
+const (
+	OperationSale         = "sale"
+	OperationReturn       = "return"
+	OperationCommission   = "commission"
+	OperationLogistics    = "logistics"
+	OperationStorage      = "storage"
+	OperationPenalty      = "penalty"
+	OperationCompensation = "compensation"
+	OperationOther        = "other"
+)
@@ -0,0 +1,91 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Organization - соответствует таблице organizations
+type Organization struct {
+	ID        int       `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	OwnerID   int       `json:"owner_id" db:"owner_id"`
+	Del       int       `json:"del" db:"del"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// OrganizationMember - соответствует таблице organization_members
+type OrganizationMember struct {
+	ID             int       `json:"id" db:"id"`
+	OrganizationID int       `json:"organization_id" db:"organization_id"`
+	UserID         int       `json:"id_user" db:"id_user"`
+	Role           string    `json:"role" db:"role"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// OrganizationInvite - соответствует таблице organization_invites
+type OrganizationInvite struct {
+	ID             int            `json:"id" db:"id"`
+	OrganizationID int            `json:"organization_id" db:"organization_id"`
+	Email          sql.NullString `json:"email" db:"email"`
+	Username       sql.NullString `json:"username" db:"username"`
+	Role           string         `json:"role" db:"role"`
+	InvitedBy      int            `json:"invited_by" db:"invited_by"`
+	Status         int            `json:"status" db:"status"`
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// Роли участников организации
+const (
+	RoleOwner   = "owner"   // Владелец: все права, управление участниками
+	RoleManager = "manager" // Менеджер: кабинеты, загрузка данных, себестоимость
+	RoleAnalyst = "analyst" // Аналитик: просмотр и загрузка отчетов, без изменения себестоимости
+	RoleViewer  = "viewer"  // Наблюдатель: только просмотр
+)
+
+// Статусы приглашений
+const (
+	InviteStatusPending  = 0
+	InviteStatusAccepted = 1
+	InviteStatusDeclined = 2
+	InviteStatusRevoked  = 3
+)
+
+// Permission - действие, доступ к которому зависит от роли
+type Permission string
+
+const (
+	PermViewData       Permission = "view_data"       // Просмотр статистики, карточек, кабинетов
+	PermRequestData    Permission = "request_data"    // Заказ отчетов и обновления карточек
+	PermEditCostPrice  Permission = "edit_cost_price" // Изменение себестоимости
+	PermEditCards      Permission = "edit_cards"      // Изменение карточек товаров в WB
+	PermEditTaxes      Permission = "edit_taxes"      // Изменение налогового профиля и сверка выплат
+	PermManageAccounts Permission = "manage_accounts" // Добавление и изменение кабинетов (API ключей)
+	PermManageMembers  Permission = "manage_members"  // Приглашения и роли участников
+)
+
+// RolePermissions - права каждой роли
+var RolePermissions = map[string][]Permission{
+	RoleOwner:   {PermViewData, PermRequestData, PermEditCostPrice, PermEditCards, PermEditTaxes, PermManageAccounts, PermManageMembers},
+	RoleManager: {PermViewData, PermRequestData, PermEditCostPrice, PermEditCards, PermEditTaxes, PermManageAccounts},
+	RoleAnalyst: {PermViewData, PermRequestData},
+	RoleViewer:  {PermViewData},
+}
+
+// RoleAllows проверяет, есть ли у роли право
+func RoleAllows(role string, perm Permission) bool {
+	for _, p := range RolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidInviteRole - роли, которые можно выдать участнику (владелец у организации один)
+func ValidInviteRole(role string) bool {
+	return role == RoleManager || role == RoleAnalyst || role == RoleViewer
+}
@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// OzonGet - соответствует таблице ozon_get (задание на загрузку данных Ozon)
+type OzonGet struct {
+	ID        int            `json:"id" db:"id"`
+	UserID    int            `json:"id_user" db:"id_user"`
+	Type      string         `json:"type" db:"type"`
+	Status    sql.NullInt64  `json:"status" db:"status"`
+	DateFrom  sql.NullString `json:"date_from" db:"date_from"`
+	DateTo    sql.NullString `json:"date_to" db:"date_to"`
+	Created   time.Time      `json:"created" db:"created"`
+	Updated   time.Time      `json:"updated" db:"updated"`
+	LastError sql.NullString `json:"last_error" db:"last_error"`
+}
+
+// Типы заданий Ozon
+const (
+	OzonJobTransactions = "transactions"
+	OzonJobProducts     = "products"
+)
+
+// Статусы ключа Ozon (users.ozon_status)
+const (
+	OzonStatusUnchecked = 0
+	OzonStatusActive    = 1
+	OzonStatusInvalid   = 2
+)
+
+// OzonTransaction - соответствует таблице ozon_transactions
+type OzonTransaction struct {
+	ID                   int64          `json:"id" db:"id"`
+	UserID               int            `json:"user_id" db:"user_id"`
+	OperationID          int64          `json:"operation_id" db:"operation_id"`
+	OperationType        sql.NullString `json:"operation_type" db:"operation_type"`
+	OperationTypeName    sql.NullString `json:"operation_type_name" db:"operation_type_name"`
+	OperationDate        sql.NullTime   `json:"operation_date" db:"operation_date"`
+	Type                 sql.NullString `json:"type" db:"type"`
+	PostingNumber        sql.NullString `json:"posting_number" db:"posting_number"`
+	DeliverySchema       sql.NullString `json:"delivery_schema" db:"delivery_schema"`
+	OrderDate            sql.NullTime   `json:"order_date" db:"order_date"`
+	WarehouseID          sql.NullInt64  `json:"warehouse_id" db:"warehouse_id"`
+	Sku                  sql.NullInt64  `json:"sku" db:"sku"`
+	ItemName             sql.NullString `json:"item_name" db:"item_name"`
+	ItemsCount           int            `json:"items_count" db:"items_count"`
+	AccrualsForSale      float64        `json:"accruals_for_sale" db:"accruals_for_sale"`
+	SaleCommission       float64        `json:"sale_commission" db:"sale_commission"`
+	DeliveryCharge       float64        `json:"delivery_charge" db:"delivery_charge"`
+	ReturnDeliveryCharge float64        `json:"return_delivery_charge" db:"return_delivery_charge"`
+	ServicesAmount       float64        `json:"services_amount" db:"services_amount"`
+	Amount               float64        `json:"amount" db:"amount"`
+	Items                []byte         `json:"items" db:"items"`
+	Services             []byte         `json:"services" db:"services"`
+	CreatedAt            time.Time      `json:"created_at" db:"created_at"`
+}
+
+// OzonProduct - соответствует таблице ozon_products
+type OzonProduct struct {
+	ID        int             `json:"id" db:"id"`
+	UserID    int             `json:"user_id" db:"user_id"`
+	ProductID int64           `json:"product_id" db:"product_id"`
+	OfferID   sql.NullString  `json:"offer_id" db:"offer_id"`
+	Sku       sql.NullInt64   `json:"sku" db:"sku"`
+	Name      sql.NullString  `json:"name" db:"name"`
+	Barcode   sql.NullString  `json:"barcode" db:"barcode"`
+	Photo     sql.NullString  `json:"photo" db:"photo"`
+	Price     sql.NullFloat64 `json:"price" db:"price"`
+	Archived  int             `json:"archived" db:"archived"`
+	CostPrice sql.NullFloat64 `json:"cost_price" db:"cost_price"`
+	Created   sql.NullTime    `json:"created" db:"created"`
+	Updated   sql.NullTime    `json:"updated" db:"updated"`
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// SellerAccount - соответствует таблице seller_accounts (кабинет продавца на маркетплейсе)
+type SellerAccount struct {
+	ID           int            `json:"id" db:"id"`
+	UserID       int            `json:"id_user" db:"id_user"`
+	Marketplace  string         `json:"marketplace" db:"marketplace"`
+	Name         string         `json:"name" db:"name"`
+	APIKey       sql.NullString `json:"-" db:"api_key"`
+	KeyExpiresAt sql.NullTime   `json:"key_expires_at" db:"key_expires_at"`
+	KeyScopes    sql.NullInt64  `json:"key_scopes" db:"key_scopes"`
+	SellerID     sql.NullInt64  `json:"seller_id" db:"seller_id"`
+	SellerUUID   sql.NullString `json:"seller_uuid" db:"seller_uuid"`
+	BusinessID   sql.NullInt64  `json:"business_id" db:"business_id"`
+	CampaignID   sql.NullInt64  `json:"campaign_id" db:"campaign_id"`
+	IsDefault    int            `json:"is_default" db:"is_default"`
+	Del          int            `json:"del" db:"del"`
+	CreatedAt    time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// Маркетплейсы кабинетов
+const (
+	MarketplaceWB     = "wb"
+	MarketplaceOzon   = "ozon"
+	MarketplaceYandex = "yandex"
+)
+
+// AllAccounts - значение account_id для сводных данных по всем кабинетам пользователя
+const AllAccounts = 0
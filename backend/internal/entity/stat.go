@@ -10,21 +10,22 @@ type Stat struct {
 	ID                  int64           `json:"id" db:"id"`
 	HashInfo            string          `json:"hash_info" db:"hash_info"`
 	UserID              int             `json:"user_id" db:"user_id"`
+	AccountID           sql.NullInt64   `json:"account_id" db:"account_id"`
 	Nmid                sql.NullInt64   `json:"nm_id" db:"nm_id"`
-	PpvzForPay          sql.NullString  `json:"ppvz_for_pay" db:"ppvz_for_pay"`
+	PpvzForPay          sql.NullFloat64 `json:"ppvz_for_pay" db:"ppvz_for_pay"`
 	SupplierOperName    sql.NullInt64   `json:"supplier_oper_name" db:"supplier_oper_name"` // Обратите внимание: integer в БД
 	DeliveryRub         sql.NullFloat64 `json:"delivery_rub" db:"delivery_rub"`
 	Penalty             sql.NullFloat64 `json:"penalty" db:"penalty"`
 	AdditionalPayment   sql.NullFloat64 `json:"additional_payment" db:"additional_payment"`
 	StorageFee          sql.NullFloat64 `json:"storage_fee" db:"storage_fee"`
-	RebillLogisticCost  sql.NullString  `json:"rebill_logistic_cost" db:"rebill_logistic_cost"`
+	RebillLogisticCost  sql.NullFloat64 `json:"rebill_logistic_cost" db:"rebill_logistic_cost"`
 	AcquiringFee        sql.NullFloat64 `json:"acquiring_fee" db:"acquiring_fee"`
 	AcquiringPercent    sql.NullFloat64 `json:"acquiring_percent" db:"acquiring_percent"`
 	PpvzSalesCommission sql.NullFloat64 `json:"ppvz_sales_commission" db:"ppvz_sales_commission"`
 	Deduction           sql.NullFloat64 `json:"deduction" db:"deduction"`
-	PpvzSppPrc          sql.NullString  `json:"ppvz_spp_prc" db:"ppvz_spp_prc"`
-	PpvzKvwPrcBase      sql.NullString  `json:"ppvz_kvw_prc_base" db:"ppvz_kvw_prc_base"`
-	PpvzKvwPrc          sql.NullString  `json:"ppvz_kvw_prc" db:"ppvz_kvw_prc"`
+	PpvzSppPrc          sql.NullFloat64 `json:"ppvz_spp_prc" db:"ppvz_spp_prc"`
+	PpvzKvwPrcBase      sql.NullFloat64 `json:"ppvz_kvw_prc_base" db:"ppvz_kvw_prc_base"`
+	PpvzKvwPrc          sql.NullFloat64 `json:"ppvz_kvw_prc" db:"ppvz_kvw_prc"`
 	Acceptance          sql.NullFloat64 `json:"acceptance" db:"acceptance"`
 	DlvPrc              sql.NullFloat64 `json:"dlv_prc" db:"dlv_prc"`
 	CreatedAt           time.Time       `json:"created_at" db:"created_at"`
@@ -40,8 +41,8 @@ type Stat struct {
 	PpvzOfficeID        sql.NullInt64   `json:"ppvz_office_id" db:"ppvz_office_id"`
 	AssemblyID          sql.NullInt64   `json:"assembly_id" db:"assembly_id"` // Обратите внимание: bigint в БД
 	SaName              sql.NullString  `json:"sa_name" db:"sa_name"`
-	PpvzVwNds           sql.NullString  `json:"ppvz_vw_nds" db:"ppvz_vw_nds"`
-	PpvzVw              sql.NullString  `json:"ppvz_vw" db:"ppvz_vw"`
+	PpvzVwNds           sql.NullFloat64 `json:"ppvz_vw_nds" db:"ppvz_vw_nds"`
+	PpvzVw              sql.NullFloat64 `json:"ppvz_vw" db:"ppvz_vw"`
 	GiBoxTypeName       sql.NullString  `json:"gi_box_type_name" db:"gi_box_type_name"`
 	SubjectName         sql.NullString  `json:"subject_name" db:"subject_name"`
 	TsName              sql.NullString  `json:"ts_name" db:"ts_name"`
@@ -57,4 +58,5 @@ type Stat struct {
 	ReportType          sql.NullInt64   `json:"report_type" db:"report_type"`
 	Srid                sql.NullString  `json:"srid" db:"srid"`
 	Rid                 sql.NullInt64   `json:"rid" db:"rid"`
+	RrdID               sql.NullInt64   `json:"rrd_id" db:"rrd_id"`
 }
@@ -0,0 +1,120 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Налоговые режимы
+const (
+	TaxRegimeUSNIncome        = "usn_income"         // УСН "Доходы"
+	TaxRegimeUSNIncomeExpense = "usn_income_expense" // УСН "Доходы минус расходы"
+	TaxRegimeOSNO             = "osno"               // ОСНО: налог на прибыль (НДФЛ) и НДС
+	TaxRegimeSelfEmployed     = "self_employed"      // НПД (самозанятый), без НДС
+)
+
+// TaxRegimeNames - названия режимов для интерфейса
+var TaxRegimeNames = map[string]string{
+	TaxRegimeUSNIncome:        "УСН Доходы",
+	TaxRegimeUSNIncomeExpense: "УСН Доходы минус расходы",
+	TaxRegimeOSNO:             "ОСНО",
+	TaxRegimeSelfEmployed:     "НПД",
+}
+
+// Доход, от которого считается налог
+const (
+	TaxBaseRetail = "retail" // Цена продажи покупателю (retail_amount), комиссия маркетплейса - расход
+	TaxBasePayout = "payout" // Сумма к перечислению от маркетплейса (ppvz_for_pay)
+)
+
+// TaxUSNMinimumRate - минимальный налог УСН "Доходы минус расходы", % от доходов за год
+const TaxUSNMinimumRate = 1.0
+
+// TaxProfileStart - valid_from первого профиля: он действует на всю прошлую статистику
+const TaxProfileStart = "1970-01-01"
+
+// TaxProfile - соответствует таблице tax_profiles в БД
+type TaxProfile struct {
+	ID        int64         `json:"id" db:"id"`
+	UserID    int           `json:"user_id" db:"user_id"`
+	Regime    string        `json:"regime" db:"regime"`
+	Rate      float64       `json:"rate" db:"rate"`
+	Base      string        `json:"base" db:"base"`
+	VatRate   float64       `json:"vat_rate" db:"vat_rate"`
+	ValidFrom time.Time     `json:"valid_from" db:"valid_from"`
+	CreatedBy sql.NullInt64 `json:"created_by" db:"created_by"`
+	CreatedAt time.Time     `json:"created_at" db:"created_at"`
+}
+
+// LegacyTaxProfile - профиль по ставке users.taxes для периодов без профиля:
+// процент от суммы к перечислению за вычетом расходов (прежний расчет)
+func LegacyTaxProfile(taxes int) TaxProfile {
+	return TaxProfile{
+		Regime: TaxRegimeUSNIncomeExpense,
+		Rate:   float64(taxes),
+		Base:   TaxBasePayout,
+	}
+}
+
+// TaxBase - суммы операций для расчета налога. Знаки: доходы положительные у продаж
+// и отрицательные у возвратов, расходы положительные
+type TaxBase struct {
+	Revenue    float64 // Продажи по цене для покупателя
+	Payout     float64 // К перечислению от маркетплейса (с компенсациями)
+	Commission float64 // Комиссия маркетплейса (Revenue - Payout продаж)
+	Expenses   float64 // Логистика, хранение, штрафы, удержания и себестоимость
+}
+
+// TaxAmount - результат расчета налога
+type TaxAmount struct {
+	Income   float64 `json:"income"`   // Доход без НДС
+	Expenses float64 `json:"expenses"` // Расходы, уменьшающие налоговую базу
+	VAT      float64 `json:"vat"`
+	Base     float64 `json:"base"` // Налоговая база
+	Tax      float64 `json:"tax"`  // Налог по режиму (без НДС)
+}
+
+// Add суммирует результаты расчета (разные товары или периоды профилей)
+func (a *TaxAmount) Add(b TaxAmount) {
+	a.Income += b.Income
+	a.Expenses += b.Expenses
+	a.VAT += b.VAT
+	a.Base += b.Base
+	a.Tax += b.Tax
+}
+
+// Total - налог вместе с НДС
+func (a TaxAmount) Total() float64 {
+	return a.Tax + a.VAT
+}
+
+// Calculate считает налог по профилю. НДС выделяется из дохода (цены включают НДС),
+// входящий НДС расходов не учитывается. База режимов с расходами может быть
+// отрицательной - так убыток одного товара уменьшает налог по остальным
+func (p TaxProfile) Calculate(b TaxBase) TaxAmount {
+	var amount TaxAmount
+
+	income := b.Payout
+	if p.Base == TaxBaseRetail {
+		income = b.Revenue
+		amount.Expenses = b.Commission
+	}
+
+	if p.VatRate > 0 && p.Regime != TaxRegimeSelfEmployed {
+		amount.VAT = income * p.VatRate / (100 + p.VatRate)
+	}
+	amount.Income = income - amount.VAT
+
+	switch p.Regime {
+	case TaxRegimeUSNIncomeExpense, TaxRegimeOSNO:
+		amount.Expenses += b.Expenses
+		amount.Base = amount.Income - amount.Expenses
+	default:
+		amount.Expenses = 0
+		amount.Base = amount.Income
+	}
+
+	amount.Tax = amount.Base * p.Rate / 100
+
+	return amount
+}
@@ -0,0 +1,118 @@
+package entity
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTaxProfileCalculate(t *testing.T) {
+	base := TaxBase{Revenue: 1000, Payout: 800, Commission: 200, Expenses: 300}
+
+	tests := []struct {
+		name    string
+		profile TaxProfile
+		base    TaxBase
+		want    TaxAmount
+	}{
+		{
+			name:    "usn income retail",
+			profile: TaxProfile{Regime: TaxRegimeUSNIncome, Rate: 6, Base: TaxBaseRetail},
+			base:    base,
+			want:    TaxAmount{Income: 1000, Base: 1000, Tax: 60},
+		},
+		{
+			name:    "usn income payout",
+			profile: TaxProfile{Regime: TaxRegimeUSNIncome, Rate: 6, Base: TaxBasePayout},
+			base:    base,
+			want:    TaxAmount{Income: 800, Base: 800, Tax: 48},
+		},
+		{
+			name:    "usn income with vat",
+			profile: TaxProfile{Regime: TaxRegimeUSNIncome, Rate: 6, Base: TaxBaseRetail, VatRate: 5},
+			base:    base,
+			want:    TaxAmount{Income: 1000 - 1000.0*5/105, VAT: 1000.0 * 5 / 105, Base: 1000 - 1000.0*5/105, Tax: (1000 - 1000.0*5/105) * 0.06},
+		},
+		{
+			name:    "usn income expense retail",
+			profile: TaxProfile{Regime: TaxRegimeUSNIncomeExpense, Rate: 15, Base: TaxBaseRetail},
+			base:    base,
+			want:    TaxAmount{Income: 1000, Expenses: 500, Base: 500, Tax: 75},
+		},
+		{
+			name:    "usn income expense payout",
+			profile: TaxProfile{Regime: TaxRegimeUSNIncomeExpense, Rate: 15, Base: TaxBasePayout},
+			base:    base,
+			want:    TaxAmount{Income: 800, Expenses: 300, Base: 500, Tax: 75},
+		},
+		{
+			name:    "usn income expense loss",
+			profile: TaxProfile{Regime: TaxRegimeUSNIncomeExpense, Rate: 15, Base: TaxBasePayout},
+			base:    TaxBase{Revenue: 1000, Payout: 800, Commission: 200, Expenses: 1000},
+			want:    TaxAmount{Income: 800, Expenses: 1000, Base: -200, Tax: -30},
+		},
+		{
+			name:    "osno retail",
+			profile: TaxProfile{Regime: TaxRegimeOSNO, Rate: 20, Base: TaxBaseRetail, VatRate: 20},
+			base:    base,
+			want:    TaxAmount{Income: 1000 - 1000.0/6, Expenses: 500, VAT: 1000.0 / 6, Base: 500 - 1000.0/6, Tax: (500 - 1000.0/6) * 0.2},
+		},
+		{
+			name:    "osno payout",
+			profile: TaxProfile{Regime: TaxRegimeOSNO, Rate: 20, Base: TaxBasePayout, VatRate: 20},
+			base:    base,
+			want:    TaxAmount{Income: 800 - 800.0/6, Expenses: 300, VAT: 800.0 / 6, Base: 500 - 800.0/6, Tax: (500 - 800.0/6) * 0.2},
+		},
+		{
+			name:    "self employed retail ignores vat",
+			profile: TaxProfile{Regime: TaxRegimeSelfEmployed, Rate: 6, Base: TaxBaseRetail, VatRate: 20},
+			base:    base,
+			want:    TaxAmount{Income: 1000, Base: 1000, Tax: 60},
+		},
+		{
+			name:    "self employed payout",
+			profile: TaxProfile{Regime: TaxRegimeSelfEmployed, Rate: 4, Base: TaxBasePayout},
+			base:    base,
+			want:    TaxAmount{Income: 800, Base: 800, Tax: 32},
+		},
+		{
+			name:    "legacy profile",
+			profile: LegacyTaxProfile(7),
+			base:    base,
+			want:    TaxAmount{Income: 800, Expenses: 300, Base: 500, Tax: 35},
+		},
+		{
+			name:    "returns only",
+			profile: TaxProfile{Regime: TaxRegimeUSNIncome, Rate: 6, Base: TaxBaseRetail},
+			base:    TaxBase{Revenue: -500, Payout: -400},
+			want:    TaxAmount{Income: -500, Base: -500, Tax: -30},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.profile.Calculate(tt.base)
+			if !taxAmountEqual(got, tt.want) {
+				t.Errorf("Calculate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaxAmountAdd(t *testing.T) {
+	a := TaxAmount{Income: 100, Expenses: 10, VAT: 20, Base: 90, Tax: 9}
+	a.Add(TaxAmount{Income: 50, Expenses: 5, VAT: 10, Base: 45, Tax: 4.5})
+
+	want := TaxAmount{Income: 150, Expenses: 15, VAT: 30, Base: 135, Tax: 13.5}
+	if !taxAmountEqual(a, want) {
+		t.Errorf("Add() = %+v, want %+v", a, want)
+	}
+	if total := a.Total(); math.Abs(total-43.5) > 1e-9 {
+		t.Errorf("Total() = %v, want 43.5", total)
+	}
+}
+
+func taxAmountEqual(a, b TaxAmount) bool {
+	const eps = 1e-9
+	return math.Abs(a.Income-b.Income) < eps && math.Abs(a.Expenses-b.Expenses) < eps &&
+		math.Abs(a.VAT-b.VAT) < eps && math.Abs(a.Base-b.Base) < eps && math.Abs(a.Tax-b.Tax) < eps
+}
@@ -24,6 +24,11 @@ type Users struct {
 	UpdatedAt       time.Time      `json:"updated_at" db:"updated_at"`
 	Del             int            `json:"del" db:"del"`
 	LastLogin       time.Time      `json:"last_login,omitempty" db:"last_login"`
+	WbKeyExpiresAt  sql.NullTime   `json:"wb_key_expires_at" db:"wb_key_expires_at"`
+	WbKeyScopes     sql.NullInt64  `json:"wb_key_scopes" db:"wb_key_scopes"`
+	WbSellerID      sql.NullInt64  `json:"wb_seller_id" db:"wb_seller_id"`
+	WbSellerUUID    sql.NullString `json:"wb_seller_uuid" db:"wb_seller_uuid"`
+	OzonClientID    sql.NullString `json:"ozon_client_id" db:"ozon_client_id"`
 }
 
 // Константы для типов аккаунтов
@@ -44,3 +49,12 @@ const (
 	StatLimitFrom = "-3m"
 	StatLimitTo   = "0d"
 )
+
+// UserDeletion - пользователь, запросивший удаление аккаунта (users.delete_after)
+type UserDeletion struct {
+	UserID            int            `json:"user_id" db:"id_user"`
+	Username          string         `json:"username" db:"username"`
+	Email             sql.NullString `json:"email" db:"email"`
+	DeleteRequestedAt time.Time      `json:"delete_requested_at" db:"delete_requested_at"`
+	DeleteAfter       time.Time      `json:"delete_after" db:"delete_after"`
+}
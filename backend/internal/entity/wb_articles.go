@@ -1,23 +1,186 @@
 package entity
 
-import "database/sql"
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
 
 // WBArticles - соответствует таблице wb_articles в БД
 type WBArticles struct {
-	ID              int            `json:"id" db:"id"`
-	UserID          int            `json:"id_user" db:"id_user"`
-	Articule        string         `json:"articule" db:"articule"`
-	Name            sql.NullString `json:"name" db:"name"`
-	Photo           sql.NullString `json:"photo" db:"photo"`
-	CostPrice       sql.NullString `json:"cost_price" db:"cost_price"`
-	Created         sql.NullTime   `json:"created" db:"created"`
-	Updated         sql.NullTime   `json:"updated" db:"updated"`
-	UpdatedAt       sql.NullTime   `json:"updated_at" db:"updated_at"`
-	SelfRansom      sql.NullInt64  `json:"self_ransom" db:"self_ransom"`
-	SelfRansomPrice sql.NullString `json:"self_ransom_price" db:"self_ransom_price"`
-	RusSize         sql.NullString `json:"rus_size" db:"rus_size"`
-	EuSize          sql.NullString `json:"eu_size" db:"eu_size"`
-	ChrtID          sql.NullInt64  `json:"chrt_id" db:"chrt_id"`
-	Barcode         sql.NullString `json:"barcode" db:"barcode"`
-	InternalID      sql.NullString `json:"internal_id" db:"internal_id"`
+	ID              int             `json:"id" db:"id"`
+	UserID          int             `json:"id_user" db:"id_user"`
+	AccountID       sql.NullInt64   `json:"account_id" db:"account_id"`
+	Marketplace     string          `json:"marketplace" db:"marketplace"`
+	Articule        string          `json:"articule" db:"articule"`
+	Name            sql.NullString  `json:"name" db:"name"`
+	Photo           sql.NullString  `json:"photo" db:"photo"`
+	CostPrice       sql.NullFloat64 `json:"cost_price" db:"cost_price"`
+	Created         sql.NullTime    `json:"created" db:"created"`
+	Updated         sql.NullTime    `json:"updated" db:"updated"`
+	UpdatedAt       sql.NullTime    `json:"updated_at" db:"updated_at"`
+	SelfRansom      sql.NullInt64   `json:"self_ransom" db:"self_ransom"`
+	SelfRansomPrice sql.NullFloat64 `json:"self_ransom_price" db:"self_ransom_price"`
+	RusSize         sql.NullString  `json:"rus_size" db:"rus_size"`
+	EuSize          sql.NullString  `json:"eu_size" db:"eu_size"`
+	ChrtID          sql.NullInt64   `json:"chrt_id" db:"chrt_id"`
+	Barcode         sql.NullString  `json:"barcode" db:"barcode"`
+	InternalID      sql.NullString  `json:"internal_id" db:"internal_id"` // Артикул продавца (vendorCode WB, offerId Маркета)
+	Brand           sql.NullString  `json:"brand" db:"brand"`
+	SubjectID       sql.NullInt64   `json:"subject_id" db:"subject_id"`
+	SubjectName     sql.NullString  `json:"subject_name" db:"subject_name"`
+	ImtID           sql.NullInt64   `json:"imt_id" db:"imt_id"`
+	NmUUID          sql.NullString  `json:"nm_uuid" db:"nm_uuid"`
+	Description     sql.NullString  `json:"description" db:"description"`
+	Length          sql.NullFloat64 `json:"length" db:"length"`
+	Width           sql.NullFloat64 `json:"width" db:"width"`
+	Height          sql.NullFloat64 `json:"height" db:"height"`
+	WeightBrutto    sql.NullFloat64 `json:"weight_brutto" db:"weight_brutto"`
+	DimensionsValid sql.NullBool    `json:"dimensions_valid" db:"dimensions_valid"`
+	CardCreatedAt   sql.NullTime    `json:"card_created_at" db:"card_created_at"`
+	CardUpdatedAt   sql.NullTime    `json:"card_updated_at" db:"card_updated_at"`
+	ArchivedAt      sql.NullTime    `json:"archived_at" db:"archived_at"`
+	ArchiveReason   sql.NullString  `json:"archive_reason" db:"archive_reason"`
+}
+
+// Причины архивации карточки
+const (
+	ArchiveReasonTrash   = "trash"   // В корзине WB
+	ArchiveReasonDeleted = "deleted" // Нет в списке карточек при полной сверке
+)
+
+// Поля журнала изменений карточек (wb_article_changes.field)
+const (
+	ArticleChangeTitle      = "title"
+	ArticleChangePhotos     = "photos"
+	ArticleChangeSizes      = "sizes"
+	ArticleChangeBarcodes   = "barcodes"
+	ArticleChangeVendorCode = "vendor_code"
+	ArticleChangeBrand      = "brand"
+	ArticleChangeSubject    = "subject"
+	ArticleChangeStatus     = "status" // active, trash, deleted
+)
+
+// ArticleStatusActive - статус карточки не в архиве (для журнала изменений)
+const ArticleStatusActive = "active"
+
+// WBArticleSnapshot - сохраненное состояние карточки для сравнения с загруженной из WB
+type WBArticleSnapshot struct {
+	Title      string
+	VendorCode string
+	Brand      string
+	Subject    string
+	Photos     []string // big по порядку
+	Sizes      []string // techSize
+	Barcodes   []string // по алфавиту
+	Status     string   // active или archive_reason
+}
+
+// WBArticleChange - соответствует таблице wb_article_changes в БД
+type WBArticleChange struct {
+	ID            int64          `json:"id" db:"id"`
+	UserID        int            `json:"user_id" db:"user_id"`
+	AccountID     sql.NullInt64  `json:"account_id" db:"account_id"`
+	NmID          int64          `json:"nm_id" db:"nm_id"`
+	Field         string         `json:"field" db:"field"`
+	OldValue      sql.NullString `json:"old_value" db:"old_value"`
+	NewValue      sql.NullString `json:"new_value" db:"new_value"`
+	CardUpdatedAt sql.NullTime   `json:"card_updated_at" db:"card_updated_at"`
+	DetectedAt    time.Time      `json:"detected_at" db:"detected_at"`
+}
+
+// ArticleChangeFilter - фильтры журнала изменений (пустые значения не фильтруют)
+type ArticleChangeFilter struct {
+	NmID     int64
+	Field    string
+	DateFrom string
+	DateTo   string
+	Page     int
+	PageSize int
+}
+
+// WBArticleSync - соответствует таблице wb_article_sync (account_id = 0 - основной кабинет)
+type WBArticleSync struct {
+	UserID          int            `json:"user_id" db:"user_id"`
+	AccountID       int            `json:"account_id" db:"account_id"`
+	CursorUpdatedAt sql.NullString `json:"cursor_updated_at" db:"cursor_updated_at"`
+	CursorNmID      sql.NullInt64  `json:"cursor_nm_id" db:"cursor_nm_id"`
+	LastSyncAt      sql.NullTime   `json:"last_sync_at" db:"last_sync_at"`
+	LastFullSyncAt  sql.NullTime   `json:"last_full_sync_at" db:"last_full_sync_at"`
+}
+
+// WBArticlePhoto - соответствует таблице wb_article_photos в БД
+type WBArticlePhoto struct {
+	NmID     int64  `json:"nm_id" db:"nm_id"`
+	Position int    `json:"position" db:"position"`
+	Big      string `json:"big" db:"big"`
+	C246x328 string `json:"c246x328" db:"c246x328"`
+	C516x688 string `json:"c516x688" db:"c516x688"`
+	Square   string `json:"square" db:"square"`
+	Tm       string `json:"tm" db:"tm"`
+}
+
+// WBArticleCharacteristic - соответствует таблице wb_article_characteristics в БД
+type WBArticleCharacteristic struct {
+	NmID             int64           `json:"nm_id" db:"nm_id"`
+	CharacteristicID int             `json:"id" db:"characteristic_id"`
+	Name             string          `json:"name" db:"name"`
+	Value            json.RawMessage `json:"value" db:"value"`
+}
+
+// ArticleFilter - фильтры списка карточек (пустые значения не фильтруют)
+type ArticleFilter struct {
+	AccountID   int
+	Marketplace string
+	Search      string // Название, артикул WB или артикул продавца
+	Brand       string
+	SubjectID   int
+	VendorCode  string
+	ImtID       int64
+	CharName    string // Характеристика: название
+	CharValue   string // и подстрока значения
+	Archived    string // "" - все, "0" - активные, "1" - в архиве
+	Page        int
+	PageSize    int
+}
+
+// WBArticleSize - соответствует таблице wb_article_sizes в БД (Skus - из wb_article_barcodes)
+type WBArticleSize struct {
+	ID        int             `json:"id" db:"id"`
+	UserID    int             `json:"user_id" db:"user_id"`
+	NmID      int64           `json:"nm_id" db:"nm_id"`
+	ChrtID    int64           `json:"chrt_id" db:"chrt_id"`
+	TechSize  string          `json:"tech_size" db:"tech_size"`
+	WbSize    string          `json:"wb_size" db:"wb_size"`
+	CostPrice sql.NullFloat64 `json:"cost_price" db:"cost_price"`
+	Skus      []string        `json:"skus"`
+}
+
+// CostPriceHistoryStart - valid_from первой себестоимости товара: она действует на всю прошлую статистику
+const CostPriceHistoryStart = "1970-01-01"
+
+// CostPriceHistory - соответствует таблице cost_price_history в БД
+type CostPriceHistory struct {
+	ID        int64         `json:"id" db:"id"`
+	UserID    int           `json:"user_id" db:"user_id"`
+	Articule  string        `json:"articule" db:"articule"`
+	ChrtID    int64         `json:"chrt_id" db:"chrt_id"`
+	CostPrice float64       `json:"cost_price" db:"cost_price"`
+	ValidFrom time.Time     `json:"valid_from" db:"valid_from"`
+	CreatedBy sql.NullInt64 `json:"created_by" db:"created_by"`
+	CreatedAt time.Time     `json:"created_at" db:"created_at"`
+}
+
+// WBStock - соответствует таблице wb_stocks в БД (снимок остатков на складах WB)
+type WBStock struct {
+	UserID          int           `json:"user_id" db:"user_id"`
+	AccountID       sql.NullInt64 `json:"account_id" db:"account_id"`
+	NmID            int64         `json:"nm_id" db:"nm_id"`
+	Barcode         string        `json:"barcode" db:"barcode"`
+	TechSize        string        `json:"tech_size" db:"tech_size"`
+	WarehouseName   string        `json:"warehouse_name" db:"warehouse_name"`
+	Quantity        int           `json:"quantity" db:"quantity"`
+	InWayToClient   int           `json:"in_way_to_client" db:"in_way_to_client"`
+	InWayFromClient int           `json:"in_way_from_client" db:"in_way_from_client"`
+	QuantityFull    int           `json:"quantity_full" db:"quantity_full"`
 }
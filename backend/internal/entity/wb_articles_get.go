@@ -9,10 +9,12 @@ import (
 type WBArticlesGet struct {
 	ID        int            `json:"id" db:"id"`
 	UserID    int            `json:"id_user" db:"id_user"`
+	AccountID sql.NullInt64  `json:"account_id" db:"account_id"`
 	Status    sql.NullInt64  `json:"status" db:"status"`
 	Created   time.Time      `json:"created" db:"created"`
 	Updated   time.Time      `json:"updated" db:"updated"`
 	LastError sql.NullString `json:"last_error" db:"last_error"`
+	FullSync  bool           `json:"full_sync" db:"full_sync"` // Полная сверка вместо загрузки изменений
 }
 
 // Константы статусов (аналогично статистике)
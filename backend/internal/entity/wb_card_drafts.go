@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// Статусы черновика карточки
+const (
+	CardDraftStatusDraft   = "draft"   // Редактируется
+	CardDraftStatusQueued  = "queued"  // В задании на отправку
+	CardDraftStatusSent    = "sent"    // Отправлен в WB, ждет результата
+	CardDraftStatusApplied = "applied" // Принят WB
+	CardDraftStatusError   = "error"   // Отклонен WB (ошибки в Errors)
+)
+
+// CardUpdateStatusSent - задание отправлено, ошибки WB по карточкам еще не проверены
+// (остальные статусы как у ArticlesStatus*)
+const CardUpdateStatusSent = 3
+
+// WBCardUpdate - соответствует таблице wb_card_updates (задание отправки черновиков)
+type WBCardUpdate struct {
+	ID        int            `json:"id" db:"id"`
+	UserID    int            `json:"id_user" db:"id_user"`
+	AccountID sql.NullInt64  `json:"account_id" db:"account_id"`
+	Status    sql.NullInt64  `json:"status" db:"status"`
+	Created   time.Time      `json:"created" db:"created"`
+	Updated   time.Time      `json:"updated" db:"updated"`
+	SentAt    sql.NullTime   `json:"sent_at" db:"sent_at"`
+	LastError sql.NullString `json:"last_error" db:"last_error"`
+}
+
+// WBCardDraft - соответствует таблице wb_card_drafts. Пустое (не Valid) поле не меняется
+type WBCardDraft struct {
+	ID              int                       `json:"id" db:"id"`
+	UserID          int                       `json:"user_id" db:"user_id"`
+	AccountID       sql.NullInt64             `json:"account_id" db:"account_id"`
+	NmID            int64                     `json:"nm_id" db:"nm_id"`
+	Title           sql.NullString            `json:"title" db:"title"`
+	Description     sql.NullString            `json:"description" db:"description"`
+	VendorCode      sql.NullString            `json:"vendor_code" db:"vendor_code"`
+	Characteristics []CardDraftCharacteristic `json:"characteristics" db:"characteristics"`
+	Length          sql.NullFloat64           `json:"length" db:"length"`
+	Width           sql.NullFloat64           `json:"width" db:"width"`
+	Height          sql.NullFloat64           `json:"height" db:"height"`
+	WeightBrutto    sql.NullFloat64           `json:"weight_brutto" db:"weight_brutto"`
+	Status          string                    `json:"status" db:"status"`
+	Errors          []string                  `json:"errors" db:"errors"`
+	UpdateID        sql.NullInt64             `json:"update_id" db:"update_id"`
+	CreatedBy       sql.NullInt64             `json:"created_by" db:"created_by"`
+	CreatedAt       time.Time                 `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time                 `json:"updated_at" db:"updated_at"`
+}
+
+// Editable - черновик можно менять, пока он не отправлен в WB
+func (d *WBCardDraft) Editable() bool {
+	return d.Status != CardDraftStatusQueued && d.Status != CardDraftStatusSent
+}
+
+// CardDraftCharacteristic - новое значение характеристики (null - удалить значение)
+type CardDraftCharacteristic struct {
+	ID    int             `json:"id"`
+	Value json.RawMessage `json:"value"`
+}
+
+// WBSubjectCharacteristic - соответствует таблице wb_subject_characteristics
+type WBSubjectCharacteristic struct {
+	SubjectID int       `json:"subject_id" db:"subject_id"`
+	CharcID   int       `json:"charc_id" db:"charc_id"`
+	Name      string    `json:"name" db:"name"`
+	Required  bool      `json:"required" db:"required"`
+	UnitName  string    `json:"unit_name" db:"unit_name"`
+	MaxCount  int       `json:"max_count" db:"max_count"`
+	CharcType int       `json:"charc_type" db:"charc_type"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SubjectCharcTypeNumber - характеристика с числовым значением (charcType в API WB)
+const SubjectCharcTypeNumber = 4
@@ -9,6 +9,7 @@ import (
 type WBStatsGet struct {
 	ID        int            `json:"id" db:"id"`
 	UserID    int            `json:"id_user" db:"id_user"`
+	AccountID sql.NullInt64  `json:"account_id" db:"account_id"`
 	Status    sql.NullInt64  `json:"status" db:"status"`
 	DateFrom  string         `json:"date_from" db:"date_from"`
 	DateTo    string         `json:"date_to" db:"date_to"`
@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// YandexGet - соответствует таблице yandex_get (задание на загрузку данных Яндекс Маркета)
+type YandexGet struct {
+	ID        int            `json:"id" db:"id"`
+	UserID    int            `json:"id_user" db:"id_user"`
+	AccountID int            `json:"account_id" db:"account_id"`
+	Type      string         `json:"type" db:"type"`
+	Status    sql.NullInt64  `json:"status" db:"status"`
+	DateFrom  sql.NullString `json:"date_from" db:"date_from"`
+	DateTo    sql.NullString `json:"date_to" db:"date_to"`
+	Created   time.Time      `json:"created" db:"created"`
+	Updated   time.Time      `json:"updated" db:"updated"`
+	LastError sql.NullString `json:"last_error" db:"last_error"`
+}
+
+// Типы заданий Яндекс Маркета
+const (
+	YandexJobOperations = "operations"
+	YandexJobProducts   = "products"
+)
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"database/sql"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"wbrost-go/internal/dto"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/repository/user"
+	"wbrost-go/internal/service/audit"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+type AuditHandler struct {
+	userRepo     *user.UserRepository
+	auditService *audit.AuditService
+	jwtSecret    []byte
+}
+
+func NewAuditHandler(userRepo *user.UserRepository, auditService *audit.AuditService, jwtSecret string) *AuditHandler {
+	return &AuditHandler{
+		userRepo:     userRepo,
+		auditService: auditService,
+		jwtSecret:    []byte(jwtSecret),
+	}
+}
+
+// GetAuditLog - GET /api/admin/audit | Журнал действий (только админ).
+// Фильтры: actor_id, target_user_id, target_type, action, date_from, date_to (YYYY-MM-DD), page, pageSize
+func (h *AuditHandler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+	if user.Admin < entity.UserAdmin {
+		respondWithJSON(w, http.StatusForbidden, dto.ErrorResponse{Error: "Admin rights required"})
+		return
+	}
+
+	query := r.URL.Query()
+	filter := entity.AuditFilter{
+		TargetType: query.Get("target_type"),
+		Action:     query.Get("action"),
+		DateFrom:   query.Get("date_from"),
+		DateTo:     query.Get("date_to"),
+		Page:       PageNum,
+		PageSize:   PageSize,
+	}
+
+	if filter.ActorID, err = parseOptionalID(query.Get("actor_id")); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid actor_id"})
+		return
+	}
+	if filter.TargetUserID, err = parseOptionalID(query.Get("target_user_id")); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid target_user_id"})
+		return
+	}
+	for _, date := range []string{filter.DateFrom, filter.DateTo} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid date format, expected YYYY-MM-DD"})
+			return
+		}
+	}
+
+	if p, err := strconv.Atoi(query.Get("page")); err == nil && p > Zero {
+		filter.Page = p
+	}
+	if ps, err := strconv.Atoi(query.Get("pageSize")); err == nil && ps > Zero && ps <= MaxPageSize {
+		filter.PageSize = ps
+	}
+
+	entries, total, err := h.auditService.List(filter)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to get audit log: " + err.Error()})
+		return
+	}
+
+	response := make([]map[string]interface{}, len(entries))
+	for i, e := range entries {
+		response[i] = map[string]interface{}{
+			"id":             e.ID,
+			"actor_id":       getIntValue(e.ActorID),
+			"target_user_id": getIntValue(e.TargetUserID),
+			"target_type":    e.TargetType,
+			"target_id":      getStringValue(e.TargetID),
+			"action":         e.Action,
+			"before":         e.Before,
+			"after":          e.After,
+			"ip":             getStringValue(e.IP),
+			"user_agent":     getStringValue(e.UserAgent),
+			"created_at":     e.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"data": response,
+		"pagination": map[string]interface{}{
+			"current_page": filter.Page,
+			"page_size":    filter.PageSize,
+			"total_items":  total,
+			"total_pages":  (total + filter.PageSize - 1) / filter.PageSize,
+		},
+	})
+}
+
+// auditMeta - автор действия, его IP и User-Agent для журнала
+func auditMeta(r *http.Request, actor *entity.Users) entity.AuditMeta {
+	return entity.AuditMeta{
+		ActorID:   actor.ID,
+		IP:        clientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
+// recordJobCreated пишет в журнал создание задания на загрузку данных
+func recordJobCreated(auditService *audit.AuditService, r *http.Request, actor *entity.Users, ownerID int, table string, jobID int, job map[string]interface{}) {
+	auditService.Record(auditMeta(r, actor), entity.AuditLog{
+		TargetUserID: sql.NullInt64{Int64: int64(ownerID), Valid: true},
+		TargetType:   table,
+		TargetID:     sql.NullString{String: strconv.Itoa(jobID), Valid: true},
+		Action:       entity.AuditJobCreate,
+		After:        job,
+	})
+}
+
+// clientIP - адрес клиента. За доверенным прокси middleware.RealIP уже подставил в RemoteAddr
+// адрес из заголовков прокси, от остальных клиентов заголовки не учитываются
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func parseOptionalID(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.Atoi(value)
+	if err != nil || id < 0 {
+		return 0, fmt.Errorf("invalid id %q", value)
+	}
+	return id, nil
+}
+
+// Вспомогательная функция для получения пользователя из JWT
+func (h *AuditHandler) getUserFromRequest(r *http.Request) (*entity.Users, error) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return nil, fmt.Errorf("no authorization header")
+	}
+
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return h.jwtSecret, nil
+	})
+
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+
+	username, ok := claims["username"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid username in token")
+	}
+
+	return h.userRepo.GetByUsername(username)
+}
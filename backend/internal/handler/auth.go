@@ -8,7 +8,9 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"wbrost-go/internal/api/ozon"
 	"wbrost-go/internal/api/wb"
+	"wbrost-go/internal/crypto"
 	dto2 "wbrost-go/internal/dto"
 	"wbrost-go/internal/entity"
 	"wbrost-go/internal/repository/user"
@@ -107,15 +109,14 @@ func (h *AuthHandler) GetUsersList(w http.ResponseWriter, r *http.Request) {
 			"id":          user.ID,
 			"taxes":       user.Taxes,
 			"username":    user.Username,
-			"password":    user.PasswordHash,
 			"email":       user.Email,
 			"admin":       user.Admin,
 			"block":       user.Block,
 			"pro":         user.Pro,
 			"name":        user.Name,
 			"phone":       phone,
-			"wb_key":      user.WbKey,
-			"ozon_key":    user.OzonKey,
+			"wb_key":      crypto.Mask(getStringValue(user.WbKey)),
+			"ozon_key":    crypto.Mask(getStringValue(user.OzonKey)),
 			"ozon_status": user.OzonStatus,
 			"created_at":  createdDate,
 			"updated_at":  updatedDate,
@@ -187,16 +188,18 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	response := dto2.LoginResponse{
 		Token: tokenString,
 		User: dto2.UserResponse{
-			ID:        user.ID,
-			Name:      getStringPtrFromNullString(user.Name),
-			Username:  user.Username,
-			Email:     getStringPtrFromNullString(user.Email),
-			Pro:       user.Pro,
-			Taxes:     user.Taxes,
-			WbKey:     getStringPtrFromNullString(user.WbKey),
-			Phone:     getStringPtrFromNullString(user.Phone),
-			Admin:     user.Admin,
-			CreatedAt: user.CreatedAt,
+			ID:           user.ID,
+			Name:         getStringPtrFromNullString(user.Name),
+			Username:     user.Username,
+			Email:        getStringPtrFromNullString(user.Email),
+			Pro:          user.Pro,
+			Taxes:        user.Taxes,
+			WbKey:        getMaskedPtrFromNullString(user.WbKey),
+			OzonKey:      getMaskedPtrFromNullString(user.OzonKey),
+			OzonClientID: getStringPtrFromNullString(user.OzonClientID),
+			Phone:        getStringPtrFromNullString(user.Phone),
+			Admin:        user.Admin,
+			CreatedAt:    user.CreatedAt,
 		},
 	}
 
@@ -291,16 +294,18 @@ func (h *AuthHandler) Signup(w http.ResponseWriter, r *http.Request) {
 	response := dto2.LoginResponse{
 		Token: tokenString,
 		User: dto2.UserResponse{
-			ID:        user.ID,
-			Name:      getStringPtrFromNullString(user.Name),
-			Username:  user.Username,
-			Email:     getStringPtrFromNullString(user.Email),
-			Pro:       user.Pro,
-			Taxes:     user.Taxes,
-			WbKey:     getStringPtrFromNullString(user.WbKey),
-			Phone:     getStringPtrFromNullString(user.Phone),
-			Admin:     user.Admin,
-			CreatedAt: user.CreatedAt,
+			ID:           user.ID,
+			Name:         getStringPtrFromNullString(user.Name),
+			Username:     user.Username,
+			Email:        getStringPtrFromNullString(user.Email),
+			Pro:          user.Pro,
+			Taxes:        user.Taxes,
+			WbKey:        getMaskedPtrFromNullString(user.WbKey),
+			OzonKey:      getMaskedPtrFromNullString(user.OzonKey),
+			OzonClientID: getStringPtrFromNullString(user.OzonClientID),
+			Phone:        getStringPtrFromNullString(user.Phone),
+			Admin:        user.Admin,
+			CreatedAt:    user.CreatedAt,
 		},
 	}
 
@@ -324,6 +329,15 @@ func getStringPtrFromNullString(ns sql.NullString) *string {
 	return nil
 }
 
+// getMaskedPtrFromNullString - маска секрета для ответа API (сам ключ наружу не отдаем)
+func getMaskedPtrFromNullString(ns sql.NullString) *string {
+	if ns.Valid && ns.String != "" {
+		s := crypto.Mask(ns.String)
+		return &s
+	}
+	return nil
+}
+
 // GetCurrentUser - получаем текущего юзера
 func (h *AuthHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	// Извлекаем токен из заголовка
@@ -368,16 +382,18 @@ func (h *AuthHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 
 	// Формируем ответ с текущими данными
 	response := dto2.UserResponse{
-		ID:        user.ID,
-		Name:      getStringPtrFromNullString(user.Name),
-		Username:  user.Username,
-		Email:     getStringPtrFromNullString(user.Email),
-		Pro:       user.Pro,
-		Taxes:     user.Taxes,
-		WbKey:     getStringPtrFromNullString(user.WbKey),
-		Phone:     getStringPtrFromNullString(user.Phone),
-		Admin:     user.Admin,
-		CreatedAt: user.CreatedAt,
+		ID:           user.ID,
+		Name:         getStringPtrFromNullString(user.Name),
+		Username:     user.Username,
+		Email:        getStringPtrFromNullString(user.Email),
+		Pro:          user.Pro,
+		Taxes:        user.Taxes,
+		WbKey:        getMaskedPtrFromNullString(user.WbKey),
+		OzonKey:      getMaskedPtrFromNullString(user.OzonKey),
+		OzonClientID: getStringPtrFromNullString(user.OzonClientID),
+		Phone:        getStringPtrFromNullString(user.Phone),
+		Admin:        user.Admin,
+		CreatedAt:    user.CreatedAt,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -425,20 +441,75 @@ func (h *AuthHandler) GetApiKeysStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 5. Проверяем WB ключ
+	wbStatus := h.wbKeyStatus(user)
+
+	// 6. Проверяем ключ Ozon
+	ozonStatus := h.ozonKeyStatus(user)
+
+	// 7. Возвращаем ответ
+	response := map[string]interface{}{
+		"wildberries": wbStatus,
+		"ozon":        ozonStatus,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// wbKeyStatus собирает статус API ключа WB: срок действия, категории доступа,
+// продавца и функции приложения, которые ключ может обслуживать
+func (h *AuthHandler) wbKeyStatus(user *entity.Users) map[string]interface{} {
 	wbStatus := map[string]interface{}{
 		"has_token": user.WbKey.Valid && user.WbKey.String != "",
 		"active":    false,
 		"message":   "Не настроен",
 	}
 
-	if user.WbKey.Valid && user.WbKey.String != "" {
+	if !user.WbKey.Valid || user.WbKey.String == "" {
+		return wbStatus
+	}
+
+	info, err := h.authService.SyncWbKeyInfo(user)
+	if info == nil {
+		wbStatus["message"] = "Неверный формат токена: " + err.Error()
+		return wbStatus
+	}
+	if err != nil {
+		fmt.Printf("Failed to save wb key info for user %d: %v\n", user.ID, err)
+	}
+
+	now := time.Now()
+	expired := info.Expired(now)
+
+	scopes := make([]map[string]interface{}, 0)
+	for _, scope := range info.GrantedScopes() {
+		scopes = append(scopes, map[string]interface{}{
+			"code": int(scope),
+			"name": wb.ScopeNames[scope],
+		})
+	}
+
+	wbStatus["seller_id"] = info.SellerID
+	wbStatus["seller_uuid"] = info.SellerUUID
+	wbStatus["scopes"] = scopes
+	wbStatus["read_only"] = info.ReadOnly()
+	wbStatus["test"] = info.Test
+	wbStatus["expires_at"] = nil
+	wbStatus["days_left"] = nil
+	if !info.ExpiresAt.IsZero() {
+		wbStatus["expires_at"] = info.ExpiresAt.Format("2006-01-02 15:04:05")
+		wbStatus["days_left"] = info.DaysLeft(now)
+	}
+
+	if expired {
+		wbStatus["message"] = "Срок действия токена истек"
+	} else {
 		// СОЗДАЕМ WB КЛИЕНТ И ПРОВЕРЯЕМ ТОКЕН
 		wbClient := wb.NewWBClient(user.WbKey.String)
 		isValid, err := wbClient.CheckToken()
 
 		if err != nil {
 			// Ошибка при проверке (сеть, timeout и т.д.)
-			wbStatus["active"] = false
 			wbStatus["message"] = "Ошибка проверки: " + err.Error()
 		} else if isValid {
 			// Токен рабочий!
@@ -446,23 +517,71 @@ func (h *AuthHandler) GetApiKeysStatus(w http.ResponseWriter, r *http.Request) {
 			wbStatus["message"] = "Активен"
 		} else {
 			// Токен невалидный (WB API вернул ошибку)
-			wbStatus["active"] = false
 			wbStatus["message"] = "Токен недействителен"
 		}
-		//// Здесь будет проверка через WB API
-		//// Пока заглушка - всегда true
-		//wbStatus["active"] = true
-		//wbStatus["message"] = "Активен"
 	}
 
-	// 6. Возвращаем ответ
-	response := map[string]interface{}{
-		"wildberries": wbStatus,
+	// Какие функции приложения может обслуживать токен
+	features := make([]map[string]interface{}, len(wb.Features))
+	for i, feature := range wb.Features {
+		features[i] = map[string]interface{}{
+			"code":      feature.Code,
+			"name":      feature.Name,
+			"scope":     wb.ScopeNames[feature.Scope],
+			"available": !expired && info.HasScope(feature.Scope),
+		}
 	}
+	wbStatus["features"] = features
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	return wbStatus
+}
+
+// ozonKeyStatus проверяет пару Client-Id + Api-Key запросом к Ozon Seller API
+// и сохраняет результат проверки в users.ozon_status
+func (h *AuthHandler) ozonKeyStatus(user *entity.Users) map[string]interface{} {
+	hasToken := user.OzonKey.Valid && user.OzonKey.String != ""
+	ozonStatus := map[string]interface{}{
+		"has_token": hasToken,
+		"active":    false,
+		"message":   "Не настроен",
+		"client_id": getStringValue(user.OzonClientID),
+	}
+
+	if !hasToken {
+		return ozonStatus
+	}
+
+	if !user.OzonClientID.Valid || user.OzonClientID.String == "" {
+		ozonStatus["message"] = "Не указан Client-Id"
+		return ozonStatus
+	}
+
+	ozonClient := ozon.NewOzonClient(user.OzonClientID.String, user.OzonKey.String)
+	isValid, err := ozonClient.CheckKey()
+
+	status := entity.OzonStatusInvalid
+	switch {
+	case err != nil:
+		// Ошибка при проверке (сеть, timeout и т.д.) - статус ключа не меняем
+		ozonStatus["message"] = "Ошибка проверки: " + err.Error()
+		return ozonStatus
+	case isValid:
+		status = entity.OzonStatusActive
+		ozonStatus["active"] = true
+		ozonStatus["message"] = "Активен"
+	default:
+		ozonStatus["message"] = "Ключ недействителен"
+	}
+
+	if user.OzonStatus != status {
+		if err := h.userRepo.UpdateOzonStatus(user.ID, status); err != nil {
+			fmt.Printf("Failed to save ozon key status for user %d: %v\n", user.ID, err)
+		}
+	}
+
+	return ozonStatus
 }
+
 func (h *AuthHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	// 1. Проверяем авторизацию
 	authHeader := r.Header.Get("Authorization")
@@ -501,6 +620,9 @@ func (h *AuthHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Состояние до изменений - для журнала
+	before := *currentUser
+
 	// 4. Парсим данные из запроса
 	var updateData map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&updateData); err != nil {
@@ -524,9 +646,29 @@ func (h *AuthHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	if taxesStr, ok := updateData["taxes"].(float64); ok {
 		currentUser.Taxes = int(taxesStr)
 	}
-	// 6. Обновляем WB токен (если изменился)
-	if wbKey, ok := updateData["wb_key"].(string); ok {
-		currentUser.WbKey = sql.NullString{String: wbKey, Valid: wbKey != ""}
+	// 6. Обновляем WB токен (если изменился) вместе с данными из его payload
+	// Маску текущего ключа фронтенд присылает обратно без изменений - ее пропускаем
+	if wbKey, ok := updateData["wb_key"].(string); ok && !crypto.IsMasked(wbKey, currentUser.WbKey.String) {
+		if err := h.authService.SetWbKey(currentUser, strings.TrimSpace(wbKey)); err != nil {
+			respondWithJSON(w, http.StatusBadRequest, dto2.ErrorResponse{Error: "Неверный API ключ WB: " + err.Error()})
+			return
+		}
+	}
+
+	// Ключ Ozon - пара Client-Id + Api-Key. При изменении статус ключа сбрасывается
+	// до следующей проверки
+	if clientID, ok := updateData["ozon_client_id"].(string); ok {
+		clientID = strings.TrimSpace(clientID)
+		if clientID != currentUser.OzonClientID.String {
+			currentUser.OzonClientID = sql.NullString{String: clientID, Valid: clientID != ""}
+			currentUser.OzonStatus = entity.OzonStatusUnchecked
+		}
+	}
+
+	if ozonKey, ok := updateData["ozon_key"].(string); ok && !crypto.IsMasked(ozonKey, currentUser.OzonKey.String) {
+		ozonKey = strings.TrimSpace(ozonKey)
+		currentUser.OzonKey = sql.NullString{String: ozonKey, Valid: ozonKey != ""}
+		currentUser.OzonStatus = entity.OzonStatusUnchecked
 	}
 
 	// 7. Обновляем пароль (если указан новый)
@@ -547,7 +689,15 @@ func (h *AuthHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 9. Возвращаем успешный ответ
+	h.authService.RecordProfileUpdate(auditMeta(r, currentUser), &before, currentUser)
+
+	// 9. Ключ из профиля - это ключ кабинета по умолчанию
+	if err := h.authService.SyncDefaultAccount(currentUser); err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto2.ErrorResponse{Error: "Failed to sync default account: " + err.Error()})
+		return
+	}
+
+	// 10. Возвращаем успешный ответ
 	respondWithJSON(w, http.StatusOK, map[string]interface{}{
 		"success": true,
 		"message": "Profile updated successfully",
@@ -614,7 +764,7 @@ func (h *AuthHandler) UpdateUserParams(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Обрабатываем данные
-	err = h.authService.UpdateUserFromParams(req.UserId, req.ActionType, req.Value)
+	err = h.authService.UpdateUserFromParams(auditMeta(r, currentUser), req.UserId, req.ActionType, req.Value)
 	if err != nil {
 		http.Error(w, "Failed to update user", http.StatusInternalServerError)
 		return
@@ -0,0 +1,435 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"wbrost-go/internal/dto"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/repository/user"
+	"wbrost-go/internal/service/organization"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// OrganizationHeader - заголовок с ID организации, в контексте которой выполняется запрос.
+// Без него (и без параметра organization_id) используется собственная организация пользователя
+const OrganizationHeader = "X-Organization-ID"
+
+type OrganizationsHandler struct {
+	userRepo   *user.UserRepository
+	orgService *organization.OrganizationService
+	jwtSecret  []byte
+}
+
+func NewOrganizationsHandler(
+	userRepo *user.UserRepository,
+	orgService *organization.OrganizationService,
+	jwtSecret string,
+) *OrganizationsHandler {
+	return &OrganizationsHandler{
+		userRepo:   userRepo,
+		orgService: orgService,
+		jwtSecret:  []byte(jwtSecret),
+	}
+}
+
+// authorize определяет организацию запроса и проверяет право роли пользователя.
+// Возвращает HTTP статус для ответа при ошибке
+func authorize(orgService *organization.OrganizationService, u *entity.Users, r *http.Request, perm entity.Permission) (*organization.Access, int, error) {
+	raw := r.Header.Get(OrganizationHeader)
+	if raw == "" {
+		raw = r.URL.Query().Get("organization_id")
+	}
+
+	organizationID := 0
+	if raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil || id < 0 {
+			return nil, http.StatusBadRequest, fmt.Errorf("Invalid organization_id")
+		}
+		organizationID = id
+	}
+
+	access, err := orgService.Access(u, organizationID)
+	if err != nil {
+		if err == organization.ErrNoAccess {
+			return nil, http.StatusForbidden, err
+		}
+		return nil, http.StatusInternalServerError, fmt.Errorf("Failed to get organization: %v", err)
+	}
+
+	if !access.Can(perm) {
+		return nil, http.StatusForbidden, organization.ErrForbidden
+	}
+
+	return access, http.StatusOK, nil
+}
+
+// orgErrorStatus - HTTP статус для ошибок сервиса организаций
+func orgErrorStatus(err error) int {
+	switch err {
+	case organization.ErrForbidden, organization.ErrNoAccess, organization.ErrOwnerImmutable:
+		return http.StatusForbidden
+	case organization.ErrInviteNotFound:
+		return http.StatusNotFound
+	case organization.ErrInvalidRole, organization.ErrAlreadyMember, organization.ErrInviteRecipient:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GetOrganizations - GET /api/organizations | Организации пользователя и его роли в них
+func (h *OrganizationsHandler) GetOrganizations(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	memberships, err := h.orgService.GetMemberships(user)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to get organizations: " + err.Error()})
+		return
+	}
+
+	response := make([]map[string]interface{}, len(memberships))
+	for i, m := range memberships {
+		response[i] = map[string]interface{}{
+			"id":          m.Organization.ID,
+			"name":        m.Organization.Name,
+			"role":        m.Role,
+			"is_personal": m.Organization.OwnerID == user.ID,
+			"permissions": entity.RolePermissions[m.Role],
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// UpdateOrganization - PUT /api/organizations | Переименовать организацию (владелец)
+func (h *OrganizationsHandler) UpdateOrganization(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	access, status, err := authorize(h.orgService, user, r, entity.PermManageMembers)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Name is required"})
+		return
+	}
+
+	if err := h.orgService.Rename(access, strings.TrimSpace(req.Name)); err != nil {
+		respondWithJSON(w, orgErrorStatus(err), dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Организация переименована",
+	})
+}
+
+// GetMembers - GET /api/organizations/members | Участники организации и ожидающие приглашения
+func (h *OrganizationsHandler) GetMembers(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	members, users, err := h.orgService.GetMembers(access)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to get members: " + err.Error()})
+		return
+	}
+
+	membersResponse := make([]map[string]interface{}, len(members))
+	for i, m := range members {
+		item := map[string]interface{}{
+			"user_id":    m.UserID,
+			"role":       m.Role,
+			"created_at": m.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if u, ok := users[m.UserID]; ok {
+			item["username"] = u.Username
+			item["name"] = getStringValue(u.Name)
+			item["email"] = getStringValue(u.Email)
+		}
+		membersResponse[i] = item
+	}
+
+	response := map[string]interface{}{
+		"organization": map[string]interface{}{
+			"id":   access.Organization.ID,
+			"name": access.Organization.Name,
+			"role": access.Role,
+		},
+		"members": membersResponse,
+		"invites": []map[string]interface{}{},
+	}
+
+	// Приглашения видит только тот, кто может управлять участниками
+	if access.Can(entity.PermManageMembers) {
+		invites, err := h.orgService.GetPendingInvites(access)
+		if err != nil {
+			respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to get invites: " + err.Error()})
+			return
+		}
+		response["invites"] = invitesResponse(invites, nil)
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// UpdateMember - PUT /api/organizations/members | Изменить роль участника (владелец)
+func (h *OrganizationsHandler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	access, status, err := authorize(h.orgService, user, r, entity.PermManageMembers)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req struct {
+		UserID int    `json:"user_id"`
+		Role   string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	if err := h.orgService.UpdateMemberRole(access, req.UserID, req.Role); err != nil {
+		respondWithJSON(w, orgErrorStatus(err), dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Роль участника изменена",
+	})
+}
+
+// RemoveMember - DELETE /api/organizations/members?user_id= | Исключить участника или выйти из организации
+func (h *OrganizationsHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	userID, err := strconv.Atoi(r.URL.Query().Get("user_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid user_id"})
+		return
+	}
+
+	if err := h.orgService.RemoveMember(access, userID); err != nil {
+		respondWithJSON(w, orgErrorStatus(err), dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Участник исключен из организации",
+	})
+}
+
+// CreateInvite - POST /api/organizations/invites | Пригласить пользователя по email или username (владелец)
+func (h *OrganizationsHandler) CreateInvite(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	access, status, err := authorize(h.orgService, user, r, entity.PermManageMembers)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req struct {
+		Email    string `json:"email"`
+		Username string `json:"username"`
+		Role     string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	invite, err := h.orgService.Invite(access, req.Email, req.Username, req.Role)
+	if err != nil {
+		respondWithJSON(w, orgErrorStatus(err), dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"id":      invite.ID,
+		"success": true,
+		"message": "Приглашение отправлено",
+	})
+}
+
+// RevokeInvite - DELETE /api/organizations/invites?id= | Отозвать приглашение (владелец)
+func (h *OrganizationsHandler) RevokeInvite(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	access, status, err := authorize(h.orgService, user, r, entity.PermManageMembers)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	inviteID, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid invite id"})
+		return
+	}
+
+	if err := h.orgService.RevokeInvite(access, inviteID); err != nil {
+		respondWithJSON(w, orgErrorStatus(err), dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Приглашение отозвано",
+	})
+}
+
+// GetMyInvites - GET /api/organizations/invites | Приглашения, адресованные текущему пользователю
+func (h *OrganizationsHandler) GetMyInvites(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	invites, err := h.orgService.GetInvitesFor(user)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to get invites: " + err.Error()})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, invitesResponse(invites, h.orgService))
+}
+
+// RespondInvite - POST /api/organizations/invites/respond | Принять или отклонить приглашение
+func (h *OrganizationsHandler) RespondInvite(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	var req struct {
+		ID     int  `json:"id"`
+		Accept bool `json:"accept"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	if err := h.orgService.RespondInvite(user, req.ID, req.Accept); err != nil {
+		respondWithJSON(w, orgErrorStatus(err), dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	message := "Приглашение отклонено"
+	if req.Accept {
+		message = "Приглашение принято"
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": message,
+	})
+}
+
+// invitesResponse форматирует приглашения. С orgService добавляется название организации
+func invitesResponse(invites []entity.OrganizationInvite, orgService *organization.OrganizationService) []map[string]interface{} {
+	response := make([]map[string]interface{}, len(invites))
+	for i, invite := range invites {
+		item := map[string]interface{}{
+			"id":              invite.ID,
+			"organization_id": invite.OrganizationID,
+			"email":           getStringValue(invite.Email),
+			"username":        getStringValue(invite.Username),
+			"role":            invite.Role,
+			"created_at":      invite.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if orgService != nil {
+			if org, err := orgService.GetOrganization(invite.OrganizationID); err == nil {
+				item["organization_name"] = org.Name
+			}
+		}
+		response[i] = item
+	}
+	return response
+}
+
+func (h *OrganizationsHandler) getUserFromRequest(r *http.Request) (*entity.Users, error) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return nil, fmt.Errorf("no authorization header")
+	}
+
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return h.jwtSecret, nil
+	})
+
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+
+	username, ok := claims["username"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid username in token")
+	}
+
+	return h.userRepo.GetByUsername(username)
+}
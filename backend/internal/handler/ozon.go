@@ -0,0 +1,338 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"wbrost-go/internal/dto"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/repository/ozon"
+	"wbrost-go/internal/repository/user"
+	"wbrost-go/internal/service/audit"
+	"wbrost-go/internal/service/organization"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+type OzonHandler struct {
+	userRepo        *user.UserRepository
+	jobRepo         *ozon.OzonGetRepository
+	transactionRepo *ozon.TransactionRepository
+	productRepo     *ozon.ProductRepository
+	orgService      *organization.OrganizationService
+	auditService    *audit.AuditService
+	jwtSecret       []byte
+}
+
+func NewOzonHandler(
+	userRepo *user.UserRepository,
+	jobRepo *ozon.OzonGetRepository,
+	transactionRepo *ozon.TransactionRepository,
+	productRepo *ozon.ProductRepository,
+	orgService *organization.OrganizationService,
+	auditService *audit.AuditService,
+	jwtSecret string,
+) *OzonHandler {
+	return &OzonHandler{
+		userRepo:        userRepo,
+		jobRepo:         jobRepo,
+		transactionRepo: transactionRepo,
+		productRepo:     productRepo,
+		orgService:      orgService,
+		auditService:    auditService,
+		jwtSecret:       []byte(jwtSecret),
+	}
+}
+
+// GetJobs - GET /api/ozon/jobs | Список заданий на загрузку данных Ozon
+func (h *OzonHandler) GetJobs(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	jobs, err := h.jobRepo.GetByUserID(access.OwnerID())
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to get ozon jobs"})
+		return
+	}
+
+	response := make([]map[string]interface{}, len(jobs))
+	for i, job := range jobs {
+		response[i] = map[string]interface{}{
+			"id":         job.ID,
+			"user_id":    job.UserID,
+			"type":       job.Type,
+			"status":     getStatusValue(job.Status),
+			"date_from":  getStringValue(job.DateFrom),
+			"date_to":    getStringValue(job.DateTo),
+			"created":    job.Created.Format("2006-01-02 15:04:05"),
+			"updated":    job.Updated.Format("2006-01-02 15:04:05"),
+			"last_error": getStringValue(job.LastError),
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// CreateJob - POST /api/ozon/jobs | Заказать загрузку финансовых операций за период или списка товаров
+func (h *OzonHandler) CreateJob(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermRequestData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req struct {
+		Type     string `json:"type"`
+		DateFrom string `json:"dateFrom"`
+		DateTo   string `json:"dateTo"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	owner := access.Owner
+	if !owner.OzonKey.Valid || owner.OzonKey.String == "" || !owner.OzonClientID.Valid || owner.OzonClientID.String == "" {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Ключ Ozon не настроен (нужны Client-Id и Api-Key)"})
+		return
+	}
+
+	job := &entity.OzonGet{
+		UserID: access.OwnerID(),
+		Type:   strings.TrimSpace(req.Type),
+		Status: getNullInt64(entity.StatusWait),
+	}
+
+	switch job.Type {
+	case entity.OzonJobTransactions:
+		if req.DateFrom == "" || req.DateTo == "" {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "DateFrom and DateTo are required"})
+			return
+		}
+		from, errFrom := time.Parse("2006-01-02", req.DateFrom)
+		to, errTo := time.Parse("2006-01-02", req.DateTo)
+		if errFrom != nil || errTo != nil || to.Before(from) {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid date range, expected YYYY-MM-DD"})
+			return
+		}
+		job.DateFrom = sql.NullString{String: req.DateFrom, Valid: true}
+		job.DateTo = sql.NullString{String: req.DateTo, Valid: true}
+	case entity.OzonJobProducts:
+	default:
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{
+			Error: fmt.Sprintf("type must be %q or %q", entity.OzonJobTransactions, entity.OzonJobProducts),
+		})
+		return
+	}
+
+	if err := h.jobRepo.Create(job); err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to create ozon job: " + err.Error()})
+		return
+	}
+
+	recordJobCreated(h.auditService, r, user, access.OwnerID(), "ozon_get", job.ID, map[string]interface{}{
+		"type":      job.Type,
+		"date_from": getStringValue(job.DateFrom),
+		"date_to":   getStringValue(job.DateTo),
+	})
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"id":      job.ID,
+		"success": true,
+		"message": "Задание поставлено в очередь",
+	})
+}
+
+// GetProducts - GET /api/ozon/products | Товары Ozon с пагинацией
+func (h *OzonHandler) GetProducts(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	page, pageSize := ozonPagination(r)
+
+	products, err := h.productRepo.GetByUserID(access.OwnerID(), page, pageSize)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	totalCount, err := h.productRepo.GetCountByUserID(access.OwnerID())
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	data := make([]map[string]interface{}, len(products))
+	for i, p := range products {
+		data[i] = map[string]interface{}{
+			"id":         p.ID,
+			"product_id": p.ProductID,
+			"offer_id":   getStringValue(p.OfferID),
+			"sku":        getIntValue(p.Sku),
+			"name":       getStringValue(p.Name),
+			"barcode":    getStringValue(p.Barcode),
+			"photo":      getStringValue(p.Photo),
+			"price":      p.Price.Float64,
+			"archived":   p.Archived,
+			"cost_price": p.CostPrice.Float64,
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"data": data,
+		"pagination": map[string]interface{}{
+			"current_page": page,
+			"page_size":    pageSize,
+			"total_items":  totalCount,
+			"total_pages":  int(math.Ceil(float64(totalCount) / float64(pageSize))),
+		},
+	})
+}
+
+// GetStats - GET /api/ozon/stats | Итоги по финансовым операциям Ozon за период и разбивка по товарам
+func (h *OzonHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	dateFrom := r.URL.Query().Get("dateFrom")
+	dateTo := r.URL.Query().Get("dateTo")
+
+	if dateFrom == "" || dateTo == "" {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Parameters dateFrom and dateTo are required",
+		})
+		return
+	}
+
+	if _, err := time.Parse("2006-01-02", dateFrom); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid dateFrom format, expected YYYY-MM-DD",
+		})
+		return
+	}
+
+	if _, err := time.Parse("2006-01-02", dateTo); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid dateTo format, expected YYYY-MM-DD",
+		})
+		return
+	}
+
+	page, pageSize := ozonPagination(r)
+
+	data, err := h.transactionRepo.GetBySku(access.OwnerID(), dateFrom, dateTo, page, pageSize)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	totalCount, err := h.transactionRepo.GetSkuCount(access.OwnerID(), dateFrom, dateTo)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	summary, err := h.transactionRepo.GetSummary(access.OwnerID(), dateFrom, dateTo)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"data":    data,
+		"summary": summary,
+		"taxes":   access.Owner.Taxes,
+		"pagination": map[string]interface{}{
+			"current_page": page,
+			"page_size":    pageSize,
+			"total_items":  totalCount,
+			"total_pages":  int(math.Ceil(float64(totalCount) / float64(pageSize))),
+		},
+	})
+}
+
+// ozonPagination - параметры page/pageSize запроса (по умолчанию 1 и 20, не больше 100 на странице)
+func ozonPagination(r *http.Request) (int, int) {
+	page := 1
+	if p, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && p > 0 {
+		page = p
+	}
+
+	pageSize := 20
+	if ps, err := strconv.Atoi(r.URL.Query().Get("pageSize")); err == nil && ps > 0 && ps <= 100 {
+		pageSize = ps
+	}
+
+	return page, pageSize
+}
+
+func (h *OzonHandler) getUserFromRequest(r *http.Request) (*entity.Users, error) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return nil, fmt.Errorf("no authorization header")
+	}
+
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return h.jwtSecret, nil
+	})
+
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+
+	username, ok := claims["username"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid username in token")
+	}
+
+	return h.userRepo.GetByUsername(username)
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"wbrost-go/internal/dto"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/repository/user"
+	"wbrost-go/internal/service/audit"
+	"wbrost-go/internal/service/profile"
+
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type ProfileHandler struct {
+	userRepo       *user.UserRepository
+	profileService *profile.ProfileService
+	auditService   *audit.AuditService
+	jwtSecret      []byte
+}
+
+func NewProfileHandler(userRepo *user.UserRepository, profileService *profile.ProfileService, auditService *audit.AuditService, jwtSecret string) *ProfileHandler {
+	return &ProfileHandler{
+		userRepo:       userRepo,
+		profileService: profileService,
+		auditService:   auditService,
+		jwtSecret:      []byte(jwtSecret),
+	}
+}
+
+// ExportProfile - POST /api/profile/export | Выгрузка всех данных пользователя zip-архивом
+func (h *ProfileHandler) ExportProfile(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Архив собирается во временный файл: ошибка чтения из БД возвращается ошибкой,
+	// а не обрезанным архивом, переданным со статусом 200
+	file, err := os.CreateTemp("", "profile-export-*.zip")
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to create export file: " + err.Error()})
+		return
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	if err := h.profileService.Export(user, file); err != nil {
+		fmt.Printf("Failed to export data of user %d: %v\n", user.ID, err)
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to export data: " + err.Error()})
+		return
+	}
+
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to read export file: " + err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, profile.ExportFileName(user, time.Now())))
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, file); err != nil {
+		fmt.Printf("Failed to send export of user %d: %v\n", user.ID, err)
+	}
+}
+
+// GetDeletion - GET /api/profile | Назначенное удаление аккаунта
+func (h *ProfileHandler) GetDeletion(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	deletion, err := h.profileService.GetDeletion(user)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to get deletion: " + err.Error()})
+		return
+	}
+
+	response := map[string]interface{}{
+		"delete_scheduled":    deletion != nil,
+		"delete_requested_at": nil,
+		"delete_after":        nil,
+	}
+	if deletion != nil {
+		response["delete_requested_at"] = deletion.DeleteRequestedAt.Format("2006-01-02 15:04:05")
+		response["delete_after"] = deletion.DeleteAfter.Format("2006-01-02 15:04:05")
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// DeleteProfile - DELETE /api/profile | Запрос удаления аккаунта и всех данных после льготного периода
+func (h *ProfileHandler) DeleteProfile(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Удаление подтверждается паролем
+	var req struct {
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Неверный пароль"})
+		return
+	}
+
+	deleteAfter, err := h.profileService.ScheduleDeletion(user)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to schedule deletion: " + err.Error()})
+		return
+	}
+
+	h.auditService.Record(auditMeta(r, user), entity.AuditLog{
+		TargetUserID: sql.NullInt64{Int64: int64(user.ID), Valid: true},
+		TargetType:   entity.AuditTargetUser,
+		TargetID:     sql.NullString{String: strconv.Itoa(user.ID), Valid: true},
+		Action:       entity.AuditProfileDelete,
+		After:        map[string]interface{}{"delete_after": deleteAfter.Format("2006-01-02 15:04:05")},
+	})
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"success":      true,
+		"delete_after": deleteAfter.Format("2006-01-02 15:04:05"),
+		"message":      "Аккаунт и все данные будут удалены " + deleteAfter.Format("02.01.2006") + ". До этой даты удаление можно отменить",
+	})
+}
+
+// CancelDeletion - POST /api/profile/delete/cancel | Отмена удаления аккаунта
+func (h *ProfileHandler) CancelDeletion(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	if err := h.profileService.CancelDeletion(user); err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to cancel deletion: " + err.Error()})
+		return
+	}
+
+	h.auditService.Record(auditMeta(r, user), entity.AuditLog{
+		TargetUserID: sql.NullInt64{Int64: int64(user.ID), Valid: true},
+		TargetType:   entity.AuditTargetUser,
+		TargetID:     sql.NullString{String: strconv.Itoa(user.ID), Valid: true},
+		Action:       entity.AuditProfileRestore,
+	})
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Удаление аккаунта отменено",
+	})
+}
+
+// GetPendingDeletions - GET /api/admin/deletions | Аккаунты, ожидающие удаления (только админ)
+func (h *ProfileHandler) GetPendingDeletions(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+	if user.Admin < entity.UserAdmin {
+		respondWithJSON(w, http.StatusForbidden, dto.ErrorResponse{Error: "Admin rights required"})
+		return
+	}
+
+	deletions, err := h.profileService.GetPendingDeletions()
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to get deletions: " + err.Error()})
+		return
+	}
+
+	now := time.Now()
+	response := make([]map[string]interface{}, len(deletions))
+	for i, d := range deletions {
+		response[i] = map[string]interface{}{
+			"user_id":             d.UserID,
+			"username":            d.Username,
+			"email":               getStringValue(d.Email),
+			"delete_requested_at": d.DeleteRequestedAt.Format("2006-01-02 15:04:05"),
+			"delete_after":        d.DeleteAfter.Format("2006-01-02 15:04:05"),
+			"overdue":             !d.DeleteAfter.After(now),
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"data":  response,
+		"total": len(response),
+	})
+}
+
+// Вспомогательная функция для получения пользователя из JWT
+func (h *ProfileHandler) getUserFromRequest(r *http.Request) (*entity.Users, error) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return nil, fmt.Errorf("no authorization header")
+	}
+
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return h.jwtSecret, nil
+	})
+
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+
+	username, ok := claims["username"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid username in token")
+	}
+
+	return h.userRepo.GetByUsername(username)
+}
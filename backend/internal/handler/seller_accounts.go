@@ -0,0 +1,433 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"wbrost-go/internal/api/wb"
+	"wbrost-go/internal/crypto"
+	"wbrost-go/internal/dto"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/repository/account"
+	"wbrost-go/internal/repository/user"
+	"wbrost-go/internal/service/organization"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+type SellerAccountsHandler struct {
+	userRepo    *user.UserRepository
+	accountRepo *account.SellerAccountRepository
+	orgService  *organization.OrganizationService
+	jwtSecret   []byte
+}
+
+func NewSellerAccountsHandler(
+	userRepo *user.UserRepository,
+	accountRepo *account.SellerAccountRepository,
+	orgService *organization.OrganizationService,
+	jwtSecret string,
+) *SellerAccountsHandler {
+	return &SellerAccountsHandler{
+		userRepo:    userRepo,
+		accountRepo: accountRepo,
+		orgService:  orgService,
+		jwtSecret:   []byte(jwtSecret),
+	}
+}
+
+// GetAccounts - GET /api/accounts | Список кабинетов организации
+func (h *SellerAccountsHandler) GetAccounts(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	accounts, err := h.accountRepo.GetByUserID(access.OwnerID())
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to get accounts: " + err.Error()})
+		return
+	}
+
+	response := make([]map[string]interface{}, len(accounts))
+	for i := range accounts {
+		response[i] = accountResponse(&accounts[i])
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// CreateAccount - POST /api/accounts | Добавить кабинет (маркетплейс, название, API ключ)
+func (h *SellerAccountsHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermManageAccounts)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req struct {
+		Marketplace string `json:"marketplace"`
+		Name        string `json:"name"`
+		APIKey      string `json:"api_key"`
+		BusinessID  int64  `json:"business_id"`
+		CampaignID  int64  `json:"campaign_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	if req.Marketplace == "" {
+		req.Marketplace = entity.MarketplaceWB
+	}
+	if req.Marketplace != entity.MarketplaceWB && req.Marketplace != entity.MarketplaceYandex {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid marketplace. Use: wb, yandex"})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	req.APIKey = strings.TrimSpace(req.APIKey)
+	if req.Name == "" || req.APIKey == "" {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Name and api_key are required"})
+		return
+	}
+
+	sellerAccount := &entity.SellerAccount{
+		UserID:      access.OwnerID(),
+		Marketplace: req.Marketplace,
+		Name:        req.Name,
+	}
+
+	if sellerAccount.Marketplace == entity.MarketplaceYandex {
+		if req.BusinessID <= 0 || req.CampaignID <= 0 {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "business_id and campaign_id are required for Yandex Market"})
+			return
+		}
+		sellerAccount.BusinessID = sql.NullInt64{Int64: req.BusinessID, Valid: true}
+		sellerAccount.CampaignID = sql.NullInt64{Int64: req.CampaignID, Valid: true}
+	}
+
+	if err := setAccountKey(sellerAccount, req.APIKey); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Неверный API ключ: " + err.Error()})
+		return
+	}
+
+	if err := h.accountRepo.Create(sellerAccount); err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to create account: " + err.Error()})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"account": accountResponse(sellerAccount),
+	})
+}
+
+// UpdateAccount - PUT /api/accounts | Переименовать кабинет, заменить его API ключ или идентификаторы магазина
+func (h *SellerAccountsHandler) UpdateAccount(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermManageAccounts)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req struct {
+		ID         int    `json:"id"`
+		Name       string `json:"name"`
+		APIKey     string `json:"api_key"`
+		BusinessID int64  `json:"business_id"`
+		CampaignID int64  `json:"campaign_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	sellerAccount, err := h.getUserAccount(access.OwnerID(), req.ID)
+	if err != nil {
+		respondWithJSON(w, http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if name := strings.TrimSpace(req.Name); name != "" {
+		sellerAccount.Name = name
+	}
+
+	if sellerAccount.Marketplace == entity.MarketplaceYandex {
+		if req.BusinessID > 0 {
+			sellerAccount.BusinessID = sql.NullInt64{Int64: req.BusinessID, Valid: true}
+		}
+		if req.CampaignID > 0 {
+			sellerAccount.CampaignID = sql.NullInt64{Int64: req.CampaignID, Valid: true}
+		}
+	}
+
+	// Маску текущего ключа фронтенд присылает обратно без изменений - ее пропускаем
+	apiKey := strings.TrimSpace(req.APIKey)
+	if apiKey != "" && !crypto.IsMasked(apiKey, sellerAccount.APIKey.String) {
+		if err := setAccountKey(sellerAccount, apiKey); err != nil {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Неверный API ключ: " + err.Error()})
+			return
+		}
+	}
+
+	if err := h.accountRepo.Update(sellerAccount); err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to update account: " + err.Error()})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"account": accountResponse(sellerAccount),
+	})
+}
+
+// DeleteAccount - DELETE /api/accounts?id= | Удалить кабинет (загруженные данные сохраняются)
+func (h *SellerAccountsHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermManageAccounts)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	accountID, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid account id"})
+		return
+	}
+
+	sellerAccount, err := h.getUserAccount(access.OwnerID(), accountID)
+	if err != nil {
+		respondWithJSON(w, http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := h.accountRepo.Delete(sellerAccount.ID); err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to delete account: " + err.Error()})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Кабинет удален",
+	})
+}
+
+func (h *SellerAccountsHandler) getUserAccount(userID, accountID int) (*entity.SellerAccount, error) {
+	sellerAccount, err := h.accountRepo.GetByID(accountID)
+	if err != nil || sellerAccount.UserID != userID {
+		return nil, fmt.Errorf("Account not found")
+	}
+	return sellerAccount, nil
+}
+
+// resolveAccountID проверяет кабинет из параметра account_id.
+// Пустое значение или 0 - все кабинеты пользователя (entity.AllAccounts)
+func resolveAccountID(accountRepo *account.SellerAccountRepository, userID int, raw string) (int, error) {
+	if raw == "" {
+		return entity.AllAccounts, nil
+	}
+
+	accountID, err := strconv.Atoi(raw)
+	if err != nil || accountID < 0 {
+		return 0, fmt.Errorf("Invalid account_id")
+	}
+	if accountID == entity.AllAccounts {
+		return entity.AllAccounts, nil
+	}
+
+	sellerAccount, err := accountRepo.GetByID(accountID)
+	if err != nil || sellerAccount.UserID != userID {
+		return 0, fmt.Errorf("Account not found")
+	}
+
+	return accountID, nil
+}
+
+// resolveMarketplace проверяет фильтр маркетплейса дашбордов.
+// Пустое значение или all - все площадки (возвращается "")
+func resolveMarketplace(raw string) (string, error) {
+	switch raw {
+	case "", "all":
+		return "", nil
+	case entity.MarketplaceWB, entity.MarketplaceOzon, entity.MarketplaceYandex:
+		return raw, nil
+	default:
+		return "", fmt.Errorf("Invalid marketplace. Use: all, wb, ozon, yandex")
+	}
+}
+
+// marketplaceLabel - значение фильтра маркетплейса для ответа
+func marketplaceLabel(marketplace string) string {
+	if marketplace == "" {
+		return "all"
+	}
+	return marketplace
+}
+
+// errNotWBAccount - для задания WB указан кабинет другого маркетплейса
+var errNotWBAccount = errors.New("Account is not a Wildberries account")
+
+// jobAccountID возвращает кабинет WB для нового задания: указанный в запросе
+// или кабинет по умолчанию. NULL - у пользователя еще нет кабинетов (используется ключ профиля)
+func jobAccountID(accountRepo *account.SellerAccountRepository, userID, accountID int) (sql.NullInt64, error) {
+	if accountID != entity.AllAccounts {
+		sellerAccount, err := accountRepo.GetByID(accountID)
+		if err != nil {
+			return sql.NullInt64{}, err
+		}
+		if sellerAccount.Marketplace != entity.MarketplaceWB {
+			return sql.NullInt64{}, errNotWBAccount
+		}
+		return getNullInt64(accountID), nil
+	}
+
+	defaultAccount, err := accountRepo.GetDefault(userID, entity.MarketplaceWB)
+	if err != nil {
+		return sql.NullInt64{}, err
+	}
+	if defaultAccount == nil {
+		return sql.NullInt64{}, nil
+	}
+
+	return getNullInt64(defaultAccount.ID), nil
+}
+
+// respondJobAccountError отвечает на ошибку jobAccountID: кабинет другого маркетплейса - 400,
+// кабинет не найден - 404, остальное - ошибка БД
+func respondJobAccountError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errNotWBAccount):
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, account.ErrAccountNotFound):
+		respondWithJSON(w, http.StatusNotFound, dto.ErrorResponse{Error: "Account not found"})
+	default:
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to get account: " + err.Error()})
+	}
+}
+
+// setAccountKey сохраняет ключ в кабинет. Ключ WB разбирается, данные из payload
+// (срок действия, категории, продавец) сохраняются вместе с ним. Ключ Яндекс Маркета
+// непрозрачный - проверяется при загрузке данных
+func setAccountKey(a *entity.SellerAccount, apiKey string) error {
+	if a.Marketplace != entity.MarketplaceWB {
+		a.APIKey = sql.NullString{String: apiKey, Valid: true}
+		return nil
+	}
+
+	info, err := wb.ParseToken(apiKey)
+	if err != nil {
+		return err
+	}
+
+	a.APIKey = sql.NullString{String: apiKey, Valid: true}
+	a.KeyExpiresAt = sql.NullTime{Time: info.ExpiresAt, Valid: !info.ExpiresAt.IsZero()}
+	a.KeyScopes = sql.NullInt64{Int64: info.Scopes, Valid: true}
+	a.SellerID = sql.NullInt64{Int64: info.SellerID, Valid: info.SellerID != 0}
+	a.SellerUUID = sql.NullString{String: info.SellerUUID, Valid: info.SellerUUID != ""}
+	return nil
+}
+
+func accountResponse(a *entity.SellerAccount) map[string]interface{} {
+	response := map[string]interface{}{
+		"id":          a.ID,
+		"name":        a.Name,
+		"marketplace": a.Marketplace,
+		"api_key":     crypto.Mask(getStringValue(a.APIKey)),
+		"is_default":  a.IsDefault == 1,
+		"seller_id":   getIntValue(a.SellerID),
+		"seller_uuid": getStringValue(a.SellerUUID),
+		"business_id": getIntValue(a.BusinessID),
+		"campaign_id": getIntValue(a.CampaignID),
+		"expires_at":  nil,
+		"days_left":   nil,
+		"expired":     false,
+		"scopes":      []map[string]interface{}{},
+		"created_at":  a.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if a.KeyExpiresAt.Valid {
+		info := &wb.TokenInfo{ExpiresAt: a.KeyExpiresAt.Time}
+		response["expires_at"] = a.KeyExpiresAt.Time.Format("2006-01-02 15:04:05")
+		response["days_left"] = info.DaysLeft(time.Now())
+		response["expired"] = info.Expired(time.Now())
+	}
+
+	if a.KeyScopes.Valid {
+		info := &wb.TokenInfo{Scopes: a.KeyScopes.Int64}
+		scopes := []map[string]interface{}{}
+		for _, scope := range info.GrantedScopes() {
+			scopes = append(scopes, map[string]interface{}{
+				"code": int(scope),
+				"name": wb.ScopeNames[scope],
+			})
+		}
+		response["scopes"] = scopes
+	}
+
+	return response
+}
+
+func (h *SellerAccountsHandler) getUserFromRequest(r *http.Request) (*entity.Users, error) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return nil, fmt.Errorf("no authorization header")
+	}
+
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return h.jwtSecret, nil
+	})
+
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+
+	username, ok := claims["username"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid username in token")
+	}
+
+	return h.userRepo.GetByUsername(username)
+}
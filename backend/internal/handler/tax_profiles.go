@@ -0,0 +1,304 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+	"wbrost-go/internal/dto"
+	"wbrost-go/internal/entity"
+)
+
+// GetTaxProfiles - GET /api/tax-profiles | Налоговые профили продавца с периодами действия
+func (h *WBStatsHandler) GetTaxProfiles(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	profiles, err := h.userRepo.GetTaxProfiles(access.OwnerID())
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get tax profiles: " + err.Error(),
+		})
+		return
+	}
+
+	// Профиль действует до даты начала следующего (более нового) профиля
+	today := time.Now().Format("2006-01-02")
+	response := make([]map[string]interface{}, len(profiles))
+	for i, profile := range profiles {
+		validFrom := profile.ValidFrom.Format("2006-01-02")
+
+		validTo := ""
+		if i > 0 {
+			validTo = profiles[i-1].ValidFrom.AddDate(0, 0, -1).Format("2006-01-02")
+		}
+
+		response[i] = map[string]interface{}{
+			"id":          profile.ID,
+			"regime":      profile.Regime,
+			"regime_name": entity.TaxRegimeNames[profile.Regime],
+			"rate":        profile.Rate,
+			"base":        profile.Base,
+			"vat_rate":    profile.VatRate,
+			"valid_from":  validFrom,
+			"valid_to":    validTo,
+			"current":     validFrom <= today && (validTo == "" || validTo >= today),
+			"created_by":  getIntValue(profile.CreatedBy),
+			"created_at":  profile.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"data":    response,
+		"regimes": entity.TaxRegimeNames,
+		// Ставка для периодов без профиля
+		"taxes": access.Owner.Taxes,
+	})
+}
+
+// SaveTaxProfile - POST /api/tax-profiles | Налоговый профиль с даты valid_from (по умолчанию - с сегодня,
+// первый профиль - на всю прошлую статистику). Профиль на ту же дату заменяется
+func (h *WBStatsHandler) SaveTaxProfile(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermEditTaxes)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req struct {
+		Regime    string   `json:"regime"`
+		Rate      *float64 `json:"rate"`
+		Base      string   `json:"base"`
+		VatRate   float64  `json:"vat_rate"`
+		ValidFrom string   `json:"valid_from"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	// Валидация
+	if _, ok := entity.TaxRegimeNames[req.Regime]; !ok {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid regime, expected usn_income, usn_income_expense, osno or self_employed",
+		})
+		return
+	}
+	if req.Rate == nil || *req.Rate < 0 || *req.Rate > 100 {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid rate, expected 0-100"})
+		return
+	}
+	if req.Base == "" {
+		req.Base = entity.TaxBasePayout
+	}
+	if req.Base != entity.TaxBasePayout && req.Base != entity.TaxBaseRetail {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid base, expected retail or payout"})
+		return
+	}
+	if req.VatRate < 0 || req.VatRate > 100 {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid vat_rate, expected 0-100"})
+		return
+	}
+	if req.Regime == entity.TaxRegimeSelfEmployed && req.VatRate != 0 {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Self-employed regime has no VAT"})
+		return
+	}
+
+	profiles, err := h.userRepo.GetTaxProfiles(access.OwnerID())
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get tax profiles: " + err.Error(),
+		})
+		return
+	}
+
+	validFrom := time.Now().Format("2006-01-02")
+	if len(profiles) == 0 {
+		validFrom = entity.TaxProfileStart
+	}
+	if req.ValidFrom != "" {
+		validFrom = req.ValidFrom
+	}
+
+	validFromDate, err := time.Parse("2006-01-02", validFrom)
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid valid_from format, expected YYYY-MM-DD"})
+		return
+	}
+
+	// Прежний профиль на эту дату - для журнала
+	var before map[string]interface{}
+	for _, profile := range profiles {
+		if !profile.ValidFrom.After(validFromDate) {
+			before = taxProfileAudit(profile)
+			break
+		}
+	}
+
+	profile := &entity.TaxProfile{
+		UserID:    access.OwnerID(),
+		Regime:    req.Regime,
+		Rate:      *req.Rate,
+		Base:      req.Base,
+		VatRate:   req.VatRate,
+		ValidFrom: validFromDate,
+		CreatedBy: sql.NullInt64{Int64: int64(user.ID), Valid: true},
+	}
+
+	if err := h.userRepo.SaveTaxProfile(profile); err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to save tax profile: " + err.Error(),
+		})
+		return
+	}
+
+	h.auditService.Record(auditMeta(r, user), entity.AuditLog{
+		TargetUserID: sql.NullInt64{Int64: int64(access.OwnerID()), Valid: true},
+		TargetType:   entity.AuditTargetTax,
+		TargetID:     sql.NullString{String: strconv.FormatInt(profile.ID, 10), Valid: true},
+		Action:       entity.AuditTaxProfileSave,
+		Before:       before,
+		After:        taxProfileAudit(*profile),
+	})
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"success":    true,
+		"message":    "Налоговый профиль сохранен",
+		"id":         profile.ID,
+		"valid_from": validFrom,
+	})
+}
+
+// DeleteTaxProfile - DELETE /api/tax-profiles?id= | Удаление налогового профиля
+func (h *WBStatsHandler) DeleteTaxProfile(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermEditTaxes)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil || id <= 0 {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid id"})
+		return
+	}
+
+	profiles, err := h.userRepo.GetTaxProfiles(access.OwnerID())
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get tax profiles: " + err.Error(),
+		})
+		return
+	}
+
+	var before map[string]interface{}
+	for _, profile := range profiles {
+		if profile.ID == id {
+			before = taxProfileAudit(profile)
+		}
+	}
+
+	deleted, err := h.userRepo.DeleteTaxProfile(access.OwnerID(), id)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to delete tax profile: " + err.Error(),
+		})
+		return
+	}
+	if !deleted {
+		respondWithJSON(w, http.StatusNotFound, dto.ErrorResponse{Error: "Tax profile not found"})
+		return
+	}
+
+	h.auditService.Record(auditMeta(r, user), entity.AuditLog{
+		TargetUserID: sql.NullInt64{Int64: int64(access.OwnerID()), Valid: true},
+		TargetType:   entity.AuditTargetTax,
+		TargetID:     sql.NullString{String: strconv.FormatInt(id, 10), Valid: true},
+		Action:       entity.AuditTaxProfileDel,
+		Before:       before,
+	})
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Налоговый профиль удален",
+	})
+}
+
+// GetTaxEstimate - GET /api/stat/tax-estimate?year= | Оценка налога по кварталам года по всем площадкам
+func (h *WBStatsHandler) GetTaxEstimate(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Год (по умолчанию - текущий)
+	year := time.Now().Year()
+	if raw := r.URL.Query().Get("year"); raw != "" {
+		if year, err = strconv.Atoi(raw); err != nil || year < 2000 || year > 2100 {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid year"})
+			return
+		}
+	}
+
+	// Кабинет (0 или не указан - все кабинеты)
+	accountID, err := resolveAccountID(h.accountRepo, access.OwnerID(), r.URL.Query().Get("account_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	estimate, err := h.analyticsRepo.GetTaxEstimate(access.OwnerID(), accountID, year)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get tax estimate: " + err.Error(),
+		})
+		return
+	}
+	estimate["account_id"] = accountID
+
+	respondWithJSON(w, http.StatusOK, estimate)
+}
+
+// taxProfileAudit - профиль для журнала
+func taxProfileAudit(profile entity.TaxProfile) map[string]interface{} {
+	return map[string]interface{}{
+		"regime":     profile.Regime,
+		"rate":       profile.Rate,
+		"base":       profile.Base,
+		"vat_rate":   profile.VatRate,
+		"valid_from": profile.ValidFrom.Format("2006-01-02"),
+	}
+}
@@ -4,39 +4,59 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 	"wbrost-go/internal/dto"
 	"wbrost-go/internal/entity"
+	"wbrost-go/internal/repository/account"
 	"wbrost-go/internal/repository/article"
 	"wbrost-go/internal/repository/user"
+	"wbrost-go/internal/service/audit"
+	"wbrost-go/internal/service/card"
+	"wbrost-go/internal/service/organization"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
 type WBArticlesHandler struct {
 	userRepo        *user.UserRepository
+	accountRepo     *account.SellerAccountRepository
 	articlesGetRepo *article.WBArticlesGetRepository
 	articleRepo     *article.WBArticlesRepository
+	orgService      *organization.OrganizationService
+	auditService    *audit.AuditService
+	cardService     *card.CardService
 	jwtSecret       []byte
 }
 
 func NewWBArticlesHandler(
 	userRepo *user.UserRepository,
+	accountRepo *account.SellerAccountRepository,
 	articlesGetRepo *article.WBArticlesGetRepository,
 	articleRepo *article.WBArticlesRepository,
+	orgService *organization.OrganizationService,
+	auditService *audit.AuditService,
+	cardService *card.CardService,
 	jwtSecret string,
 ) *WBArticlesHandler {
 	return &WBArticlesHandler{
 		userRepo:        userRepo,
+		accountRepo:     accountRepo,
 		articlesGetRepo: articlesGetRepo,
 		articleRepo:     articleRepo,
+		orgService:      orgService,
+		auditService:    auditService,
+		cardService:     cardService,
 		jwtSecret:       []byte(jwtSecret),
 	}
 }
 
-// GetArticles - GET /api/articles | Получение списка карточек товаров
+// GetArticles - GET /api/articles | Получение списка карточек товаров.
+// Фильтры: account_id, marketplace, search, brand, subject_id, vendor_code, imt_id, char_name + char_value,
+// archived (0 - активные, 1 - в архиве: в корзине WB или удалены)
 func (h *WBArticlesHandler) GetArticles(w http.ResponseWriter, r *http.Request) {
 	// Получить пользователя по токену
 	user, err := h.getUserFromRequest(r)
@@ -45,10 +65,30 @@ func (h *WBArticlesHandler) GetArticles(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	// Параметры запроса
-	pageStr := r.URL.Query().Get("page")
-	pageSizeStr := r.URL.Query().Get("pageSize")
-	searchQuery := r.URL.Query().Get("search")
+	query := r.URL.Query()
+	pageStr := query.Get("page")
+	pageSizeStr := query.Get("pageSize")
+
+	// Кабинет (0 или не указан - все кабинеты)
+	accountID, err := resolveAccountID(h.accountRepo, access.OwnerID(), query.Get("account_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	marketplace, err := resolveMarketplace(query.Get("marketplace"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
 
 	page := 1
 	if pageStr != "" {
@@ -64,43 +104,95 @@ func (h *WBArticlesHandler) GetArticles(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	var articles []entity.WBArticles
-	var totalCount int
+	// Фильтры карточек
+	filter := entity.ArticleFilter{
+		AccountID:   accountID,
+		Marketplace: marketplace,
+		Search:      strings.TrimSpace(query.Get("search")),
+		Brand:       strings.TrimSpace(query.Get("brand")),
+		VendorCode:  strings.TrimSpace(query.Get("vendor_code")),
+		CharName:    strings.TrimSpace(query.Get("char_name")),
+		CharValue:   strings.TrimSpace(query.Get("char_value")),
+		Archived:    query.Get("archived"),
+		Page:        page,
+		PageSize:    pageSize,
+	}
 
-	if searchQuery != "" {
-		// Поиск по запросу
-		articles, err = h.articleRepo.SearchArticles(user.ID, searchQuery, page, pageSize)
-		if err != nil {
-			respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
-				Error: "Failed to search articles: " + err.Error(),
-			})
+	if raw := query.Get("subject_id"); raw != "" {
+		if filter.SubjectID, err = strconv.Atoi(raw); err != nil || filter.SubjectID < 0 {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid subject_id"})
 			return
 		}
-		// Временное решение для поиска
-		totalCount = len(articles)
-	} else {
-		// Получение всех карточек с пагинацией
-		articles, err = h.articleRepo.GetByUserID(user.ID, page, pageSize)
-		if err != nil {
-			respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
-				Error: "Failed to get articles: " + err.Error(),
-			})
+	}
+	if raw := query.Get("imt_id"); raw != "" {
+		if filter.ImtID, err = strconv.ParseInt(raw, 10, 64); err != nil || filter.ImtID < 0 {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid imt_id"})
 			return
 		}
+	}
+	if filter.Archived != "" && filter.Archived != "0" && filter.Archived != "1" {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid archived: expected 0 or 1"})
+		return
+	}
+	if filter.CharValue != "" && filter.CharName == "" {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "char_value requires char_name"})
+		return
+	}
 
-		// Получить общее количество
-		totalCount, err = h.articleRepo.GetCountByUserID(user.ID)
-		if err != nil {
-			respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
-				Error: "Failed to get total count: " + err.Error(),
-			})
-			return
+	articles, err := h.articleRepo.List(access.OwnerID(), filter)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get articles: " + err.Error(),
+		})
+		return
+	}
+
+	// Получить общее количество
+	totalCount, err := h.articleRepo.Count(access.OwnerID(), filter)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get total count: " + err.Error(),
+		})
+		return
+	}
+
+	// Размеры, фотографии и характеристики карточек WB
+	nmIDs := make([]int64, 0, len(articles))
+	for _, article := range articles {
+		if nmID := articleNmID(article); nmID != 0 {
+			nmIDs = append(nmIDs, nmID)
 		}
 	}
 
+	sizes, err := h.articleRepo.GetSizes(access.OwnerID(), nmIDs)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get article sizes: " + err.Error(),
+		})
+		return
+	}
+
+	photos, err := h.articleRepo.GetPhotos(access.OwnerID(), nmIDs)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get article photos: " + err.Error(),
+		})
+		return
+	}
+
+	characteristics, err := h.articleRepo.GetCharacteristics(access.OwnerID(), nmIDs)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get article characteristics: " + err.Error(),
+		})
+		return
+	}
+
 	// Форматировать ответ
 	response := make([]map[string]interface{}, len(articles))
 	for i, article := range articles {
+		nmID := articleNmID(article)
+
 		// Генерация URL фото
 		photoURL := ""
 		if article.Photo.Valid && article.Photo.String != "" {
@@ -121,18 +213,38 @@ func (h *WBArticlesHandler) GetArticles(w http.ResponseWriter, r *http.Request)
 			updatedDate = article.Updated.Time.Format("2006-01-02")
 		}
 
+		archivedAt := ""
+		if article.ArchivedAt.Valid {
+			archivedAt = article.ArchivedAt.Time.Format(time.RFC3339)
+		}
+
 		response[i] = map[string]interface{}{
-			"id":         article.ID,
-			"articule":   article.Articule,
-			"name":       getStringValue(article.Name),
-			"photo":      photoURL,
-			"cost_price": getStringValue(article.CostPrice),
-			"created":    createdDate,
-			"updated":    updatedDate,
-			"rus_size":   getStringValue(article.RusSize),
-			"eu_size":    getStringValue(article.EuSize),
-			"chrt_id":    getIntValue(article.ChrtID),
-			"barcode":    getStringValue(article.Barcode),
+			"id":              article.ID,
+			"account_id":      getIntValue(article.AccountID),
+			"marketplace":     article.Marketplace,
+			"articule":        article.Articule,
+			"name":            getStringValue(article.Name),
+			"photo":           photoURL,
+			"cost_price":      article.CostPrice.Float64,
+			"created":         createdDate,
+			"updated":         updatedDate,
+			"rus_size":        getStringValue(article.RusSize),
+			"eu_size":         getStringValue(article.EuSize),
+			"chrt_id":         getIntValue(article.ChrtID),
+			"barcode":         getStringValue(article.Barcode),
+			"sizes":           sizesResponse(sizes[nmID]),
+			"vendor_code":     getStringValue(article.InternalID),
+			"nm_uuid":         getStringValue(article.NmUUID),
+			"brand":           getStringValue(article.Brand),
+			"subject_id":      getIntValue(article.SubjectID),
+			"subject_name":    getStringValue(article.SubjectName),
+			"imt_id":          getIntValue(article.ImtID),
+			"description":     getStringValue(article.Description),
+			"dimensions":      dimensionsResponse(article),
+			"photos":          photosResponse(photos[nmID]),
+			"characteristics": characteristicsResponse(characteristics[nmID]),
+			"archived_at":     archivedAt,
+			"archive_reason":  getStringValue(article.ArchiveReason),
 		}
 	}
 
@@ -150,7 +262,44 @@ func (h *WBArticlesHandler) GetArticles(w http.ResponseWriter, r *http.Request)
 	respondWithJSON(w, http.StatusOK, fullResponse)
 }
 
-// CreateArticlesRequest - POST /api/articles/request | Запрос обновления карточек товаров
+// GetArticleFilters - GET /api/articles/filters | Бренды и предметы карточек для фильтров списка
+func (h *WBArticlesHandler) GetArticleFilters(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Кабинет (0 или не указан - все кабинеты)
+	accountID, err := resolveAccountID(h.accountRepo, access.OwnerID(), r.URL.Query().Get("account_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	brands, subjects, err := h.articleRepo.GetFilterValues(access.OwnerID(), accountID)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get article filters: " + err.Error(),
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"brands":   brands,
+		"subjects": subjects,
+	})
+}
+
+// CreateArticlesRequest - POST /api/articles/request | Запрос обновления карточек товаров.
+// По умолчанию загружаются карточки, измененные с прошлой синхронизации; full_sync - полная сверка
 func (h *WBArticlesHandler) CreateArticlesRequest(w http.ResponseWriter, r *http.Request) {
 	// Получить пользователя по токену
 	user, err := h.getUserFromRequest(r)
@@ -159,8 +308,37 @@ func (h *WBArticlesHandler) CreateArticlesRequest(w http.ResponseWriter, r *http
 		return
 	}
 
-	// Проверяем наличие WB ключа
-	if !user.WbKey.Valid || user.WbKey.String == "" {
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermRequestData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Тело запроса необязательно: без account_id карточки загружаются по основному кабинету
+	var req struct {
+		AccountID int  `json:"account_id"`
+		FullSync  bool `json:"full_sync"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	accountID, err := resolveAccountID(h.accountRepo, access.OwnerID(), strconv.Itoa(req.AccountID))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	jobAccount, err := jobAccountID(h.accountRepo, access.OwnerID(), accountID)
+	if err != nil {
+		respondJobAccountError(w, err)
+		return
+	}
+
+	// Проверяем наличие WB ключа (у кабинета ключ есть всегда, без кабинета - ключ профиля владельца)
+	if !jobAccount.Valid && (!access.Owner.WbKey.Valid || access.Owner.WbKey.String == "") {
 		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{
 			Error: "WB ключ не указан. Добавьте ключ в настройках профиля.",
 		})
@@ -169,8 +347,10 @@ func (h *WBArticlesHandler) CreateArticlesRequest(w http.ResponseWriter, r *http
 
 	// Создаем запись в wb_articles_get
 	articleRequest := &entity.WBArticlesGet{
-		UserID: user.ID,
-		Status: getNullInt64(entity.ArticlesStatusWait),
+		UserID:    access.OwnerID(),
+		AccountID: jobAccount,
+		Status:    getNullInt64(entity.ArticlesStatusWait),
+		FullSync:  req.FullSync,
 	}
 
 	if err := h.articlesGetRepo.Create(articleRequest); err != nil {
@@ -180,6 +360,11 @@ func (h *WBArticlesHandler) CreateArticlesRequest(w http.ResponseWriter, r *http
 		return
 	}
 
+	recordJobCreated(h.auditService, r, user, access.OwnerID(), "wb_articles_get", articleRequest.ID, map[string]interface{}{
+		"account_id": getIntValue(articleRequest.AccountID),
+		"full_sync":  req.FullSync,
+	})
+
 	respondWithJSON(w, http.StatusOK, map[string]interface{}{
 		"id":      articleRequest.ID,
 		"success": true,
@@ -187,7 +372,8 @@ func (h *WBArticlesHandler) CreateArticlesRequest(w http.ResponseWriter, r *http
 	})
 }
 
-// UpdateCostPrice - POST /api/articles/cost-price | Обновление себестоимости
+// UpdateCostPrice - POST /api/articles/cost-price | Себестоимость товара или его размера (chrt_id)
+// с даты valid_from (по умолчанию - с сегодня)
 func (h *WBArticlesHandler) UpdateCostPrice(w http.ResponseWriter, r *http.Request) {
 	// Получить пользователя по токену
 	user, err := h.getUserFromRequest(r)
@@ -196,10 +382,19 @@ func (h *WBArticlesHandler) UpdateCostPrice(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermEditCostPrice)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	// Парсим запрос
 	var req struct {
 		Articule  string `json:"articule"`
+		ChrtID    int64  `json:"chrt_id"`
 		CostPrice string `json:"cost_price"`
+		ValidFrom string `json:"valid_from"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -213,20 +408,349 @@ func (h *WBArticlesHandler) UpdateCostPrice(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Обновляем себестоимость
-	if err := h.articleRepo.UpdateCostPrice(user.ID, req.Articule, req.CostPrice); err != nil {
+	// Пустое значение - себестоимость не указана (0); для размера - с этой даты
+	// действует себестоимость карточки
+	costPrice := 0.0
+	if value := strings.ReplaceAll(strings.TrimSpace(req.CostPrice), ",", "."); value != "" {
+		costPrice, err = strconv.ParseFloat(value, 64)
+		if err != nil || costPrice < 0 {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid cost_price"})
+			return
+		}
+	}
+
+	nmID, err := strconv.ParseInt(req.Articule, 10, 64)
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid articule"})
+		return
+	}
+
+	// Себестоимость карточки - карточка должна быть у владельца
+	if req.ChrtID == 0 {
+		exists, err := h.articleRepo.Exists(access.OwnerID(), nmID)
+		if err != nil {
+			respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+				Error: "Failed to get article: " + err.Error(),
+			})
+			return
+		}
+		if !exists {
+			respondWithJSON(w, http.StatusNotFound, dto.ErrorResponse{Error: "Article not found"})
+			return
+		}
+	}
+
+	// Себестоимость размера (chrt_id) - размер должен быть в карточке
+	if req.ChrtID != 0 {
+		sizes, err := h.articleRepo.GetSizes(access.OwnerID(), []int64{nmID})
+		if err != nil {
+			respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+				Error: "Failed to get article sizes: " + err.Error(),
+			})
+			return
+		}
+
+		found := false
+		for _, size := range sizes[nmID] {
+			found = found || size.ChrtID == req.ChrtID
+		}
+		if !found {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Size chrt_id not found in article"})
+			return
+		}
+	}
+
+	allHistory, err := h.articleRepo.GetCostPriceHistory(access.OwnerID(), req.Articule)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get cost price history: " + err.Error(),
+		})
+		return
+	}
+
+	// История карточки или выбранного размера
+	var history []entity.CostPriceHistory
+	for _, entry := range allHistory {
+		if entry.ChrtID == req.ChrtID {
+			history = append(history, entry)
+		}
+	}
+
+	// Дата начала действия. Без даты новая себестоимость действует с сегодняшнего дня,
+	// а самая первая - на всю прошлую статистику
+	validFrom := time.Now().Format("2006-01-02")
+	if len(history) == 0 {
+		validFrom = entity.CostPriceHistoryStart
+	}
+	if req.ValidFrom != "" {
+		validFrom = req.ValidFrom
+	}
+
+	validFromDate, err := time.Parse("2006-01-02", validFrom)
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid valid_from format, expected YYYY-MM-DD"})
+		return
+	}
+
+	// Прежнее значение на эту дату - для журнала
+	before := map[string]interface{}{"chrt_id": req.ChrtID, "cost_price": nil, "valid_from": validFrom}
+	for _, entry := range history {
+		if !entry.ValidFrom.After(validFromDate) {
+			before["cost_price"] = entry.CostPrice
+			break
+		}
+	}
+
+	entry := &entity.CostPriceHistory{
+		UserID:    access.OwnerID(),
+		Articule:  req.Articule,
+		ChrtID:    req.ChrtID,
+		CostPrice: costPrice,
+		ValidFrom: validFromDate,
+		CreatedBy: sql.NullInt64{Int64: int64(user.ID), Valid: true},
+	}
+
+	// Добавляем себестоимость в историю, текущее значение карточки пересчитывается
+	if err := h.articleRepo.AddCostPrice(entry); err != nil {
 		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
 			Error: "Failed to update cost price: " + err.Error(),
 		})
 		return
 	}
 
+	h.auditService.Record(auditMeta(r, user), entity.AuditLog{
+		TargetUserID: sql.NullInt64{Int64: int64(access.OwnerID()), Valid: true},
+		TargetType:   entity.AuditTargetArticle,
+		TargetID:     sql.NullString{String: req.Articule, Valid: true},
+		Action:       entity.AuditCostPriceUpdate,
+		Before:       before,
+		After:        map[string]interface{}{"chrt_id": req.ChrtID, "cost_price": costPrice, "valid_from": validFrom},
+	})
+
 	respondWithJSON(w, http.StatusOK, map[string]interface{}{
-		"success": true,
-		"message": "Себестоимость обновлена",
+		"success":    true,
+		"message":    "Себестоимость обновлена",
+		"id":         entry.ID,
+		"valid_from": validFrom,
+	})
+}
+
+// GetCostPriceHistory - GET /api/articles/cost-price?articule= | История себестоимости товара
+func (h *WBArticlesHandler) GetCostPriceHistory(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	articule := r.URL.Query().Get("articule")
+	if articule == "" {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Articule is required"})
+		return
+	}
+
+	history, err := h.articleRepo.GetCostPriceHistory(access.OwnerID(), articule)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get cost price history: " + err.Error(),
+		})
+		return
+	}
+
+	// Запись действует до даты начала следующей (более новой) записи того же размера
+	today := time.Now().Format("2006-01-02")
+	response := make([]map[string]interface{}, len(history))
+	for i, entry := range history {
+		validFrom := entry.ValidFrom.Format("2006-01-02")
+
+		validTo := ""
+		if i > 0 && history[i-1].ChrtID == entry.ChrtID {
+			validTo = history[i-1].ValidFrom.AddDate(0, 0, -1).Format("2006-01-02")
+		}
+
+		response[i] = map[string]interface{}{
+			"id":         entry.ID,
+			"chrt_id":    entry.ChrtID,
+			"cost_price": entry.CostPrice,
+			"valid_from": validFrom,
+			"valid_to":   validTo,
+			"current":    validFrom <= today && (validTo == "" || validTo >= today),
+			"created_by": getIntValue(entry.CreatedBy),
+			"created_at": entry.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"articule": articule,
+		"data":     response,
+	})
+}
+
+// GetArticleChanges - GET /api/articles/changes | Журнал изменений карточек WB (название, фото,
+// размеры, баркоды, статус). Фильтры: nm_id, field, dateFrom, dateTo
+func (h *WBArticlesHandler) GetArticleChanges(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	query := r.URL.Query()
+	filter := entity.ArticleChangeFilter{
+		Field:    query.Get("field"),
+		DateFrom: query.Get("dateFrom"),
+		DateTo:   query.Get("dateTo"),
+		Page:     PageNum,
+		PageSize: PageSize,
+	}
+
+	if raw := query.Get("nm_id"); raw != "" {
+		if filter.NmID, err = strconv.ParseInt(raw, 10, 64); err != nil || filter.NmID < 0 {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid nm_id"})
+			return
+		}
+	}
+	for _, date := range []string{filter.DateFrom, filter.DateTo} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid date format, expected YYYY-MM-DD"})
+			return
+		}
+	}
+
+	if p, err := strconv.Atoi(query.Get("page")); err == nil && p > Zero {
+		filter.Page = p
+	}
+	if ps, err := strconv.Atoi(query.Get("pageSize")); err == nil && ps > Zero && ps <= MaxPageSize {
+		filter.PageSize = ps
+	}
+
+	changes, total, err := h.articleRepo.ListChanges(access.OwnerID(), filter)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get article changes: " + err.Error(),
+		})
+		return
+	}
+
+	response := make([]map[string]interface{}, len(changes))
+	for i, c := range changes {
+		cardUpdatedAt := ""
+		if c.CardUpdatedAt.Valid {
+			cardUpdatedAt = c.CardUpdatedAt.Time.Format(time.RFC3339)
+		}
+
+		response[i] = map[string]interface{}{
+			"id":              c.ID,
+			"account_id":      getIntValue(c.AccountID),
+			"nm_id":           c.NmID,
+			"field":           c.Field,
+			"old_value":       getStringValue(c.OldValue),
+			"new_value":       getStringValue(c.NewValue),
+			"card_updated_at": cardUpdatedAt,
+			"detected_at":     c.DetectedAt.Format(time.RFC3339),
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"data": response,
+		"pagination": map[string]interface{}{
+			"current_page": filter.Page,
+			"page_size":    filter.PageSize,
+			"total_items":  total,
+			"total_pages":  (total + filter.PageSize - 1) / filter.PageSize,
+		},
 	})
 }
 
+// articleNmID - nm_id карточки WB (0 - карточка другой площадки)
+func articleNmID(article entity.WBArticles) int64 {
+	if article.Marketplace != entity.MarketplaceWB {
+		return 0
+	}
+	nmID, _ := strconv.ParseInt(article.Articule, 10, 64)
+	return nmID
+}
+
+// dimensionsResponse - габариты упаковки (nil - не заполнены)
+func dimensionsResponse(article entity.WBArticles) interface{} {
+	if !article.Length.Valid && !article.Width.Valid && !article.Height.Valid && !article.WeightBrutto.Valid {
+		return nil
+	}
+	return map[string]interface{}{
+		"length":        article.Length.Float64,
+		"width":         article.Width.Float64,
+		"height":        article.Height.Float64,
+		"weight_brutto": article.WeightBrutto.Float64,
+		"is_valid":      article.DimensionsValid.Bool,
+	}
+}
+
+// photosResponse - фотографии карточки во всех размерах
+func photosResponse(photos []entity.WBArticlePhoto) []map[string]interface{} {
+	response := make([]map[string]interface{}, len(photos))
+	for i, photo := range photos {
+		response[i] = map[string]interface{}{
+			"position": photo.Position,
+			"big":      photo.Big,
+			"c246x328": photo.C246x328,
+			"c516x688": photo.C516x688,
+			"square":   photo.Square,
+			"tm":       photo.Tm,
+		}
+	}
+	return response
+}
+
+// characteristicsResponse - характеристики карточки (value - как в API WB)
+func characteristicsResponse(characteristics []entity.WBArticleCharacteristic) []map[string]interface{} {
+	response := make([]map[string]interface{}, len(characteristics))
+	for i, c := range characteristics {
+		response[i] = map[string]interface{}{
+			"id":    c.CharacteristicID,
+			"name":  c.Name,
+			"value": c.Value,
+		}
+	}
+	return response
+}
+
+// sizesResponse - размеры карточки для ответа API
+func sizesResponse(sizes []entity.WBArticleSize) []map[string]interface{} {
+	response := make([]map[string]interface{}, len(sizes))
+	for i, size := range sizes {
+		var costPrice interface{}
+		if size.CostPrice.Valid {
+			costPrice = size.CostPrice.Float64
+		}
+
+		response[i] = map[string]interface{}{
+			"chrt_id":    size.ChrtID,
+			"tech_size":  size.TechSize,
+			"wb_size":    size.WbSize,
+			"skus":       size.Skus,
+			"cost_price": costPrice,
+		}
+	}
+	return response
+}
+
 // Вспомогательная функция для генерации URL фото
 func (h *WBArticlesHandler) generatePhotoURL(articule string) string {
 	if articule == "" {
@@ -0,0 +1,361 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+	"wbrost-go/internal/dto"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/service/card"
+)
+
+// GetCardDrafts - GET /api/articles/drafts | Черновики изменений карточек WB с ошибками проверки
+// и ошибками WB. Фильтр: status (draft, queued, sent, applied, error)
+func (h *WBArticlesHandler) GetCardDrafts(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	drafts, err := h.cardService.ListDrafts(access.OwnerID(), r.URL.Query().Get("status"))
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get card drafts: " + err.Error(),
+		})
+		return
+	}
+
+	response := make([]map[string]interface{}, len(drafts))
+	for i := range drafts {
+		response[i] = draftResponse(&drafts[i])
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"data": response,
+	})
+}
+
+// SaveCardDraft - POST /api/articles/drafts | Создание или замена черновика изменений карточки.
+// Не указанные поля не меняются; черновик проверяется по характеристикам предмета
+func (h *WBArticlesHandler) SaveCardDraft(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermEditCards)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req struct {
+		NmID            int64                            `json:"nm_id"`
+		Title           *string                          `json:"title"`
+		Description     *string                          `json:"description"`
+		VendorCode      *string                          `json:"vendor_code"`
+		Characteristics []entity.CardDraftCharacteristic `json:"characteristics"`
+		Dimensions      *struct {
+			Length       *float64 `json:"length"`
+			Width        *float64 `json:"width"`
+			Height       *float64 `json:"height"`
+			WeightBrutto *float64 `json:"weight_brutto"`
+		} `json:"dimensions"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+	if req.NmID <= 0 {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "nm_id is required"})
+		return
+	}
+
+	draft := &entity.WBCardDraft{
+		UserID:          access.OwnerID(),
+		NmID:            req.NmID,
+		Title:           nullString(req.Title),
+		Description:     nullString(req.Description),
+		VendorCode:      nullString(req.VendorCode),
+		Characteristics: req.Characteristics,
+		CreatedBy:       sql.NullInt64{Int64: int64(user.ID), Valid: true},
+	}
+	if req.Dimensions != nil {
+		draft.Length = nullFloat(req.Dimensions.Length)
+		draft.Width = nullFloat(req.Dimensions.Width)
+		draft.Height = nullFloat(req.Dimensions.Height)
+		draft.WeightBrutto = nullFloat(req.Dimensions.WeightBrutto)
+	}
+
+	if err := h.cardService.SaveDraft(draft); err != nil {
+		switch err {
+		case card.ErrCardNotFound:
+			respondWithJSON(w, http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case card.ErrDraftLocked:
+			respondWithJSON(w, http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		default:
+			respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to save card draft: " + err.Error()})
+		}
+		return
+	}
+
+	h.auditService.Record(auditMeta(r, user), entity.AuditLog{
+		TargetUserID: sql.NullInt64{Int64: int64(access.OwnerID()), Valid: true},
+		TargetType:   entity.AuditTargetArticle,
+		TargetID:     sql.NullString{String: strconv.FormatInt(draft.NmID, 10), Valid: true},
+		Action:       entity.AuditCardDraftSave,
+		After:        draftResponse(draft),
+	})
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"valid":   len(draft.Errors) == 0,
+		"draft":   draftResponse(draft),
+	})
+}
+
+// DeleteCardDraft - DELETE /api/articles/drafts?nm_id= | Удаление неотправленного черновика
+func (h *WBArticlesHandler) DeleteCardDraft(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermEditCards)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	nmID, err := strconv.ParseInt(r.URL.Query().Get("nm_id"), 10, 64)
+	if err != nil || nmID <= 0 {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid nm_id"})
+		return
+	}
+
+	if err := h.cardService.DeleteDraft(access.OwnerID(), nmID); err != nil {
+		switch err {
+		case card.ErrDraftNotFound:
+			respondWithJSON(w, http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case card.ErrDraftLocked:
+			respondWithJSON(w, http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		default:
+			respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to delete card draft: " + err.Error()})
+		}
+		return
+	}
+
+	h.auditService.Record(auditMeta(r, user), entity.AuditLog{
+		TargetUserID: sql.NullInt64{Int64: int64(access.OwnerID()), Valid: true},
+		TargetType:   entity.AuditTargetArticle,
+		TargetID:     sql.NullString{String: strconv.FormatInt(nmID, 10), Valid: true},
+		Action:       entity.AuditCardDraftDelete,
+	})
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Черновик удален",
+	})
+}
+
+// SubmitCardDrafts - POST /api/articles/drafts/submit | Отправка черновиков в WB.
+// Черновики nm_ids (без nm_ids - все со статусом draft) повторно проверяются, прошедшие проверку
+// ставятся в задания отправки (по одному на кабинет), ошибки возвращаются по каждой карточке
+func (h *WBArticlesHandler) SubmitCardDrafts(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermEditCards)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req struct {
+		NmIDs []int64 `json:"nm_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	result, err := h.cardService.Submit(access.OwnerID(), req.NmIDs)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to submit card drafts: " + err.Error(),
+		})
+		return
+	}
+
+	invalidIDs := make([]int64, 0, len(result.Invalid))
+	for nmID := range result.Invalid {
+		invalidIDs = append(invalidIDs, nmID)
+	}
+	sort.Slice(invalidIDs, func(i, j int) bool { return invalidIDs[i] < invalidIDs[j] })
+
+	invalid := make([]map[string]interface{}, 0, len(invalidIDs))
+	for _, nmID := range invalidIDs {
+		invalid = append(invalid, map[string]interface{}{
+			"nm_id":  nmID,
+			"errors": result.Invalid[nmID],
+		})
+	}
+
+	if len(result.Updates) == 0 {
+		message := "Нет черновиков для отправки"
+		if len(invalid) > 0 {
+			message = "Черновики не прошли проверку"
+		}
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":   message,
+			"invalid": invalid,
+		})
+		return
+	}
+
+	jobIDs := make([]int, 0, len(result.Updates))
+	for _, update := range result.Updates {
+		jobIDs = append(jobIDs, update.ID)
+		recordJobCreated(h.auditService, r, user, access.OwnerID(), "wb_card_updates", update.ID, map[string]interface{}{
+			"account_id": getIntValue(update.AccountID),
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"ids":     jobIDs,
+		"queued":  result.Queued,
+		"invalid": invalid,
+		"message": "Изменения карточек поставлены в очередь на отправку в WB",
+	})
+}
+
+// GetSubjectCharacteristics - GET /api/articles/subject-characteristics?subject_id= |
+// Характеристики предмета WB (обязательные - первыми) для редактирования черновика
+func (h *WBArticlesHandler) GetSubjectCharacteristics(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	if _, status, err := authorize(h.orgService, user, r, entity.PermViewData); err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	subjectID, err := strconv.Atoi(r.URL.Query().Get("subject_id"))
+	if err != nil || subjectID <= 0 {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid subject_id"})
+		return
+	}
+
+	characteristics, err := h.cardService.SubjectCharacteristics(subjectID)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get subject characteristics: " + err.Error(),
+		})
+		return
+	}
+
+	response := make([]map[string]interface{}, len(characteristics))
+	for i, c := range characteristics {
+		response[i] = map[string]interface{}{
+			"id":         c.CharcID,
+			"name":       c.Name,
+			"required":   c.Required,
+			"unit_name":  c.UnitName,
+			"max_count":  c.MaxCount,
+			"charc_type": c.CharcType,
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"subject_id": subjectID,
+		"data":       response,
+	})
+}
+
+// draftResponse - черновик для ответа (незаданные поля - null)
+func draftResponse(d *entity.WBCardDraft) map[string]interface{} {
+	optional := func(v sql.NullString) interface{} {
+		if v.Valid {
+			return v.String
+		}
+		return nil
+	}
+	optionalFloat := func(v sql.NullFloat64) interface{} {
+		if v.Valid {
+			return v.Float64
+		}
+		return nil
+	}
+
+	characteristics := d.Characteristics
+	if characteristics == nil {
+		characteristics = []entity.CardDraftCharacteristic{}
+	}
+	errs := d.Errors
+	if errs == nil {
+		errs = []string{}
+	}
+
+	return map[string]interface{}{
+		"id":              d.ID,
+		"nm_id":           d.NmID,
+		"account_id":      getIntValue(d.AccountID),
+		"title":           optional(d.Title),
+		"description":     optional(d.Description),
+		"vendor_code":     optional(d.VendorCode),
+		"characteristics": characteristics,
+		"dimensions": map[string]interface{}{
+			"length":        optionalFloat(d.Length),
+			"width":         optionalFloat(d.Width),
+			"height":        optionalFloat(d.Height),
+			"weight_brutto": optionalFloat(d.WeightBrutto),
+		},
+		"status":     d.Status,
+		"errors":     errs,
+		"update_id":  getIntValue(d.UpdateID),
+		"created_by": getIntValue(d.CreatedBy),
+		"created_at": d.CreatedAt.Format(time.RFC3339),
+		"updated_at": d.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// nullString - необязательное строковое поле запроса
+func nullString(v *string) sql.NullString {
+	if v == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *v, Valid: true}
+}
+
+// nullFloat - необязательное числовое поле запроса
+func nullFloat(v *float64) sql.NullFloat64 {
+	if v == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: *v, Valid: true}
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"wbrost-go/internal/dto"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/repository/stat"
+)
+
+// GetRealizationReports - GET /api/wb/realization-reports | Еженедельные отчеты реализации WB с итогами,
+// суммой к перечислению и сверкой (mismatch=1 - только отчеты с расхождениями)
+func (h *WBStatsHandler) GetRealizationReports(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	query := r.URL.Query()
+
+	dateFrom, dateTo, err := parseDateRange(query)
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Кабинет (0 или не указан - все кабинеты)
+	accountID, err := resolveAccountID(h.accountRepo, access.OwnerID(), query.Get("account_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	mismatch := query.Get("mismatch")
+	if mismatch != "" && mismatch != "0" && mismatch != "1" {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid mismatch, expected 0 or 1"})
+		return
+	}
+
+	// Параметры пагинации
+	page := PageNum
+	if p, err := strconv.Atoi(query.Get("page")); err == nil && p > Zero {
+		page = p
+	}
+	pageSize := PageSize
+	if ps, err := strconv.Atoi(query.Get("pageSize")); err == nil && ps > Zero && ps <= MaxPageSize {
+		pageSize = ps
+	}
+
+	reports, totalCount, err := h.statRepo.GetRealizationReports(access.OwnerID(), accountID, dateFrom, dateTo, mismatch == "1", page, pageSize)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get realization reports: " + err.Error(),
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"data":       reports,
+		"account_id": accountID,
+		"tolerance":  stat.ReportPayoutTolerance,
+		"pagination": map[string]interface{}{
+			"current_page": page,
+			"page_size":    pageSize,
+			"total_items":  totalCount,
+			"total_pages":  int(math.Ceil(float64(totalCount) / float64(pageSize))),
+		},
+	})
+}
+
+// SaveReportPayout - POST /api/wb/realization-reports/payout | Сумма "Итого к оплате" по данным WB
+// для сверки отчета (expected_payout = null - убрать сумму)
+func (h *WBStatsHandler) SaveReportPayout(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermEditTaxes)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req struct {
+		RealizationReportID int64    `json:"realizationreport_id"`
+		ExpectedPayout      *float64 `json:"expected_payout"`
+		Comment             string   `json:"comment"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	if req.RealizationReportID <= 0 {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "realizationreport_id is required"})
+		return
+	}
+
+	exists, err := h.statRepo.ReportExists(access.OwnerID(), req.RealizationReportID)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if !exists {
+		respondWithJSON(w, http.StatusNotFound, dto.ErrorResponse{Error: "Realization report not found"})
+		return
+	}
+
+	expected := sql.NullFloat64{}
+	if req.ExpectedPayout != nil {
+		expected = sql.NullFloat64{Float64: *req.ExpectedPayout, Valid: true}
+	}
+
+	if err := h.statRepo.SaveExpectedPayout(access.OwnerID(), req.RealizationReportID, expected, req.Comment, user.ID); err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to save expected payout: " + err.Error(),
+		})
+		return
+	}
+
+	h.auditService.Record(auditMeta(r, user), entity.AuditLog{
+		TargetUserID: sql.NullInt64{Int64: int64(access.OwnerID()), Valid: true},
+		TargetType:   entity.AuditTargetReport,
+		TargetID:     sql.NullString{String: strconv.FormatInt(req.RealizationReportID, 10), Valid: true},
+		Action:       entity.AuditReportPayout,
+		After:        map[string]interface{}{"expected_payout": req.ExpectedPayout, "comment": req.Comment},
+	})
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Сумма для сверки отчета сохранена",
+	})
+}
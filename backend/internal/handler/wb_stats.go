@@ -6,39 +6,52 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 	"wbrost-go/internal/dto"
 	"wbrost-go/internal/entity"
+	"wbrost-go/internal/repository/account"
 	"wbrost-go/internal/repository/stat"
 	"wbrost-go/internal/repository/user"
+	"wbrost-go/internal/service/audit"
+	"wbrost-go/internal/service/organization"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
 type WBStatsHandler struct {
 	userRepo       *user.UserRepository
+	accountRepo    *account.SellerAccountRepository
 	wbStatsGetRepo *stat.WBStatsGetRepository
 	statRepo       *stat.StatRepository
 	analyticsRepo  *stat.AnalyticsRepository
 	dashboardRepo  *stat.DashboardRepository
+	orgService     *organization.OrganizationService
+	auditService   *audit.AuditService
 	jwtSecret      []byte
 }
 
 func NewWBStatsHandler(
 	userRepo *user.UserRepository,
+	accountRepo *account.SellerAccountRepository,
 	wbStatsGetRepo *stat.WBStatsGetRepository,
 	statRepo *stat.StatRepository,
 	analyticsRepo *stat.AnalyticsRepository,
 	dashboardRepo *stat.DashboardRepository,
+	orgService *organization.OrganizationService,
+	auditService *audit.AuditService,
 	jwtSecret string) *WBStatsHandler {
 	return &WBStatsHandler{
 		userRepo:       userRepo,
+		accountRepo:    accountRepo,
 		wbStatsGetRepo: wbStatsGetRepo,
 		statRepo:       statRepo,
 		analyticsRepo:  analyticsRepo,
 		dashboardRepo:  dashboardRepo,
+		orgService:     orgService,
+		auditService:   auditService,
 		jwtSecret:      []byte(jwtSecret),
 	}
 }
@@ -52,6 +65,13 @@ func (h *WBStatsHandler) GetStatDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	// Получить параметры дат из запроса(query params)
 	dateFrom := r.URL.Query().Get("dateFrom")
 	dateTo := r.URL.Query().Get("dateTo")
@@ -81,6 +101,13 @@ func (h *WBStatsHandler) GetStatDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Кабинет (0 или не указан - все кабинеты)
+	accountID, err := resolveAccountID(h.accountRepo, access.OwnerID(), r.URL.Query().Get("account_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	// Параметры пагинации
 	page := 1
 	if pageStr != "" {
@@ -97,7 +124,7 @@ func (h *WBStatsHandler) GetStatDetail(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Получить детальные данные статистики
-	statDetails, err := h.analyticsRepo.GetStatDetails(user.ID, dateFrom, dateTo, page, pageSize)
+	statDetails, err := h.analyticsRepo.GetStatDetails(access.OwnerID(), accountID, dateFrom, dateTo, page, pageSize)
 	if err != nil {
 		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
 			Error: "Failed to get stat details: " + err.Error(),
@@ -106,7 +133,7 @@ func (h *WBStatsHandler) GetStatDetail(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Получить общее количество записей для пагинации
-	totalCount, err := h.analyticsRepo.GetStatDetailsCount(user.ID, dateFrom, dateTo)
+	totalCount, err := h.analyticsRepo.GetStatDetailsCount(access.OwnerID(), accountID, dateFrom, dateTo)
 	if err != nil {
 		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
 			Error: "Failed to get total count: " + err.Error(),
@@ -115,7 +142,7 @@ func (h *WBStatsHandler) GetStatDetail(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Получить итоговые суммы
-	summaryStatDetails, err := h.analyticsRepo.GetStatSummary(user.ID, dateFrom, dateTo)
+	summaryStatDetails, err := h.analyticsRepo.GetStatSummary(access.OwnerID(), accountID, dateFrom, dateTo)
 	if err != nil {
 		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
 			Error: "Failed to get stat summary: " + err.Error(),
@@ -125,9 +152,10 @@ func (h *WBStatsHandler) GetStatDetail(w http.ResponseWriter, r *http.Request) {
 
 	// Формируем ответ
 	response := map[string]interface{}{
-		"data":    statDetails,
-		"summary": summaryStatDetails,
-		"taxes":   user.Taxes,
+		"data":       statDetails,
+		"summary":    summaryStatDetails,
+		"taxes":      access.Owner.Taxes,
+		"account_id": accountID,
 		"pagination": map[string]interface{}{
 			"current_page": page,
 			"page_size":    pageSize,
@@ -140,6 +168,228 @@ func (h *WBStatsHandler) GetStatDetail(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// GetSizeDetails - GET /api/stat/sizes | Продажи, возвраты и маржа по размерам (nm_id - один товар)
+func (h *WBStatsHandler) GetSizeDetails(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	dateFrom, dateTo, err := parseDateRange(r.URL.Query())
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Кабинет (0 или не указан - все кабинеты)
+	accountID, err := resolveAccountID(h.accountRepo, access.OwnerID(), r.URL.Query().Get("account_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	nmID := int64(0)
+	if raw := r.URL.Query().Get("nm_id"); raw != "" {
+		if nmID, err = strconv.ParseInt(raw, 10, 64); err != nil || nmID < 0 {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid nm_id"})
+			return
+		}
+	}
+
+	sizes, err := h.analyticsRepo.GetSizeDetails(access.OwnerID(), accountID, nmID, dateFrom, dateTo)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get size details: " + err.Error(),
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"data":       sizes,
+		"account_id": accountID,
+		"nm_id":      nmID,
+	})
+}
+
+// GetUnitEconomics - GET /api/stat/unit-economics | Юнит-экономика по товарам: комиссия, логистика, хранение, себестоимость, налог, маржа и ROI
+func (h *WBStatsHandler) GetUnitEconomics(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	dateFrom, dateTo, err := parseDateRange(r.URL.Query())
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Кабинет (0 или не указан - все кабинеты)
+	accountID, err := resolveAccountID(h.accountRepo, access.OwnerID(), r.URL.Query().Get("account_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	products, summary, err := h.analyticsRepo.GetUnitEconomics(access.OwnerID(), accountID, dateFrom, dateTo)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get unit economics: " + err.Error(),
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"data":       products,
+		"summary":    summary,
+		"taxes":      access.Owner.Taxes,
+		"account_id": accountID,
+	})
+}
+
+// GetABCXYZ - GET /api/stat/abc-xyz | ABC-анализ товаров по выручке, прибыли или продажам и XYZ-анализ
+// по вариативности продаж: матрица классов, вклад товаров и сравнение с предыдущим периодом
+func (h *WBStatsHandler) GetABCXYZ(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	query := r.URL.Query()
+
+	dateFrom, dateTo, err := parseDateRange(query)
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Кабинет (0 или не указан - все кабинеты)
+	accountID, err := resolveAccountID(h.accountRepo, access.OwnerID(), query.Get("account_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	opts := stat.ABCXYZOptions{
+		Metric:   stat.ABCMetricRevenue,
+		Interval: stat.XYZIntervalWeek,
+		ABC:      [2]float64{80, 95},
+		XYZ:      [2]float64{10, 25},
+	}
+
+	switch metric := query.Get("metric"); metric {
+	case "":
+	case stat.ABCMetricRevenue, stat.ABCMetricProfit, stat.ABCMetricUnits:
+		opts.Metric = metric
+	default:
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid metric, expected revenue, profit or units"})
+		return
+	}
+
+	switch interval := query.Get("interval"); interval {
+	case "":
+	case stat.XYZIntervalWeek, stat.XYZIntervalMonth:
+		opts.Interval = interval
+	default:
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid interval, expected week or month"})
+		return
+	}
+
+	// Границы классов: abc=80,95 (накопленная доля, %), xyz=10,25 (коэффициент вариации, %)
+	if opts.ABC, err = parseThresholds(query.Get("abc"), opts.ABC, 100); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid abc: " + err.Error()})
+		return
+	}
+	if opts.XYZ, err = parseThresholds(query.Get("xyz"), opts.XYZ, 0); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid xyz: " + err.Error()})
+		return
+	}
+
+	result, err := h.analyticsRepo.GetABCXYZ(access.OwnerID(), accountID, dateFrom, dateTo, opts)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get ABC/XYZ analysis: " + err.Error(),
+		})
+		return
+	}
+	result["account_id"] = accountID
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// parseThresholds - две возрастающие положительные границы через запятую ("80,95").
+// max > 0 - верхний предел значений
+func parseThresholds(raw string, def [2]float64, max float64) ([2]float64, error) {
+	if raw == "" {
+		return def, nil
+	}
+
+	parts := strings.Split(raw, ",")
+	if len(parts) != 2 {
+		return def, fmt.Errorf("expected two values separated by comma")
+	}
+
+	var result [2]float64
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || value <= 0 || (max > 0 && value > max) {
+			return def, fmt.Errorf("invalid value %q", part)
+		}
+		result[i] = value
+	}
+	if result[0] >= result[1] {
+		return def, fmt.Errorf("first value must be less than second")
+	}
+
+	return result, nil
+}
+
+// parseDateRange - обязательные параметры dateFrom и dateTo в формате YYYY-MM-DD
+func parseDateRange(query url.Values) (string, string, error) {
+	dateFrom, dateTo := query.Get("dateFrom"), query.Get("dateTo")
+	if dateFrom == "" || dateTo == "" {
+		return "", "", fmt.Errorf("Parameters dateFrom and dateTo are required")
+	}
+
+	from, err := time.Parse("2006-01-02", dateFrom)
+	if err != nil {
+		return "", "", fmt.Errorf("Invalid dateFrom format, expected YYYY-MM-DD")
+	}
+	to, err := time.Parse("2006-01-02", dateTo)
+	if err != nil {
+		return "", "", fmt.Errorf("Invalid dateTo format, expected YYYY-MM-DD")
+	}
+	if to.Before(from) {
+		return "", "", fmt.Errorf("dateTo must not be before dateFrom")
+	}
+
+	return dateFrom, dateTo, nil
+}
+
 // GetWBReports - GET /api/wb/stats | Получение списка репортов(заказов отчетов из бд)
 func (h *WBStatsHandler) GetWBReports(w http.ResponseWriter, r *http.Request) {
 	// Получить пользователя по токену
@@ -149,8 +399,21 @@ func (h *WBStatsHandler) GetWBReports(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	accountID, err := resolveAccountID(h.accountRepo, access.OwnerID(), r.URL.Query().Get("account_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	// Получить отчеты для этого пользователя
-	reports, err := h.wbStatsGetRepo.GetByUserID(user.ID)
+	reports, err := h.wbStatsGetRepo.GetByUserID(access.OwnerID(), accountID)
 	if err != nil {
 		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to get reports"})
 		return
@@ -162,6 +425,7 @@ func (h *WBStatsHandler) GetWBReports(w http.ResponseWriter, r *http.Request) {
 		response[i] = map[string]interface{}{
 			"id":         report.ID,
 			"user_id":    report.UserID,
+			"account_id": getIntValue(report.AccountID),
 			"status":     getStatusValue(report.Status),
 			"date_from":  report.DateFrom,
 			"date_to":    report.DateTo,
@@ -183,10 +447,18 @@ func (h *WBStatsHandler) CreateWBReport(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermRequestData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	// Парсим запрос
 	var req struct {
-		DateFrom string `json:"dateFrom"`
-		DateTo   string `json:"dateTo"`
+		DateFrom  string `json:"dateFrom"`
+		DateTo    string `json:"dateTo"`
+		AccountID int    `json:"account_id"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -200,12 +472,26 @@ func (h *WBStatsHandler) CreateWBReport(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Кабинет, по которому заказывается отчет (по умолчанию - основной)
+	accountID, err := resolveAccountID(h.accountRepo, access.OwnerID(), strconv.Itoa(req.AccountID))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	jobAccount, err := jobAccountID(h.accountRepo, access.OwnerID(), accountID)
+	if err != nil {
+		respondJobAccountError(w, err)
+		return
+	}
+
 	// Создание репорта в бд
 	stats := &entity.WBStatsGet{ // Меняем repository.WBStatsGet на entity.WBStatsGet
-		UserID:   user.ID,
-		Status:   getNullInt64(0), // 0 = в обработке
-		DateFrom: req.DateFrom,
-		DateTo:   req.DateTo,
+		UserID:    access.OwnerID(),
+		AccountID: jobAccount,
+		Status:    getNullInt64(0), // 0 = в обработке
+		DateFrom:  req.DateFrom,
+		DateTo:    req.DateTo,
 	}
 
 	if err := h.wbStatsGetRepo.Create(stats); err != nil {
@@ -213,6 +499,12 @@ func (h *WBStatsHandler) CreateWBReport(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	recordJobCreated(h.auditService, r, user, access.OwnerID(), "wb_stats_get", stats.ID, map[string]interface{}{
+		"account_id": getIntValue(stats.AccountID),
+		"date_from":  stats.DateFrom,
+		"date_to":    stats.DateTo,
+	})
+
 	// Запустите асинхронную задачу для получения данных из API WB.
 	//go h.fetchWBDataAsync(stats.ID, user, req.DateFrom, req.DateTo)
 
@@ -237,7 +529,7 @@ func (h *WBStatsHandler) fetchWBDataAsync(reportID int, user *entity.Users, date
 	fmt.Printf("Report %d processed successfully for user %d\n", reportID, user.ID)
 }
 
-// GetDashboardStats - GET /api/dashboard/stats | Получение статистики для дашборда
+// GetDashboardStats - GET /api/dashboard/stats?marketplace= | Получение статистики для дашборда (all, wb, ozon, yandex)
 func (h *WBStatsHandler) GetDashboardStats(w http.ResponseWriter, r *http.Request) {
 	// Получить пользователя по токену
 	user, err := h.getUserFromRequest(r)
@@ -246,6 +538,13 @@ func (h *WBStatsHandler) GetDashboardStats(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	// Получить параметры дат (по умолчанию - текущий месяц)
 	now := time.Now()
 	firstDay := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
@@ -261,8 +560,20 @@ func (h *WBStatsHandler) GetDashboardStats(w http.ResponseWriter, r *http.Reques
 		dateTo = lastDay.Format("2006-01-02")
 	}
 
+	accountID, err := resolveAccountID(h.accountRepo, access.OwnerID(), r.URL.Query().Get("account_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	marketplace, err := resolveMarketplace(r.URL.Query().Get("marketplace"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	// Получить статистику для дашборда
-	stats, err := h.dashboardRepo.GetDashboardStats(user.ID, dateFrom, dateTo)
+	stats, err := h.dashboardRepo.GetDashboardStats(access.OwnerID(), accountID, marketplace, dateFrom, dateTo)
 	if err != nil {
 		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
 			Error: "Failed to get dashboard stats: " + err.Error(),
@@ -271,7 +582,7 @@ func (h *WBStatsHandler) GetDashboardStats(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Получить данные для графиков
-	chartData, err := h.dashboardRepo.GetChartData(user.ID, dateFrom, dateTo)
+	chartData, err := h.dashboardRepo.GetChartData(access.OwnerID(), accountID, marketplace, dateFrom, dateTo)
 	if err != nil {
 		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
 			Error: "Failed to get chart data: " + err.Error(),
@@ -280,7 +591,7 @@ func (h *WBStatsHandler) GetDashboardStats(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Получить данные по месяцам
-	monthlyRevenue, err := h.dashboardRepo.GetMonthlyRevenue(user.ID)
+	monthlyRevenue, err := h.dashboardRepo.GetMonthlyRevenue(access.OwnerID(), accountID, marketplace)
 	if err != nil {
 		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
 			Error: "Failed to get monthly revenue: " + err.Error(),
@@ -293,6 +604,8 @@ func (h *WBStatsHandler) GetDashboardStats(w http.ResponseWriter, r *http.Reques
 		"stats":           stats,
 		"charts":          chartData,
 		"monthly_revenue": monthlyRevenue, // Добавляем новые данные
+		"account_id":      accountID,
+		"marketplace":     marketplaceLabel(marketplace),
 		"period": map[string]string{
 			"dateFrom": dateFrom,
 			"dateTo":   dateTo,
@@ -302,6 +615,95 @@ func (h *WBStatsHandler) GetDashboardStats(w http.ResponseWriter, r *http.Reques
 	respondWithJSON(w, http.StatusOK, response)
 }
 
+// GetAccountsDashboard - GET /api/dashboard/accounts?marketplace= | Сводная статистика по всем кабинетам пользователя
+func (h *WBStatsHandler) GetAccountsDashboard(w http.ResponseWriter, r *http.Request) {
+	// Получить пользователя по токену
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Получить параметры дат (по умолчанию - текущий месяц)
+	now := time.Now()
+	firstDay := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	lastDay := firstDay.AddDate(0, 1, -1)
+
+	dateFrom := r.URL.Query().Get("dateFrom")
+	dateTo := r.URL.Query().Get("dateTo")
+
+	if dateFrom == "" {
+		dateFrom = firstDay.Format("2006-01-02")
+	}
+	if dateTo == "" {
+		dateTo = lastDay.Format("2006-01-02")
+	}
+
+	marketplace, err := resolveMarketplace(r.URL.Query().Get("marketplace"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	accounts, err := h.accountRepo.GetByUserID(access.OwnerID())
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get accounts: " + err.Error(),
+		})
+		return
+	}
+
+	// Статистика по каждому кабинету (данные кабинета - только его площадки)
+	accountsStats := []map[string]interface{}{}
+	for _, a := range accounts {
+		if marketplace != "" && a.Marketplace != marketplace {
+			continue
+		}
+
+		stats, err := h.dashboardRepo.GetDashboardStats(access.OwnerID(), a.ID, a.Marketplace, dateFrom, dateTo)
+		if err != nil {
+			respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+				Error: "Failed to get dashboard stats: " + err.Error(),
+			})
+			return
+		}
+
+		accountsStats = append(accountsStats, map[string]interface{}{
+			"id":          a.ID,
+			"name":        a.Name,
+			"marketplace": a.Marketplace,
+			"is_default":  a.IsDefault == 1,
+			"stats":       stats,
+		})
+	}
+
+	// Итого по всем кабинетам
+	total, err := h.dashboardRepo.GetDashboardStats(access.OwnerID(), entity.AllAccounts, marketplace, dateFrom, dateTo)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get dashboard stats: " + err.Error(),
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"accounts":    accountsStats,
+		"total":       total,
+		"marketplace": marketplaceLabel(marketplace),
+		"period": map[string]string{
+			"dateFrom": dateFrom,
+			"dateTo":   dateTo,
+		},
+	})
+}
+
 // Вспомогательная функция для получения пользователя из JWT-токена.
 func (h *WBStatsHandler) getUserFromRequest(r *http.Request) (*entity.Users, error) {
 	authHeader := r.Header.Get("Authorization")
@@ -352,3 +754,306 @@ func getStringValue(ns sql.NullString) string {
 func getNullInt64(v int) sql.NullInt64 {
 	return sql.NullInt64{Int64: int64(v), Valid: true}
 }
+
+// GetDemandForecast - GET /api/stat/forecast | Прогноз спроса по товарам, размерам и складам
+// с рекомендацией поставки на horizon дней с учетом срока поставки lead_time и ошибкой прогноза
+func (h *WBStatsHandler) GetDemandForecast(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	query := r.URL.Query()
+
+	// Кабинет (0 или не указан - все кабинеты)
+	accountID, err := resolveAccountID(h.accountRepo, access.OwnerID(), query.Get("account_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	opts := stat.ForecastOptions{
+		Method:   stat.ForecastMethodSMA,
+		GroupBy:  stat.ForecastGroupProduct,
+		History:  90,
+		Window:   28,
+		Alpha:    0.3,
+		LeadTime: 7,
+		Horizon:  30,
+		Page:     PageNum,
+		PageSize: PageSize,
+	}
+
+	switch method := query.Get("method"); method {
+	case "":
+	case stat.ForecastMethodSMA, stat.ForecastMethodSES:
+		opts.Method = method
+	default:
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid method, expected sma or ses"})
+		return
+	}
+
+	switch group := query.Get("group"); group {
+	case "":
+	case stat.ForecastGroupProduct, stat.ForecastGroupSize, stat.ForecastGroupWarehouse, stat.ForecastGroupSizeWarehouse:
+		opts.GroupBy = group
+	default:
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid group, expected product, size, warehouse or size_warehouse",
+		})
+		return
+	}
+
+	// Целые параметры в днях: значение по умолчанию и допустимые пределы
+	days := []struct {
+		name     string
+		value    *int
+		min, max int
+	}{
+		{"history", &opts.History, 14, 365},
+		{"window", &opts.Window, 1, 90},
+		{"lead_time", &opts.LeadTime, 0, 120},
+		{"horizon", &opts.Horizon, 1, 180},
+	}
+	for _, param := range days {
+		raw := query.Get(param.name)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < param.min || value > param.max {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{
+				Error: fmt.Sprintf("Invalid %s, expected %d-%d", param.name, param.min, param.max),
+			})
+			return
+		}
+		*param.value = value
+	}
+	if opts.Window > opts.History {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "window must not exceed history"})
+		return
+	}
+
+	if raw := query.Get("alpha"); raw != "" {
+		if opts.Alpha, err = strconv.ParseFloat(raw, 64); err != nil || opts.Alpha <= 0 || opts.Alpha > 1 {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid alpha, expected 0-1"})
+			return
+		}
+	}
+
+	if raw := query.Get("nm_id"); raw != "" {
+		if opts.NmID, err = strconv.ParseInt(raw, 10, 64); err != nil || opts.NmID < 0 {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid nm_id"})
+			return
+		}
+	}
+
+	// Параметры пагинации
+	if p, err := strconv.Atoi(query.Get("page")); err == nil && p > Zero {
+		opts.Page = p
+	}
+	if ps, err := strconv.Atoi(query.Get("pageSize")); err == nil && ps > Zero && ps <= MaxPageSize {
+		opts.PageSize = ps
+	}
+
+	result, err := h.analyticsRepo.GetDemandForecast(access.OwnerID(), accountID, opts)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get demand forecast: " + err.Error(),
+		})
+		return
+	}
+	result["account_id"] = accountID
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// GetReturnAnalytics - GET /api/stat/returns | Процент выкупа, доля возвратов и обратная логистика
+// по товарам, размерам, складам или неделям и товары со скачком доли возвратов
+func (h *WBStatsHandler) GetReturnAnalytics(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	query := r.URL.Query()
+
+	dateFrom, dateTo, err := parseDateRange(query)
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Кабинет (0 или не указан - все кабинеты)
+	accountID, err := resolveAccountID(h.accountRepo, access.OwnerID(), query.Get("account_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	opts := stat.ReturnsOptions{
+		GroupBy:       stat.ReturnsGroupProduct,
+		BaselineWeeks: 8,
+		JumpPoints:    10,
+		MinSales:      10,
+		Page:          PageNum,
+		PageSize:      PageSize,
+	}
+
+	switch group := query.Get("group"); group {
+	case "":
+	case stat.ReturnsGroupProduct, stat.ReturnsGroupSize, stat.ReturnsGroupWarehouse, stat.ReturnsGroupWeek:
+		opts.GroupBy = group
+	default:
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid group, expected product, size, warehouse or week",
+		})
+		return
+	}
+
+	if raw := query.Get("nm_id"); raw != "" {
+		if opts.NmID, err = strconv.ParseInt(raw, 10, 64); err != nil || opts.NmID < 0 {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid nm_id"})
+			return
+		}
+	}
+
+	// Параметры скачков: недели базы, рост доли возвратов в п.п., минимум продаж
+	if raw := query.Get("baseline_weeks"); raw != "" {
+		if opts.BaselineWeeks, err = strconv.Atoi(raw); err != nil || opts.BaselineWeeks < 1 || opts.BaselineWeeks > 52 {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid baseline_weeks, expected 1-52"})
+			return
+		}
+	}
+	if raw := query.Get("jump"); raw != "" {
+		if opts.JumpPoints, err = strconv.ParseFloat(raw, 64); err != nil || opts.JumpPoints <= 0 || opts.JumpPoints > 100 {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid jump, expected 0-100"})
+			return
+		}
+	}
+	if raw := query.Get("min_sales"); raw != "" {
+		if opts.MinSales, err = strconv.Atoi(raw); err != nil || opts.MinSales < 1 {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid min_sales"})
+			return
+		}
+	}
+
+	// Параметры пагинации
+	if p, err := strconv.Atoi(query.Get("page")); err == nil && p > Zero {
+		opts.Page = p
+	}
+	if ps, err := strconv.Atoi(query.Get("pageSize")); err == nil && ps > Zero && ps <= MaxPageSize {
+		opts.PageSize = ps
+	}
+
+	result, err := h.analyticsRepo.GetReturnAnalytics(access.OwnerID(), accountID, dateFrom, dateTo, opts)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get return analytics: " + err.Error(),
+		})
+		return
+	}
+	result["account_id"] = accountID
+	result["baseline_weeks"] = opts.BaselineWeeks
+	result["jump"] = opts.JumpPoints
+	result["min_sales"] = opts.MinSales
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// GetLogisticsAnalytics - GET /api/stat/logistics | Стоимость логистики на единицу, доля обратной логистики,
+// разбивка по складам и типам коробов, динамика и товары с логистикой выше max_share % цены
+func (h *WBStatsHandler) GetLogisticsAnalytics(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	query := r.URL.Query()
+
+	dateFrom, dateTo, err := parseDateRange(query)
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Кабинет (0 или не указан - все кабинеты)
+	accountID, err := resolveAccountID(h.accountRepo, access.OwnerID(), query.Get("account_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	opts := stat.LogisticsOptions{
+		Interval: stat.LogisticsIntervalWeek,
+		MaxShare: 10,
+		Page:     PageNum,
+		PageSize: PageSize,
+	}
+
+	switch interval := query.Get("interval"); interval {
+	case "":
+	case stat.LogisticsIntervalDay, stat.LogisticsIntervalWeek, stat.LogisticsIntervalMonth:
+		opts.Interval = interval
+	default:
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid interval, expected day, week or month"})
+		return
+	}
+
+	if raw := query.Get("max_share"); raw != "" {
+		if opts.MaxShare, err = strconv.ParseFloat(raw, 64); err != nil || opts.MaxShare <= 0 || opts.MaxShare > 100 {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid max_share, expected 0-100"})
+			return
+		}
+	}
+
+	if raw := query.Get("nm_id"); raw != "" {
+		if opts.NmID, err = strconv.ParseInt(raw, 10, 64); err != nil || opts.NmID < 0 {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid nm_id"})
+			return
+		}
+	}
+
+	// Параметры пагинации (список товаров с логистикой выше доли цены)
+	if p, err := strconv.Atoi(query.Get("page")); err == nil && p > Zero {
+		opts.Page = p
+	}
+	if ps, err := strconv.Atoi(query.Get("pageSize")); err == nil && ps > Zero && ps <= MaxPageSize {
+		opts.PageSize = ps
+	}
+
+	result, err := h.analyticsRepo.GetLogisticsAnalytics(access.OwnerID(), accountID, dateFrom, dateTo, opts)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to get logistics analytics: " + err.Error(),
+		})
+		return
+	}
+	result["account_id"] = accountID
+
+	respondWithJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"wbrost-go/internal/dto"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/repository/account"
+	"wbrost-go/internal/repository/user"
+	"wbrost-go/internal/repository/yandex"
+	"wbrost-go/internal/service/audit"
+	"wbrost-go/internal/service/organization"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+type YandexHandler struct {
+	userRepo     *user.UserRepository
+	accountRepo  *account.SellerAccountRepository
+	jobRepo      *yandex.YandexGetRepository
+	orgService   *organization.OrganizationService
+	auditService *audit.AuditService
+	jwtSecret    []byte
+}
+
+func NewYandexHandler(
+	userRepo *user.UserRepository,
+	accountRepo *account.SellerAccountRepository,
+	jobRepo *yandex.YandexGetRepository,
+	orgService *organization.OrganizationService,
+	auditService *audit.AuditService,
+	jwtSecret string,
+) *YandexHandler {
+	return &YandexHandler{
+		userRepo:     userRepo,
+		accountRepo:  accountRepo,
+		jobRepo:      jobRepo,
+		orgService:   orgService,
+		auditService: auditService,
+		jwtSecret:    []byte(jwtSecret),
+	}
+}
+
+// GetJobs - GET /api/yandex/jobs?account_id= | Список заданий на загрузку данных Яндекс Маркета
+func (h *YandexHandler) GetJobs(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermViewData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	accountID, err := resolveAccountID(h.accountRepo, access.OwnerID(), r.URL.Query().Get("account_id"))
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	jobs, err := h.jobRepo.GetByUserID(access.OwnerID(), accountID)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to get yandex jobs"})
+		return
+	}
+
+	response := make([]map[string]interface{}, len(jobs))
+	for i, job := range jobs {
+		response[i] = map[string]interface{}{
+			"id":         job.ID,
+			"user_id":    job.UserID,
+			"account_id": job.AccountID,
+			"type":       job.Type,
+			"status":     getStatusValue(job.Status),
+			"date_from":  getStringValue(job.DateFrom),
+			"date_to":    getStringValue(job.DateTo),
+			"created":    job.Created.Format("2006-01-02 15:04:05"),
+			"updated":    job.Updated.Format("2006-01-02 15:04:05"),
+			"last_error": getStringValue(job.LastError),
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// CreateJob - POST /api/yandex/jobs | Заказать загрузку заказов с комиссиями за период или списка товаров кабинета
+func (h *YandexHandler) CreateJob(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromRequest(r)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnauthorized, dto.ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Организация запроса и права роли пользователя в ней
+	access, status, err := authorize(h.orgService, user, r, entity.PermRequestData)
+	if err != nil {
+		respondWithJSON(w, status, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req struct {
+		AccountID int    `json:"account_id"`
+		Type      string `json:"type"`
+		DateFrom  string `json:"dateFrom"`
+		DateTo    string `json:"dateTo"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	// Кабинет указан явно или берется кабинет Яндекс Маркета по умолчанию
+	var sellerAccount *entity.SellerAccount
+	if req.AccountID != entity.AllAccounts {
+		sellerAccount, err = h.accountRepo.GetByID(req.AccountID)
+		if err != nil || sellerAccount.UserID != access.OwnerID() {
+			sellerAccount = nil
+		}
+	} else {
+		sellerAccount, err = h.accountRepo.GetDefault(access.OwnerID(), entity.MarketplaceYandex)
+		if err != nil {
+			respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to get account: " + err.Error()})
+			return
+		}
+	}
+
+	if sellerAccount == nil || sellerAccount.Marketplace != entity.MarketplaceYandex {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Кабинет Яндекс Маркета не найден"})
+		return
+	}
+
+	if !sellerAccount.APIKey.Valid || sellerAccount.APIKey.String == "" || !sellerAccount.BusinessID.Valid || !sellerAccount.CampaignID.Valid {
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "В кабинете не настроены Api-Key, business_id или campaign_id"})
+		return
+	}
+
+	job := &entity.YandexGet{
+		UserID:    access.OwnerID(),
+		AccountID: sellerAccount.ID,
+		Type:      strings.TrimSpace(req.Type),
+		Status:    getNullInt64(entity.StatusWait),
+	}
+
+	switch job.Type {
+	case entity.YandexJobOperations:
+		if req.DateFrom == "" || req.DateTo == "" {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "DateFrom and DateTo are required"})
+			return
+		}
+		from, errFrom := time.Parse("2006-01-02", req.DateFrom)
+		to, errTo := time.Parse("2006-01-02", req.DateTo)
+		if errFrom != nil || errTo != nil || to.Before(from) {
+			respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid date range, expected YYYY-MM-DD"})
+			return
+		}
+		job.DateFrom = sql.NullString{String: req.DateFrom, Valid: true}
+		job.DateTo = sql.NullString{String: req.DateTo, Valid: true}
+	case entity.YandexJobProducts:
+	default:
+		respondWithJSON(w, http.StatusBadRequest, dto.ErrorResponse{
+			Error: fmt.Sprintf("type must be %q or %q", entity.YandexJobOperations, entity.YandexJobProducts),
+		})
+		return
+	}
+
+	if err := h.jobRepo.Create(job); err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: "Failed to create yandex job: " + err.Error()})
+		return
+	}
+
+	recordJobCreated(h.auditService, r, user, access.OwnerID(), "yandex_get", job.ID, map[string]interface{}{
+		"account_id": job.AccountID,
+		"type":       job.Type,
+		"date_from":  getStringValue(job.DateFrom),
+		"date_to":    getStringValue(job.DateTo),
+	})
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"id":      job.ID,
+		"success": true,
+		"message": "Задание поставлено в очередь",
+	})
+}
+
+func (h *YandexHandler) getUserFromRequest(r *http.Request) (*entity.Users, error) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return nil, fmt.Errorf("no authorization header")
+	}
+
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return h.jwtSecret, nil
+	})
+
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+
+	username, ok := claims["username"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid username in token")
+	}
+
+	return h.userRepo.GetByUsername(username)
+}
@@ -0,0 +1,116 @@
+package marketplace
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+	"wbrost-go/internal/entity"
+)
+
+// Ошибки провайдеров
+var (
+	ErrInvalidCredentials = errors.New("ключ API маркетплейса недействителен")
+	ErrRateLimited        = errors.New("too many requests")
+	ErrNotSupported       = errors.New("маркетплейс не поддерживает эту операцию")
+)
+
+// Provider - источник данных маркетплейса. Для подключения новой площадки
+// достаточно написать адаптер, реализующий этот интерфейс
+type Provider interface {
+	// Marketplace возвращает код площадки (entity.MarketplaceWB, entity.MarketplaceOzon, ...)
+	Marketplace() string
+
+	// ValidateCredentials проверяет ключ запросом к API.
+	// Неверный ключ - ErrInvalidCredentials, сбой сети или API - другая ошибка
+	ValidateCredentials() error
+
+	// ListProducts возвращает все товары продавца
+	ListProducts() ([]Product, error)
+
+	// FetchOperations возвращает финансовые операции за период (даты включительно)
+	FetchOperations(dateFrom, dateTo time.Time) ([]Operation, error)
+
+	// FetchOrders возвращает заказы за период (даты включительно)
+	FetchOrders(dateFrom, dateTo time.Time) ([]Order, error)
+}
+
+// Product - товар маркетплейса
+type Product struct {
+	ExternalID string // WB - nm_id, Ozon - product_id
+	OfferID    string // артикул продавца
+	Name       string
+	Barcode    string
+	Photo      string
+	Size       string
+	Price      float64
+	Archived   bool
+	Raw        interface{} // исходная карточка API (для таблиц конкретного маркетплейса)
+}
+
+// Operation - финансовая операция в общем для всех площадок виде.
+// Знаки сумм - как в таблице marketplace_operations
+type Operation struct {
+	ExternalID   string
+	Type         string // entity.OperationSale, entity.OperationReturn, ...
+	Name         string // название операции на маркетплейсе
+	Date         time.Time
+	ProductID    string
+	OfferID      string
+	ProductName  string
+	Quantity     int
+	Revenue      float64
+	Payout       float64
+	Commission   float64
+	Logistics    float64
+	Storage      float64
+	Penalty      float64
+	OtherCharges float64
+	Raw          interface{} // исходная строка отчета API
+}
+
+// Order - заказ покупателя
+type Order struct {
+	ExternalID string
+	Date       time.Time
+	ProductID  string
+	OfferID    string
+	Quantity   int
+	Price      float64
+	Warehouse  string
+	Region     string
+	Cancelled  bool
+	Raw        interface{}
+}
+
+// Entity преобразует операцию в строку marketplace_operations
+func (o *Operation) Entity(userID int, accountID sql.NullInt64, marketplace string) *entity.MarketplaceOperation {
+	op := &entity.MarketplaceOperation{
+		UserID:        userID,
+		AccountID:     accountID,
+		Marketplace:   marketplace,
+		ExternalID:    o.ExternalID,
+		OperationType: o.Type,
+		OperationName: nullString(o.Name),
+		ProductID:     nullString(o.ProductID),
+		OfferID:       nullString(o.OfferID),
+		ProductName:   nullString(o.ProductName),
+		Quantity:      o.Quantity,
+		Revenue:       o.Revenue,
+		Payout:        o.Payout,
+		Commission:    o.Commission,
+		Logistics:     o.Logistics,
+		Storage:       o.Storage,
+		Penalty:       o.Penalty,
+		OtherCharges:  o.OtherCharges,
+	}
+
+	if !o.Date.IsZero() {
+		op.OperationDate = sql.NullTime{Time: o.Date, Valid: true}
+	}
+
+	return op
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
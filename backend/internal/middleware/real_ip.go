@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"wbrost-go/internal/config"
+)
+
+// RealIP подставляет в RemoteAddr адрес клиента из заголовков прокси, но только если запрос
+// пришел от доверенного прокси (TRUSTED_PROXIES). X-Real-IP nginx задает из $remote_addr;
+// в X-Forwarded-For nginx дописывает адрес в конец к присланному клиентом, поэтому берется последний
+func RealIP(cfg *config.Config) func(http.Handler) http.Handler {
+	var trusted []*net.IPNet
+	for _, proxy := range cfg.TrustedProxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			log.Printf("TRUSTED_PROXIES: пропущен неверный адрес %q", proxy)
+			continue
+		}
+		trusted = append(trusted, network)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isTrustedProxy(trusted, r.RemoteAddr) {
+				if ip := forwardedIP(r); ip != "" {
+					r.RemoteAddr = ip
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isTrustedProxy проверяет, что адрес соединения входит в одну из доверенных подсетей
+func isTrustedProxy(trusted []*net.IPNet, remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedIP - адрес клиента из X-Real-IP или последнего адреса X-Forwarded-For ("" - заголовков нет)
+func forwardedIP(r *http.Request) string {
+	value := strings.TrimSpace(r.Header.Get("X-Real-IP"))
+	if value == "" {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			hops := strings.Split(forwarded, ",")
+			value = strings.TrimSpace(hops[len(hops)-1])
+		}
+	}
+
+	if net.ParseIP(value) == nil {
+		return ""
+	}
+	return value
+}
@@ -0,0 +1,266 @@
+package account
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"wbrost-go/internal/crypto"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/repository/database/postgres"
+)
+
+// ErrAccountNotFound - кабинет не найден или удален
+var ErrAccountNotFound = errors.New("seller account not found")
+
+// SellerAccountRepository - кабинеты продавца. API ключи хранятся зашифрованными тем же
+// набором мастер-ключей, что и users.wb_key
+type SellerAccountRepository struct {
+	db      *postgres.PostgresDB
+	keyring *crypto.Keyring
+}
+
+func NewSellerAccountRepository(db *postgres.PostgresDB, keyring *crypto.Keyring) *SellerAccountRepository {
+	return &SellerAccountRepository{db: db, keyring: keyring}
+}
+
+const accountColumns = `id, id_user, marketplace, name, api_key, key_expires_at, key_scopes,
+		       seller_id, seller_uuid, business_id, campaign_id, is_default, del, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (r *SellerAccountRepository) scanAccount(row rowScanner, a *entity.SellerAccount) error {
+	err := row.Scan(
+		&a.ID,
+		&a.UserID,
+		&a.Marketplace,
+		&a.Name,
+		&a.APIKey,
+		&a.KeyExpiresAt,
+		&a.KeyScopes,
+		&a.SellerID,
+		&a.SellerUUID,
+		&a.BusinessID,
+		&a.CampaignID,
+		&a.IsDefault,
+		&a.Del,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if a.APIKey.Valid && a.APIKey.String != "" {
+		plain, err := r.keyring.Decrypt(a.APIKey.String)
+		if err != nil {
+			return fmt.Errorf("api_key of account %d: %w", a.ID, err)
+		}
+		a.APIKey.String = plain
+	}
+
+	return nil
+}
+
+func (r *SellerAccountRepository) encryptKey(key sql.NullString) (interface{}, error) {
+	if !key.Valid || key.String == "" {
+		return nil, nil
+	}
+
+	return r.keyring.Encrypt(key.String)
+}
+
+// Create создает новый кабинет
+func (r *SellerAccountRepository) Create(a *entity.SellerAccount) error {
+	apiKey, err := r.encryptKey(a.APIKey)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt api key: %w", err)
+	}
+
+	query := `
+		INSERT INTO seller_accounts (
+			id_user, marketplace, name, api_key, key_expires_at, key_scopes,
+			seller_id, seller_uuid, business_id, campaign_id, is_default
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = r.db.QueryRow(query,
+		a.UserID,
+		a.Marketplace,
+		a.Name,
+		apiKey,
+		a.KeyExpiresAt,
+		a.KeyScopes,
+		a.SellerID,
+		a.SellerUUID,
+		a.BusinessID,
+		a.CampaignID,
+		a.IsDefault,
+	).Scan(&a.ID, &a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create seller account: %w", err)
+	}
+
+	return nil
+}
+
+// Update обновляет название, ключ и идентификаторы кабинета
+func (r *SellerAccountRepository) Update(a *entity.SellerAccount) error {
+	apiKey, err := r.encryptKey(a.APIKey)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt api key: %w", err)
+	}
+
+	query := `
+		UPDATE seller_accounts
+		SET name = $1, api_key = $2, key_expires_at = $3, key_scopes = $4,
+		    seller_id = $5, seller_uuid = $6, business_id = $7, campaign_id = $8,
+		    updated_at = $9
+		WHERE id = $10
+	`
+
+	_, err = r.db.Exec(query,
+		a.Name,
+		apiKey,
+		a.KeyExpiresAt,
+		a.KeyScopes,
+		a.SellerID,
+		a.SellerUUID,
+		a.BusinessID,
+		a.CampaignID,
+		time.Now(),
+		a.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update seller account: %w", err)
+	}
+
+	return nil
+}
+
+// Delete помечает кабинет удаленным (данные кабинета остаются в статистике)
+func (r *SellerAccountRepository) Delete(accountID int) error {
+	_, err := r.db.Exec(
+		"UPDATE seller_accounts SET del = 1, is_default = 0, updated_at = $1 WHERE id = $2",
+		time.Now(), accountID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete seller account: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID получает кабинет по ID
+func (r *SellerAccountRepository) GetByID(accountID int) (*entity.SellerAccount, error) {
+	query := `SELECT ` + accountColumns + ` FROM seller_accounts WHERE id = $1 AND del = 0`
+
+	var a entity.SellerAccount
+	if err := r.scanAccount(r.db.QueryRow(query, accountID), &a); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrAccountNotFound
+		}
+		return nil, fmt.Errorf("failed to get seller account: %w", err)
+	}
+
+	return &a, nil
+}
+
+// GetByUserID получает все кабинеты пользователя
+func (r *SellerAccountRepository) GetByUserID(userID int) ([]entity.SellerAccount, error) {
+	query := `
+		SELECT ` + accountColumns + `
+		FROM seller_accounts
+		WHERE id_user = $1 AND del = 0
+		ORDER BY is_default DESC, created_at ASC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []entity.SellerAccount
+	for rows.Next() {
+		var a entity.SellerAccount
+		if err := r.scanAccount(rows, &a); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, a)
+	}
+
+	return accounts, nil
+}
+
+// GetDefault получает кабинет пользователя по умолчанию для маркетплейса
+func (r *SellerAccountRepository) GetDefault(userID int, marketplace string) (*entity.SellerAccount, error) {
+	query := `
+		SELECT ` + accountColumns + `
+		FROM seller_accounts
+		WHERE id_user = $1 AND marketplace = $2 AND del = 0
+		ORDER BY is_default DESC, created_at ASC
+		LIMIT 1
+	`
+
+	var a entity.SellerAccount
+	if err := r.scanAccount(r.db.QueryRow(query, userID, marketplace), &a); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get default seller account: %w", err)
+	}
+
+	return &a, nil
+}
+
+// ReencryptKeys перешифровывает ключи кабинетов текущей версией мастер-ключа
+// (аналогично UserRepository.ReencryptKeys)
+func (r *SellerAccountRepository) ReencryptKeys(dryRun bool) (int, error) {
+	rows, err := r.db.Query(`SELECT id, api_key FROM seller_accounts WHERE COALESCE(api_key, '') != '' ORDER BY id`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to select account keys: %w", err)
+	}
+
+	pending := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var apiKey string
+		if err := rows.Scan(&id, &apiKey); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan account key: %w", err)
+		}
+		if !r.keyring.IsCurrent(apiKey) {
+			pending[id] = apiKey
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if dryRun {
+		return len(pending), nil
+	}
+
+	updated := 0
+	for id, stored := range pending {
+		plain, err := r.keyring.Decrypt(stored)
+		if err != nil {
+			return updated, fmt.Errorf("api_key of account %d: %w", id, err)
+		}
+		encrypted, err := r.keyring.Encrypt(plain)
+		if err != nil {
+			return updated, err
+		}
+		if _, err := r.db.Exec("UPDATE seller_accounts SET api_key = $1 WHERE id = $2", encrypted, id); err != nil {
+			return updated, fmt.Errorf("failed to update key of account %d: %w", id, err)
+		}
+		updated++
+	}
+
+	return updated, nil
+}
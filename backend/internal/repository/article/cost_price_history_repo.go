@@ -0,0 +1,96 @@
+package article
+
+import (
+	"fmt"
+	"wbrost-go/internal/entity"
+)
+
+// AddCostPrice добавляет себестоимость карточки или размера (ChrtID) с датой начала действия
+// (запись на ту же дату заменяется) и обновляет текущую себестоимость - значение, действующее на сегодня
+func (r *WBArticlesRepository) AddCostPrice(entry *entity.CostPriceHistory) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO cost_price_history (user_id, articule, chrt_id, cost_price, valid_from, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, articule, chrt_id, valid_from) DO UPDATE
+		SET cost_price = EXCLUDED.cost_price,
+		    created_by = EXCLUDED.created_by,
+		    created_at = CURRENT_TIMESTAMP
+		RETURNING id, created_at
+	`, entry.UserID, entry.Articule, entry.ChrtID, entry.CostPrice, entry.ValidFrom, entry.CreatedBy).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add cost price: %w", err)
+	}
+
+	// Значение, действующее на сегодня
+	current := `(
+		SELECT h.cost_price
+		FROM cost_price_history h
+		WHERE h.user_id = $1 AND h.articule = $2 AND h.chrt_id = $3 AND h.valid_from <= CURRENT_DATE
+		ORDER BY h.valid_from DESC
+		LIMIT 1
+	)`
+
+	if entry.ChrtID == 0 {
+		_, err = tx.Exec(`
+			UPDATE wb_articles
+			SET cost_price = COALESCE(`+current+`, 0),
+			    updated = CURRENT_DATE,
+			    updated_at = CURRENT_DATE
+			WHERE id_user = $1 AND articule::text = $2
+		`, entry.UserID, entry.Articule, entry.ChrtID)
+	} else {
+		// Очищенная себестоимость размера (0) - NULL: используется себестоимость карточки
+		_, err = tx.Exec(`
+			UPDATE wb_article_sizes
+			SET cost_price = NULLIF(`+current+`, 0),
+			    updated_at = CURRENT_TIMESTAMP
+			WHERE user_id = $1 AND nm_id::text = $2 AND chrt_id = $3
+		`, entry.UserID, entry.Articule, entry.ChrtID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update current cost price: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetCostPriceHistory возвращает историю себестоимости товара и его размеров
+// (сначала карточка, затем размеры по chrt_id; новые записи первыми)
+func (r *WBArticlesRepository) GetCostPriceHistory(userID int, articule string) ([]entity.CostPriceHistory, error) {
+	rows, err := r.db.Query(`
+		SELECT id, user_id, articule, chrt_id, cost_price, valid_from, created_by, created_at
+		FROM cost_price_history
+		WHERE user_id = $1 AND articule = $2
+		ORDER BY chrt_id, valid_from DESC
+	`, userID, articule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cost price history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []entity.CostPriceHistory
+	for rows.Next() {
+		var entry entity.CostPriceHistory
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.UserID,
+			&entry.Articule,
+			&entry.ChrtID,
+			&entry.CostPrice,
+			&entry.ValidFrom,
+			&entry.CreatedBy,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan cost price history: %w", err)
+		}
+		history = append(history, entry)
+	}
+
+	return history, rows.Err()
+}
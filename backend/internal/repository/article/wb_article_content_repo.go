@@ -0,0 +1,116 @@
+package article
+
+import (
+	"fmt"
+	"wbrost-go/internal/entity"
+
+	"github.com/lib/pq"
+)
+
+// SaveContent заменяет фотографии и характеристики карточки
+func (r *WBArticlesRepository) SaveContent(userID int, nmID int64, photos []entity.WBArticlePhoto, characteristics []entity.WBArticleCharacteristic) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM wb_article_photos WHERE user_id = $1 AND nm_id = $2`, userID, nmID); err != nil {
+		return fmt.Errorf("failed to clear photos: %w", err)
+	}
+
+	for _, photo := range photos {
+		_, err := tx.Exec(`
+			INSERT INTO wb_article_photos (user_id, nm_id, position, big, c246x328, c516x688, square, tm)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, userID, nmID, photo.Position, photo.Big, photo.C246x328, photo.C516x688, photo.Square, photo.Tm)
+		if err != nil {
+			return fmt.Errorf("failed to save photo %d: %w", photo.Position, err)
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM wb_article_characteristics WHERE user_id = $1 AND nm_id = $2`, userID, nmID); err != nil {
+		return fmt.Errorf("failed to clear characteristics: %w", err)
+	}
+
+	for _, c := range characteristics {
+		// Пустое значение сохраняется как NULL
+		var value interface{}
+		if len(c.Value) > 0 {
+			value = string(c.Value)
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO wb_article_characteristics (user_id, nm_id, characteristic_id, name, value)
+			VALUES ($1, $2, $3, $4, $5::jsonb)
+			ON CONFLICT (user_id, nm_id, characteristic_id) DO UPDATE
+			SET name = EXCLUDED.name, value = EXCLUDED.value
+		`, userID, nmID, c.CharacteristicID, c.Name, value)
+		if err != nil {
+			return fmt.Errorf("failed to save characteristic %d: %w", c.CharacteristicID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetPhotos возвращает фотографии карточек по порядку, сгруппированные по nm_id
+func (r *WBArticlesRepository) GetPhotos(userID int, nmIDs []int64) (map[int64][]entity.WBArticlePhoto, error) {
+	result := make(map[int64][]entity.WBArticlePhoto)
+	if len(nmIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := r.db.Query(`
+		SELECT nm_id, position, COALESCE(big, ''), COALESCE(c246x328, ''), COALESCE(c516x688, ''),
+		       COALESCE(square, ''), COALESCE(tm, '')
+		FROM wb_article_photos
+		WHERE user_id = $1 AND nm_id = ANY($2)
+		ORDER BY nm_id, position
+	`, userID, pq.Array(nmIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query photos: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p entity.WBArticlePhoto
+		if err := rows.Scan(&p.NmID, &p.Position, &p.Big, &p.C246x328, &p.C516x688, &p.Square, &p.Tm); err != nil {
+			return nil, fmt.Errorf("failed to scan photo: %w", err)
+		}
+		result[p.NmID] = append(result[p.NmID], p)
+	}
+
+	return result, rows.Err()
+}
+
+// GetCharacteristics возвращает характеристики карточек, сгруппированные по nm_id
+func (r *WBArticlesRepository) GetCharacteristics(userID int, nmIDs []int64) (map[int64][]entity.WBArticleCharacteristic, error) {
+	result := make(map[int64][]entity.WBArticleCharacteristic)
+	if len(nmIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := r.db.Query(`
+		SELECT nm_id, characteristic_id, name, COALESCE(value, 'null'::jsonb)
+		FROM wb_article_characteristics
+		WHERE user_id = $1 AND nm_id = ANY($2)
+		ORDER BY nm_id, id
+	`, userID, pq.Array(nmIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query characteristics: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c entity.WBArticleCharacteristic
+		var value []byte
+		if err := rows.Scan(&c.NmID, &c.CharacteristicID, &c.Name, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan characteristic: %w", err)
+		}
+		c.Value = value
+		result[c.NmID] = append(result[c.NmID], c)
+	}
+
+	return result, rows.Err()
+}
@@ -0,0 +1,102 @@
+package article
+
+import (
+	"fmt"
+	"wbrost-go/internal/entity"
+
+	"github.com/lib/pq"
+)
+
+// SaveSizes сохраняет все размеры карточки с баркодами. Размеры, которых больше нет
+// в карточке, удаляются; себестоимость оставшихся размеров не меняется
+func (r *WBArticlesRepository) SaveSizes(userID int, nmID int64, sizes []entity.WBArticleSize) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	chrtIDs := make([]int64, 0, len(sizes))
+	for _, size := range sizes {
+		var sizeID int
+		err := tx.QueryRow(`
+			INSERT INTO wb_article_sizes (user_id, nm_id, chrt_id, tech_size, wb_size)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (user_id, nm_id, chrt_id) DO UPDATE
+			SET tech_size = EXCLUDED.tech_size,
+			    wb_size = EXCLUDED.wb_size,
+			    updated_at = CURRENT_TIMESTAMP
+			RETURNING id
+		`, userID, nmID, size.ChrtID, size.TechSize, size.WbSize).Scan(&sizeID)
+		if err != nil {
+			return fmt.Errorf("failed to save size %d: %w", size.ChrtID, err)
+		}
+		chrtIDs = append(chrtIDs, size.ChrtID)
+
+		if _, err := tx.Exec(`DELETE FROM wb_article_barcodes WHERE size_id = $1`, sizeID); err != nil {
+			return fmt.Errorf("failed to clear barcodes of size %d: %w", size.ChrtID, err)
+		}
+
+		for _, sku := range size.Skus {
+			_, err := tx.Exec(`
+				INSERT INTO wb_article_barcodes (size_id, user_id, barcode)
+				VALUES ($1, $2, $3)
+				ON CONFLICT (size_id, barcode) DO NOTHING
+			`, sizeID, userID, sku)
+			if err != nil {
+				return fmt.Errorf("failed to save barcode %s: %w", sku, err)
+			}
+		}
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM wb_article_sizes
+		WHERE user_id = $1 AND nm_id = $2 AND NOT (chrt_id = ANY($3))
+	`, userID, nmID, pq.Array(chrtIDs))
+	if err != nil {
+		return fmt.Errorf("failed to delete removed sizes: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetSizes возвращает размеры карточек с баркодами, сгруппированные по nm_id
+func (r *WBArticlesRepository) GetSizes(userID int, nmIDs []int64) (map[int64][]entity.WBArticleSize, error) {
+	result := make(map[int64][]entity.WBArticleSize)
+	if len(nmIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := r.db.Query(`
+		SELECT sz.id, sz.user_id, sz.nm_id, sz.chrt_id, sz.tech_size, sz.wb_size, sz.cost_price,
+		       COALESCE(array_agg(b.barcode ORDER BY b.barcode) FILTER (WHERE b.barcode IS NOT NULL), '{}')
+		FROM wb_article_sizes sz
+		LEFT JOIN wb_article_barcodes b ON b.size_id = sz.id
+		WHERE sz.user_id = $1 AND sz.nm_id = ANY($2)
+		GROUP BY sz.id
+		ORDER BY sz.nm_id, sz.id
+	`, userID, pq.Array(nmIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sizes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var size entity.WBArticleSize
+		if err := rows.Scan(
+			&size.ID,
+			&size.UserID,
+			&size.NmID,
+			&size.ChrtID,
+			&size.TechSize,
+			&size.WbSize,
+			&size.CostPrice,
+			pq.Array(&size.Skus),
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan size: %w", err)
+		}
+		result[size.NmID] = append(result[size.NmID], size)
+	}
+
+	return result, rows.Err()
+}
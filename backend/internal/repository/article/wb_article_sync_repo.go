@@ -0,0 +1,221 @@
+package article
+
+import (
+	"database/sql"
+	"fmt"
+	"wbrost-go/internal/entity"
+
+	"github.com/lib/pq"
+)
+
+// GetSyncState возвращает курсор синхронизации кабинета (пустой, если карточки еще не загружались)
+func (r *WBArticlesRepository) GetSyncState(userID, accountID int) (*entity.WBArticleSync, error) {
+	state := &entity.WBArticleSync{UserID: userID, AccountID: accountID}
+	err := r.db.QueryRow(`
+		SELECT cursor_updated_at, cursor_nm_id, last_sync_at, last_full_sync_at
+		FROM wb_article_sync
+		WHERE user_id = $1 AND account_id = $2
+	`, userID, accountID).Scan(&state.CursorUpdatedAt, &state.CursorNmID, &state.LastSyncAt, &state.LastFullSyncAt)
+	if err == sql.ErrNoRows {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sync state: %w", err)
+	}
+	return state, nil
+}
+
+// SaveSyncState сохраняет курсор и время синхронизации кабинета
+func (r *WBArticlesRepository) SaveSyncState(state *entity.WBArticleSync) error {
+	_, err := r.db.Exec(`
+		INSERT INTO wb_article_sync (user_id, account_id, cursor_updated_at, cursor_nm_id, last_sync_at, last_full_sync_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, account_id) DO UPDATE
+		SET cursor_updated_at = EXCLUDED.cursor_updated_at,
+		    cursor_nm_id = EXCLUDED.cursor_nm_id,
+		    last_sync_at = EXCLUDED.last_sync_at,
+		    last_full_sync_at = EXCLUDED.last_full_sync_at
+	`, state.UserID, state.AccountID, state.CursorUpdatedAt, state.CursorNmID, state.LastSyncAt, state.LastFullSyncAt)
+	if err != nil {
+		return fmt.Errorf("failed to save sync state: %w", err)
+	}
+	return nil
+}
+
+// GetSnapshot возвращает сохраненное состояние карточки WB (nil - карточки еще нет)
+func (r *WBArticlesRepository) GetSnapshot(userID int, nmID int64) (*entity.WBArticleSnapshot, error) {
+	var snapshot entity.WBArticleSnapshot
+	err := r.db.QueryRow(`
+		SELECT
+			COALESCE(wa.name, ''),
+			COALESCE(wa.internal_id, ''),
+			COALESCE(wa.brand, ''),
+			COALESCE(wa.subject_name, ''),
+			COALESCE(wa.archive_reason, $3),
+			COALESCE((
+				SELECT array_agg(COALESCE(p.big, '') ORDER BY p.position)
+				FROM wb_article_photos p
+				WHERE p.user_id = wa.id_user AND p.nm_id = $2
+			), '{}'),
+			COALESCE((
+				SELECT array_agg(sz.tech_size ORDER BY sz.tech_size)
+				FROM wb_article_sizes sz
+				WHERE sz.user_id = wa.id_user AND sz.nm_id = $2
+			), '{}'),
+			COALESCE((
+				SELECT array_agg(b.barcode ORDER BY b.barcode)
+				FROM wb_article_barcodes b
+				JOIN wb_article_sizes sz ON sz.id = b.size_id
+				WHERE sz.user_id = wa.id_user AND sz.nm_id = $2
+			), '{}')
+		FROM wb_articles wa
+		WHERE wa.id_user = $1 AND wa.articule = $2 AND wa.marketplace = 'wb'
+		LIMIT 1
+	`, userID, nmID, entity.ArticleStatusActive).Scan(
+		&snapshot.Title,
+		&snapshot.VendorCode,
+		&snapshot.Brand,
+		&snapshot.Subject,
+		&snapshot.Status,
+		pq.Array(&snapshot.Photos),
+		pq.Array(&snapshot.Sizes),
+		pq.Array(&snapshot.Barcodes),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get article snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// GetNmIDs возвращает nm_id карточек WB кабинета (accountID без Valid - основной кабинет)
+func (r *WBArticlesRepository) GetNmIDs(userID int, accountID sql.NullInt64) ([]int64, error) {
+	rows, err := r.db.Query(`
+		SELECT articule
+		FROM wb_articles
+		WHERE id_user = $1 AND marketplace = 'wb' AND account_id IS NOT DISTINCT FROM $2
+	`, userID, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query nm ids: %w", err)
+	}
+	defer rows.Close()
+
+	var nmIDs []int64
+	for rows.Next() {
+		var nmID int64
+		if err := rows.Scan(&nmID); err != nil {
+			return nil, fmt.Errorf("failed to scan nm id: %w", err)
+		}
+		nmIDs = append(nmIDs, nmID)
+	}
+	return nmIDs, rows.Err()
+}
+
+// SetArchived переносит карточки кабинета в архив с причиной reason и возвращает
+// изменения статуса (карточки, уже архивированные с той же причиной, не меняются)
+func (r *WBArticlesRepository) SetArchived(userID int, accountID sql.NullInt64, nmIDs []int64, reason string) ([]entity.WBArticleChange, error) {
+	if len(nmIDs) == 0 {
+		return nil, nil
+	}
+
+	rows, err := r.db.Query(`
+		WITH target AS (
+			SELECT id, archive_reason
+			FROM wb_articles
+			WHERE id_user = $1
+			  AND marketplace = 'wb'
+			  AND account_id IS NOT DISTINCT FROM $2
+			  AND articule = ANY($3)
+			  AND archive_reason IS DISTINCT FROM $4
+			FOR UPDATE
+		)
+		UPDATE wb_articles wa
+		SET archived_at = CURRENT_TIMESTAMP, archive_reason = $4
+		FROM target t
+		WHERE wa.id = t.id
+		RETURNING wa.articule, COALESCE(t.archive_reason, $5), wa.card_updated_at
+	`, userID, accountID, pq.Array(nmIDs), reason, entity.ArticleStatusActive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to archive articles: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []entity.WBArticleChange
+	for rows.Next() {
+		change := entity.WBArticleChange{
+			UserID:    userID,
+			AccountID: accountID,
+			Field:     entity.ArticleChangeStatus,
+			NewValue:  sql.NullString{String: reason, Valid: true},
+		}
+		if err := rows.Scan(&change.NmID, &change.OldValue, &change.CardUpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan archived article: %w", err)
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
+
+// AddChanges записывает изменения карточек в журнал
+func (r *WBArticlesRepository) AddChanges(changes []entity.WBArticleChange) error {
+	for _, c := range changes {
+		_, err := r.db.Exec(`
+			INSERT INTO wb_article_changes (user_id, account_id, nm_id, field, old_value, new_value, card_updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, c.UserID, c.AccountID, c.NmID, c.Field, c.OldValue, c.NewValue, c.CardUpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to add article change: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListChanges возвращает журнал изменений карточек по фильтрам (новые первыми) и общее количество
+func (r *WBArticlesRepository) ListChanges(userID int, f entity.ArticleChangeFilter) ([]entity.WBArticleChange, int, error) {
+	where := `
+		WHERE user_id = $1
+		  AND ($2 = 0 OR nm_id = $2)
+		  AND ($3 = '' OR field = $3)
+		  AND ($4 = '' OR detected_at >= NULLIF($4, '')::date)
+		  AND ($5 = '' OR detected_at < NULLIF($5, '')::date + 1)`
+	args := []interface{}{userID, f.NmID, f.Field, f.DateFrom, f.DateTo}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM wb_article_changes`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count article changes: %w", err)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT id, user_id, account_id, nm_id, field, old_value, new_value, card_updated_at, detected_at
+		FROM wb_article_changes`+where+`
+		ORDER BY detected_at DESC, id DESC
+		LIMIT $6 OFFSET $7
+	`, append(args, f.PageSize, (f.Page-1)*f.PageSize)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query article changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []entity.WBArticleChange
+	for rows.Next() {
+		var c entity.WBArticleChange
+		if err := rows.Scan(
+			&c.ID,
+			&c.UserID,
+			&c.AccountID,
+			&c.NmID,
+			&c.Field,
+			&c.OldValue,
+			&c.NewValue,
+			&c.CardUpdatedAt,
+			&c.DetectedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan article change: %w", err)
+		}
+		changes = append(changes, c)
+	}
+
+	return changes, total, rows.Err()
+}
@@ -18,22 +18,24 @@ func NewWBArticlesGetRepository(db *postgres.PostgresDB) *WBArticlesGetRepositor
 // Create создает новую запись запроса карточек товаров
 func (r *WBArticlesGetRepository) Create(article *entity.WBArticlesGet) error {
 	query := `
-		INSERT INTO wb_articles_get (id_user, status, last_error)
-		VALUES ($1, $2, $3)
+		INSERT INTO wb_articles_get (id_user, account_id, status, last_error, full_sync)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id, created, updated
 	`
 
 	return r.db.QueryRow(query,
 		article.UserID,
+		article.AccountID,
 		article.Status,
 		article.LastError,
+		article.FullSync,
 	).Scan(&article.ID, &article.Created, &article.Updated)
 }
 
 // GetByUserID получает запросы пользователя
 func (r *WBArticlesGetRepository) GetByUserID(userID int) ([]entity.WBArticlesGet, error) {
 	query := `
-		SELECT id, id_user, status, created, updated, last_error
+		SELECT id, id_user, account_id, status, created, updated, last_error, full_sync
 		FROM wb_articles_get 
 		WHERE id_user = $1 
 		ORDER BY created DESC
@@ -51,10 +53,12 @@ func (r *WBArticlesGetRepository) GetByUserID(userID int) ([]entity.WBArticlesGe
 		err := rows.Scan(
 			&a.ID,
 			&a.UserID,
+			&a.AccountID,
 			&a.Status,
 			&a.Created,
 			&a.Updated,
 			&a.LastError,
+			&a.FullSync,
 		)
 		if err != nil {
 			return nil, err
@@ -68,7 +72,7 @@ func (r *WBArticlesGetRepository) GetByUserID(userID int) ([]entity.WBArticlesGe
 // GetPendingArticles возвращает запросы с статусом 0 (в обработке)
 func (r *WBArticlesGetRepository) GetPendingArticles() ([]entity.WBArticlesGet, error) {
 	query := `
-		SELECT id, id_user, status, created, updated, last_error
+		SELECT id, id_user, account_id, status, created, updated, last_error, full_sync
 		FROM wb_articles_get 
 		WHERE status = $1 
 		ORDER BY created ASC
@@ -86,10 +90,12 @@ func (r *WBArticlesGetRepository) GetPendingArticles() ([]entity.WBArticlesGet,
 		err := rows.Scan(
 			&article.ID,
 			&article.UserID,
+			&article.AccountID,
 			&article.Status,
 			&article.Created,
 			&article.Updated,
 			&article.LastError,
+			&article.FullSync,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan article: %w", err)
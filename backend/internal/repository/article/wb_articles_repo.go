@@ -1,6 +1,7 @@
 package article
 
 import (
+	"database/sql"
 	"fmt"
 	"strings"
 	"time"
@@ -18,12 +19,17 @@ func NewWBArticlesRepository(db *postgres.PostgresDB) *WBArticlesRepository {
 
 // CreateOrUpdate создает или обновляет карточку товара
 func (r *WBArticlesRepository) CreateOrUpdate(article *entity.WBArticles) error {
+	if article.Marketplace == "" {
+		article.Marketplace = entity.MarketplaceWB
+	}
+
 	// Проверяем существование
 	var exists bool
 	err := r.db.QueryRow(
-		"SELECT EXISTS(SELECT 1 FROM wb_articles WHERE id_user = $1 AND articule = $2)",
+		"SELECT EXISTS(SELECT 1 FROM wb_articles WHERE id_user = $1 AND articule = $2 AND marketplace = $3)",
 		article.UserID,
 		article.Articule,
+		article.Marketplace,
 	).Scan(&exists)
 
 	if err != nil {
@@ -38,10 +44,15 @@ func (r *WBArticlesRepository) CreateOrUpdate(article *entity.WBArticles) error
 			UPDATE wb_articles 
 			SET name = $1, photo = $2, updated = $3, updated_at = $4,
 			    rus_size = $5, eu_size = $6, chrt_id = $7, 
-			    barcode = $8, internal_id = $9
-			WHERE id_user = $10 AND articule = $11
+			    barcode = $8, internal_id = $9, account_id = $10,
+			    brand = $14, subject_id = $15, subject_name = $16, imt_id = $17, nm_uuid = $18,
+			    description = $19, length = $20, width = $21, height = $22, weight_brutto = $23,
+			    dimensions_valid = $24, card_created_at = $25, card_updated_at = $26,
+			    archived_at = NULL, archive_reason = NULL
+			WHERE id_user = $11 AND articule = $12 AND marketplace = $13
+			RETURNING id
 		`
-		_, err := r.db.Exec(query,
+		return r.db.QueryRow(query,
 			article.Name,
 			article.Photo,
 			now,
@@ -51,18 +62,36 @@ func (r *WBArticlesRepository) CreateOrUpdate(article *entity.WBArticles) error
 			article.ChrtID,
 			article.Barcode,
 			article.InternalID,
+			article.AccountID,
 			article.UserID,
 			article.Articule,
-		)
-		return err
+			article.Marketplace,
+			article.Brand,
+			article.SubjectID,
+			article.SubjectName,
+			article.ImtID,
+			article.NmUUID,
+			article.Description,
+			article.Length,
+			article.Width,
+			article.Height,
+			article.WeightBrutto,
+			article.DimensionsValid,
+			article.CardCreatedAt,
+			article.CardUpdatedAt,
+		).Scan(&article.ID)
 	} else {
 		// Создаем новую запись
 		query := `
 			INSERT INTO wb_articles (
 				id_user, articule, name, photo, cost_price, created, 
 				updated, updated_at, rus_size, eu_size, chrt_id, 
-				barcode, internal_id
-			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+				barcode, internal_id, account_id, marketplace,
+				brand, subject_id, subject_name, imt_id, nm_uuid, description,
+				length, width, height, weight_brutto, dimensions_valid,
+				card_created_at, card_updated_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15,
+			          $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28)
 			RETURNING id
 		`
 		return r.db.QueryRow(query,
@@ -79,25 +108,79 @@ func (r *WBArticlesRepository) CreateOrUpdate(article *entity.WBArticles) error
 			article.ChrtID,
 			article.Barcode,
 			article.InternalID,
+			article.AccountID,
+			article.Marketplace,
+			article.Brand,
+			article.SubjectID,
+			article.SubjectName,
+			article.ImtID,
+			article.NmUUID,
+			article.Description,
+			article.Length,
+			article.Width,
+			article.Height,
+			article.WeightBrutto,
+			article.DimensionsValid,
+			article.CardCreatedAt,
+			article.CardUpdatedAt,
 		).Scan(&article.ID)
 	}
 }
 
-// GetByUserID получает карточки товаров пользователя
-func (r *WBArticlesRepository) GetByUserID(userID int, page, pageSize int) ([]entity.WBArticles, error) {
-	offset := (page - 1) * pageSize
-
-	query := `
-		SELECT id, id_user, articule, name, photo, cost_price, 
-		       created, updated, updated_at, rus_size, eu_size,
-		       chrt_id, barcode, internal_id
-		FROM wb_articles 
-		WHERE id_user = $1 
-		ORDER BY updated DESC NULLS LAST, created DESC
-		LIMIT $2 OFFSET $3
+// articleColumns - поля карточки в порядке сканирования в List
+const articleColumns = `
+	id, id_user, articule, name, photo, cost_price,
+	created, updated, updated_at, rus_size, eu_size,
+	chrt_id, barcode, internal_id, account_id, marketplace,
+	brand, subject_id, subject_name, imt_id, nm_uuid, description,
+	length, width, height, weight_brutto, dimensions_valid,
+	card_created_at, card_updated_at, archived_at, archive_reason`
+
+// articleFilterWhere - условия ArticleFilter ($1 - пользователь, $2..$11 - фильтры)
+const articleFilterWhere = `
+	WHERE id_user = $1
+	  AND ($2 = 0 OR account_id = $2)
+	  AND ($3 = '' OR marketplace = $3)
+	  AND ($4 = '' OR LOWER(name) LIKE $4 OR articule::text LIKE $4 OR LOWER(internal_id) LIKE $4)
+	  AND ($5 = '' OR LOWER(brand) = LOWER($5))
+	  AND ($6 = 0 OR subject_id = $6)
+	  AND ($7 = '' OR LOWER(internal_id) = LOWER($7))
+	  AND ($8 = 0 OR imt_id = $8)
+	  AND ($9 = '' OR EXISTS (
+	        SELECT 1 FROM wb_article_characteristics c
+	        WHERE c.user_id = wb_articles.id_user
+	          AND c.nm_id::text = wb_articles.articule::text
+	          AND LOWER(c.name) = LOWER($9)
+	          AND ($10 = '' OR LOWER(c.value::text) LIKE $10)
+	      ))
+	  AND ($11 = '' OR ($11 = '1') = (archived_at IS NOT NULL))`
+
+// filterArgs - параметры запроса для articleFilterWhere
+func filterArgs(userID int, f entity.ArticleFilter) []interface{} {
+	search := ""
+	if f.Search != "" {
+		search = "%" + strings.ToLower(f.Search) + "%"
+	}
+	charValue := ""
+	if f.CharValue != "" {
+		charValue = "%" + strings.ToLower(f.CharValue) + "%"
+	}
+
+	return []interface{}{
+		userID, f.AccountID, f.Marketplace, search, f.Brand, f.SubjectID, f.VendorCode, f.ImtID, f.CharName, charValue, f.Archived,
+	}
+}
+
+// List получает карточки товаров пользователя по фильтрам с пагинацией
+func (r *WBArticlesRepository) List(userID int, f entity.ArticleFilter) ([]entity.WBArticles, error) {
+	query := `SELECT ` + articleColumns + `
+		FROM wb_articles` + articleFilterWhere + `
+		ORDER BY updated DESC NULLS LAST, created DESC, id DESC
+		LIMIT $12 OFFSET $13
 	`
 
-	rows, err := r.db.Query(query, userID, pageSize, offset)
+	args := append(filterArgs(userID, f), f.PageSize, (f.Page-1)*f.PageSize)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -105,101 +188,149 @@ func (r *WBArticlesRepository) GetByUserID(userID int, page, pageSize int) ([]en
 
 	var articles []entity.WBArticles
 	for rows.Next() {
-		var a entity.WBArticles
-		err := rows.Scan(
-			&a.ID,
-			&a.UserID,
-			&a.Articule,
-			&a.Name,
-			&a.Photo,
-			&a.CostPrice,
-			&a.Created,
-			&a.Updated,
-			&a.UpdatedAt,
-			&a.RusSize,
-			&a.EuSize,
-			&a.ChrtID,
-			&a.Barcode,
-			&a.InternalID,
-		)
+		a, err := scanArticle(rows)
 		if err != nil {
 			return nil, err
 		}
-		articles = append(articles, a)
+		articles = append(articles, *a)
 	}
 
-	return articles, nil
+	return articles, rows.Err()
 }
 
-// GetCountByUserID получает общее количество карточек пользователя
-func (r *WBArticlesRepository) GetCountByUserID(userID int) (int, error) {
-	var count int
+// GetByNmID получает карточку WB по nm_id (nil - карточки нет)
+func (r *WBArticlesRepository) GetByNmID(userID int, nmID int64) (*entity.WBArticles, error) {
+	a, err := scanArticle(r.db.QueryRow(`SELECT `+articleColumns+`
+		FROM wb_articles
+		WHERE id_user = $1 AND articule = $2 AND marketplace = 'wb'
+		LIMIT 1
+	`, userID, nmID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return a, err
+}
+
+// Exists проверяет, что у пользователя есть карточка с таким артикулом (любого маркетплейса)
+func (r *WBArticlesRepository) Exists(userID int, articule int64) (bool, error) {
+	var exists bool
 	err := r.db.QueryRow(
-		"SELECT COUNT(*) FROM wb_articles WHERE id_user = $1",
+		"SELECT EXISTS(SELECT 1 FROM wb_articles WHERE id_user = $1 AND articule = $2)",
 		userID,
-	).Scan(&count)
+		articule,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check article existence: %w", err)
+	}
 
-	return count, err
+	return exists, nil
 }
 
-// UpdateCostPrice обновляет себестоимость товара
-func (r *WBArticlesRepository) UpdateCostPrice(userID int, articule, costPrice string) error {
-	query := `
-		UPDATE wb_articles 
-		SET cost_price = $1, updated = $2, updated_at = $3
-		WHERE id_user = $4 AND articule = $5
-	`
+// rowScanner - общий интерфейс *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
-	_, err := r.db.Exec(query, costPrice, time.Now(), time.Now(), userID, articule)
-	return err
+// scanArticle сканирует строку с полями articleColumns
+func scanArticle(row rowScanner) (*entity.WBArticles, error) {
+	var a entity.WBArticles
+	err := row.Scan(
+		&a.ID,
+		&a.UserID,
+		&a.Articule,
+		&a.Name,
+		&a.Photo,
+		&a.CostPrice,
+		&a.Created,
+		&a.Updated,
+		&a.UpdatedAt,
+		&a.RusSize,
+		&a.EuSize,
+		&a.ChrtID,
+		&a.Barcode,
+		&a.InternalID,
+		&a.AccountID,
+		&a.Marketplace,
+		&a.Brand,
+		&a.SubjectID,
+		&a.SubjectName,
+		&a.ImtID,
+		&a.NmUUID,
+		&a.Description,
+		&a.Length,
+		&a.Width,
+		&a.Height,
+		&a.WeightBrutto,
+		&a.DimensionsValid,
+		&a.CardCreatedAt,
+		&a.CardUpdatedAt,
+		&a.ArchivedAt,
+		&a.ArchiveReason,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
 }
 
-// SearchArticles поиск карточек по названию или артикулу
-func (r *WBArticlesRepository) SearchArticles(userID int, search string, page, pageSize int) ([]entity.WBArticles, error) {
-	offset := (page - 1) * pageSize
-	searchPattern := "%" + strings.ToLower(search) + "%"
-
-	query := `
-		SELECT id, id_user, articule, name, photo, cost_price, 
-		       created, updated, updated_at, rus_size, eu_size,
-		       chrt_id, barcode, internal_id
-		FROM wb_articles 
-		WHERE id_user = $1 
-		  AND (LOWER(name) LIKE $2 OR articule::text LIKE $2)
-		ORDER BY updated DESC NULLS LAST, created DESC
-		LIMIT $3 OFFSET $4
-	`
+// Count получает количество карточек по фильтрам
+func (r *WBArticlesRepository) Count(userID int, f entity.ArticleFilter) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM wb_articles`+articleFilterWhere, filterArgs(userID, f)...).Scan(&count)
+	return count, err
+}
 
-	rows, err := r.db.Query(query, userID, searchPattern, pageSize, offset)
+// GetFilterValues возвращает бренды и предметы карточек пользователя для фильтров
+func (r *WBArticlesRepository) GetFilterValues(userID, accountID int) ([]string, []map[string]interface{}, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT brand
+		FROM wb_articles
+		WHERE id_user = $1 AND ($2 = 0 OR account_id = $2) AND COALESCE(brand, '') <> ''
+		ORDER BY brand
+	`, userID, accountID)
 	if err != nil {
-		return nil, err
+		return nil, nil, fmt.Errorf("failed to query brands: %w", err)
 	}
 	defer rows.Close()
 
-	var articles []entity.WBArticles
+	brands := []string{}
 	for rows.Next() {
-		var a entity.WBArticles
-		err := rows.Scan(
-			&a.ID,
-			&a.UserID,
-			&a.Articule,
-			&a.Name,
-			&a.Photo,
-			&a.CostPrice,
-			&a.Created,
-			&a.Updated,
-			&a.UpdatedAt,
-			&a.RusSize,
-			&a.EuSize,
-			&a.ChrtID,
-			&a.Barcode,
-			&a.InternalID,
-		)
-		if err != nil {
-			return nil, err
+		var brand string
+		if err := rows.Scan(&brand); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan brand: %w", err)
+		}
+		brands = append(brands, brand)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	subjectRows, err := r.db.Query(`
+		SELECT subject_id, COALESCE(MAX(subject_name), ''), COUNT(*)
+		FROM wb_articles
+		WHERE id_user = $1 AND ($2 = 0 OR account_id = $2) AND subject_id IS NOT NULL
+		GROUP BY subject_id
+		ORDER BY MAX(subject_name)
+	`, userID, accountID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query subjects: %w", err)
+	}
+	defer subjectRows.Close()
+
+	subjects := []map[string]interface{}{}
+	for subjectRows.Next() {
+		var subjectID int64
+		var subjectName string
+		var count int
+		if err := subjectRows.Scan(&subjectID, &subjectName, &count); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan subject: %w", err)
 		}
-		articles = append(articles, a)
+		subjects = append(subjects, map[string]interface{}{
+			"subject_id":   subjectID,
+			"subject_name": subjectName,
+			"count":        count,
+		})
 	}
 
-	return articles, nil
+	return brands, subjects, subjectRows.Err()
 }
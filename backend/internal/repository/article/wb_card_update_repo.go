@@ -0,0 +1,402 @@
+package article
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/repository/database/postgres"
+
+	"github.com/lib/pq"
+)
+
+type WBCardUpdateRepository struct {
+	db *postgres.PostgresDB
+}
+
+func NewWBCardUpdateRepository(db *postgres.PostgresDB) *WBCardUpdateRepository {
+	return &WBCardUpdateRepository{db: db}
+}
+
+// draftColumns - поля черновика в порядке сканирования в scanDraft
+const draftColumns = `
+	id, user_id, account_id, nm_id, title, description, vendor_code, characteristics,
+	length, width, height, weight_brutto, status, errors, update_id, created_by, created_at, updated_at`
+
+// scanDraft сканирует строку с полями draftColumns
+func scanDraft(row rowScanner) (*entity.WBCardDraft, error) {
+	var d entity.WBCardDraft
+	var characteristics []byte
+	err := row.Scan(
+		&d.ID,
+		&d.UserID,
+		&d.AccountID,
+		&d.NmID,
+		&d.Title,
+		&d.Description,
+		&d.VendorCode,
+		&characteristics,
+		&d.Length,
+		&d.Width,
+		&d.Height,
+		&d.WeightBrutto,
+		&d.Status,
+		pq.Array(&d.Errors),
+		&d.UpdateID,
+		&d.CreatedBy,
+		&d.CreatedAt,
+		&d.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(characteristics) > 0 {
+		if err := json.Unmarshal(characteristics, &d.Characteristics); err != nil {
+			return nil, fmt.Errorf("failed to parse draft characteristics: %w", err)
+		}
+	}
+	return &d, nil
+}
+
+// GetDraft получает черновик карточки (nil - черновика нет)
+func (r *WBCardUpdateRepository) GetDraft(userID int, nmID int64) (*entity.WBCardDraft, error) {
+	d, err := scanDraft(r.db.QueryRow(`SELECT `+draftColumns+`
+		FROM wb_card_drafts
+		WHERE user_id = $1 AND nm_id = $2
+	`, userID, nmID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get card draft: %w", err)
+	}
+	return d, nil
+}
+
+// ListDrafts получает черновики пользователя (status = "" - все)
+func (r *WBCardUpdateRepository) ListDrafts(userID int, status string) ([]entity.WBCardDraft, error) {
+	return r.queryDrafts(`SELECT `+draftColumns+`
+		FROM wb_card_drafts
+		WHERE user_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY updated_at DESC, id DESC
+	`, userID, status)
+}
+
+// GetUpdateDrafts получает черновики задания отправки
+func (r *WBCardUpdateRepository) GetUpdateDrafts(updateID int) ([]entity.WBCardDraft, error) {
+	return r.queryDrafts(`SELECT `+draftColumns+`
+		FROM wb_card_drafts
+		WHERE update_id = $1
+		ORDER BY id
+	`, updateID)
+}
+
+func (r *WBCardUpdateRepository) queryDrafts(query string, args ...interface{}) ([]entity.WBCardDraft, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query card drafts: %w", err)
+	}
+	defer rows.Close()
+
+	var drafts []entity.WBCardDraft
+	for rows.Next() {
+		d, err := scanDraft(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan card draft: %w", err)
+		}
+		drafts = append(drafts, *d)
+	}
+	return drafts, rows.Err()
+}
+
+// SaveDraft создает или заменяет черновик карточки. Черновик снова становится
+// редактируемым (status = draft) и отвязывается от прежнего задания
+func (r *WBCardUpdateRepository) SaveDraft(d *entity.WBCardDraft) error {
+	var characteristics interface{}
+	if len(d.Characteristics) > 0 {
+		raw, err := json.Marshal(d.Characteristics)
+		if err != nil {
+			return fmt.Errorf("failed to marshal draft characteristics: %w", err)
+		}
+		characteristics = string(raw)
+	}
+
+	d.Status = entity.CardDraftStatusDraft
+	if d.Errors == nil {
+		d.Errors = []string{}
+	}
+
+	err := r.db.QueryRow(`
+		INSERT INTO wb_card_drafts (
+			user_id, account_id, nm_id, title, description, vendor_code, characteristics,
+			length, width, height, weight_brutto, status, errors, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (user_id, nm_id) DO UPDATE
+		SET account_id = EXCLUDED.account_id,
+		    title = EXCLUDED.title,
+		    description = EXCLUDED.description,
+		    vendor_code = EXCLUDED.vendor_code,
+		    characteristics = EXCLUDED.characteristics,
+		    length = EXCLUDED.length,
+		    width = EXCLUDED.width,
+		    height = EXCLUDED.height,
+		    weight_brutto = EXCLUDED.weight_brutto,
+		    status = EXCLUDED.status,
+		    errors = EXCLUDED.errors,
+		    update_id = NULL,
+		    created_by = EXCLUDED.created_by,
+		    updated_at = CURRENT_TIMESTAMP
+		RETURNING id, created_at, updated_at
+	`,
+		d.UserID,
+		d.AccountID,
+		d.NmID,
+		d.Title,
+		d.Description,
+		d.VendorCode,
+		characteristics,
+		d.Length,
+		d.Width,
+		d.Height,
+		d.WeightBrutto,
+		d.Status,
+		pq.Array(d.Errors),
+		d.CreatedBy,
+	).Scan(&d.ID, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save card draft: %w", err)
+	}
+	return nil
+}
+
+// DeleteDraft удаляет черновик, если он еще не отправлен
+func (r *WBCardUpdateRepository) DeleteDraft(userID int, nmID int64) (bool, error) {
+	result, err := r.db.Exec(`
+		DELETE FROM wb_card_drafts
+		WHERE user_id = $1 AND nm_id = $2 AND status NOT IN ($3, $4)
+	`, userID, nmID, entity.CardDraftStatusQueued, entity.CardDraftStatusSent)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete card draft: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// SetDraftResult сохраняет статус черновика и ошибки WB по карточке
+func (r *WBCardUpdateRepository) SetDraftResult(draftID int, status string, errors []string) error {
+	if errors == nil {
+		errors = []string{}
+	}
+	_, err := r.db.Exec(`
+		UPDATE wb_card_drafts
+		SET status = $1, errors = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`, status, pq.Array(errors), draftID)
+	if err != nil {
+		return fmt.Errorf("failed to update card draft: %w", err)
+	}
+	return nil
+}
+
+// CreateUpdate создает задание отправки и переводит в него черновики nmIDs
+// (только редактируемые черновики со статусом draft)
+func (r *WBCardUpdateRepository) CreateUpdate(update *entity.WBCardUpdate, nmIDs []int64) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO wb_card_updates (id_user, account_id, status)
+		VALUES ($1, $2, $3)
+		RETURNING id, created, updated
+	`, update.UserID, update.AccountID, update.Status).Scan(&update.ID, &update.Created, &update.Updated)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create card update: %w", err)
+	}
+
+	result, err := tx.Exec(`
+		UPDATE wb_card_drafts
+		SET status = $1, update_id = $2, errors = '{}', updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $3 AND nm_id = ANY($4) AND status = $5
+	`, entity.CardDraftStatusQueued, update.ID, update.UserID, pq.Array(nmIDs), entity.CardDraftStatusDraft)
+	if err != nil {
+		return 0, fmt.Errorf("failed to queue card drafts: %w", err)
+	}
+	queued, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit card update: %w", err)
+	}
+	return int(queued), nil
+}
+
+// GetPendingUpdates возвращает задания, ожидающие отправки
+func (r *WBCardUpdateRepository) GetPendingUpdates() ([]entity.WBCardUpdate, error) {
+	return r.queryUpdates(`
+		SELECT id, id_user, account_id, status, created, updated, sent_at, last_error
+		FROM wb_card_updates
+		WHERE status = $1
+		ORDER BY created ASC
+	`, entity.ArticlesStatusWait)
+}
+
+// GetSentUpdates возвращает отправленные задания, по которым WB успел обработать карточки
+// (отправлены не позже sentBefore)
+func (r *WBCardUpdateRepository) GetSentUpdates(sentBefore time.Time) ([]entity.WBCardUpdate, error) {
+	return r.queryUpdates(`
+		SELECT id, id_user, account_id, status, created, updated, sent_at, last_error
+		FROM wb_card_updates
+		WHERE status = $1 AND sent_at <= $2
+		ORDER BY sent_at ASC
+	`, entity.CardUpdateStatusSent, sentBefore)
+}
+
+func (r *WBCardUpdateRepository) queryUpdates(query string, args ...interface{}) ([]entity.WBCardUpdate, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get card updates: %w", err)
+	}
+	defer rows.Close()
+
+	var updates []entity.WBCardUpdate
+	for rows.Next() {
+		var u entity.WBCardUpdate
+		if err := rows.Scan(
+			&u.ID,
+			&u.UserID,
+			&u.AccountID,
+			&u.Status,
+			&u.Created,
+			&u.Updated,
+			&u.SentAt,
+			&u.LastError,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan card update: %w", err)
+		}
+		updates = append(updates, u)
+	}
+	return updates, rows.Err()
+}
+
+// MarkSent отмечает задание и его черновики отправленными в WB (sentAt - в UTC)
+func (r *WBCardUpdateRepository) MarkSent(updateID int, sentAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE wb_card_updates SET status = $1, sent_at = $2, updated = CURRENT_TIMESTAMP WHERE id = $3
+	`, entity.CardUpdateStatusSent, sentAt, updateID); err != nil {
+		return fmt.Errorf("failed to update card update: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE wb_card_drafts SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE update_id = $2 AND status = $3
+	`, entity.CardDraftStatusSent, updateID, entity.CardDraftStatusQueued); err != nil {
+		return fmt.Errorf("failed to update card drafts: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// UpdateStatus обновляет статус задания
+func (r *WBCardUpdateRepository) UpdateStatus(updateID int, status int, errorMsg string) error {
+	_, err := r.db.Exec(`
+		UPDATE wb_card_updates
+		SET status = $1, last_error = $2, updated = $3
+		WHERE id = $4
+	`, status, errorMsg, time.Now(), updateID)
+	if err != nil {
+		return fmt.Errorf("failed to update card update status: %w", err)
+	}
+	return nil
+}
+
+// GetSubjectCharacteristics получает сохраненные характеристики предмета
+func (r *WBCardUpdateRepository) GetSubjectCharacteristics(subjectID int) ([]entity.WBSubjectCharacteristic, error) {
+	rows, err := r.db.Query(`
+		SELECT subject_id, charc_id, name, required, COALESCE(unit_name, ''), max_count, charc_type, updated_at
+		FROM wb_subject_characteristics
+		WHERE subject_id = $1
+		ORDER BY required DESC, name
+	`, subjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subject characteristics: %w", err)
+	}
+	defer rows.Close()
+
+	var characteristics []entity.WBSubjectCharacteristic
+	for rows.Next() {
+		var c entity.WBSubjectCharacteristic
+		if err := rows.Scan(&c.SubjectID, &c.CharcID, &c.Name, &c.Required, &c.UnitName, &c.MaxCount, &c.CharcType, &c.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan subject characteristic: %w", err)
+		}
+		characteristics = append(characteristics, c)
+	}
+	return characteristics, rows.Err()
+}
+
+// GetStaleSubjects возвращает предметы из subjectIDs, характеристики которых
+// не загружены или загружены раньше updatedBefore
+func (r *WBCardUpdateRepository) GetStaleSubjects(subjectIDs []int64, updatedBefore time.Time) ([]int64, error) {
+	rows, err := r.db.Query(`
+		SELECT s.id
+		FROM unnest($1::bigint[]) AS s(id)
+		WHERE NOT EXISTS (
+			SELECT 1 FROM wb_subject_characteristics c
+			WHERE c.subject_id = s.id AND c.updated_at >= $2
+		)
+	`, pq.Array(subjectIDs), updatedBefore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stale subjects: %w", err)
+	}
+	defer rows.Close()
+
+	var stale []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan subject: %w", err)
+		}
+		stale = append(stale, id)
+	}
+	return stale, rows.Err()
+}
+
+// SaveSubjectCharacteristics заменяет характеристики предмета
+func (r *WBCardUpdateRepository) SaveSubjectCharacteristics(subjectID int, characteristics []entity.WBSubjectCharacteristic) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM wb_subject_characteristics WHERE subject_id = $1`, subjectID); err != nil {
+		return fmt.Errorf("failed to delete subject characteristics: %w", err)
+	}
+
+	for _, c := range characteristics {
+		_, err := tx.Exec(`
+			INSERT INTO wb_subject_characteristics (subject_id, charc_id, name, required, unit_name, max_count, charc_type)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)
+			ON CONFLICT (subject_id, charc_id) DO NOTHING
+		`, subjectID, c.CharcID, c.Name, c.Required, c.UnitName, c.MaxCount, c.CharcType)
+		if err != nil {
+			return fmt.Errorf("failed to save subject characteristic: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,37 @@
+package article
+
+import (
+	"fmt"
+	"wbrost-go/internal/entity"
+)
+
+// ReplaceStocks заменяет снимок остатков кабинета на складах WB новым
+func (r *WBArticlesRepository) ReplaceStocks(userID int, accountID int, stocks []entity.WBStock) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		DELETE FROM wb_stocks
+		WHERE user_id = $1 AND COALESCE(account_id, 0) = $2
+	`, userID, accountID)
+	if err != nil {
+		return fmt.Errorf("failed to clear stocks: %w", err)
+	}
+
+	for _, stock := range stocks {
+		_, err := tx.Exec(`
+			INSERT INTO wb_stocks (user_id, account_id, nm_id, barcode, tech_size, warehouse_name,
+			                       quantity, in_way_to_client, in_way_from_client, quantity_full)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, userID, stock.AccountID, stock.NmID, stock.Barcode, stock.TechSize, stock.WarehouseName,
+			stock.Quantity, stock.InWayToClient, stock.InWayFromClient, stock.QuantityFull)
+		if err != nil {
+			return fmt.Errorf("failed to save stock of %d: %w", stock.NmID, err)
+		}
+	}
+
+	return tx.Commit()
+}
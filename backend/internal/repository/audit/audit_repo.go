@@ -0,0 +1,128 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/repository/database/postgres"
+)
+
+type AuditRepository struct {
+	db *postgres.PostgresDB
+}
+
+func NewAuditRepository(db *postgres.PostgresDB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Create записывает действие в журнал
+func (r *AuditRepository) Create(entry *entity.AuditLog) error {
+	before, err := marshalState(entry.Before)
+	if err != nil {
+		return err
+	}
+	after, err := marshalState(entry.After)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO audit_log (actor_id, target_user_id, target_type, target_id, action, before, after, ip, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`
+
+	err = r.db.QueryRow(query,
+		entry.ActorID,
+		entry.TargetUserID,
+		entry.TargetType,
+		entry.TargetID,
+		entry.Action,
+		before,
+		after,
+		entry.IP,
+		entry.UserAgent,
+	).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+
+	return nil
+}
+
+// List возвращает записи журнала по фильтрам (новые сначала) и общее количество
+func (r *AuditRepository) List(f entity.AuditFilter) ([]entity.AuditLog, int, error) {
+	where := `
+		WHERE ($1 = 0 OR actor_id = $1)
+		  AND ($2 = 0 OR target_user_id = $2)
+		  AND ($3 = '' OR target_type = $3)
+		  AND ($4 = '' OR action = $4)
+		  AND ($5 = '' OR created_at >= NULLIF($5, '')::date)
+		  AND ($6 = '' OR created_at < NULLIF($6, '')::date + INTERVAL '1 day')
+	`
+	args := []interface{}{f.ActorID, f.TargetUserID, f.TargetType, f.Action, f.DateFrom, f.DateTo}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM audit_log`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit log: %w", err)
+	}
+
+	query := `
+		SELECT id, actor_id, target_user_id, target_type, target_id, action, before, after, ip, user_agent, created_at
+		FROM audit_log` + where + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT $7 OFFSET $8
+	`
+
+	rows, err := r.db.Query(query, append(args, f.PageSize, (f.Page-1)*f.PageSize)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []entity.AuditLog
+	for rows.Next() {
+		var e entity.AuditLog
+		var before, after []byte
+		err := rows.Scan(
+			&e.ID, &e.ActorID, &e.TargetUserID, &e.TargetType, &e.TargetID, &e.Action,
+			&before, &after, &e.IP, &e.UserAgent, &e.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		if e.Before, err = unmarshalState(before); err != nil {
+			return nil, 0, err
+		}
+		if e.After, err = unmarshalState(after); err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, total, rows.Err()
+}
+
+func marshalState(state map[string]interface{}) (interface{}, error) {
+	if state == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit state: %w", err)
+	}
+	return string(data), nil
+}
+
+func unmarshalState(data []byte) (map[string]interface{}, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	var state map[string]interface{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal audit state: %w", err)
+	}
+	return state, nil
+}
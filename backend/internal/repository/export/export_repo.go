@@ -0,0 +1,161 @@
+package export
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"wbrost-go/internal/repository/database/postgres"
+)
+
+// ExportRepository выгружает данные пользователя в CSV построчно, не загружая таблицу в память
+type ExportRepository struct {
+	db *postgres.PostgresDB
+}
+
+func NewExportRepository(db *postgres.PostgresDB) *ExportRepository {
+	return &ExportRepository{db: db}
+}
+
+// WriteArticles - карточки товаров всех маркетплейсов
+func (r *ExportRepository) WriteArticles(w io.Writer, userID int) (int, error) {
+	return r.writeCSV(w, `
+		SELECT id, account_id, marketplace, articule, name, photo, barcode, rus_size, eu_size,
+		       chrt_id, internal_id, nm_uuid, brand, subject_id, subject_name, imt_id, description,
+		       length, width, height, weight_brutto, dimensions_valid,
+		       cost_price, self_ransom, self_ransom_price, created, updated, card_created_at, card_updated_at
+		FROM wb_articles
+		WHERE id_user = $1
+		ORDER BY marketplace, articule, id
+	`, userID)
+}
+
+// WriteArticleSizes - размеры карточек WB с баркодами
+func (r *ExportRepository) WriteArticleSizes(w io.Writer, userID int) (int, error) {
+	return r.writeCSV(w, `
+		SELECT sz.nm_id, sz.chrt_id, sz.tech_size, sz.wb_size, sz.cost_price,
+		       COALESCE(string_agg(b.barcode, ',' ORDER BY b.barcode), '') as barcodes
+		FROM wb_article_sizes sz
+		LEFT JOIN wb_article_barcodes b ON b.size_id = sz.id
+		WHERE sz.user_id = $1
+		GROUP BY sz.id
+		ORDER BY sz.nm_id, sz.chrt_id
+	`, userID)
+}
+
+// WriteArticleCharacteristics - характеристики карточек WB (value - JSON)
+func (r *ExportRepository) WriteArticleCharacteristics(w io.Writer, userID int) (int, error) {
+	return r.writeCSV(w, `
+		SELECT nm_id, characteristic_id, name, value
+		FROM wb_article_characteristics
+		WHERE user_id = $1
+		ORDER BY nm_id, id
+	`, userID)
+}
+
+// WriteArticleChanges - журнал изменений карточек WB
+func (r *ExportRepository) WriteArticleChanges(w io.Writer, userID int) (int, error) {
+	return r.writeCSV(w, `
+		SELECT nm_id, field, old_value, new_value, card_updated_at, detected_at
+		FROM wb_article_changes
+		WHERE user_id = $1
+		ORDER BY detected_at, id
+	`, userID)
+}
+
+// WriteCostPrices - себестоимость товаров (только заполненная)
+func (r *ExportRepository) WriteCostPrices(w io.Writer, userID int) (int, error) {
+	return r.writeCSV(w, `
+		SELECT marketplace, articule, barcode, name, cost_price, self_ransom_price
+		FROM wb_articles
+		WHERE id_user = $1 AND COALESCE(cost_price, 0) <> 0
+		ORDER BY marketplace, articule, id
+	`, userID)
+}
+
+// WriteCostPriceHistory - история себестоимости с датами начала действия
+func (r *ExportRepository) WriteCostPriceHistory(w io.Writer, userID int) (int, error) {
+	return r.writeCSV(w, `
+		SELECT articule, chrt_id, cost_price, valid_from, created_by, created_at
+		FROM cost_price_history
+		WHERE user_id = $1
+		ORDER BY articule, chrt_id, valid_from
+	`, userID)
+}
+
+// WriteTaxProfiles - налоговые профили с датами начала действия
+func (r *ExportRepository) WriteTaxProfiles(w io.Writer, userID int) (int, error) {
+	return r.writeCSV(w, `
+		SELECT regime, rate, base, vat_rate, valid_from, created_by, created_at
+		FROM tax_profiles
+		WHERE user_id = $1
+		ORDER BY valid_from
+	`, userID)
+}
+
+// WriteStats - строки отчетов реализации WB
+func (r *ExportRepository) WriteStats(w io.Writer, userID int) (int, error) {
+	return r.writeCSV(w, `
+		SELECT *
+		FROM wb_stats
+		WHERE user_id = $1
+		ORDER BY sale_dt, id
+	`, userID)
+}
+
+// WriteOperations - нормализованные операции маркетплейсов
+func (r *ExportRepository) WriteOperations(w io.Writer, userID int) (int, error) {
+	return r.writeCSV(w, `
+		SELECT *
+		FROM marketplace_operations
+		WHERE user_id = $1
+		ORDER BY operation_date, id
+	`, userID)
+}
+
+// writeCSV пишет результат запроса в CSV с заголовком из имен колонок.
+// Возвращает количество строк данных
+func (r *ExportRepository) writeCSV(w io.Writer, query string, args ...interface{}) (int, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query export: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return 0, err
+	}
+
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	record := make([]string, len(columns))
+
+	count := 0
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return count, fmt.Errorf("failed to scan export row: %w", err)
+		}
+		for i, v := range values {
+			record[i] = v.String
+		}
+		if err := writer.Write(record); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+
+	writer.Flush()
+	return count, writer.Error()
+}
@@ -0,0 +1,140 @@
+package operation
+
+import (
+	"fmt"
+	"strings"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/repository/database/postgres"
+
+	"github.com/lib/pq"
+)
+
+// operationColumns - колонки marketplace_operations, заполняемые при загрузке
+var operationColumns = []string{
+	"user_id", "account_id", "marketplace", "external_id", "operation_type",
+	"operation_name", "operation_date", "product_id", "offer_id", "product_name",
+	"quantity", "revenue", "payout", "commission", "logistics",
+	"storage", "penalty", "other_charges",
+}
+
+type OperationRepository struct {
+	db *postgres.PostgresDB
+}
+
+func NewOperationRepository(db *postgres.PostgresDB) *OperationRepository {
+	return &OperationRepository{db: db}
+}
+
+// Create сохраняет нормализованную операцию маркетплейса. Повторно загруженные
+// операции (тот же external_id) пропускаются - возвращается false
+func (r *OperationRepository) Create(op *entity.MarketplaceOperation) (bool, error) {
+	query := `
+		INSERT INTO marketplace_operations (
+			user_id, account_id, marketplace, external_id, operation_type,
+			operation_name, operation_date, product_id, offer_id, product_name,
+			quantity, revenue, payout, commission, logistics,
+			storage, penalty, other_charges
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
+			$11, $12, $13, $14, $15, $16, $17, $18
+		)
+		ON CONFLICT (user_id, marketplace, external_id) DO NOTHING
+	`
+
+	res, err := r.db.Exec(query,
+		op.UserID,
+		op.AccountID,
+		op.Marketplace,
+		op.ExternalID,
+		op.OperationType,
+		op.OperationName,
+		op.OperationDate,
+		op.ProductID,
+		op.OfferID,
+		op.ProductName,
+		op.Quantity,
+		op.Revenue,
+		op.Payout,
+		op.Commission,
+		op.Logistics,
+		op.Storage,
+		op.Penalty,
+		op.OtherCharges,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to create marketplace operation: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// CreateBatch сохраняет пачку операций одним COPY во временную таблицу и одним INSERT.
+// Уже загруженные операции (тот же external_id) пропускаются. Возвращает количество новых
+func (r *OperationRepository) CreateBatch(ops []*entity.MarketplaceOperation) (int, error) {
+	if len(ops) == 0 {
+		return 0, nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`CREATE TEMP TABLE marketplace_operations_staging (LIKE marketplace_operations INCLUDING DEFAULTS) ON COMMIT DROP`); err != nil {
+		return 0, fmt.Errorf("failed to create staging table: %w", err)
+	}
+
+	stmt, err := tx.Prepare(pq.CopyIn("marketplace_operations_staging", operationColumns...))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare copy: %w", err)
+	}
+
+	for _, op := range ops {
+		_, err := stmt.Exec(
+			op.UserID, op.AccountID, op.Marketplace, op.ExternalID, op.OperationType,
+			op.OperationName, op.OperationDate, op.ProductID, op.OfferID, op.ProductName,
+			op.Quantity, op.Revenue, op.Payout, op.Commission, op.Logistics,
+			op.Storage, op.Penalty, op.OtherCharges,
+		)
+		if err != nil {
+			stmt.Close()
+			return 0, fmt.Errorf("failed to copy marketplace operation: %w", err)
+		}
+	}
+
+	if _, err := stmt.Exec(); err != nil {
+		stmt.Close()
+		return 0, fmt.Errorf("failed to flush copy: %w", err)
+	}
+	if err := stmt.Close(); err != nil {
+		return 0, fmt.Errorf("failed to close copy: %w", err)
+	}
+
+	columns := strings.Join(operationColumns, ", ")
+	result, err := tx.Exec(`
+		INSERT INTO marketplace_operations (` + columns + `)
+		SELECT ` + columns + `
+		FROM marketplace_operations_staging
+		ON CONFLICT (user_id, marketplace, external_id) DO NOTHING
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert marketplace operations: %w", err)
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit marketplace operations: %w", err)
+	}
+
+	return int(inserted), nil
+}
@@ -0,0 +1,300 @@
+package organization
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/repository/database/postgres"
+)
+
+type OrganizationRepository struct {
+	db *postgres.PostgresDB
+}
+
+func NewOrganizationRepository(db *postgres.PostgresDB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+const (
+	organizationColumns = `id, name, owner_id, del, created_at, updated_at`
+	memberColumns       = `id, organization_id, id_user, role, created_at, updated_at`
+	inviteColumns       = `id, organization_id, email, username, role, invited_by, status, created_at, updated_at`
+)
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanOrganization(row rowScanner, o *entity.Organization) error {
+	return row.Scan(&o.ID, &o.Name, &o.OwnerID, &o.Del, &o.CreatedAt, &o.UpdatedAt)
+}
+
+func scanMember(row rowScanner, m *entity.OrganizationMember) error {
+	return row.Scan(&m.ID, &m.OrganizationID, &m.UserID, &m.Role, &m.CreatedAt, &m.UpdatedAt)
+}
+
+func scanInvite(row rowScanner, i *entity.OrganizationInvite) error {
+	return row.Scan(&i.ID, &i.OrganizationID, &i.Email, &i.Username, &i.Role, &i.InvitedBy, &i.Status, &i.CreatedAt, &i.UpdatedAt)
+}
+
+// CreateWithOwner создает организацию и добавляет владельца участником с ролью owner
+func (r *OrganizationRepository) CreateWithOwner(o *entity.Organization) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		`INSERT INTO organizations (name, owner_id) VALUES ($1, $2) RETURNING id, created_at, updated_at`,
+		o.Name, o.OwnerID,
+	).Scan(&o.ID, &o.CreatedAt, &o.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO organization_members (organization_id, id_user, role) VALUES ($1, $2, $3)`,
+		o.ID, o.OwnerID, entity.RoleOwner,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add organization owner: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetByID получает организацию по ID
+func (r *OrganizationRepository) GetByID(id int) (*entity.Organization, error) {
+	query := `SELECT ` + organizationColumns + ` FROM organizations WHERE id = $1 AND del = 0`
+
+	var o entity.Organization
+	if err := scanOrganization(r.db.QueryRow(query, id), &o); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("organization not found")
+		}
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	return &o, nil
+}
+
+// GetByOwnerID получает собственную организацию пользователя (nil, если ее еще нет)
+func (r *OrganizationRepository) GetByOwnerID(userID int) (*entity.Organization, error) {
+	query := `SELECT ` + organizationColumns + ` FROM organizations WHERE owner_id = $1 AND del = 0`
+
+	var o entity.Organization
+	if err := scanOrganization(r.db.QueryRow(query, userID), &o); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	return &o, nil
+}
+
+// UpdateName переименовывает организацию
+func (r *OrganizationRepository) UpdateName(id int, name string) error {
+	_, err := r.db.Exec(
+		"UPDATE organizations SET name = $1, updated_at = $2 WHERE id = $3",
+		name, time.Now(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update organization: %w", err)
+	}
+	return nil
+}
+
+// GetMember получает участника организации (nil, если пользователь не состоит в организации)
+func (r *OrganizationRepository) GetMember(organizationID, userID int) (*entity.OrganizationMember, error) {
+	query := `SELECT ` + memberColumns + ` FROM organization_members WHERE organization_id = $1 AND id_user = $2`
+
+	var m entity.OrganizationMember
+	if err := scanMember(r.db.QueryRow(query, organizationID, userID), &m); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get organization member: %w", err)
+	}
+
+	return &m, nil
+}
+
+// GetMembers получает участников организации
+func (r *OrganizationRepository) GetMembers(organizationID int) ([]entity.OrganizationMember, error) {
+	query := `
+		SELECT ` + memberColumns + `
+		FROM organization_members
+		WHERE organization_id = $1
+		ORDER BY created_at ASC
+	`
+
+	return r.queryMembers(query, organizationID)
+}
+
+// GetMembershipsByUserID получает все членства пользователя в организациях
+func (r *OrganizationRepository) GetMembershipsByUserID(userID int) ([]entity.OrganizationMember, error) {
+	query := `
+		SELECT m.id, m.organization_id, m.id_user, m.role, m.created_at, m.updated_at
+		FROM organization_members m
+		JOIN organizations o ON o.id = m.organization_id AND o.del = 0
+		WHERE m.id_user = $1
+		ORDER BY (m.role = 'owner') DESC, m.created_at ASC
+	`
+
+	return r.queryMembers(query, userID)
+}
+
+func (r *OrganizationRepository) queryMembers(query string, args ...interface{}) ([]entity.OrganizationMember, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []entity.OrganizationMember
+	for rows.Next() {
+		var m entity.OrganizationMember
+		if err := scanMember(rows, &m); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	return members, nil
+}
+
+// AddMember добавляет участника в организацию
+func (r *OrganizationRepository) AddMember(m *entity.OrganizationMember) error {
+	query := `
+		INSERT INTO organization_members (organization_id, id_user, role)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(query, m.OrganizationID, m.UserID, m.Role).Scan(&m.ID, &m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add organization member: %w", err)
+	}
+	return nil
+}
+
+// UpdateMemberRole меняет роль участника
+func (r *OrganizationRepository) UpdateMemberRole(organizationID, userID int, role string) error {
+	_, err := r.db.Exec(
+		"UPDATE organization_members SET role = $1, updated_at = $2 WHERE organization_id = $3 AND id_user = $4",
+		role, time.Now(), organizationID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update member role: %w", err)
+	}
+	return nil
+}
+
+// RemoveMember исключает участника из организации
+func (r *OrganizationRepository) RemoveMember(organizationID, userID int) error {
+	_, err := r.db.Exec(
+		"DELETE FROM organization_members WHERE organization_id = $1 AND id_user = $2",
+		organizationID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+	return nil
+}
+
+// CreateInvite создает приглашение
+func (r *OrganizationRepository) CreateInvite(i *entity.OrganizationInvite) error {
+	query := `
+		INSERT INTO organization_invites (organization_id, email, username, role, invited_by, status)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(query,
+		i.OrganizationID,
+		i.Email,
+		i.Username,
+		i.Role,
+		i.InvitedBy,
+		i.Status,
+	).Scan(&i.ID, &i.CreatedAt, &i.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create invite: %w", err)
+	}
+	return nil
+}
+
+// GetInviteByID получает приглашение по ID
+func (r *OrganizationRepository) GetInviteByID(id int) (*entity.OrganizationInvite, error) {
+	query := `SELECT ` + inviteColumns + ` FROM organization_invites WHERE id = $1`
+
+	var i entity.OrganizationInvite
+	if err := scanInvite(r.db.QueryRow(query, id), &i); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("invite not found")
+		}
+		return nil, fmt.Errorf("failed to get invite: %w", err)
+	}
+
+	return &i, nil
+}
+
+// GetPendingInvitesByOrganization получает ожидающие приглашения организации
+func (r *OrganizationRepository) GetPendingInvitesByOrganization(organizationID int) ([]entity.OrganizationInvite, error) {
+	query := `
+		SELECT ` + inviteColumns + `
+		FROM organization_invites
+		WHERE organization_id = $1 AND status = $2
+		ORDER BY created_at DESC
+	`
+
+	return r.queryInvites(query, organizationID, entity.InviteStatusPending)
+}
+
+// GetPendingInvitesFor получает ожидающие приглашения пользователя по email или username
+func (r *OrganizationRepository) GetPendingInvitesFor(email, username string) ([]entity.OrganizationInvite, error) {
+	query := `
+		SELECT ` + inviteColumns + `
+		FROM organization_invites
+		WHERE status = $1
+		  AND ((email IS NOT NULL AND LOWER(email) = $2) OR (username IS NOT NULL AND LOWER(username) = $3))
+		ORDER BY created_at DESC
+	`
+
+	return r.queryInvites(query, entity.InviteStatusPending, strings.ToLower(email), strings.ToLower(username))
+}
+
+func (r *OrganizationRepository) queryInvites(query string, args ...interface{}) ([]entity.OrganizationInvite, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invites []entity.OrganizationInvite
+	for rows.Next() {
+		var i entity.OrganizationInvite
+		if err := scanInvite(rows, &i); err != nil {
+			return nil, err
+		}
+		invites = append(invites, i)
+	}
+
+	return invites, nil
+}
+
+// UpdateInviteStatus меняет статус приглашения
+func (r *OrganizationRepository) UpdateInviteStatus(id, status int) error {
+	_, err := r.db.Exec(
+		"UPDATE organization_invites SET status = $1, updated_at = $2 WHERE id = $3",
+		status, time.Now(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update invite status: %w", err)
+	}
+	return nil
+}
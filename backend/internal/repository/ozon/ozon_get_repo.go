@@ -0,0 +1,108 @@
+package ozon
+
+import (
+	"fmt"
+	"time"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/repository/database/postgres"
+)
+
+type OzonGetRepository struct {
+	db *postgres.PostgresDB
+}
+
+func NewOzonGetRepository(db *postgres.PostgresDB) *OzonGetRepository {
+	return &OzonGetRepository{db: db}
+}
+
+// Create создает новое задание на загрузку данных Ozon
+func (r *OzonGetRepository) Create(job *entity.OzonGet) error {
+	query := `
+		INSERT INTO ozon_get (id_user, type, status, date_from, date_to, last_error)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created, updated
+	`
+
+	return r.db.QueryRow(query,
+		job.UserID,
+		job.Type,
+		job.Status,
+		job.DateFrom,
+		job.DateTo,
+		job.LastError,
+	).Scan(&job.ID, &job.Created, &job.Updated)
+}
+
+// GetByUserID получает задания пользователя
+func (r *OzonGetRepository) GetByUserID(userID int) ([]entity.OzonGet, error) {
+	query := `
+		SELECT id, id_user, type, status, date_from, date_to, created, updated, last_error
+		FROM ozon_get
+		WHERE id_user = $1
+		ORDER BY created DESC
+	`
+
+	return r.query(query, userID)
+}
+
+// GetPendingJobs возвращает задания со статусом 0 (в обработке)
+func (r *OzonGetRepository) GetPendingJobs() ([]entity.OzonGet, error) {
+	query := `
+		SELECT id, id_user, type, status, date_from, date_to, created, updated, last_error
+		FROM ozon_get
+		WHERE status = $1
+		ORDER BY created ASC
+	`
+
+	jobs, err := r.query(query, entity.StatusWait)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending ozon jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+func (r *OzonGetRepository) query(query string, args ...interface{}) ([]entity.OzonGet, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []entity.OzonGet
+	for rows.Next() {
+		var j entity.OzonGet
+		err := rows.Scan(
+			&j.ID,
+			&j.UserID,
+			&j.Type,
+			&j.Status,
+			&j.DateFrom,
+			&j.DateTo,
+			&j.Created,
+			&j.Updated,
+			&j.LastError,
+		)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+
+	return jobs, nil
+}
+
+// UpdateStatus обновляет статус задания
+func (r *OzonGetRepository) UpdateStatus(jobID int, status int, errorMsg string) error {
+	query := `
+		UPDATE ozon_get
+		SET status = $1, last_error = $2, updated = $3
+		WHERE id = $4
+	`
+
+	_, err := r.db.Exec(query, status, errorMsg, time.Now(), jobID)
+	if err != nil {
+		return fmt.Errorf("failed to update ozon job status: %w", err)
+	}
+
+	return nil
+}
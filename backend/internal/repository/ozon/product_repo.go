@@ -0,0 +1,105 @@
+package ozon
+
+import (
+	"fmt"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/repository/database/postgres"
+)
+
+type ProductRepository struct {
+	db *postgres.PostgresDB
+}
+
+func NewProductRepository(db *postgres.PostgresDB) *ProductRepository {
+	return &ProductRepository{db: db}
+}
+
+// CreateOrUpdate создает товар или обновляет существующий (по product_id).
+// Себестоимость, заданная пользователем, не перезаписывается
+func (r *ProductRepository) CreateOrUpdate(p *entity.OzonProduct) error {
+	query := `
+		INSERT INTO ozon_products (user_id, product_id, offer_id, sku, name, barcode, photo, price, archived)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id, product_id) DO UPDATE SET
+			offer_id = EXCLUDED.offer_id,
+			sku = EXCLUDED.sku,
+			name = EXCLUDED.name,
+			barcode = EXCLUDED.barcode,
+			photo = EXCLUDED.photo,
+			price = EXCLUDED.price,
+			archived = EXCLUDED.archived,
+			updated = CURRENT_TIMESTAMP
+	`
+
+	_, err := r.db.Exec(query,
+		p.UserID,
+		p.ProductID,
+		p.OfferID,
+		p.Sku,
+		p.Name,
+		p.Barcode,
+		p.Photo,
+		p.Price,
+		p.Archived,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save ozon product: %w", err)
+	}
+
+	return nil
+}
+
+// GetByUserID получает товары пользователя с пагинацией
+func (r *ProductRepository) GetByUserID(userID, page, pageSize int) ([]entity.OzonProduct, error) {
+	offset := (page - 1) * pageSize
+	query := `
+		SELECT id, user_id, product_id, offer_id, sku, name, barcode, photo, price, archived, cost_price, created, updated
+		FROM ozon_products
+		WHERE user_id = $1
+		ORDER BY archived ASC, name ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Query(query, userID, pageSize, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ozon products: %w", err)
+	}
+	defer rows.Close()
+
+	var products []entity.OzonProduct
+	for rows.Next() {
+		var p entity.OzonProduct
+		err := rows.Scan(
+			&p.ID,
+			&p.UserID,
+			&p.ProductID,
+			&p.OfferID,
+			&p.Sku,
+			&p.Name,
+			&p.Barcode,
+			&p.Photo,
+			&p.Price,
+			&p.Archived,
+			&p.CostPrice,
+			&p.Created,
+			&p.Updated,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ozon product: %w", err)
+		}
+		products = append(products, p)
+	}
+
+	return products, nil
+}
+
+// GetCountByUserID получает количество товаров пользователя (для пагинации)
+func (r *ProductRepository) GetCountByUserID(userID int) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM ozon_products WHERE user_id = $1`, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get ozon products count: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,219 @@
+package ozon
+
+import (
+	"database/sql"
+	"fmt"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/repository/database/postgres"
+)
+
+type TransactionRepository struct {
+	db *postgres.PostgresDB
+}
+
+func NewTransactionRepository(db *postgres.PostgresDB) *TransactionRepository {
+	return &TransactionRepository{db: db}
+}
+
+// Create сохраняет финансовую операцию. Повторно загруженные операции
+// (тот же operation_id) пропускаются - возвращается false
+func (r *TransactionRepository) Create(t *entity.OzonTransaction) (bool, error) {
+	query := `
+		INSERT INTO ozon_transactions (
+			user_id, operation_id, operation_type, operation_type_name, operation_date,
+			type, posting_number, delivery_schema, order_date, warehouse_id,
+			sku, item_name, items_count, accruals_for_sale, sale_commission,
+			delivery_charge, return_delivery_charge, services_amount, amount, items,
+			services
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
+			$11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
+			$21
+		)
+		ON CONFLICT (user_id, operation_id) DO NOTHING
+	`
+
+	res, err := r.db.Exec(query,
+		t.UserID,
+		t.OperationID,
+		t.OperationType,
+		t.OperationTypeName,
+		t.OperationDate,
+		t.Type,
+		t.PostingNumber,
+		t.DeliverySchema,
+		t.OrderDate,
+		t.WarehouseID,
+		t.Sku,
+		t.ItemName,
+		t.ItemsCount,
+		t.AccrualsForSale,
+		t.SaleCommission,
+		t.DeliveryCharge,
+		t.ReturnDeliveryCharge,
+		t.ServicesAmount,
+		t.Amount,
+		t.Items,
+		t.Services,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to create ozon transaction: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// GetSummary получает итоги по финансовым операциям за период
+func (r *TransactionRepository) GetSummary(userID int, dateFrom, dateTo string) (map[string]interface{}, error) {
+	query := `
+		SELECT
+			COALESCE(SUM(t.accruals_for_sale), 0) as accruals_for_sale,
+			COALESCE(SUM(t.sale_commission), 0) as sale_commission,
+			COALESCE(SUM(t.delivery_charge), 0) as delivery_charge,
+			COALESCE(SUM(t.return_delivery_charge), 0) as return_delivery_charge,
+			COALESCE(SUM(t.services_amount), 0) as services_amount,
+			COALESCE(SUM(t.amount), 0) as amount,
+			COALESCE(SUM(CASE WHEN t.type = 'orders' THEN t.items_count ELSE 0 END), 0) as sales_count,
+			COALESCE(SUM(CASE WHEN t.type = 'returns' THEN t.items_count ELSE 0 END), 0) as returns_count,
+			COUNT(*) as operations_count
+		FROM ozon_transactions t
+		WHERE t.user_id = $1
+			AND t.operation_date BETWEEN $2 AND $3
+	`
+
+	dateToWithTime := dateTo + " 23:59:59"
+
+	var accruals, commission, delivery, returnDelivery, services, amount float64
+	var salesCount, returnsCount, operationsCount int
+
+	err := r.db.QueryRow(query, userID, dateFrom, dateToWithTime).Scan(
+		&accruals,
+		&commission,
+		&delivery,
+		&returnDelivery,
+		&services,
+		&amount,
+		&salesCount,
+		&returnsCount,
+		&operationsCount,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ozon summary: %w", err)
+	}
+
+	return map[string]interface{}{
+		"accruals_for_sale":      accruals,
+		"sale_commission":        commission,
+		"delivery_charge":        delivery,
+		"return_delivery_charge": returnDelivery,
+		"services_amount":        services,
+		"amount":                 amount,
+		"sales_count":            salesCount,
+		"returns_count":          returnsCount,
+		"operations_count":       operationsCount,
+	}, nil
+}
+
+// GetBySku получает итоги по товарам (SKU) за период с пагинацией
+func (r *TransactionRepository) GetBySku(userID int, dateFrom, dateTo string, page, pageSize int) ([]map[string]interface{}, error) {
+	offset := (page - 1) * pageSize
+	query := `
+		SELECT
+			t.sku,
+			COALESCE(MAX(p.name), MAX(t.item_name), 'Нет названия') as name,
+			MAX(p.photo) as photo,
+			MAX(p.offer_id) as offer_id,
+			SUM(t.accruals_for_sale) as accruals_for_sale,
+			SUM(t.sale_commission) as sale_commission,
+			SUM(t.delivery_charge) as delivery_charge,
+			SUM(t.return_delivery_charge) as return_delivery_charge,
+			SUM(t.services_amount) as services_amount,
+			SUM(t.amount) as amount,
+			SUM(CASE WHEN t.type = 'orders' THEN t.items_count ELSE 0 END) as sales_count,
+			SUM(CASE WHEN t.type = 'returns' THEN t.items_count ELSE 0 END) as returns_count
+		FROM ozon_transactions t
+		LEFT JOIN ozon_products p ON p.user_id = t.user_id AND p.sku = t.sku
+		WHERE t.user_id = $1
+			AND t.operation_date BETWEEN $2 AND $3
+			AND t.sku IS NOT NULL
+		GROUP BY t.sku
+		ORDER BY amount DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	dateToWithTime := dateTo + " 23:59:59"
+
+	rows, err := r.db.Query(query, userID, dateFrom, dateToWithTime, pageSize, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ozon stats by sku: %w", err)
+	}
+	defer rows.Close()
+
+	results := []map[string]interface{}{}
+	for rows.Next() {
+		var sku int64
+		var name string
+		var photo, offerID sql.NullString
+		var accruals, commission, delivery, returnDelivery, services, amount float64
+		var salesCount, returnsCount int
+
+		err := rows.Scan(
+			&sku,
+			&name,
+			&photo,
+			&offerID,
+			&accruals,
+			&commission,
+			&delivery,
+			&returnDelivery,
+			&services,
+			&amount,
+			&salesCount,
+			&returnsCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ozon stats: %w", err)
+		}
+
+		results = append(results, map[string]interface{}{
+			"sku":                    sku,
+			"name":                   name,
+			"photo":                  photo.String,
+			"offer_id":               offerID.String,
+			"accruals_for_sale":      accruals,
+			"sale_commission":        commission,
+			"delivery_charge":        delivery,
+			"return_delivery_charge": returnDelivery,
+			"services_amount":        services,
+			"amount":                 amount,
+			"sales_count":            salesCount,
+			"returns_count":          returnsCount,
+		})
+	}
+
+	return results, nil
+}
+
+// GetSkuCount получает количество товаров с операциями за период (для пагинации)
+func (r *TransactionRepository) GetSkuCount(userID int, dateFrom, dateTo string) (int, error) {
+	query := `
+		SELECT COUNT(DISTINCT t.sku)
+		FROM ozon_transactions t
+		WHERE t.user_id = $1
+			AND t.operation_date BETWEEN $2 AND $3
+			AND t.sku IS NOT NULL
+	`
+
+	var count int
+	err := r.db.QueryRow(query, userID, dateFrom, dateTo+" 23:59:59").Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get ozon sku count: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,454 @@
+package stat
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Показатели ABC-анализа
+const (
+	ABCMetricRevenue = "revenue" // Выручка (продажи за вычетом возвратов по цене для покупателя)
+	ABCMetricProfit  = "profit"  // Чистая прибыль после себестоимости и налога
+	ABCMetricUnits   = "units"   // Проданные единицы за вычетом возвратов
+)
+
+// Интервалы XYZ-анализа: вариативность продаж считается по неделям или месяцам периода
+const (
+	XYZIntervalWeek  = "week"
+	XYZIntervalMonth = "month"
+)
+
+// ABCXYZOptions - параметры классификации. ABC - границы накопленной доли показателя для классов
+// A и B, %; XYZ - границы коэффициента вариации продаж для классов X и Y, %
+type ABCXYZOptions struct {
+	Metric   string
+	Interval string
+	ABC      [2]float64
+	XYZ      [2]float64
+}
+
+// abcProduct - показатели товара за период для классификации
+type abcProduct struct {
+	NmID    int64
+	Name    string
+	Revenue float64
+	Profit  float64
+	Units   int
+	Buckets map[string]int // Проданные единицы по интервалам (неделя или месяц)
+	Value   float64        // Значение выбранного показателя
+	Share   float64        // Доля в сумме показателя, %
+	Cum     float64        // Накопленная доля, %
+	CV      *float64       // Коэффициент вариации продаж, %
+	ABC     string
+	XYZ     string
+}
+
+// GetABCXYZ - ABC-анализ товаров по доле показателя и XYZ-анализ по вариативности продаж
+// за период с матрицей классов и сравнением с предыдущим периодом такой же длины
+func (r *AnalyticsRepository) GetABCXYZ(userID, accountID int, dateFrom, dateTo string, opts ABCXYZOptions) (map[string]interface{}, error) {
+	from, err := time.Parse("2006-01-02", dateFrom)
+	if err != nil {
+		return nil, err
+	}
+	to, err := time.Parse("2006-01-02", dateTo)
+	if err != nil {
+		return nil, err
+	}
+
+	// Интервал подставляется в date_trunc
+	if opts.Interval != XYZIntervalMonth {
+		opts.Interval = XYZIntervalWeek
+	}
+
+	// Предыдущий период той же длины, заканчивается накануне dateFrom
+	days := int(to.Sub(from).Hours()/24) + 1
+	prevTo := from.AddDate(0, 0, -1)
+	prevFrom := prevTo.AddDate(0, 0, -(days - 1))
+
+	current, err := r.classifyProducts(userID, accountID, from, to, opts)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := r.classifyProducts(userID, accountID, prevFrom, prevTo, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	previousByID := make(map[int64]*abcProduct, len(previous))
+	for _, p := range previous {
+		previousByID[p.NmID] = p
+	}
+
+	nmIDs := make([]int64, 0, len(current)+len(previous))
+	for _, p := range current {
+		nmIDs = append(nmIDs, p.NmID)
+	}
+	for _, p := range previous {
+		nmIDs = append(nmIDs, p.NmID)
+	}
+	names, photos, err := r.articleNames(userID, nmIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Матрица классов: количество товаров и доля показателя
+	matrix := make(map[string]map[string]interface{})
+	for _, abc := range []string{"A", "B", "C"} {
+		for _, xyz := range []string{"X", "Y", "Z"} {
+			matrix[abc+xyz] = map[string]interface{}{"count": 0, "share": 0.0}
+		}
+	}
+
+	total := 0.0
+	data := make([]map[string]interface{}, 0, len(current))
+	seen := make(map[int64]bool, len(current))
+
+	for _, p := range current {
+		seen[p.NmID] = true
+		total += p.Value
+
+		cell := matrix[p.ABC+p.XYZ]
+		cell["count"] = cell["count"].(int) + 1
+		cell["share"] = cell["share"].(float64) + p.Share
+
+		item := abcItem(p, names, photos)
+		prev := previousByID[p.NmID]
+		item["trend"] = abcTrend(p, prev)
+		if prev != nil {
+			item["previous"] = abcPrevious(prev)
+			if prev.Value != 0 {
+				item["value_change"] = (p.Value - prev.Value) / math.Abs(prev.Value) * 100
+			}
+		}
+		data = append(data, item)
+	}
+
+	// Товары, которые продавались только в предыдущем периоде
+	previousTotal := 0.0
+	for _, prev := range previous {
+		previousTotal += prev.Value
+		if seen[prev.NmID] {
+			continue
+		}
+		data = append(data, map[string]interface{}{
+			"nm_id":    prev.NmID,
+			"name":     abcName(prev, names),
+			"photo":    photos[prev.NmID],
+			"value":    0.0,
+			"abc":      "",
+			"xyz":      "",
+			"trend":    "gone",
+			"previous": abcPrevious(prev),
+		})
+	}
+
+	return map[string]interface{}{
+		"metric":   opts.Metric,
+		"interval": opts.Interval,
+		"thresholds": map[string]interface{}{
+			"abc": opts.ABC,
+			"xyz": opts.XYZ,
+		},
+		"period":          map[string]interface{}{"date_from": dateFrom, "date_to": dateTo},
+		"previous_period": map[string]interface{}{"date_from": prevFrom.Format("2006-01-02"), "date_to": prevTo.Format("2006-01-02")},
+		"matrix":          matrix,
+		"data":            data,
+		"summary": map[string]interface{}{
+			"products":       len(current),
+			"total":          total,
+			"previous_total": previousTotal,
+		},
+	}, nil
+}
+
+// classifyProducts - показатели товаров за период и их классы ABC и XYZ
+// (товары отсортированы по убыванию показателя)
+func (r *AnalyticsRepository) classifyProducts(userID, accountID int, from, to time.Time, opts ABCXYZOptions) ([]*abcProduct, error) {
+	dateFrom, dateTo := from.Format("2006-01-02"), to.Format("2006-01-02")
+
+	query := `
+        SELECT
+            s.nm_id,
+            date_trunc('` + opts.Interval + `', s.sale_dt)::date as bucket,
+            COALESCE(MAX(s.subject_name), '') as subject_name,
+            SUM(
+                CASE
+                    WHEN s.supplier_oper_name IN (1, 7) THEN COALESCE(s.retail_amount, 0)
+                    WHEN s.supplier_oper_name = 2 THEN -COALESCE(s.retail_amount, 0)
+                    ELSE 0
+                END
+            ) as revenue,
+            SUM(
+                CASE
+                    WHEN s.supplier_oper_name = 2 THEN -COALESCE(s.ppvz_for_pay, 0)
+                    ELSE COALESCE(s.ppvz_for_pay, 0)
+                END
+                - COALESCE(s.delivery_rub, 0) - COALESCE(s.rebill_logistic_cost, 0) - COALESCE(s.storage_fee, 0)
+                - COALESCE(s.penalty, 0) - COALESCE(s.deduction, 0)
+            ) -` + costPriceSum + ` as profit,
+            SUM(
+                CASE
+                    WHEN s.supplier_oper_name IN (1, 7) THEN COALESCE(s.quantity, 0)
+                    WHEN s.supplier_oper_name = 2 THEN -COALESCE(s.return_amount, 0)
+                    ELSE 0
+                END
+            ) as units
+        FROM wb_stats s` + costPriceJoin + `
+        WHERE s.user_id = $1
+            AND s.sale_dt BETWEEN $2 AND $3
+            AND ($4 = 0 OR s.account_id = $4)
+            AND s.nm_id IS NOT NULL
+            AND s.nm_id != 0
+        GROUP BY s.nm_id, bucket
+    `
+
+	rows, err := r.db.Query(query, userID, dateFrom, dateTo+" 23:59:59", accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query abc analysis: %w", err)
+	}
+	defer rows.Close()
+
+	products := make(map[int64]*abcProduct)
+	for rows.Next() {
+		var nmID int64
+		var bucket time.Time
+		var subjectName string
+		var revenue, profit float64
+		var units int
+		if err := rows.Scan(&nmID, &bucket, &subjectName, &revenue, &profit, &units); err != nil {
+			return nil, fmt.Errorf("failed to scan abc analysis: %w", err)
+		}
+
+		p := products[nmID]
+		if p == nil {
+			p = &abcProduct{NmID: nmID, Name: subjectName, Buckets: make(map[string]int)}
+			products[nmID] = p
+		}
+		p.Revenue += revenue
+		p.Profit += profit
+		p.Units += units
+		p.Buckets[bucket.Format("2006-01-02")] += units
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Прибыль - после налога по налоговому профилю
+	if opts.Metric == ABCMetricProfit && len(products) > 0 {
+		calculator, err := newTaxCalculator(r.db, r.userRepo, userID)
+		if err != nil {
+			return nil, err
+		}
+		taxes, err := calculator.wbTaxes(userID, accountID, dateFrom, dateTo, "s.nm_id", true)
+		if err != nil {
+			return nil, err
+		}
+		for nmID, p := range products {
+			p.Profit -= taxes[strconv.FormatInt(nmID, 10)].Total()
+		}
+	}
+
+	result := make([]*abcProduct, 0, len(products))
+	positiveTotal := 0.0
+	for _, p := range products {
+		switch opts.Metric {
+		case ABCMetricProfit:
+			p.Value = p.Profit
+		case ABCMetricUnits:
+			p.Value = float64(p.Units)
+		default:
+			p.Value = p.Revenue
+		}
+		if p.Value > 0 {
+			positiveTotal += p.Value
+		}
+		result = append(result, p)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Value != result[j].Value {
+			return result[i].Value > result[j].Value
+		}
+		return result[i].NmID < result[j].NmID
+	})
+
+	buckets := xyzBuckets(from, to, opts.Interval)
+
+	// ABC: товар относится к классу по накопленной доле до него включительно.
+	// Товары с нулевым и отрицательным показателем - всегда C
+	cumulative := 0.0
+	for _, p := range result {
+		if positiveTotal > 0 {
+			p.Share = p.Value / positiveTotal * 100
+		}
+
+		p.ABC = "C"
+		if p.Value > 0 {
+			prevCumulative := cumulative
+			cumulative += p.Share
+			switch {
+			case prevCumulative < opts.ABC[0]:
+				p.ABC = "A"
+			case prevCumulative < opts.ABC[1]:
+				p.ABC = "B"
+			}
+		}
+		p.Cum = cumulative
+
+		p.CV = variation(p.Buckets, buckets)
+		p.XYZ = "Z"
+		if p.CV != nil {
+			switch {
+			case *p.CV <= opts.XYZ[0]:
+				p.XYZ = "X"
+			case *p.CV <= opts.XYZ[1]:
+				p.XYZ = "Y"
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// xyzBuckets - начала интервалов периода (как date_trunc в PostgreSQL: неделя с понедельника)
+func xyzBuckets(from, to time.Time, interval string) []string {
+	var start time.Time
+	step := func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+
+	if interval == XYZIntervalMonth {
+		start = time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+		step = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	} else {
+		offset := (int(from.Weekday()) + 6) % 7
+		start = time.Date(from.Year(), from.Month(), from.Day()-offset, 0, 0, 0, 0, time.UTC)
+	}
+
+	var buckets []string
+	for t := start; !t.After(to); t = step(t) {
+		buckets = append(buckets, t.Format("2006-01-02"))
+	}
+	return buckets
+}
+
+// variation - коэффициент вариации продаж по интервалам, % (интервалы без продаж - нули).
+// Меньше двух интервалов или нет продаж - nil
+func variation(values map[string]int, buckets []string) *float64 {
+	if len(buckets) < 2 {
+		return nil
+	}
+
+	mean := 0.0
+	for _, bucket := range buckets {
+		mean += float64(values[bucket])
+	}
+	mean /= float64(len(buckets))
+	if mean <= 0 {
+		return nil
+	}
+
+	variance := 0.0
+	for _, bucket := range buckets {
+		d := float64(values[bucket]) - mean
+		variance += d * d
+	}
+	variance /= float64(len(buckets))
+
+	cv := math.Sqrt(variance) / mean * 100
+	return &cv
+}
+
+// abcTrend - изменение класса ABC относительно предыдущего периода: up, down, same или new
+func abcTrend(current, previous *abcProduct) string {
+	if previous == nil {
+		return "new"
+	}
+	switch {
+	case current.ABC < previous.ABC:
+		return "up"
+	case current.ABC > previous.ABC:
+		return "down"
+	}
+	return "same"
+}
+
+// abcItem - товар в ответе
+func abcItem(p *abcProduct, names, photos map[int64]string) map[string]interface{} {
+	var cv interface{}
+	if p.CV != nil {
+		cv = *p.CV
+	}
+
+	return map[string]interface{}{
+		"nm_id":            p.NmID,
+		"name":             abcName(p, names),
+		"photo":            photos[p.NmID],
+		"value":            p.Value,
+		"share":            p.Share,
+		"cumulative_share": p.Cum,
+		"revenue":          p.Revenue,
+		"profit":           p.Profit,
+		"units":            p.Units,
+		"cv":               cv,
+		"abc":              p.ABC,
+		"xyz":              p.XYZ,
+		"class":            p.ABC + p.XYZ,
+	}
+}
+
+// abcPrevious - показатели товара за предыдущий период
+func abcPrevious(p *abcProduct) map[string]interface{} {
+	return map[string]interface{}{
+		"value": p.Value,
+		"share": p.Share,
+		"abc":   p.ABC,
+		"xyz":   p.XYZ,
+		"class": p.ABC + p.XYZ,
+	}
+}
+
+// abcName - название карточки, без карточки - предмет из отчета
+func abcName(p *abcProduct, names map[int64]string) string {
+	if name := names[p.NmID]; name != "" {
+		return name
+	}
+	if p.Name != "" {
+		return p.Name
+	}
+	return "Нет названия"
+}
+
+// articleNames - названия и фото карточек WB по nm_id
+func (r *AnalyticsRepository) articleNames(userID int, nmIDs []int64) (map[int64]string, map[int64]string, error) {
+	names := make(map[int64]string)
+	photos := make(map[int64]string)
+	if len(nmIDs) == 0 {
+		return names, photos, nil
+	}
+
+	rows, err := r.db.Query(`
+        SELECT DISTINCT ON (articule) articule::bigint, COALESCE(name, ''), COALESCE(photo, '')
+        FROM wb_articles
+        WHERE id_user = $1 AND marketplace = 'wb' AND articule::bigint = ANY($2)
+        ORDER BY articule, id
+    `, userID, pq.Array(nmIDs))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query article names: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var nmID int64
+		var name, photo string
+		if err := rows.Scan(&nmID, &name, &photo); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan article name: %w", err)
+		}
+		names[nmID], photos[nmID] = name, photo
+	}
+
+	return names, photos, rows.Err()
+}
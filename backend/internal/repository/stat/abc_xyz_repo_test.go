@@ -0,0 +1,78 @@
+package stat
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestXYZBuckets(t *testing.T) {
+	date := func(value string) time.Time {
+		d, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	tests := []struct {
+		name     string
+		from, to string
+		interval string
+		want     []string
+	}{
+		{name: "week from wednesday", from: "2025-03-05", to: "2025-03-16", interval: XYZIntervalWeek, want: []string{"2025-03-03", "2025-03-10"}},
+		{name: "week from sunday", from: "2025-03-09", to: "2025-03-10", interval: XYZIntervalWeek, want: []string{"2025-03-03", "2025-03-10"}},
+		{name: "single day", from: "2025-03-03", to: "2025-03-03", interval: XYZIntervalWeek, want: []string{"2025-03-03"}},
+		{name: "unknown interval is week", from: "2025-03-05", to: "2025-03-09", interval: "", want: []string{"2025-03-03"}},
+		{name: "month from last day", from: "2025-01-31", to: "2025-03-01", interval: XYZIntervalMonth, want: []string{"2025-01-01", "2025-02-01", "2025-03-01"}},
+		{name: "month across year", from: "2024-12-15", to: "2025-01-10", interval: XYZIntervalMonth, want: []string{"2024-12-01", "2025-01-01"}},
+		{name: "to before from", from: "2025-03-10", to: "2025-03-01", interval: XYZIntervalWeek, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := xyzBuckets(date(tt.from), date(tt.to), tt.interval)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("xyzBuckets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVariation(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  map[string]int
+		buckets []string
+		want    *float64
+	}{
+		{name: "no buckets", values: map[string]int{}, buckets: nil, want: nil},
+		{name: "single bucket", values: map[string]int{"a": 10}, buckets: []string{"a"}, want: nil},
+		{name: "no sales", values: map[string]int{}, buckets: []string{"a", "b"}, want: nil},
+		{name: "returns exceed sales", values: map[string]int{"a": -2, "b": 1}, buckets: []string{"a", "b"}, want: nil},
+		{name: "constant sales", values: map[string]int{"a": 5, "b": 5, "c": 5}, buckets: []string{"a", "b", "c"}, want: floatPtr(0)},
+		{name: "empty bucket counts as zero", values: map[string]int{"a": 4}, buckets: []string{"a", "b"}, want: floatPtr(100)},
+		{name: "values outside buckets ignored", values: map[string]int{"a": 4, "b": 4, "x": 100}, buckets: []string{"a", "b"}, want: floatPtr(0)},
+		{name: "growing sales", values: map[string]int{"a": 1, "b": 2, "c": 3, "d": 4}, buckets: []string{"a", "b", "c", "d"}, want: floatPtr(math.Sqrt(1.25) / 2.5 * 100)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := variation(tt.values, tt.buckets)
+			switch {
+			case tt.want == nil && got != nil:
+				t.Errorf("variation() = %v, want nil", *got)
+			case tt.want != nil && got == nil:
+				t.Errorf("variation() = nil, want %v", *tt.want)
+			case tt.want != nil && math.Abs(*got-*tt.want) > 1e-9:
+				t.Errorf("variation() = %v, want %v", *got, *tt.want)
+			}
+		})
+	}
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
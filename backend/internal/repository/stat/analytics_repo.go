@@ -3,10 +3,66 @@ package stat
 import (
 	"database/sql"
 	"fmt"
+	"strconv"
 	"wbrost-go/internal/repository/database/postgres"
 	"wbrost-go/internal/repository/user"
 )
 
+// articleSizeJoin - размер карточки строки отчета (sz.chrt_id, sz.tech_size, sz.wb_size):
+// по баркоду, а если баркода нет в карточке - по ts_name
+const articleSizeJoin = `
+        LEFT JOIN LATERAL (
+            SELECT asz.chrt_id, asz.tech_size, asz.wb_size
+            FROM wb_article_sizes asz
+            LEFT JOIN wb_article_barcodes ab ON ab.size_id = asz.id AND ab.barcode = s.barcode
+            WHERE asz.user_id = s.user_id
+                AND asz.nm_id = s.nm_id
+                AND (ab.id IS NOT NULL OR asz.tech_size = s.ts_name)
+            ORDER BY ab.id IS NULL
+            LIMIT 1
+        ) sz ON TRUE`
+
+// costPriceJoin - себестоимость товара, действовавшая на дату продажи строки отчета (cp.cost_price).
+// Себестоимость размера важнее себестоимости карточки; очищенная себестоимость размера (0)
+// означает, что с этой даты действует себестоимость карточки
+const costPriceJoin = articleSizeJoin + `
+        LEFT JOIN LATERAL (
+            SELECT COALESCE(
+                NULLIF((
+                    SELECT h.cost_price
+                    FROM cost_price_history h
+                    WHERE h.user_id = s.user_id
+                        AND h.articule = s.nm_id::text
+                        AND h.chrt_id = sz.chrt_id
+                        AND h.valid_from <= s.sale_dt::date
+                    ORDER BY h.valid_from DESC
+                    LIMIT 1
+                ), 0),
+                (
+                    SELECT h.cost_price
+                    FROM cost_price_history h
+                    WHERE h.user_id = s.user_id
+                        AND h.articule = s.nm_id::text
+                        AND h.chrt_id = 0
+                        AND h.valid_from <= s.sale_dt::date
+                    ORDER BY h.valid_from DESC
+                    LIMIT 1
+                )
+            ) as cost_price
+        ) cp ON TRUE`
+
+// costPriceSum - себестоимость проданных товаров за вычетом возвращенных
+const costPriceSum = `
+            SUM(
+                CASE
+                    WHEN s.supplier_oper_name IN (1, 7) -- Продажа или Коррекция продаж
+                    THEN COALESCE(s.quantity, 0)
+                    WHEN s.supplier_oper_name = 2 -- Возврат
+                    THEN -COALESCE(s.return_amount, 0)
+                    ELSE 0
+                END * COALESCE(cp.cost_price, 0)
+            )`
+
 type AnalyticsRepository struct {
 	db       *postgres.PostgresDB
 	userRepo *user.UserRepository
@@ -17,11 +73,15 @@ func NewAnalyticsRepository(db *postgres.PostgresDB, userRepo *user.UserReposito
 }
 
 // GetStatDetails получает детальную статистику по фильтрам (с группировкой по nm_id)
-func (r *AnalyticsRepository) GetStatDetails(userID int, dateFrom, dateTo string, page, pageSize int) ([]map[string]interface{}, error) {
-	// Сначала получаем информацию о пользователе
-	user, err := r.userRepo.GetByID(userID)
+func (r *AnalyticsRepository) GetStatDetails(userID, accountID int, dateFrom, dateTo string, page, pageSize int) ([]map[string]interface{}, error) {
+	// Сначала считаем налог по товарам: профили пользователя по периодам их действия
+	calculator, err := newTaxCalculator(r.db, r.userRepo, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user info: %w", err)
+		return nil, err
+	}
+	taxes, err := calculator.wbTaxes(userID, accountID, dateFrom, dateTo, "s.nm_id", true)
+	if err != nil {
+		return nil, err
 	}
 	offset := (page - 1) * pageSize
 	query := `
@@ -29,25 +89,13 @@ func (r *AnalyticsRepository) GetStatDetails(userID int, dateFrom, dateTo string
             COALESCE(s.nm_id, 0) as nm_id,
             COALESCE(s.subject_name, 'Нет названия') as name,
             wa.photo, -- <-- Добавляем фото из wb_articles
-            SUM(
-                CASE 
-                    WHEN s.ppvz_for_pay ~ '^[0-9]+\.?[0-9]*$' 
-                    THEN CAST(s.ppvz_for_pay AS NUMERIC) 
-                    ELSE 0 
-                END
-            ) as ppvz_for_pay,
+            SUM(COALESCE(s.ppvz_for_pay, 0)) as ppvz_for_pay,
             SUM(COALESCE(s.delivery_rub, 0)) as delivery_rub,
             SUM(COALESCE(s.deduction, 0)) as deduction,
             SUM(COALESCE(s.storage_fee, 0)) as storage_fee,
             SUM(COALESCE(s.additional_payment, 0)) as additional_payment,
             SUM(COALESCE(s.penalty, 0)) as penalty,
-            SUM(
-                CASE 
-                    WHEN s.rebill_logistic_cost ~ '^[0-9]+\.?[0-9]*$' 
-                    THEN CAST(s.rebill_logistic_cost AS NUMERIC)
-                    ELSE 0 
-                END
-            ) as rebill_logistic_cost,
+            SUM(COALESCE(s.rebill_logistic_cost, 0)) as rebill_logistic_cost,
             SUM(
                 CASE 
                     WHEN s.supplier_oper_name IN (1, 7) -- Продажа или Коррекция продаж
@@ -75,11 +123,12 @@ func (r *AnalyticsRepository) GetStatDetails(userID int, dateFrom, dateTo string
                     THEN COALESCE(s.return_amount, 0)
                     ELSE 0 
                 END
-            ) as returns
+            ) as returns,` + costPriceSum + ` as cost_price_total
         FROM wb_stats s
-        LEFT JOIN wb_articles wa ON wa.articule::bigint = s.nm_id AND wa.id_user = s.user_id
+        LEFT JOIN wb_articles wa ON wa.articule::bigint = s.nm_id AND wa.id_user = s.user_id AND wa.marketplace = 'wb'` + costPriceJoin + `
         WHERE s.user_id = $1
             AND s.sale_dt BETWEEN $2 AND $3
+            AND ($6 = 0 OR s.account_id = $6)
             AND s.nm_id IS NOT NULL
             AND s.nm_id != 0
         GROUP BY s.nm_id, s.subject_name, wa.photo -- <-- Добавляем wa.photo в GROUP BY
@@ -90,7 +139,7 @@ func (r *AnalyticsRepository) GetStatDetails(userID int, dateFrom, dateTo string
 	// Добавляем время для правильного диапазона дат
 	dateToWithTime := dateTo + " 23:59:59"
 
-	rows, err := r.db.Query(query, userID, dateFrom, dateToWithTime, pageSize, offset)
+	rows, err := r.db.Query(query, userID, dateFrom, dateToWithTime, pageSize, offset, accountID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query stat details: %w", err)
 	}
@@ -102,7 +151,7 @@ func (r *AnalyticsRepository) GetStatDetails(userID int, dateFrom, dateTo string
 		var nmID int64
 		var name string
 		var photo sql.NullString // <-- Добавляем переменную для фото
-		var ppvzForPay, deliveryRub, deduction, storageFee, additionalPayment, penalty, rebillLogisticCost float64
+		var ppvzForPay, deliveryRub, deduction, storageFee, additionalPayment, penalty, rebillLogisticCost, costPriceTotal float64
 		var countSales, countRefund, sales, returns int
 
 		err := rows.Scan(
@@ -120,6 +169,7 @@ func (r *AnalyticsRepository) GetStatDetails(userID int, dateFrom, dateTo string
 			&countRefund,
 			&sales,
 			&returns,
+			&costPriceTotal,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan stat detail: %w", err)
@@ -130,17 +180,14 @@ func (r *AnalyticsRepository) GetStatDetails(userID int, dateFrom, dateTo string
 		if sales+returns > 0 {
 			deliveryPerUnit = deliveryRub / float64(sales+returns)
 		}
-		costPriceTotal := 0.0
 
 		// ВАШ ОРИГИНАЛЬНЫЙ РАСЧЕТ
 		rebillLogisticCostInt := rebillLogisticCost
 		netProfitBeforeTax := deliveryRub + penalty + deduction + storageFee + additionalPayment + rebillLogisticCostInt + float64(costPriceTotal)
-		taxesAmount := 0.0
 
-		// Учитываем налог пользователя (если он задан)
-		if user.Taxes > 0 {
-			taxesAmount = (ppvzForPay - netProfitBeforeTax) * (float64(user.Taxes) / 100)
-		}
+		// Налог по налоговому профилю вместе с НДС
+		tax := taxes[strconv.FormatInt(nmID, 10)]
+		taxesAmount := tax.Total()
 
 		netProfit := (ppvzForPay - netProfitBeforeTax) - taxesAmount
 
@@ -160,8 +207,10 @@ func (r *AnalyticsRepository) GetStatDetails(userID int, dateFrom, dateTo string
 			"count_refund":         countRefund,
 			"sales":                sales,
 			"returns":              returns,
+			"cost_price_total":     costPriceTotal,
 			"net_profit":           netProfit,
 			"taxesAmount":          taxesAmount,
+			"vat_amount":           tax.VAT,
 		}
 
 		results = append(results, item)
@@ -171,12 +220,13 @@ func (r *AnalyticsRepository) GetStatDetails(userID int, dateFrom, dateTo string
 }
 
 // GetStatDetailsCount получает общее количество записей для пагинации
-func (r *AnalyticsRepository) GetStatDetailsCount(userID int, dateFrom, dateTo string) (int, error) {
+func (r *AnalyticsRepository) GetStatDetailsCount(userID, accountID int, dateFrom, dateTo string) (int, error) {
 	query := `
         SELECT COUNT(DISTINCT s.nm_id)
         FROM wb_stats s
         WHERE s.user_id = $1
             AND s.sale_dt BETWEEN $2 AND $3
+            AND ($4 = 0 OR s.account_id = $4)
             AND s.nm_id IS NOT NULL
             AND s.nm_id != 0
     `
@@ -184,7 +234,7 @@ func (r *AnalyticsRepository) GetStatDetailsCount(userID int, dateFrom, dateTo s
 	dateToWithTime := dateTo + " 23:59:59"
 
 	var count int
-	err := r.db.QueryRow(query, userID, dateFrom, dateToWithTime).Scan(&count)
+	err := r.db.QueryRow(query, userID, dateFrom, dateToWithTime, accountID).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get stat details count: %w", err)
 	}
@@ -193,16 +243,10 @@ func (r *AnalyticsRepository) GetStatDetailsCount(userID int, dateFrom, dateTo s
 }
 
 // GetStatSummary получает итоговые суммы (уже агрегированные)
-func (r *AnalyticsRepository) GetStatSummary(userID int, dateFrom, dateTo string) (map[string]interface{}, error) {
+func (r *AnalyticsRepository) GetStatSummary(userID, accountID int, dateFrom, dateTo string) (map[string]interface{}, error) {
 	query := `
         SELECT 
-            SUM(
-                CASE 
-                    WHEN s.ppvz_for_pay ~ '^[0-9]+\.?[0-9]*$' 
-                    THEN CAST(s.ppvz_for_pay AS NUMERIC) 
-                    ELSE 0 
-                END
-            ) as total_ppvz_for_pay,
+            SUM(COALESCE(s.ppvz_for_pay, 0)) as total_ppvz_for_pay,
             SUM(COALESCE(s.delivery_rub, 0)) as total_delivery_rub,
             SUM(COALESCE(s.deduction, 0)) as total_deduction,
             SUM(COALESCE(s.storage_fee, 0)) as total_storage_fee,
@@ -224,19 +268,20 @@ func (r *AnalyticsRepository) GetStatSummary(userID int, dateFrom, dateTo string
             ) as total_count_refund,
             SUM(COALESCE(s.quantity, 0)) as total_quantity,
             SUM(COALESCE(s.return_amount, 0)) as total_return_amount,
-            COUNT(DISTINCT s.nm_id) as unique_products
-        FROM wb_stats s
+            COUNT(DISTINCT s.nm_id) as unique_products,` + costPriceSum + ` as total_cost_price
+        FROM wb_stats s` + costPriceJoin + `
         WHERE s.user_id = $1
             AND s.sale_dt BETWEEN $2 AND $3
+            AND ($4 = 0 OR s.account_id = $4)
             AND s.nm_id IS NOT NULL
             AND s.nm_id != 0
     `
 
 	dateToWithTime := dateTo + " 23:59:59"
 
-	row := r.db.QueryRow(query, userID, dateFrom, dateToWithTime)
+	row := r.db.QueryRow(query, userID, dateFrom, dateToWithTime, accountID)
 
-	var totalPpvzForPay, totalDeliveryRub, totalDeduction, totalStorageFee, totalAdditionalPayment, totalPenalty sql.NullFloat64
+	var totalPpvzForPay, totalDeliveryRub, totalDeduction, totalStorageFee, totalAdditionalPayment, totalPenalty, totalCostPrice sql.NullFloat64
 	var totalCountSales, totalCountRefund, totalQuantity, totalReturnAmount, uniqueProducts sql.NullInt64
 
 	err := row.Scan(
@@ -251,15 +296,28 @@ func (r *AnalyticsRepository) GetStatSummary(userID int, dateFrom, dateTo string
 		&totalQuantity,
 		&totalReturnAmount,
 		&uniqueProducts,
+		&totalCostPrice,
 	)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get stat summary: %w", err)
 	}
 
+	// Налог по налоговому профилю (как у товаров - только строки с nm_id)
+	calculator, err := newTaxCalculator(r.db, r.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	taxes, err := calculator.wbTaxes(userID, accountID, dateFrom, dateTo, "''", true)
+	if err != nil {
+		return nil, err
+	}
+	tax := taxes[""]
+
 	totalNetProfit := getFloatValue(totalPpvzForPay) - getFloatValue(totalDeliveryRub) -
 		getFloatValue(totalDeduction) - getFloatValue(totalStorageFee) -
-		getFloatValue(totalAdditionalPayment) - getFloatValue(totalPenalty)
+		getFloatValue(totalAdditionalPayment) - getFloatValue(totalPenalty) -
+		getFloatValue(totalCostPrice) - tax.Total()
 
 	summary := map[string]interface{}{
 		"total_ppvz_for_pay":       getFloatValue(totalPpvzForPay),
@@ -273,8 +331,97 @@ func (r *AnalyticsRepository) GetStatSummary(userID int, dateFrom, dateTo string
 		"total_quantity":           getIntValue(totalQuantity),
 		"total_return_amount":      getIntValue(totalReturnAmount),
 		"unique_products":          getIntValue(uniqueProducts),
+		"total_cost_price":         getFloatValue(totalCostPrice),
+		"total_taxes":              tax.Total(),
+		"total_vat":                tax.VAT,
 		"total_net_profit":         totalNetProfit,
 	}
 
 	return summary, nil
 }
+
+// GetSizeDetails - продажи, возвраты и маржа по размерам (nmID = 0 - по всем товарам).
+// Размер определяется по баркоду строки отчета, иначе по ts_name
+func (r *AnalyticsRepository) GetSizeDetails(userID, accountID int, nmID int64, dateFrom, dateTo string) ([]map[string]interface{}, error) {
+	query := `
+        SELECT
+            s.nm_id,
+            COALESCE(sz.chrt_id, 0) as chrt_id,
+            COALESCE(sz.tech_size, s.ts_name, '') as tech_size,
+            COALESCE(sz.wb_size, '') as wb_size,
+            SUM(CASE WHEN s.supplier_oper_name IN (1, 7) THEN COALESCE(s.quantity, 0) ELSE 0 END) as sales,
+            SUM(CASE WHEN s.supplier_oper_name = 2 THEN COALESCE(s.return_amount, 0) ELSE 0 END) as returns,
+            SUM(
+                CASE
+                    WHEN s.supplier_oper_name IN (1, 7) THEN COALESCE(s.retail_amount, 0)
+                    WHEN s.supplier_oper_name = 2 THEN -COALESCE(s.retail_amount, 0)
+                    ELSE 0
+                END
+            ) as revenue,
+            SUM(COALESCE(s.ppvz_for_pay, 0)) as ppvz_for_pay,
+            SUM(
+                COALESCE(s.delivery_rub, 0) + COALESCE(s.penalty, 0) + COALESCE(s.deduction, 0) +
+                COALESCE(s.storage_fee, 0) + COALESCE(s.additional_payment, 0) + COALESCE(s.rebill_logistic_cost, 0)
+            ) as expenses,` + costPriceSum + ` as cost_price_total
+        FROM wb_stats s` + costPriceJoin + `
+        WHERE s.user_id = $1
+            AND s.sale_dt BETWEEN $2 AND $3
+            AND ($4 = 0 OR s.account_id = $4)
+            AND ($5 = 0 OR s.nm_id = $5)
+            AND s.nm_id IS NOT NULL
+            AND s.nm_id != 0
+        GROUP BY s.nm_id, 2, 3, 4
+        ORDER BY s.nm_id, tech_size
+    `
+
+	dateToWithTime := dateTo + " 23:59:59"
+
+	rows, err := r.db.Query(query, userID, dateFrom, dateToWithTime, accountID, nmID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query size details: %w", err)
+	}
+	defer rows.Close()
+
+	results := []map[string]interface{}{}
+	for rows.Next() {
+		var rowNmID, chrtID int64
+		var techSize, wbSize string
+		var sales, returns int
+		var revenue, ppvzForPay, expenses, costPriceTotal float64
+
+		if err := rows.Scan(&rowNmID, &chrtID, &techSize, &wbSize, &sales, &returns, &revenue, &ppvzForPay, &expenses, &costPriceTotal); err != nil {
+			return nil, fmt.Errorf("failed to scan size detail: %w", err)
+		}
+
+		// Доля возвратов от проданных единиц размера
+		returnRate := 0.0
+		if sales > 0 {
+			returnRate = float64(returns) / float64(sales) * 100
+		}
+
+		// Прибыль до налога и маржа от выручки
+		profit := ppvzForPay - expenses - costPriceTotal
+		margin := 0.0
+		if revenue != 0 {
+			margin = profit / revenue * 100
+		}
+
+		results = append(results, map[string]interface{}{
+			"nm_id":            rowNmID,
+			"chrt_id":          chrtID,
+			"tech_size":        techSize,
+			"wb_size":          wbSize,
+			"sales":            sales,
+			"returns":          returns,
+			"return_rate":      returnRate,
+			"revenue":          revenue,
+			"ppvz_for_pay":     ppvzForPay,
+			"expenses":         expenses,
+			"cost_price_total": costPriceTotal,
+			"profit":           profit,
+			"margin":           margin,
+		})
+	}
+
+	return results, rows.Err()
+}
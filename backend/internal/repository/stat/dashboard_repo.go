@@ -3,7 +3,9 @@ package stat
 import (
 	"database/sql"
 	"fmt"
+	"sort"
 	"time"
+	"wbrost-go/internal/entity"
 	"wbrost-go/internal/repository/database/postgres"
 	"wbrost-go/internal/repository/user"
 )
@@ -17,8 +19,73 @@ func NewDashboardRepository(db *postgres.PostgresDB, userRepo *user.UserReposito
 	return &DashboardRepository{db: db, userRepo: userRepo}
 }
 
-// GetDashboardStats получает статистику для дашборда
-func (r *DashboardRepository) GetDashboardStats(userID int, dateFrom, dateTo string) (map[string]interface{}, error) {
+// Фильтр маркетплейса дашбордов: пустое значение - все площадки.
+// Данные WB берутся из wb_stats, остальных площадок - из marketplace_operations
+func includesWB(marketplace string) bool {
+	return marketplace == "" || marketplace == entity.MarketplaceWB
+}
+
+func includesOperations(marketplace string) bool {
+	return marketplace != entity.MarketplaceWB
+}
+
+// operationsFilter - условие на marketplace_operations (WB там тоже есть, но считается по wb_stats)
+const operationsFilter = `o.marketplace <> 'wb' AND ($5 = '' OR o.marketplace = $5)`
+
+// GetDashboardStats получает статистику для дашборда (marketplace = "" - по всем площадкам)
+func (r *DashboardRepository) GetDashboardStats(userID, accountID int, marketplace, dateFrom, dateTo string) (map[string]interface{}, error) {
+	salesCount, ppvzForPayTotal, returnsCount, netProfit := int64(0), 0.0, int64(0), 0.0
+
+	// Налог по налоговому профилю вычитается из чистой прибыли
+	calculator, err := newTaxCalculator(r.db, r.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	var tax entity.TaxAmount
+
+	if includesWB(marketplace) {
+		sales, payout, returns, profit, err := r.getWBDashboardStats(userID, accountID, dateFrom, dateTo)
+		if err != nil {
+			return nil, err
+		}
+		salesCount, ppvzForPayTotal, returnsCount, netProfit = salesCount+sales, ppvzForPayTotal+payout, returnsCount+returns, netProfit+profit
+
+		taxes, err := calculator.wbTaxes(userID, accountID, dateFrom, dateTo, "''", false)
+		if err != nil {
+			return nil, err
+		}
+		tax.Add(taxes[""])
+	}
+
+	if includesOperations(marketplace) {
+		sales, payout, returns, profit, err := r.getOperationsDashboardStats(userID, accountID, marketplace, dateFrom, dateTo)
+		if err != nil {
+			return nil, err
+		}
+		salesCount, ppvzForPayTotal, returnsCount, netProfit = salesCount+sales, ppvzForPayTotal+payout, returnsCount+returns, netProfit+profit
+
+		taxes, err := calculator.operationsTaxes(userID, accountID, marketplace, dateFrom, dateTo, "''")
+		if err != nil {
+			return nil, err
+		}
+		tax.Add(taxes[""])
+	}
+	netProfit -= tax.Total()
+
+	// Форматируем значения
+	stats := map[string]interface{}{
+		"sales_count":        salesCount,
+		"ppvz_for_pay_total": ppvzForPayTotal,
+		"returns_count":      returnsCount,
+		"net_profit":         netProfit,
+		"taxes_amount":       tax.Total(),
+	}
+
+	return stats, nil
+}
+
+// getWBDashboardStats - продажи, к перечислению, возвраты и чистая прибыль WB по wb_stats
+func (r *DashboardRepository) getWBDashboardStats(userID, accountID int, dateFrom, dateTo string) (int64, float64, int64, float64, error) {
 	dateToWithTime := dateTo + " 23:59:59"
 
 	query := `
@@ -32,13 +99,7 @@ func (r *DashboardRepository) GetDashboardStats(userID int, dateFrom, dateTo str
             ) as sales_count,
             
             -- Сумма к перечислению
-            SUM(
-                CASE 
-                    WHEN s.ppvz_for_pay ~ '^[0-9]+\.?[0-9]*$' 
-                    THEN CAST(s.ppvz_for_pay AS NUMERIC) 
-                    ELSE 0 
-                END
-            ) as ppvz_for_pay_total,
+            SUM(COALESCE(s.ppvz_for_pay, 0)) as ppvz_for_pay_total,
             
             -- Возвраты
             COUNT(DISTINCT 
@@ -49,62 +110,151 @@ func (r *DashboardRepository) GetDashboardStats(userID int, dateFrom, dateTo str
             ) as returns_count,
             
             -- Чистая прибыль
-            SUM(
-                CASE 
-                    WHEN s.ppvz_for_pay ~ '^[0-9]+\.?[0-9]*$' 
-                    THEN CAST(s.ppvz_for_pay AS NUMERIC) 
-                    ELSE 0 
-                END
-            ) - 
+            SUM(COALESCE(s.ppvz_for_pay, 0)) - 
             SUM(COALESCE(s.delivery_rub, 0)) - 
             SUM(COALESCE(s.deduction, 0)) - 
             SUM(COALESCE(s.storage_fee, 0)) - 
             SUM(COALESCE(s.additional_payment, 0)) - 
-            SUM(COALESCE(s.penalty, 0)) as net_profit
-        FROM wb_stats s
+            SUM(COALESCE(s.penalty, 0)) -
+            COALESCE(` + costPriceSum + `, 0) as net_profit
+        FROM wb_stats s` + costPriceJoin + `
         WHERE s.user_id = $1
             AND s.sale_dt BETWEEN $2 AND $3
+            AND ($4 = 0 OR s.account_id = $4)
     `
 
 	var salesCount, returnsCount sql.NullInt64
 	var ppvzForPayTotal, netProfit sql.NullFloat64
 
-	err := r.db.QueryRow(query, userID, dateFrom, dateToWithTime).Scan(
+	err := r.db.QueryRow(query, userID, dateFrom, dateToWithTime, accountID).Scan(
 		&salesCount,
 		&ppvzForPayTotal,
 		&returnsCount,
 		&netProfit,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get dashboard stats: %w", err)
+		return 0, 0, 0, 0, fmt.Errorf("failed to get dashboard stats: %w", err)
 	}
 
-	// Форматируем значения
-	stats := map[string]interface{}{
-		"sales_count":        getIntValue(salesCount),
-		"ppvz_for_pay_total": getFloatValue(ppvzForPayTotal),
-		"returns_count":      getIntValue(returnsCount),
-		"net_profit":         getFloatValue(netProfit),
+	return getIntValue(salesCount), getFloatValue(ppvzForPayTotal), getIntValue(returnsCount), getFloatValue(netProfit), nil
+}
+
+// getOperationsDashboardStats - те же показатели по нормализованным операциям остальных площадок
+func (r *DashboardRepository) getOperationsDashboardStats(userID, accountID int, marketplace, dateFrom, dateTo string) (int64, float64, int64, float64, error) {
+	dateToWithTime := dateTo + " 23:59:59"
+
+	query := `
+        SELECT 
+            SUM(CASE WHEN o.operation_type = 'sale' THEN o.quantity ELSE 0 END) as sales_count,
+            SUM(CASE WHEN o.operation_type IN ('sale', 'return') THEN o.payout ELSE 0 END) as payout_total,
+            SUM(CASE WHEN o.operation_type = 'return' THEN o.quantity ELSE 0 END) as returns_count,
+            SUM(o.payout - o.logistics - o.storage - o.penalty - o.other_charges) as net_profit
+        FROM marketplace_operations o
+        WHERE o.user_id = $1
+            AND o.operation_date BETWEEN $2 AND $3
+            AND ($4 = 0 OR o.account_id = $4)
+            AND ` + operationsFilter
+
+	var salesCount, returnsCount sql.NullInt64
+	var payoutTotal, netProfit sql.NullFloat64
+
+	err := r.db.QueryRow(query, userID, dateFrom, dateToWithTime, accountID, marketplace).Scan(
+		&salesCount,
+		&payoutTotal,
+		&returnsCount,
+		&netProfit,
+	)
+	if err != nil {
+		return 0, 0, 0, 0, fmt.Errorf("failed to get marketplace operations stats: %w", err)
 	}
 
-	return stats, nil
+	return getIntValue(salesCount), getFloatValue(payoutTotal), getIntValue(returnsCount), getFloatValue(netProfit), nil
+}
+
+// dailyChartPoint - точка линейного графика за день
+type dailyChartPoint struct {
+	revenue float64
+	sales   float64
+}
+
+// chartCategory - столбец графика топ категорий
+type chartCategory struct {
+	label   string
+	revenue float64
+}
+
+// GetChartData получает данные для графиков (marketplace = "" - по всем площадкам)
+func (r *DashboardRepository) GetChartData(userID, accountID int, marketplace, dateFrom, dateTo string) (map[string]interface{}, error) {
+	daily := make(map[time.Time]*dailyChartPoint)
+	var categories []chartCategory
+
+	if includesWB(marketplace) {
+		if err := r.addWBChartData(userID, accountID, dateFrom, dateTo, daily, &categories); err != nil {
+			return nil, err
+		}
+	}
+
+	if includesOperations(marketplace) {
+		if err := r.addOperationsChartData(userID, accountID, marketplace, dateFrom, dateTo, daily, &categories); err != nil {
+			return nil, err
+		}
+	}
+
+	dates := make([]time.Time, 0, len(daily))
+	for date := range daily {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	lineChartLabels := []string{}
+	lineChartRevenue := []float64{}
+	lineChartSales := []float64{}
+
+	for _, date := range dates {
+		lineChartLabels = append(lineChartLabels, date.Format("02 Jan"))
+		lineChartRevenue = append(lineChartRevenue, daily[date].revenue)
+		lineChartSales = append(lineChartSales, daily[date].sales)
+	}
+
+	// Топ-5 по выручке среди категорий WB и товаров остальных площадок
+	sort.SliceStable(categories, func(i, j int) bool { return categories[i].revenue > categories[j].revenue })
+	if len(categories) > 5 {
+		categories = categories[:5]
+	}
+
+	barChartLabels := []string{}
+	barChartData := []float64{}
+
+	for _, c := range categories {
+		barChartLabels = append(barChartLabels, c.label)
+		barChartData = append(barChartData, c.revenue)
+	}
+
+	chartData := map[string]interface{}{
+		"line_chart": map[string]interface{}{
+			"labels":  lineChartLabels,
+			"revenue": lineChartRevenue,
+			"sales":   lineChartSales,
+		},
+		"bar_chart": map[string]interface{}{
+			"labels": barChartLabels,
+			"data":   barChartData,
+		},
+	}
+
+	return chartData, nil
 }
 
-// GetChartData получает данные для графиков
-func (r *DashboardRepository) GetChartData(userID int, dateFrom, dateTo string) (map[string]interface{}, error) {
+// addWBChartData добавляет продажи по дням и топ категорий WB из wb_stats
+func (r *DashboardRepository) addWBChartData(userID, accountID int, dateFrom, dateTo string,
+	daily map[time.Time]*dailyChartPoint, categories *[]chartCategory) error {
 	dateToWithTime := dateTo + " 23:59:59"
 
 	// Данные для линейного графика (продажи по дням)
 	queryLineChart := `
         SELECT 
             DATE(s.sale_dt) as sale_date,
-            SUM(
-                CASE 
-                    WHEN s.ppvz_for_pay ~ '^[0-9]+\.?[0-9]*$' 
-                    THEN CAST(s.ppvz_for_pay AS NUMERIC) 
-                    ELSE 0 
-                END
-            ) as daily_revenue,
+            SUM(COALESCE(s.ppvz_for_pay, 0)) as daily_revenue,
             COUNT(DISTINCT 
                 CASE 
                     WHEN s.supplier_oper_name IN (1, 7) 
@@ -114,33 +264,28 @@ func (r *DashboardRepository) GetChartData(userID int, dateFrom, dateTo string)
         FROM wb_stats s
         WHERE s.user_id = $1
             AND s.sale_dt BETWEEN $2 AND $3
+            AND ($4 = 0 OR s.account_id = $4)
             AND s.sale_dt IS NOT NULL
         GROUP BY DATE(s.sale_dt)
         ORDER BY sale_date
     `
 
-	rows, err := r.db.Query(queryLineChart, userID, dateFrom, dateToWithTime)
+	rows, err := r.db.Query(queryLineChart, userID, dateFrom, dateToWithTime, accountID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get line chart data: %w", err)
+		return fmt.Errorf("failed to get line chart data: %w", err)
 	}
 	defer rows.Close()
 
-	lineChartLabels := []string{}
-	lineChartRevenue := []float64{}
-	lineChartSales := []float64{}
-
 	for rows.Next() {
 		var saleDate time.Time
 		var dailyRevenue, dailySales sql.NullFloat64
 
 		err := rows.Scan(&saleDate, &dailyRevenue, &dailySales)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan line chart data: %w", err)
+			return fmt.Errorf("failed to scan line chart data: %w", err)
 		}
 
-		lineChartLabels = append(lineChartLabels, saleDate.Format("02 Jan"))
-		lineChartRevenue = append(lineChartRevenue, getFloatValue(dailyRevenue))
-		lineChartSales = append(lineChartSales, getFloatValue(dailySales))
+		addDailyPoint(daily, saleDate, getFloatValue(dailyRevenue), getFloatValue(dailySales))
 	}
 
 	// Данные для столбчатого графика (топ категорий)
@@ -148,31 +293,23 @@ func (r *DashboardRepository) GetChartData(userID int, dateFrom, dateTo string)
         SELECT 
             COALESCE(s.subject_name, 'Без категории') as category,
             COUNT(DISTINCT s.nm_id) as product_count,
-            SUM(
-                CASE 
-                    WHEN s.ppvz_for_pay ~ '^[0-9]+\.?[0-9]*$' 
-                    THEN CAST(s.ppvz_for_pay AS NUMERIC) 
-                    ELSE 0 
-                END
-            ) as category_revenue
+            SUM(COALESCE(s.ppvz_for_pay, 0)) as category_revenue
         FROM wb_stats s
         WHERE s.user_id = $1
             AND s.sale_dt BETWEEN $2 AND $3
+            AND ($4 = 0 OR s.account_id = $4)
             AND s.subject_name IS NOT NULL
         GROUP BY s.subject_name
         ORDER BY category_revenue DESC
         LIMIT 5
     `
 
-	rowsBar, err := r.db.Query(queryBarChart, userID, dateFrom, dateToWithTime)
+	rowsBar, err := r.db.Query(queryBarChart, userID, dateFrom, dateToWithTime, accountID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get bar chart data: %w", err)
+		return fmt.Errorf("failed to get bar chart data: %w", err)
 	}
 	defer rowsBar.Close()
 
-	barChartLabels := []string{}
-	barChartData := []float64{}
-
 	for rowsBar.Next() {
 		var category string
 		var productCount sql.NullInt64
@@ -180,30 +317,100 @@ func (r *DashboardRepository) GetChartData(userID int, dateFrom, dateTo string)
 
 		err := rowsBar.Scan(&category, &productCount, &categoryRevenue)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan bar chart data: %w", err)
+			return fmt.Errorf("failed to scan bar chart data: %w", err)
 		}
 
-		barChartLabels = append(barChartLabels, category)
-		barChartData = append(barChartData, getFloatValue(categoryRevenue))
+		*categories = append(*categories, chartCategory{label: category, revenue: getFloatValue(categoryRevenue)})
 	}
 
-	chartData := map[string]interface{}{
-		"line_chart": map[string]interface{}{
-			"labels":  lineChartLabels,
-			"revenue": lineChartRevenue,
-			"sales":   lineChartSales,
-		},
-		"bar_chart": map[string]interface{}{
-			"labels": barChartLabels,
-			"data":   barChartData,
-		},
+	return nil
+}
+
+// addOperationsChartData добавляет продажи по дням и топ товаров остальных площадок.
+// Категорий у нормализованных операций нет, поэтому в топ попадают товары
+func (r *DashboardRepository) addOperationsChartData(userID, accountID int, marketplace, dateFrom, dateTo string,
+	daily map[time.Time]*dailyChartPoint, categories *[]chartCategory) error {
+	dateToWithTime := dateTo + " 23:59:59"
+
+	queryLineChart := `
+        SELECT 
+            DATE(o.operation_date) as sale_date,
+            SUM(CASE WHEN o.operation_type IN ('sale', 'return') THEN o.payout ELSE 0 END) as daily_revenue,
+            SUM(CASE WHEN o.operation_type = 'sale' THEN o.quantity ELSE 0 END) as daily_sales
+        FROM marketplace_operations o
+        WHERE o.user_id = $1
+            AND o.operation_date BETWEEN $2 AND $3
+            AND ($4 = 0 OR o.account_id = $4)
+            AND ` + operationsFilter + `
+        GROUP BY DATE(o.operation_date)
+        ORDER BY sale_date
+    `
+
+	rows, err := r.db.Query(queryLineChart, userID, dateFrom, dateToWithTime, accountID, marketplace)
+	if err != nil {
+		return fmt.Errorf("failed to get operations line chart data: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var saleDate time.Time
+		var dailyRevenue, dailySales sql.NullFloat64
+
+		if err := rows.Scan(&saleDate, &dailyRevenue, &dailySales); err != nil {
+			return fmt.Errorf("failed to scan operations line chart data: %w", err)
+		}
+
+		addDailyPoint(daily, saleDate, getFloatValue(dailyRevenue), getFloatValue(dailySales))
 	}
 
-	return chartData, nil
+	queryBarChart := `
+        SELECT 
+            COALESCE(o.product_name, 'Без названия') as product,
+            SUM(o.payout) as product_revenue
+        FROM marketplace_operations o
+        WHERE o.user_id = $1
+            AND o.operation_date BETWEEN $2 AND $3
+            AND ($4 = 0 OR o.account_id = $4)
+            AND ` + operationsFilter + `
+            AND o.operation_type IN ('sale', 'return')
+        GROUP BY COALESCE(o.product_name, 'Без названия')
+        ORDER BY product_revenue DESC
+        LIMIT 5
+    `
+
+	rowsBar, err := r.db.Query(queryBarChart, userID, dateFrom, dateToWithTime, accountID, marketplace)
+	if err != nil {
+		return fmt.Errorf("failed to get operations bar chart data: %w", err)
+	}
+	defer rowsBar.Close()
+
+	for rowsBar.Next() {
+		var product string
+		var productRevenue sql.NullFloat64
+
+		if err := rowsBar.Scan(&product, &productRevenue); err != nil {
+			return fmt.Errorf("failed to scan operations bar chart data: %w", err)
+		}
+
+		*categories = append(*categories, chartCategory{label: product, revenue: getFloatValue(productRevenue)})
+	}
+
+	return nil
 }
 
-// GetMonthlyRevenue получает выручку по месяцам за последние 12 месяцев
-func (r *DashboardRepository) GetMonthlyRevenue(userID int) (map[string]interface{}, error) {
+func addDailyPoint(daily map[time.Time]*dailyChartPoint, date time.Time, revenue, sales float64) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	point, ok := daily[day]
+	if !ok {
+		point = &dailyChartPoint{}
+		daily[day] = point
+	}
+	point.revenue += revenue
+	point.sales += sales
+}
+
+// GetMonthlyRevenue получает выручку по месяцам за последние 12 месяцев (marketplace = "" - по всем площадкам)
+func (r *DashboardRepository) GetMonthlyRevenue(userID, accountID int, marketplace string) (map[string]interface{}, error) {
 	// Получаем текущую дату
 	now := time.Now()
 
@@ -224,51 +431,49 @@ func (r *DashboardRepository) GetMonthlyRevenue(userID int) (map[string]interfac
         SELECT 
             TO_CHAR(m.month_start, 'Mon YYYY') as month_name,
             EXTRACT(YEAR FROM m.month_start) as year,
-            COALESCE(SUM(
-                CASE 
-                    WHEN s.ppvz_for_pay ~ '^[0-9]+\.?[0-9]*$' 
-                    THEN CAST(s.ppvz_for_pay AS NUMERIC) 
-                    ELSE 0 
-                END
-            ), 0) as monthly_revenue
+            COALESCE(SUM(COALESCE(s.ppvz_for_pay, 0)), 0) as monthly_revenue
         FROM months m
         LEFT JOIN wb_stats s ON 
             date_trunc('month', s.sale_dt) = m.month_start 
             AND s.user_id = $1
+            AND ($4 = 0 OR s.account_id = $4)
         GROUP BY m.month_start, year
         ORDER BY m.month_start
     `
 
-	rows, err := r.db.Query(query, userID,
-		startDate.Format("2006-01-02"),
-		endDate.Format("2006-01-02"))
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to get monthly revenue: %w", err)
-	}
-	defer rows.Close()
-
 	monthlyLabels := []string{}
 	monthlyData := []float64{}
 
-	for rows.Next() {
-		var monthName string
-		var year int
-		var monthlyRevenue float64
+	if includesWB(marketplace) {
+		rows, err := r.db.Query(query, userID,
+			startDate.Format("2006-01-02"),
+			endDate.Format("2006-01-02"),
+			accountID)
 
-		err := rows.Scan(&monthName, &year, &monthlyRevenue)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan monthly revenue: %w", err)
+			return nil, fmt.Errorf("failed to get monthly revenue: %w", err)
 		}
+		defer rows.Close()
 
-		// Форматируем метку: "Фев 2025"
-		monthLabel := formatMonthLabel(monthName, year)
-		monthlyLabels = append(monthlyLabels, monthLabel)
-		monthlyData = append(monthlyData, monthlyRevenue)
+		for rows.Next() {
+			var monthName string
+			var year int
+			var monthlyRevenue float64
 
-		// Отладка
-		//fmt.Printf("Month: %s, Year: %d, Revenue: %.2f, Label: %s\n",
-		//	monthName, year, monthlyRevenue, monthLabel)
+			err := rows.Scan(&monthName, &year, &monthlyRevenue)
+			if err != nil {
+				return nil, fmt.Errorf("failed to scan monthly revenue: %w", err)
+			}
+
+			// Форматируем метку: "Фев 2025"
+			monthLabel := formatMonthLabel(monthName, year)
+			monthlyLabels = append(monthlyLabels, monthLabel)
+			monthlyData = append(monthlyData, monthlyRevenue)
+
+			// Отладка
+			//fmt.Printf("Month: %s, Year: %d, Revenue: %.2f, Label: %s\n",
+			//	monthName, year, monthlyRevenue, monthLabel)
+		}
 	}
 
 	// Если нет данных, создаем метки для последних 12 месяцев
@@ -283,8 +488,66 @@ func (r *DashboardRepository) GetMonthlyRevenue(userID int) (map[string]interfac
 		}
 	}
 
+	// Выручка остальных площадок по тем же месяцам
+	if includesOperations(marketplace) {
+		operationsData, err := r.getOperationsMonthlyRevenue(userID, accountID, marketplace, startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+		for i := range monthlyData {
+			if i < len(operationsData) {
+				monthlyData[i] += operationsData[i]
+			}
+		}
+	}
+
 	return map[string]interface{}{
 		"labels": monthlyLabels,
 		"data":   monthlyData,
 	}, nil
 }
+
+// getOperationsMonthlyRevenue - выручка остальных площадок по месяцам периода (по порядку месяцев)
+func (r *DashboardRepository) getOperationsMonthlyRevenue(userID, accountID int, marketplace string, startDate, endDate time.Time) ([]float64, error) {
+	query := `
+        WITH months AS (
+            SELECT generate_series(
+                date_trunc('month', $2::timestamp),
+                date_trunc('month', $3::timestamp),
+                '1 month'::interval
+            ) as month_start
+        )
+        SELECT 
+            COALESCE(SUM(o.payout), 0) as monthly_revenue
+        FROM months m
+        LEFT JOIN marketplace_operations o ON 
+            date_trunc('month', o.operation_date) = m.month_start 
+            AND o.user_id = $1
+            AND ($4 = 0 OR o.account_id = $4)
+            AND o.operation_type IN ('sale', 'return')
+            AND ` + operationsFilter + `
+        GROUP BY m.month_start
+        ORDER BY m.month_start
+    `
+
+	rows, err := r.db.Query(query, userID,
+		startDate.Format("2006-01-02"),
+		endDate.Format("2006-01-02"),
+		accountID,
+		marketplace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operations monthly revenue: %w", err)
+	}
+	defer rows.Close()
+
+	var data []float64
+	for rows.Next() {
+		var monthlyRevenue float64
+		if err := rows.Scan(&monthlyRevenue); err != nil {
+			return nil, fmt.Errorf("failed to scan operations monthly revenue: %w", err)
+		}
+		data = append(data, monthlyRevenue)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,176 @@
+package stat
+
+import (
+	"math"
+	"time"
+)
+
+// Методы прогноза спроса
+const (
+	ForecastMethodSMA = "sma" // Скользящее среднее с недельной сезонностью
+	ForecastMethodSES = "ses" // Простое экспоненциальное сглаживание
+)
+
+// forecastMinSeasonDays - история, начиная с которой считается недельная сезонность (две полные недели)
+const forecastMinSeasonDays = 14
+
+// forecastModel - прогноз ряда дневных продаж: уровень спроса, индексы дней недели и ошибка
+// прогноза на день вперед на истории
+type forecastModel struct {
+	Level      float64    // Средние продажи в день без сезонности
+	Season     [7]float64 // Индексы дней недели (time.Weekday)
+	AbsError   float64    // Сумма модулей ошибок на истории
+	Error      float64    // Сумма ошибок (прогноз - факт)
+	Actual     float64    // Сумма фактических продаж в проверенных днях
+	TestedDays int        // Количество проверенных дней
+}
+
+// weekdaySeason - индексы дней недели по суммарным продажам: средние продажи дня недели
+// к средним продажам в день. При короткой истории или без продаж сезонность не учитывается
+func weekdaySeason(series []float64, start time.Time) [7]float64 {
+	season := [7]float64{1, 1, 1, 1, 1, 1, 1}
+	if len(series) < forecastMinSeasonDays {
+		return season
+	}
+
+	var sums, counts [7]float64
+	total := 0.0
+	for i, value := range series {
+		day := start.AddDate(0, 0, i).Weekday()
+		sums[day] += value
+		counts[day]++
+		total += value
+	}
+	if total <= 0 {
+		return season
+	}
+
+	mean := total / float64(len(series))
+	for day := range season {
+		if counts[day] > 0 {
+			season[day] = sums[day] / counts[day] / mean
+		}
+	}
+	return season
+}
+
+// fitForecast строит прогноз ряда дневных продаж, начинающегося с даты start.
+// SMA - средние продажи за последние window дней без сезонности (сумма продаж к сумме индексов дней),
+// SES - уровень с экспоненциальным сглаживанием с коэффициентом alpha.
+// Ошибка считается прогнозом на день вперед по дням после первых window дней (не больше половины истории)
+func fitForecast(series []float64, start time.Time, method string, window int, alpha float64, season [7]float64) forecastModel {
+	if method == ForecastMethodSES {
+		season = [7]float64{1, 1, 1, 1, 1, 1, 1}
+	}
+	model := forecastModel{Season: season}
+	if len(series) == 0 {
+		return model
+	}
+
+	index := func(i int) float64 {
+		return season[start.AddDate(0, 0, i).Weekday()]
+	}
+
+	// level(t) - уровень по дням до t (не включая t)
+	level := func(t int) float64 {
+		from := t - window
+		if from < 0 {
+			from = 0
+		}
+		sum, weights := 0.0, 0.0
+		for i := from; i < t; i++ {
+			sum += series[i]
+			weights += index(i)
+		}
+		if weights <= 0 {
+			return 0
+		}
+		return sum / weights
+	}
+
+	warmup := window
+	if warmup > len(series)/2 {
+		warmup = len(series) / 2
+	}
+	if warmup < 1 {
+		warmup = 1
+	}
+
+	smoothed := series[0]
+	for t := 1; t <= len(series); t++ {
+		var current float64
+		if method == ForecastMethodSES {
+			current = smoothed
+		} else {
+			current = level(t)
+		}
+
+		if t == len(series) {
+			model.Level = current
+			break
+		}
+
+		if t >= warmup {
+			e := current*index(t) - series[t]
+			model.AbsError += math.Abs(e)
+			model.Error += e
+			model.Actual += series[t]
+			model.TestedDays++
+		}
+
+		smoothed = alpha*series[t] + (1-alpha)*smoothed
+	}
+
+	return model
+}
+
+// demand - прогноз продаж за days дней, начиная с даты from
+func (m forecastModel) demand(from time.Time, days int) float64 {
+	total := 0.0
+	for i := 0; i < days; i++ {
+		total += m.Level * m.Season[from.AddDate(0, 0, i).Weekday()]
+	}
+	return total
+}
+
+// daily - прогноз продаж по дням, начиная с даты from
+func (m forecastModel) daily(from time.Time, days int) []map[string]interface{} {
+	points := make([]map[string]interface{}, days)
+	for i := range points {
+		date := from.AddDate(0, 0, i)
+		points[i] = map[string]interface{}{
+			"date":   date.Format("2006-01-02"),
+			"demand": roundForecast(m.Level * m.Season[date.Weekday()]),
+		}
+	}
+	return points
+}
+
+// mae - средняя абсолютная ошибка прогноза на день, шт.
+func (m forecastModel) mae() interface{} {
+	if m.TestedDays == 0 {
+		return nil
+	}
+	return roundForecast(m.AbsError / float64(m.TestedDays))
+}
+
+// wape - суммарная абсолютная ошибка к фактическим продажам, %
+func (m forecastModel) wape() interface{} {
+	if m.Actual <= 0 {
+		return nil
+	}
+	return roundForecast(m.AbsError / m.Actual * 100)
+}
+
+// bias - среднее смещение прогноза на день (больше нуля - прогноз завышен), шт.
+func (m forecastModel) bias() interface{} {
+	if m.TestedDays == 0 {
+		return nil
+	}
+	return roundForecast(m.Error / float64(m.TestedDays))
+}
+
+// roundForecast округляет прогноз до сотых
+func roundForecast(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,383 @@
+package stat
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Уровни прогноза спроса: товар, размер, склад или размер на складе
+const (
+	ForecastGroupProduct       = "product"
+	ForecastGroupSize          = "size"
+	ForecastGroupWarehouse     = "warehouse"
+	ForecastGroupSizeWarehouse = "size_warehouse"
+)
+
+// ForecastOptions - параметры прогноза. History - дней истории продаж, Window - дней скользящего
+// среднего, Alpha - коэффициент сглаживания, LeadTime - дней до поступления поставки на склад,
+// Horizon - дней, на которые поставка должна покрыть спрос. NmID != 0 - только один товар
+// с прогнозом по дням
+type ForecastOptions struct {
+	Method   string
+	GroupBy  string
+	History  int
+	Window   int
+	Alpha    float64
+	LeadTime int
+	Horizon  int
+	NmID     int64
+	Page     int
+	PageSize int
+}
+
+// forecastKey - ряд прогноза: товар, размер и склад (пустые, если по ним не группируется)
+type forecastKey struct {
+	NmID      int64
+	Size      string
+	Warehouse string
+}
+
+// forecastStock - остаток ряда на складах WB
+type forecastStock struct {
+	Quantity        int
+	InWayFromClient int
+}
+
+// forecastGroupColumns - выражения размера и склада для уровня прогноза (строки wb_stats и wb_stocks)
+func forecastGroupColumns(groupBy, sizeColumn, warehouseColumn string) (string, string) {
+	size, warehouse := "''", "''"
+	if groupBy == ForecastGroupSize || groupBy == ForecastGroupSizeWarehouse {
+		size = "COALESCE(" + sizeColumn + ", '')"
+	}
+	if groupBy == ForecastGroupWarehouse || groupBy == ForecastGroupSizeWarehouse {
+		warehouse = "COALESCE(" + warehouseColumn + ", '')"
+	}
+	return size, warehouse
+}
+
+// GetDemandForecast - прогноз спроса по дневным продажам wb_stats и рекомендация поставки:
+// сколько отгрузить, чтобы после поступления через LeadTime дней хватило на Horizon дней.
+// Доступный остаток - последний снимок wb_stocks с возвратами в пути; без снимка
+// рекомендуется весь спрос горизонта
+func (r *AnalyticsRepository) GetDemandForecast(userID, accountID int, opts ForecastOptions) (map[string]interface{}, error) {
+	// История заканчивается последним днем продаж в загруженных отчетах
+	var lastSale sql.NullTime
+	err := r.db.QueryRow(`
+        SELECT MAX(s.sale_dt)::date
+        FROM wb_stats s
+        WHERE s.user_id = $1
+            AND ($2 = 0 OR s.account_id = $2)
+            AND s.supplier_oper_name IN (1, 7)
+    `, userID, accountID).Scan(&lastSale)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last sale date: %w", err)
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	forecastFrom := today.AddDate(0, 0, 1)
+
+	result := map[string]interface{}{
+		"data":          []map[string]interface{}{},
+		"method":        opts.Method,
+		"group_by":      opts.GroupBy,
+		"history_from":  nil,
+		"history_to":    nil,
+		"forecast_from": forecastFrom.Format("2006-01-02"),
+		"lead_time":     opts.LeadTime,
+		"horizon":       opts.Horizon,
+		"pagination": map[string]interface{}{
+			"current_page": opts.Page,
+			"page_size":    opts.PageSize,
+			"total_items":  0,
+			"total_pages":  0,
+		},
+	}
+	if !lastSale.Valid {
+		result["summary"] = map[string]interface{}{"items": 0, "stock_known": false}
+		return result, nil
+	}
+
+	historyTo := time.Date(lastSale.Time.Year(), lastSale.Time.Month(), lastSale.Time.Day(), 0, 0, 0, 0, time.UTC)
+	historyFrom := historyTo.AddDate(0, 0, 1-opts.History)
+	days := opts.History
+	result["history_from"] = historyFrom.Format("2006-01-02")
+	result["history_to"] = historyTo.Format("2006-01-02")
+
+	sizeColumn, warehouseColumn := forecastGroupColumns(opts.GroupBy, "s.ts_name", "s.office_name")
+	rows, err := r.db.Query(`
+        SELECT
+            s.nm_id,
+            `+sizeColumn+` as size,
+            `+warehouseColumn+` as warehouse,
+            s.sale_dt::date as day,
+            SUM(COALESCE(s.quantity, 0)) as units
+        FROM wb_stats s
+        WHERE s.user_id = $1
+            AND s.sale_dt BETWEEN $2 AND $3
+            AND ($4 = 0 OR s.account_id = $4)
+            AND ($5 = 0 OR s.nm_id = $5)
+            AND s.supplier_oper_name IN (1, 7)
+            AND s.nm_id IS NOT NULL
+            AND s.nm_id != 0
+        GROUP BY 1, 2, 3, 4
+    `, userID, historyFrom.Format("2006-01-02"), historyTo.Format("2006-01-02")+" 23:59:59", accountID, opts.NmID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily sales: %w", err)
+	}
+	defer rows.Close()
+
+	series := make(map[forecastKey][]float64)
+	total := make([]float64, days)
+	for rows.Next() {
+		var key forecastKey
+		var day time.Time
+		var units float64
+		if err := rows.Scan(&key.NmID, &key.Size, &key.Warehouse, &day, &units); err != nil {
+			return nil, fmt.Errorf("failed to scan daily sales: %w", err)
+		}
+
+		i := int(day.Sub(historyFrom).Hours() / 24)
+		if i < 0 || i >= days {
+			continue
+		}
+		if series[key] == nil {
+			series[key] = make([]float64, days)
+		}
+		series[key][i] += units
+		total[i] += units
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	stocks, stockUpdatedAt, err := r.forecastStocks(userID, accountID, opts)
+	if err != nil {
+		return nil, err
+	}
+	stockKnown := stockUpdatedAt.Valid
+
+	// Сезонность дней недели - по суммарным продажам: в рядах отдельных товаров ее не различить
+	season := weekdaySeason(total, historyFrom)
+
+	type forecastItem struct {
+		key         forecastKey
+		model       forecastModel
+		historyDays int
+		sold        float64
+		leadDemand  float64
+		coverDemand float64
+	}
+
+	items := make([]forecastItem, 0, len(series))
+	var absError, actual float64
+	for key, values := range series {
+		// Ряд начинается с первой продажи (новые товары)
+		first := 0
+		for first < len(values) && values[first] == 0 {
+			first++
+		}
+		values = values[first:]
+
+		model := fitForecast(values, historyFrom.AddDate(0, 0, first), opts.Method, opts.Window, opts.Alpha, season)
+		absError += model.AbsError
+		actual += model.Actual
+
+		sold := 0.0
+		for _, value := range values {
+			sold += value
+		}
+
+		items = append(items, forecastItem{
+			key:         key,
+			model:       model,
+			historyDays: len(values),
+			sold:        sold,
+			leadDemand:  model.demand(forecastFrom, opts.LeadTime),
+			coverDemand: model.demand(forecastFrom.AddDate(0, 0, opts.LeadTime), opts.Horizon),
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].coverDemand != items[j].coverDemand {
+			return items[i].coverDemand > items[j].coverDemand
+		}
+		if items[i].key.NmID != items[j].key.NmID {
+			return items[i].key.NmID < items[j].key.NmID
+		}
+		if items[i].key.Size != items[j].key.Size {
+			return items[i].key.Size < items[j].key.Size
+		}
+		return items[i].key.Warehouse < items[j].key.Warehouse
+	})
+
+	// Итоги по всем рядам
+	var totalLead, totalCover float64
+	totalRecommended, totalStock := 0, 0
+	recommendations := make([]int, len(items))
+	for i, item := range items {
+		totalLead += item.leadDemand
+		totalCover += item.coverDemand
+
+		need := item.coverDemand
+		if stockKnown {
+			stock := stocks[item.key]
+			available := float64(stock.Quantity + stock.InWayFromClient)
+			totalStock += stock.Quantity + stock.InWayFromClient
+			need -= math.Max(available-item.leadDemand, 0)
+		}
+		recommendations[i] = int(math.Ceil(math.Max(need, 0) - 1e-9))
+		totalRecommended += recommendations[i]
+	}
+
+	start := (opts.Page - 1) * opts.PageSize
+	end := start + opts.PageSize
+	if start > len(items) {
+		start = len(items)
+	}
+	if end > len(items) {
+		end = len(items)
+	}
+
+	nmIDs := make([]int64, 0, end-start)
+	for _, item := range items[start:end] {
+		nmIDs = append(nmIDs, item.key.NmID)
+	}
+	names, photos, err := r.articleNames(userID, nmIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]map[string]interface{}, 0, end-start)
+	for i := start; i < end; i++ {
+		item := items[i]
+
+		var stock, daysOfStock, projectedStock interface{}
+		if stockKnown {
+			s := stocks[item.key]
+			available := s.Quantity + s.InWayFromClient
+			stock = map[string]interface{}{
+				"quantity":           s.Quantity,
+				"in_way_from_client": s.InWayFromClient,
+				"available":          available,
+			}
+			projectedStock = roundForecast(math.Max(float64(available)-item.leadDemand, 0))
+			if item.model.Level > 0 {
+				daysOfStock = roundForecast(float64(available) / item.model.Level)
+			}
+		}
+
+		row := map[string]interface{}{
+			"nm_id":           item.key.NmID,
+			"name":            names[item.key.NmID],
+			"photo":           photos[item.key.NmID],
+			"tech_size":       item.key.Size,
+			"warehouse":       item.key.Warehouse,
+			"history_days":    item.historyDays,
+			"sold":            item.sold,
+			"daily_demand":    roundForecast(item.model.Level),
+			"lead_demand":     roundForecast(item.leadDemand),
+			"cover_demand":    roundForecast(item.coverDemand),
+			"stock":           stock,
+			"projected_stock": projectedStock,
+			"days_of_stock":   daysOfStock,
+			"recommended":     recommendations[i],
+			"error": map[string]interface{}{
+				"mae":         item.model.mae(),
+				"wape":        item.model.wape(),
+				"bias":        item.model.bias(),
+				"tested_days": item.model.TestedDays,
+			},
+		}
+		if opts.NmID != 0 {
+			row["daily"] = item.model.daily(forecastFrom, opts.LeadTime+opts.Horizon)
+		}
+		data = append(data, row)
+	}
+
+	var wape interface{}
+	if actual > 0 {
+		wape = roundForecast(absError / actual * 100)
+	}
+
+	seasonality := make(map[string]float64, 7)
+	if opts.Method == ForecastMethodSMA {
+		for day, value := range season {
+			seasonality[time.Weekday(day).String()] = roundForecast(value)
+		}
+	}
+
+	result["data"] = data
+	result["seasonality"] = seasonality
+	result["pagination"] = map[string]interface{}{
+		"current_page": opts.Page,
+		"page_size":    opts.PageSize,
+		"total_items":  len(items),
+		"total_pages":  int(math.Ceil(float64(len(items)) / float64(opts.PageSize))),
+	}
+
+	summary := map[string]interface{}{
+		"items":            len(items),
+		"lead_demand":      roundForecast(totalLead),
+		"cover_demand":     roundForecast(totalCover),
+		"recommended":      totalRecommended,
+		"wape":             wape,
+		"stock_known":      stockKnown,
+		"stock_updated_at": nil,
+		"stock":            nil,
+	}
+	if stockKnown {
+		summary["stock_updated_at"] = stockUpdatedAt.Time.Format("2006-01-02 15:04:05")
+		summary["stock"] = totalStock
+	}
+	result["summary"] = summary
+
+	return result, nil
+}
+
+// forecastStocks - остатки из последнего снимка wb_stocks по рядам прогноза и время снимка
+// (NULL - остатки кабинета не загружались)
+func (r *AnalyticsRepository) forecastStocks(userID, accountID int, opts ForecastOptions) (map[forecastKey]forecastStock, sql.NullTime, error) {
+	var updatedAt sql.NullTime
+	err := r.db.QueryRow(`
+        SELECT MAX(updated_at)
+        FROM wb_stocks
+        WHERE user_id = $1 AND ($2 = 0 OR account_id = $2)
+    `, userID, accountID).Scan(&updatedAt)
+	if err != nil {
+		return nil, updatedAt, fmt.Errorf("failed to get stocks date: %w", err)
+	}
+
+	sizeColumn, warehouseColumn := forecastGroupColumns(opts.GroupBy, "st.tech_size", "st.warehouse_name")
+	rows, err := r.db.Query(`
+        SELECT
+            st.nm_id,
+            `+sizeColumn+` as size,
+            `+warehouseColumn+` as warehouse,
+            SUM(st.quantity),
+            SUM(st.in_way_from_client)
+        FROM wb_stocks st
+        WHERE st.user_id = $1
+            AND ($2 = 0 OR st.account_id = $2)
+            AND ($3 = 0 OR st.nm_id = $3)
+        GROUP BY 1, 2, 3
+    `, userID, accountID, opts.NmID)
+	if err != nil {
+		return nil, updatedAt, fmt.Errorf("failed to query stocks: %w", err)
+	}
+	defer rows.Close()
+
+	stocks := make(map[forecastKey]forecastStock)
+	for rows.Next() {
+		var key forecastKey
+		var stock forecastStock
+		if err := rows.Scan(&key.NmID, &key.Size, &key.Warehouse, &stock.Quantity, &stock.InWayFromClient); err != nil {
+			return nil, updatedAt, fmt.Errorf("failed to scan stock: %w", err)
+		}
+		stocks[key] = stock
+	}
+
+	return stocks, updatedAt, rows.Err()
+}
@@ -0,0 +1,145 @@
+package stat
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// forecastStart - понедельник, начало тестовых рядов
+var forecastStart = time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+
+var flatSeason = [7]float64{1, 1, 1, 1, 1, 1, 1}
+
+func constantSeries(days int, value float64) []float64 {
+	series := make([]float64, days)
+	for i := range series {
+		series[i] = value
+	}
+	return series
+}
+
+func TestWeekdaySeason(t *testing.T) {
+	mondays := make([]float64, 14)
+	mondays[0], mondays[7] = 7, 7
+
+	tests := []struct {
+		name   string
+		series []float64
+		want   [7]float64
+	}{
+		{name: "short history", series: []float64{5, 0, 0, 0, 0, 0, 0, 5, 0, 0, 0, 0, 0}, want: flatSeason},
+		{name: "no sales", series: constantSeries(14, 0), want: flatSeason},
+		{name: "constant sales", series: constantSeries(21, 2), want: flatSeason},
+		{
+			name:   "sales on mondays only",
+			series: mondays,
+			want:   [7]float64{time.Monday: 7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := weekdaySeason(tt.series, forecastStart)
+			for day := range got {
+				if math.Abs(got[day]-tt.want[day]) > 1e-9 {
+					t.Fatalf("weekdaySeason() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFitForecast(t *testing.T) {
+	mondaySeason := [7]float64{1, 2, 1, 1, 1, 1, 1}
+
+	tests := []struct {
+		name   string
+		series []float64
+		method string
+		window int
+		alpha  float64
+		season [7]float64
+		want   forecastModel
+	}{
+		{
+			name:   "empty series",
+			method: ForecastMethodSMA, window: 7, season: flatSeason,
+			want: forecastModel{Season: flatSeason},
+		},
+		{
+			name:   "single day",
+			series: []float64{4},
+			method: ForecastMethodSMA, window: 7, season: flatSeason,
+			want: forecastModel{Level: 4, Season: flatSeason},
+		},
+		{
+			name:   "sma constant sales",
+			series: constantSeries(10, 3),
+			method: ForecastMethodSMA, window: 7, season: flatSeason,
+			want: forecastModel{Level: 3, Season: flatSeason, Actual: 15, TestedDays: 5},
+		},
+		{
+			name:   "sma growing sales",
+			series: []float64{1, 3, 5, 7},
+			method: ForecastMethodSMA, window: 2, season: flatSeason,
+			want: forecastModel{Level: 6, Season: flatSeason, AbsError: 6, Error: -6, Actual: 12, TestedDays: 2},
+		},
+		{
+			name:   "sma removes season from level",
+			series: []float64{4, 2},
+			method: ForecastMethodSMA, window: 7, season: mondaySeason,
+			want: forecastModel{Level: 2, Season: mondaySeason, Actual: 2, TestedDays: 1},
+		},
+		{
+			name:   "ses ignores season",
+			series: []float64{2, 4, 6},
+			method: ForecastMethodSES, window: 7, alpha: 0.5, season: mondaySeason,
+			want: forecastModel{Level: 4.5, Season: flatSeason, AbsError: 5, Error: -5, Actual: 10, TestedDays: 2},
+		},
+		{
+			name:   "ses alpha one follows last day",
+			series: []float64{2, 4, 6},
+			method: ForecastMethodSES, window: 7, alpha: 1, season: flatSeason,
+			want: forecastModel{Level: 6, Season: flatSeason, AbsError: 4, Error: -4, Actual: 10, TestedDays: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fitForecast(tt.series, forecastStart, tt.method, tt.window, tt.alpha, tt.season)
+			if got.Season != tt.want.Season || got.TestedDays != tt.want.TestedDays ||
+				math.Abs(got.Level-tt.want.Level) > 1e-9 || math.Abs(got.AbsError-tt.want.AbsError) > 1e-9 ||
+				math.Abs(got.Error-tt.want.Error) > 1e-9 || math.Abs(got.Actual-tt.want.Actual) > 1e-9 {
+				t.Errorf("fitForecast() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForecastModelMetrics(t *testing.T) {
+	model := forecastModel{Level: 2, Season: [7]float64{1, 2, 1, 1, 1, 1, 1}, AbsError: 6, Error: -6, Actual: 12, TestedDays: 2}
+
+	if got := model.demand(forecastStart, 7); math.Abs(got-16) > 1e-9 {
+		t.Errorf("demand() = %v, want 16", got)
+	}
+	if got := model.mae(); got != 3.0 {
+		t.Errorf("mae() = %v, want 3", got)
+	}
+	if got := model.wape(); got != 50.0 {
+		t.Errorf("wape() = %v, want 50", got)
+	}
+	if got := model.bias(); got != -3.0 {
+		t.Errorf("bias() = %v, want -3", got)
+	}
+
+	points := model.daily(forecastStart, 2)
+	if len(points) != 2 || points[0]["date"] != "2025-03-03" || points[0]["demand"] != 4.0 || points[1]["demand"] != 2.0 {
+		t.Errorf("daily() = %v", points)
+	}
+
+	var empty forecastModel
+	if empty.mae() != nil || empty.wape() != nil || empty.bias() != nil {
+		t.Errorf("metrics without tested days = %v, %v, %v, want nil", empty.mae(), empty.wape(), empty.bias())
+	}
+}
@@ -34,6 +34,15 @@ func getNullTime(nt sql.NullTime) interface{} {
 	}
 	return nil
 }
+
+// formatDate - дата YYYY-MM-DD или nil
+func formatDate(t sql.NullTime) interface{} {
+	if !t.Valid {
+		return nil
+	}
+	return t.Time.Format("2006-01-02")
+}
+
 func getStringValue(ns sql.NullString) string {
 	if ns.Valid {
 		return ns.String
@@ -0,0 +1,263 @@
+package stat
+
+import (
+	"fmt"
+	"math"
+)
+
+// Интервалы динамики стоимости логистики
+const (
+	LogisticsIntervalDay   = "day"
+	LogisticsIntervalWeek  = "week"
+	LogisticsIntervalMonth = "month"
+)
+
+// LogisticsOptions - параметры отчета по логистике. MaxShare - допустимая доля логистики
+// на проданную единицу в средней цене товара, %: товары выше нее попадают в отчет
+type LogisticsOptions struct {
+	Interval string
+	MaxShare float64
+	NmID     int64
+	Page     int
+	PageSize int
+}
+
+// logisticsTotals - логистика строк отчета: доставки покупателям и обратные доставки (количество
+// в строках логистики), стоимость логистики, из нее обратной (строки с обратной доставкой),
+// возмещение издержек по перевозке и проданные единицы
+const logisticsTotals = `
+            SUM(CASE WHEN s.supplier_oper_name = 3 THEN COALESCE(s.delivery_amount, 0) ELSE 0 END) as deliveries,
+            SUM(CASE WHEN s.supplier_oper_name = 3 THEN COALESCE(s.return_amount, 0) ELSE 0 END) as reverse_deliveries,
+            SUM(COALESCE(s.delivery_rub, 0)) as logistics,
+            SUM(CASE WHEN s.supplier_oper_name = 3 AND COALESCE(s.return_amount, 0) > 0 THEN COALESCE(s.delivery_rub, 0) ELSE 0 END) as reverse_logistics,
+            SUM(COALESCE(s.rebill_logistic_cost, 0)) as rebill_logistic_cost,
+            SUM(CASE WHEN s.supplier_oper_name IN (1, 7) THEN COALESCE(s.quantity, 0) ELSE 0 END) as sales`
+
+// logisticsRow - итоги группы отчета по логистике
+type logisticsRow struct {
+	Deliveries        int
+	ReverseDeliveries int
+	Logistics         float64
+	ReverseLogistics  float64
+	Rebill            float64
+	Sales             int
+}
+
+// values - показатели группы: стоимость доставки покупателю на единицу, логистика
+// с возмещением издержек на проданную единицу и доля обратной логистики
+func (row logisticsRow) values() map[string]interface{} {
+	total := row.Logistics + row.Rebill
+
+	var perDelivery, perReverse, perUnit, reverseShare interface{}
+	if row.Deliveries > 0 {
+		perDelivery = (row.Logistics - row.ReverseLogistics) / float64(row.Deliveries)
+	}
+	if row.ReverseDeliveries > 0 {
+		perReverse = row.ReverseLogistics / float64(row.ReverseDeliveries)
+	}
+	if row.Sales > 0 {
+		perUnit = total / float64(row.Sales)
+	}
+	if row.Logistics != 0 {
+		reverseShare = row.ReverseLogistics / row.Logistics * 100
+	}
+
+	return map[string]interface{}{
+		"deliveries":           row.Deliveries,
+		"reverse_deliveries":   row.ReverseDeliveries,
+		"sales":                row.Sales,
+		"logistics":            row.Logistics,
+		"reverse_logistics":    row.ReverseLogistics,
+		"rebill_logistic_cost": row.Rebill,
+		"total":                total,
+		"per_delivery":         perDelivery,
+		"per_reverse":          perReverse,
+		"per_unit":             perUnit,
+		"reverse_share":        reverseShare,
+	}
+}
+
+// GetLogisticsAnalytics - стоимость логистики за период: итоги, разбивка по складам отгрузки
+// и типам коробов, динамика по интервалам и товары, логистика которых на проданную единицу
+// превышает MaxShare процентов средней цены продажи
+func (r *AnalyticsRepository) GetLogisticsAnalytics(userID, accountID int, dateFrom, dateTo string, opts LogisticsOptions) (map[string]interface{}, error) {
+	summary, err := r.logisticsGroups(userID, accountID, dateFrom, dateTo, opts.NmID, "''", "1")
+	if err != nil {
+		return nil, err
+	}
+	warehouses, err := r.logisticsGroups(userID, accountID, dateFrom, dateTo, opts.NmID, "COALESCE(NULLIF(s.office_name, ''), 'Не указан')", "total DESC")
+	if err != nil {
+		return nil, err
+	}
+	boxTypes, err := r.logisticsGroups(userID, accountID, dateFrom, dateTo, opts.NmID, "COALESCE(NULLIF(s.gi_box_type_name, ''), 'Не указан')", "total DESC")
+	if err != nil {
+		return nil, err
+	}
+	trend, err := r.logisticsGroups(userID, accountID, dateFrom, dateTo, opts.NmID,
+		"to_char(date_trunc('"+opts.Interval+"', s.sale_dt), 'YYYY-MM-DD')", "group_key")
+	if err != nil {
+		return nil, err
+	}
+
+	products, totalCount, err := r.logisticsProducts(userID, accountID, dateFrom, dateTo, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := logisticsRow{}.values()
+	if len(summary) > 0 {
+		totals = summary[0]
+		delete(totals, "key")
+	}
+
+	renameKey := func(groups []map[string]interface{}, name string) {
+		for _, group := range groups {
+			group[name] = group["key"]
+			delete(group, "key")
+		}
+	}
+	renameKey(warehouses, "warehouse")
+	renameKey(boxTypes, "box_type")
+	renameKey(trend, "period")
+
+	return map[string]interface{}{
+		"summary":    totals,
+		"warehouses": warehouses,
+		"box_types":  boxTypes,
+		"trend":      trend,
+		"interval":   opts.Interval,
+		"max_share":  opts.MaxShare,
+		"products":   products,
+		"pagination": map[string]interface{}{
+			"current_page": opts.Page,
+			"page_size":    opts.PageSize,
+			"total_items":  totalCount,
+			"total_pages":  int(math.Ceil(float64(totalCount) / float64(opts.PageSize))),
+		},
+	}, nil
+}
+
+// logisticsGroups - итоги логистики с группировкой по выражению groupBy (ключ - его текстовое значение)
+func (r *AnalyticsRepository) logisticsGroups(userID, accountID int, dateFrom, dateTo string, nmID int64, groupBy, order string) ([]map[string]interface{}, error) {
+	query := `
+        WITH grouped AS (
+            SELECT
+                (` + groupBy + `)::text as group_key,` + logisticsTotals + `
+            FROM wb_stats s
+            WHERE s.user_id = $1
+                AND s.sale_dt BETWEEN $2 AND $3
+                AND ($4 = 0 OR s.account_id = $4)
+                AND ($5 = 0 OR s.nm_id = $5)
+            GROUP BY 1
+        )
+        SELECT g.*, g.logistics + g.rebill_logistic_cost as total
+        FROM grouped g
+        WHERE g.deliveries > 0 OR g.reverse_deliveries > 0 OR g.logistics != 0 OR g.rebill_logistic_cost != 0
+        ORDER BY ` + order + `
+    `
+
+	rows, err := r.db.Query(query, userID, dateFrom, dateTo+" 23:59:59", accountID, nmID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query logistics: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []map[string]interface{}{}
+	for rows.Next() {
+		var key string
+		var row logisticsRow
+		var total float64
+		if err := rows.Scan(&key, &row.Deliveries, &row.ReverseDeliveries, &row.Logistics, &row.ReverseLogistics,
+			&row.Rebill, &row.Sales, &total); err != nil {
+			return nil, fmt.Errorf("failed to scan logistics: %w", err)
+		}
+
+		group := row.values()
+		group["key"] = key
+		groups = append(groups, group)
+	}
+
+	return groups, rows.Err()
+}
+
+// logisticsProducts - товары с проданными единицами, логистика которых на проданную единицу
+// больше MaxShare процентов средней цены продажи (retail_amount на единицу), по убыванию доли
+func (r *AnalyticsRepository) logisticsProducts(userID, accountID int, dateFrom, dateTo string, opts LogisticsOptions) ([]map[string]interface{}, int, error) {
+	query := `
+        WITH products AS (
+            SELECT
+                s.nm_id,` + logisticsTotals + `,
+                SUM(CASE WHEN s.supplier_oper_name IN (1, 7) THEN COALESCE(s.retail_amount, 0) ELSE 0 END) as sales_amount
+            FROM wb_stats s
+            WHERE s.user_id = $1
+                AND s.sale_dt BETWEEN $2 AND $3
+                AND ($4 = 0 OR s.account_id = $4)
+                AND ($5 = 0 OR s.nm_id = $5)
+                AND s.nm_id IS NOT NULL
+                AND s.nm_id != 0
+            GROUP BY s.nm_id
+        ),
+        shares AS (
+            SELECT
+                p.*,
+                p.sales_amount / NULLIF(p.sales, 0) as avg_price,
+                (p.logistics + p.rebill_logistic_cost) / NULLIF(p.sales, 0) as per_unit
+            FROM products p
+            WHERE p.sales > 0 AND p.sales_amount > 0
+        )
+        SELECT
+            sh.nm_id, sh.deliveries, sh.reverse_deliveries, sh.logistics, sh.reverse_logistics,
+            sh.rebill_logistic_cost, sh.sales, sh.sales_amount, sh.avg_price,
+            sh.per_unit / NULLIF(sh.avg_price, 0) * 100 as price_share,
+            COUNT(*) OVER() as total_count
+        FROM shares sh
+        WHERE sh.per_unit / NULLIF(sh.avg_price, 0) * 100 > $6
+        ORDER BY price_share DESC, sh.nm_id
+        LIMIT $7 OFFSET $8
+    `
+
+	rows, err := r.db.Query(query, userID, dateFrom, dateTo+" 23:59:59", accountID, opts.NmID, opts.MaxShare,
+		opts.PageSize, (opts.Page-1)*opts.PageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query logistics by product: %w", err)
+	}
+	defer rows.Close()
+
+	var products []map[string]interface{}
+	var nmIDs []int64
+	totalCount := 0
+	for rows.Next() {
+		var nmID int64
+		var row logisticsRow
+		var salesAmount, avgPrice, priceShare float64
+		if err := rows.Scan(&nmID, &row.Deliveries, &row.ReverseDeliveries, &row.Logistics, &row.ReverseLogistics,
+			&row.Rebill, &row.Sales, &salesAmount, &avgPrice, &priceShare, &totalCount); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan logistics by product: %w", err)
+		}
+
+		product := row.values()
+		product["nm_id"] = nmID
+		product["sales_amount"] = salesAmount
+		product["avg_price"] = avgPrice
+		product["price_share"] = priceShare
+		products = append(products, product)
+		nmIDs = append(nmIDs, nmID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	names, photos, err := r.articleNames(userID, nmIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i, product := range products {
+		product["name"] = names[nmIDs[i]]
+		product["photo"] = photos[nmIDs[i]]
+	}
+
+	if products == nil {
+		products = []map[string]interface{}{}
+	}
+	return products, totalCount, nil
+}
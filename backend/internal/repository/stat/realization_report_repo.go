@@ -0,0 +1,232 @@
+package stat
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+)
+
+// ReportPayoutTolerance - допустимое расхождение суммы к перечислению при сверке (округление WB), руб.
+const ReportPayoutTolerance = 1.0
+
+// Статусы сверки отчета реализации
+const (
+	ReportStatusUnchecked = "unchecked" // Сумма WB не внесена, проблем в строках нет
+	ReportStatusOK        = "ok"        // Сумма по строкам совпадает с суммой WB
+	ReportStatusMismatch  = "mismatch"  // Расхождение с суммой WB или проблемы в строках
+)
+
+// realizationReportTotals - итоги отчета реализации по строкам wb_stats.
+// "Итого к оплате" как у WB: к перечислению за продажи и компенсации минус к перечислению
+// за возвраты, минус логистика, возмещение издержек по перевозке, хранение, штрафы,
+// удержания и платная приемка, плюс доплаты
+const realizationReportTotals = `
+            COUNT(*) as rows_count,
+            COUNT(DISTINCT s.account_id) as accounts,
+            MIN(s.rr_dt)::date as date_from,
+            MAX(s.rr_dt)::date as date_to,
+            SUM(CASE WHEN s.supplier_oper_name IN (1, 7) THEN COALESCE(s.quantity, 0) ELSE 0 END) as sales_count,
+            SUM(CASE WHEN s.supplier_oper_name IN (1, 7) THEN COALESCE(s.retail_amount, 0) ELSE 0 END) as sales_amount,
+            SUM(CASE WHEN s.supplier_oper_name IN (1, 7) THEN COALESCE(s.ppvz_for_pay, 0) ELSE 0 END) as sales_payout,
+            SUM(CASE WHEN s.supplier_oper_name = 2 THEN COALESCE(s.return_amount, 0) ELSE 0 END) as returns_count,
+            SUM(CASE WHEN s.supplier_oper_name = 2 THEN COALESCE(s.retail_amount, 0) ELSE 0 END) as returns_amount,
+            SUM(CASE WHEN s.supplier_oper_name = 2 THEN COALESCE(s.ppvz_for_pay, 0) ELSE 0 END) as returns_payout,
+            SUM(CASE WHEN s.supplier_oper_name NOT IN (1, 2, 7) THEN COALESCE(s.ppvz_for_pay, 0) ELSE 0 END) as other_payout,
+            SUM(COALESCE(s.delivery_rub, 0)) as logistics,
+            SUM(COALESCE(s.rebill_logistic_cost, 0)) as rebill_logistic_cost,
+            SUM(COALESCE(s.storage_fee, 0)) as storage_fee,
+            SUM(COALESCE(s.penalty, 0)) as penalty,
+            SUM(COALESCE(s.deduction, 0)) as deduction,
+            SUM(COALESCE(s.acceptance, 0)) as acceptance,
+            SUM(COALESCE(s.additional_payment, 0)) as additional_payment`
+
+// GetRealizationReports - отчеты реализации WB, строки которых попадают в период (по rr_dt),
+// с итогами по всем строкам отчета и результатом сверки. mismatchOnly - только отчеты с расхождениями.
+// Возвращает страницу отчетов (новые первыми) и общее количество
+func (r *StatRepository) GetRealizationReports(userID, accountID int, dateFrom, dateTo string, mismatchOnly bool, page, pageSize int) ([]map[string]interface{}, int, error) {
+	query := `
+        WITH report_ids AS (
+            SELECT DISTINCT s.realizationreport_id
+            FROM wb_stats s
+            WHERE s.user_id = $1
+                AND s.rr_dt BETWEEN $2 AND $3
+                AND ($4 = 0 OR s.account_id = $4)
+                AND s.realizationreport_id IS NOT NULL
+        ),
+        reports AS (
+            SELECT
+                s.realizationreport_id,
+                MIN(s.account_id) as account_id,` + realizationReportTotals + `
+            FROM wb_stats s
+            JOIN report_ids ri ON ri.realizationreport_id = s.realizationreport_id
+            WHERE s.user_id = $1
+            GROUP BY s.realizationreport_id
+        ),
+        duplicates AS (
+            SELECT d.realizationreport_id, COUNT(*) as duplicate_rows
+            FROM (
+                SELECT s.realizationreport_id, s.rrd_id
+                FROM wb_stats s
+                JOIN report_ids ri ON ri.realizationreport_id = s.realizationreport_id
+                WHERE s.user_id = $1 AND s.rrd_id IS NOT NULL
+                GROUP BY s.realizationreport_id, s.rrd_id
+                HAVING COUNT(*) > 1
+            ) d
+            GROUP BY d.realizationreport_id
+        ),
+        checked AS (
+            SELECT
+                rp.*,
+                rp.sales_payout - rp.returns_payout + rp.other_payout
+                    - rp.logistics - rp.rebill_logistic_cost - rp.storage_fee - rp.penalty
+                    - rp.deduction - rp.acceptance + rp.additional_payment as payout,
+                COALESCE(d.duplicate_rows, 0) as duplicate_rows,
+                wr.expected_payout,
+                wr.comment,
+                wr.updated_at as expected_updated_at
+            FROM reports rp
+            LEFT JOIN duplicates d ON d.realizationreport_id = rp.realizationreport_id
+            LEFT JOIN wb_realization_reports wr ON wr.user_id = $1 AND wr.realizationreport_id = rp.realizationreport_id
+        )
+        SELECT
+            c.realizationreport_id, c.account_id, c.rows_count, c.accounts, c.date_from, c.date_to,
+            c.sales_count, c.sales_amount, c.sales_payout,
+            c.returns_count, c.returns_amount, c.returns_payout, c.other_payout,
+            c.logistics, c.rebill_logistic_cost, c.storage_fee, c.penalty, c.deduction, c.acceptance,
+            c.additional_payment, c.payout, c.duplicate_rows,
+            c.expected_payout, c.comment, c.expected_updated_at,
+            COUNT(*) OVER() as total_count
+        FROM checked c
+        WHERE NOT $5
+            OR c.duplicate_rows > 0
+            OR c.accounts > 1
+            OR ABS(c.payout - c.expected_payout) > $6
+        ORDER BY c.date_to DESC NULLS LAST, c.realizationreport_id DESC
+        LIMIT $7 OFFSET $8
+    `
+
+	rows, err := r.db.Query(query, userID, dateFrom, dateTo+" 23:59:59", accountID, mismatchOnly,
+		ReportPayoutTolerance, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query realization reports: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []map[string]interface{}
+	totalCount := 0
+	for rows.Next() {
+		var reportID int64
+		var accountIDValue sql.NullInt64
+		var rowsCount, accounts, salesCount, returnsCount, duplicateRows int
+		var dateFromValue, dateToValue sql.NullTime
+		var salesAmount, salesPayout, returnsAmount, returnsPayout, otherPayout float64
+		var logistics, rebill, storage, penalty, deduction, acceptance, additional, payout float64
+		var expectedPayout sql.NullFloat64
+		var comment sql.NullString
+		var expectedUpdatedAt sql.NullTime
+
+		if err := rows.Scan(
+			&reportID, &accountIDValue, &rowsCount, &accounts, &dateFromValue, &dateToValue,
+			&salesCount, &salesAmount, &salesPayout,
+			&returnsCount, &returnsAmount, &returnsPayout, &otherPayout,
+			&logistics, &rebill, &storage, &penalty, &deduction, &acceptance,
+			&additional, &payout, &duplicateRows,
+			&expectedPayout, &comment, &expectedUpdatedAt,
+			&totalCount,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan realization report: %w", err)
+		}
+
+		// Проблемы сверки
+		issues := []string{}
+		var difference interface{}
+		if expectedPayout.Valid {
+			diff := payout - expectedPayout.Float64
+			difference = diff
+			if math.Abs(diff) > ReportPayoutTolerance {
+				issues = append(issues, fmt.Sprintf("Сумма к перечислению по строкам отличается от суммы WB на %.2f", diff))
+			}
+		}
+		if duplicateRows > 0 {
+			issues = append(issues, fmt.Sprintf("Повторяющиеся строки отчета (rrd_id): %d", duplicateRows))
+		}
+		if accounts > 1 {
+			issues = append(issues, "Строки отчета относятся к разным кабинетам")
+		}
+
+		status := ReportStatusUnchecked
+		switch {
+		case len(issues) > 0:
+			status = ReportStatusMismatch
+		case expectedPayout.Valid:
+			status = ReportStatusOK
+		}
+
+		var expectedUpdated interface{}
+		if expectedUpdatedAt.Valid {
+			expectedUpdated = expectedUpdatedAt.Time.Format("2006-01-02 15:04:05")
+		}
+
+		reports = append(reports, map[string]interface{}{
+			"realizationreport_id": reportID,
+			"account_id":           getIntValue(accountIDValue),
+			"date_from":            formatDate(dateFromValue),
+			"date_to":              formatDate(dateToValue),
+			"rows_count":           rowsCount,
+			"sales_count":          salesCount,
+			"sales_amount":         salesAmount,
+			"sales_payout":         salesPayout,
+			"returns_count":        returnsCount,
+			"returns_amount":       returnsAmount,
+			"returns_payout":       returnsPayout,
+			"other_payout":         otherPayout,
+			"logistics":            logistics,
+			"rebill_logistic_cost": rebill,
+			"storage_fee":          storage,
+			"penalty":              penalty,
+			"deduction":            deduction,
+			"acceptance":           acceptance,
+			"additional_payment":   additional,
+			"payout":               payout,
+			"expected_payout":      getNullFloat64(expectedPayout),
+			"difference":           difference,
+			"comment":              getStringValue(comment),
+			"expected_updated_at":  expectedUpdated,
+			"duplicate_rows":       duplicateRows,
+			"status":               status,
+			"issues":               issues,
+		})
+	}
+
+	return reports, totalCount, rows.Err()
+}
+
+// ReportExists проверяет, что у пользователя есть строки отчета реализации
+func (r *StatRepository) ReportExists(userID int, reportID int64) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM wb_stats WHERE user_id = $1 AND realizationreport_id = $2)
+	`, userID, reportID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check realization report: %w", err)
+	}
+	return exists, nil
+}
+
+// SaveExpectedPayout сохраняет сумму "Итого к оплате" по данным WB для сверки отчета
+// (expected = NULL - сумма не указана)
+func (r *StatRepository) SaveExpectedPayout(userID int, reportID int64, expected sql.NullFloat64, comment string, updatedBy int) error {
+	_, err := r.db.Exec(`
+		INSERT INTO wb_realization_reports (user_id, realizationreport_id, expected_payout, comment, updated_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, realizationreport_id) DO UPDATE
+		SET expected_payout = EXCLUDED.expected_payout,
+		    comment = EXCLUDED.comment,
+		    updated_by = EXCLUDED.updated_by,
+		    updated_at = CURRENT_TIMESTAMP
+	`, userID, reportID, getNullFloat64(expected), sql.NullString{String: comment, Valid: comment != ""}, updatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to save expected payout: %w", err)
+	}
+	return nil
+}
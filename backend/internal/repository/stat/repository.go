@@ -2,8 +2,12 @@ package stat
 
 import (
 	"fmt"
+	"strings"
+	"time"
 	"wbrost-go/internal/entity"
 	"wbrost-go/internal/repository/database/postgres"
+
+	"github.com/lib/pq"
 )
 
 type StatRepository struct {
@@ -11,62 +15,53 @@ type StatRepository struct {
 }
 
 //type StatRepositoryInterface interface {
-//	Create(stat *entity.Stat) error
+//	InsertBatch(stats []*entity.Stat) (int, error)
 //	GetStatDetails(userID int, dateFrom, dateTo string, page, pageSize int) ([]map[string]interface{}, error)
 //	GetStatDetailsCount(userID int, dateFrom, dateTo string) (int, error)
 //	GetStatSummary(userID int, dateFrom, dateTo string) (map[string]interface{}, error)
-//	ExistsByHash(hash string) (bool, error)
 //}
 
 func NewStatRepository(db *postgres.PostgresDB) *StatRepository {
 	return &StatRepository{db: db}
 }
 
-// Create создает новую запись статистики в таблицу stat
-func (r *StatRepository) Create(stat *entity.Stat) error {
-	query := `
-		INSERT INTO wb_stats (
-			hash_info, user_id, nm_id, ppvz_for_pay, supplier_oper_name,
-			delivery_rub, penalty, additional_payment, storage_fee,
-			rebill_logistic_cost, acquiring_fee, acquiring_percent,
-			ppvz_sales_commission, deduction, ppvz_spp_prc, ppvz_kvw_prc_base,
-			ppvz_kvw_prc, acceptance, dlv_prc, created_at, rr_dt,
-			shk_id, sticker_id, gi_id, realizationreport_id, barcode,
-			bonus_type_name, last_error, brand_name, ppvz_office_id,
-			assembly_id, sa_name, ppvz_vw_nds, ppvz_vw, gi_box_type_name,
-			subject_name, ts_name, quantity, retail_price, retail_amount,
-			commission_percent, office_name, order_dt, sale_dt,
-			delivery_amount, return_amount, report_type, srid, rid
-		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
-			$11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
-			$21, $22, $23, $24, $25, $26, $27, $28, $29, $30,
-			$31, $32, $33, $34, $35, $36, $37, $38, $39, $40,
-			$41, $42, $43, $44, $45, $46, $47, $48, $49
-		)
-		RETURNING id
-	`
+// statColumns - колонки wb_stats, которые заполняются из отчета (порядок как в statValues)
+var statColumns = []string{
+	"hash_info", "user_id", "nm_id", "ppvz_for_pay", "supplier_oper_name",
+	"delivery_rub", "penalty", "additional_payment", "storage_fee",
+	"rebill_logistic_cost", "acquiring_fee", "acquiring_percent",
+	"ppvz_sales_commission", "deduction", "ppvz_spp_prc", "ppvz_kvw_prc_base",
+	"ppvz_kvw_prc", "acceptance", "dlv_prc", "created_at", "rr_dt",
+	"shk_id", "sticker_id", "gi_id", "realizationreport_id", "barcode",
+	"bonus_type_name", "last_error", "brand_name", "ppvz_office_id",
+	"assembly_id", "sa_name", "ppvz_vw_nds", "ppvz_vw", "gi_box_type_name",
+	"subject_name", "ts_name", "quantity", "retail_price", "retail_amount",
+	"commission_percent", "office_name", "order_dt", "sale_dt",
+	"delivery_amount", "return_amount", "report_type", "srid", "rid",
+	"account_id", "rrd_id",
+}
 
-	err := r.db.QueryRow(query,
+func statValues(stat *entity.Stat) []interface{} {
+	return []interface{}{
 		// 1-10
 		stat.HashInfo, // <-- HashInfo это string, передаем напрямую
 		stat.UserID,
 		getNullInt64(stat.Nmid),
-		getNullString(stat.PpvzForPay),
+		getNullFloat64(stat.PpvzForPay),
 		getNullInt64(stat.SupplierOperName),
 		getNullFloat64(stat.DeliveryRub),
 		getNullFloat64(stat.Penalty),
 		getNullFloat64(stat.AdditionalPayment),
 		getNullFloat64(stat.StorageFee),
-		getNullString(stat.RebillLogisticCost),
+		getNullFloat64(stat.RebillLogisticCost),
 		// 11-20
 		getNullFloat64(stat.AcquiringFee),
 		getNullFloat64(stat.AcquiringPercent),
 		getNullFloat64(stat.PpvzSalesCommission),
 		getNullFloat64(stat.Deduction),
-		getNullString(stat.PpvzSppPrc),
-		getNullString(stat.PpvzKvwPrcBase),
-		getNullString(stat.PpvzKvwPrc),
+		getNullFloat64(stat.PpvzSppPrc),
+		getNullFloat64(stat.PpvzKvwPrcBase),
+		getNullFloat64(stat.PpvzKvwPrc),
 		getNullFloat64(stat.Acceptance),
 		getNullFloat64(stat.DlvPrc),
 		stat.CreatedAt,
@@ -84,15 +79,15 @@ func (r *StatRepository) Create(stat *entity.Stat) error {
 		// 31-40
 		getNullInt64(stat.AssemblyID),
 		getNullString(stat.SaName),
-		getNullString(stat.PpvzVwNds),
-		getNullString(stat.PpvzVw),
+		getNullFloat64(stat.PpvzVwNds),
+		getNullFloat64(stat.PpvzVw),
 		getNullString(stat.GiBoxTypeName),
 		getNullString(stat.SubjectName),
 		getNullString(stat.TsName),
 		getNullInt64(stat.Quantity),
 		getNullFloat64(stat.RetailPrice),
 		getNullFloat64(stat.RetailAmount),
-		// 41-50
+		// 41-51
 		getNullFloat64(stat.CommissionPercent),
 		getNullString(stat.OfficeName),
 		getNullTime(stat.OrderDt),
@@ -102,24 +97,107 @@ func (r *StatRepository) Create(stat *entity.Stat) error {
 		getNullInt64(stat.ReportType),
 		getNullString(stat.Srid),
 		getNullInt64(stat.Rid),
-	).Scan(&stat.ID)
+		getNullInt64(stat.AccountID),
+		getNullInt64(stat.RrdID),
+	}
+}
+
+// InsertBatch сохраняет пачку строк отчета: COPY во временную таблицу и один INSERT ... SELECT.
+// Дубли пропускает уникальный индекс (кабинет + rrd_id, для строк без rrd_id - хеш),
+// строки, загруженные до появления rrd_id, сверяются по хешу.
+// Возвращает количество вставленных строк
+func (r *StatRepository) InsertBatch(stats []*entity.Stat) (int, error) {
+	if len(stats) == 0 {
+		return 0, nil
+	}
 
+	// Секции месяцев пачки создаем заранее, иначе строки уйдут в default-секцию
+	var from, to time.Time
+	for _, stat := range stats {
+		if !stat.SaleDt.Valid {
+			continue
+		}
+		if from.IsZero() || stat.SaleDt.Time.Before(from) {
+			from = stat.SaleDt.Time
+		}
+		if to.IsZero() || stat.SaleDt.Time.After(to) {
+			to = stat.SaleDt.Time
+		}
+	}
+	if !from.IsZero() {
+		if err := r.EnsurePartitions(from, to); err != nil {
+			return 0, err
+		}
+	}
+
+	tx, err := r.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to create stat: %w", err)
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	return nil
-}
+	if _, err := tx.Exec(`CREATE TEMP TABLE wb_stats_staging (LIKE wb_stats INCLUDING DEFAULTS) ON COMMIT DROP`); err != nil {
+		return 0, fmt.Errorf("failed to create staging table: %w", err)
+	}
+
+	stmt, err := tx.Prepare(pq.CopyIn("wb_stats_staging", statColumns...))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare copy: %w", err)
+	}
 
-// ExistsByHash проверяет существует ли запись с таким хешем в таблице stat
-func (r *StatRepository) ExistsByHash(hash string) (bool, error) {
-	query := `SELECT COUNT(*) FROM wb_stats WHERE hash_info = $1`
+	for _, stat := range stats {
+		if _, err := stmt.Exec(statValues(stat)...); err != nil {
+			stmt.Close()
+			return 0, fmt.Errorf("failed to copy stat: %w", err)
+		}
+	}
+
+	if _, err := stmt.Exec(); err != nil {
+		stmt.Close()
+		return 0, fmt.Errorf("failed to flush copy: %w", err)
+	}
+	if err := stmt.Close(); err != nil {
+		return 0, fmt.Errorf("failed to close copy: %w", err)
+	}
+
+	columns := strings.Join(statColumns, ", ")
+	query := `
+		INSERT INTO wb_stats (` + columns + `)
+		SELECT ` + columns + `
+		FROM wb_stats_staging s
+		WHERE NOT EXISTS (
+			SELECT 1 FROM wb_stats w
+			WHERE w.user_id = s.user_id
+			  AND w.hash_info = s.hash_info
+			  AND w.rrd_id IS NULL
+		)
+		ON CONFLICT DO NOTHING
+	`
+
+	result, err := tx.Exec(query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert stats: %w", err)
+	}
 
-	var count int
-	err := r.db.QueryRow(query, hash).Scan(&count)
+	inserted, err := result.RowsAffected()
 	if err != nil {
-		return false, fmt.Errorf("failed to check hash existence: %w", err)
+		return 0, err
 	}
 
-	return count > 0, nil
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit stats: %w", err)
+	}
+
+	return int(inserted), nil
+}
+
+// EnsurePartitions создает помесячные секции wb_stats для всех месяцев периода (включительно)
+func (r *StatRepository) EnsurePartitions(from, to time.Time) error {
+	var months int
+	err := r.db.QueryRow(`SELECT create_wb_stats_partitions($1, $2)`,
+		from.Format("2006-01-02"), to.Format("2006-01-02")).Scan(&months)
+	if err != nil {
+		return fmt.Errorf("failed to create wb_stats partitions: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,305 @@
+package stat
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Группировки аналитики возвратов
+const (
+	ReturnsGroupProduct   = "product"
+	ReturnsGroupSize      = "size"
+	ReturnsGroupWarehouse = "warehouse"
+	ReturnsGroupWeek      = "week"
+)
+
+// ReturnsOptions - параметры аналитики возвратов. BaselineWeeks - недель перед периодом для базовой
+// доли возвратов товара, JumpPoints - рост доли возвратов против базовой в процентных пунктах,
+// начиная с которого товар попадает в скачки, MinSales - минимум продаж в периоде и в базе
+type ReturnsOptions struct {
+	GroupBy       string
+	NmID          int64
+	BaselineWeeks int
+	JumpPoints    float64
+	MinSales      int
+	Page          int
+	PageSize      int
+}
+
+// returnsGroupColumns - nm_id и подпись строки для группировки
+var returnsGroupColumns = map[string][2]string{
+	ReturnsGroupProduct:   {"s.nm_id", "''"},
+	ReturnsGroupSize:      {"s.nm_id", "COALESCE(s.ts_name, '')"},
+	ReturnsGroupWarehouse: {"0", "COALESCE(s.office_name, '')"},
+	ReturnsGroupWeek:      {"0", "to_char(date_trunc('week', s.sale_dt), 'YYYY-MM-DD')"},
+}
+
+// returnsTotals - продажи, возвраты и логистика строк отчета. Доставки и обратные доставки -
+// количество в строках логистики (delivery_amount и return_amount), обратная логистика -
+// стоимость строк логистики с обратной доставкой (возвраты и невыкупы)
+const returnsTotals = `
+            SUM(CASE WHEN s.supplier_oper_name IN (1, 7) THEN COALESCE(s.quantity, 0) ELSE 0 END) as sales,
+            SUM(CASE WHEN s.supplier_oper_name = 2 THEN COALESCE(s.return_amount, 0) ELSE 0 END) as returns,
+            SUM(CASE WHEN s.supplier_oper_name IN (1, 7) THEN COALESCE(s.retail_amount, 0) ELSE 0 END) as sales_amount,
+            SUM(CASE WHEN s.supplier_oper_name = 2 THEN COALESCE(s.retail_amount, 0) ELSE 0 END) as returns_amount,
+            SUM(CASE WHEN s.supplier_oper_name = 3 THEN COALESCE(s.delivery_amount, 0) ELSE 0 END) as deliveries,
+            SUM(CASE WHEN s.supplier_oper_name = 3 THEN COALESCE(s.return_amount, 0) ELSE 0 END) as reverse_deliveries,
+            SUM(COALESCE(s.delivery_rub, 0)) as logistics,
+            SUM(CASE WHEN s.supplier_oper_name = 3 AND COALESCE(s.return_amount, 0) > 0 THEN COALESCE(s.delivery_rub, 0) ELSE 0 END) as reverse_logistics`
+
+// returnsRow - итоги группы аналитики возвратов
+type returnsRow struct {
+	Sales             int
+	Returns           int
+	SalesAmount       float64
+	ReturnsAmount     float64
+	Deliveries        int
+	ReverseDeliveries int
+	Logistics         float64
+	ReverseLogistics  float64
+}
+
+// values - показатели группы: процент выкупа от доставок покупателям, доля возвратов
+// от продаж и доля обратной логистики в стоимости логистики
+func (row returnsRow) values() map[string]interface{} {
+	var buyoutRate, returnRate, reverseShare, reversePerReturn interface{}
+	if row.Deliveries > 0 {
+		buyoutRate = float64(row.Sales) / float64(row.Deliveries) * 100
+	}
+	if row.Sales > 0 {
+		returnRate = float64(row.Returns) / float64(row.Sales) * 100
+	}
+	if row.Logistics != 0 {
+		reverseShare = row.ReverseLogistics / row.Logistics * 100
+	}
+	if row.ReverseDeliveries > 0 {
+		reversePerReturn = row.ReverseLogistics / float64(row.ReverseDeliveries)
+	}
+
+	return map[string]interface{}{
+		"sales":              row.Sales,
+		"returns":            row.Returns,
+		"sales_amount":       row.SalesAmount,
+		"returns_amount":     row.ReturnsAmount,
+		"deliveries":         row.Deliveries,
+		"reverse_deliveries": row.ReverseDeliveries,
+		"buyout_rate":        buyoutRate,
+		"return_rate":        returnRate,
+		"logistics":          row.Logistics,
+		"reverse_logistics":  row.ReverseLogistics,
+		"reverse_share":      reverseShare,
+		"reverse_per_unit":   reversePerReturn,
+	}
+}
+
+// GetReturnAnalytics - процент выкупа, доля возвратов и обратная логистика за период
+// по товарам, размерам, складам или неделям, итоги периода и товары, доля возвратов
+// которых выросла против их базовой доли за BaselineWeeks недель до периода
+func (r *AnalyticsRepository) GetReturnAnalytics(userID, accountID int, dateFrom, dateTo string, opts ReturnsOptions) (map[string]interface{}, error) {
+	columns := returnsGroupColumns[opts.GroupBy]
+
+	order := "returns DESC, sales DESC, nm_id, label"
+	if opts.GroupBy == ReturnsGroupWeek {
+		order = "label"
+	}
+
+	query := `
+        WITH grouped AS (
+            SELECT
+                ` + columns[0] + ` as nm_id,
+                ` + columns[1] + ` as label,` + returnsTotals + `
+            FROM wb_stats s
+            WHERE s.user_id = $1
+                AND s.sale_dt BETWEEN $2 AND $3
+                AND ($4 = 0 OR s.account_id = $4)
+                AND ($5 = 0 OR s.nm_id = $5)
+                AND s.nm_id IS NOT NULL
+                AND s.nm_id != 0
+            GROUP BY 1, 2
+        )
+        SELECT g.*, COUNT(*) OVER() as total_count
+        FROM grouped g
+        WHERE g.sales > 0 OR g.returns > 0 OR g.deliveries > 0 OR g.reverse_deliveries > 0
+        ORDER BY ` + order + `
+        LIMIT $6 OFFSET $7
+    `
+
+	rows, err := r.db.Query(query, userID, dateFrom, dateTo+" 23:59:59", accountID, opts.NmID,
+		opts.PageSize, (opts.Page-1)*opts.PageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query return analytics: %w", err)
+	}
+	defer rows.Close()
+
+	type groupRow struct {
+		nmID  int64
+		label string
+		row   returnsRow
+	}
+
+	var groups []groupRow
+	var nmIDs []int64
+	totalCount := 0
+	for rows.Next() {
+		var g groupRow
+		if err := rows.Scan(
+			&g.nmID, &g.label,
+			&g.row.Sales, &g.row.Returns, &g.row.SalesAmount, &g.row.ReturnsAmount,
+			&g.row.Deliveries, &g.row.ReverseDeliveries, &g.row.Logistics, &g.row.ReverseLogistics,
+			&totalCount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan return analytics: %w", err)
+		}
+		groups = append(groups, g)
+		if g.nmID != 0 {
+			nmIDs = append(nmIDs, g.nmID)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Итоги периода
+	var total returnsRow
+	err = r.db.QueryRow(`
+        SELECT`+returnsTotals+`
+        FROM wb_stats s
+        WHERE s.user_id = $1
+            AND s.sale_dt BETWEEN $2 AND $3
+            AND ($4 = 0 OR s.account_id = $4)
+            AND ($5 = 0 OR s.nm_id = $5)
+            AND s.nm_id IS NOT NULL
+            AND s.nm_id != 0
+    `, userID, dateFrom, dateTo+" 23:59:59", accountID, opts.NmID).Scan(
+		&total.Sales, &total.Returns, &total.SalesAmount, &total.ReturnsAmount,
+		&total.Deliveries, &total.ReverseDeliveries, &total.Logistics, &total.ReverseLogistics,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query return totals: %w", err)
+	}
+
+	jumps, jumpNmIDs, err := r.returnJumps(userID, accountID, dateFrom, dateTo, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	names, photos, err := r.articleNames(userID, append(nmIDs, jumpNmIDs...))
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]map[string]interface{}, 0, len(groups))
+	for _, g := range groups {
+		item := g.row.values()
+		switch opts.GroupBy {
+		case ReturnsGroupProduct:
+			item["nm_id"] = g.nmID
+		case ReturnsGroupSize:
+			item["nm_id"] = g.nmID
+			item["tech_size"] = g.label
+		case ReturnsGroupWarehouse:
+			item["warehouse"] = g.label
+		case ReturnsGroupWeek:
+			item["week"] = g.label
+		}
+		if g.nmID != 0 {
+			item["name"] = names[g.nmID]
+			item["photo"] = photos[g.nmID]
+		}
+		data = append(data, item)
+	}
+
+	for i, jump := range jumps {
+		jump["name"] = names[jumpNmIDs[i]]
+		jump["photo"] = photos[jumpNmIDs[i]]
+	}
+
+	return map[string]interface{}{
+		"data":     data,
+		"group_by": opts.GroupBy,
+		"summary":  total.values(),
+		"jumps":    jumps,
+		"pagination": map[string]interface{}{
+			"current_page": opts.Page,
+			"page_size":    opts.PageSize,
+			"total_items":  totalCount,
+			"total_pages":  int(math.Ceil(float64(totalCount) / float64(opts.PageSize))),
+		},
+	}, nil
+}
+
+// returnJumps - товары, доля возвратов которых в периоде выше базовой доли за BaselineWeeks недель
+// до периода на JumpPoints п.п. и больше (при MinSales продаж и в периоде, и в базе).
+// Отсортированы по росту доли возвратов
+func (r *AnalyticsRepository) returnJumps(userID, accountID int, dateFrom, dateTo string, opts ReturnsOptions) ([]map[string]interface{}, []int64, error) {
+	from, err := time.Parse("2006-01-02", dateFrom)
+	if err != nil {
+		return nil, nil, err
+	}
+	baselineFrom := from.AddDate(0, 0, -7*opts.BaselineWeeks).Format("2006-01-02")
+
+	query := `
+        WITH products AS (
+            SELECT
+                s.nm_id,
+                SUM(CASE WHEN s.sale_dt >= $3 AND s.supplier_oper_name IN (1, 7) THEN COALESCE(s.quantity, 0) ELSE 0 END) as sales,
+                SUM(CASE WHEN s.sale_dt >= $3 AND s.supplier_oper_name = 2 THEN COALESCE(s.return_amount, 0) ELSE 0 END) as returns,
+                SUM(CASE WHEN s.sale_dt < $3 AND s.supplier_oper_name IN (1, 7) THEN COALESCE(s.quantity, 0) ELSE 0 END) as baseline_sales,
+                SUM(CASE WHEN s.sale_dt < $3 AND s.supplier_oper_name = 2 THEN COALESCE(s.return_amount, 0) ELSE 0 END) as baseline_returns
+            FROM wb_stats s
+            WHERE s.user_id = $1
+                AND s.sale_dt BETWEEN $2 AND $4
+                AND ($5 = 0 OR s.account_id = $5)
+                AND ($6 = 0 OR s.nm_id = $6)
+                AND s.nm_id IS NOT NULL
+                AND s.nm_id != 0
+                AND s.supplier_oper_name IN (1, 2, 7)
+            GROUP BY s.nm_id
+        ),
+        rates AS (
+            SELECT
+                p.*,
+                p.returns::numeric / NULLIF(p.sales, 0) * 100 as return_rate,
+                p.baseline_returns::numeric / NULLIF(p.baseline_sales, 0) * 100 as baseline_rate
+            FROM products p
+            WHERE p.sales >= $7 AND p.baseline_sales >= $7
+        )
+        SELECT nm_id, sales, returns, baseline_sales, baseline_returns, return_rate, baseline_rate
+        FROM rates
+        WHERE return_rate - baseline_rate >= $8
+        ORDER BY return_rate - baseline_rate DESC, nm_id
+    `
+
+	rows, err := r.db.Query(query, userID, baselineFrom, dateFrom, dateTo+" 23:59:59", accountID, opts.NmID,
+		opts.MinSales, opts.JumpPoints)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query return jumps: %w", err)
+	}
+	defer rows.Close()
+
+	jumps := []map[string]interface{}{}
+	var nmIDs []int64
+	for rows.Next() {
+		var nmID int64
+		var sales, returns, baselineSales, baselineReturns int
+		var returnRate, baselineRate float64
+		if err := rows.Scan(&nmID, &sales, &returns, &baselineSales, &baselineReturns, &returnRate, &baselineRate); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan return jump: %w", err)
+		}
+
+		nmIDs = append(nmIDs, nmID)
+		jumps = append(jumps, map[string]interface{}{
+			"nm_id":            nmID,
+			"sales":            sales,
+			"returns":          returns,
+			"return_rate":      returnRate,
+			"baseline_sales":   baselineSales,
+			"baseline_returns": baselineReturns,
+			"baseline_rate":    baselineRate,
+			"change":           returnRate - baselineRate,
+		})
+	}
+
+	return jumps, nmIDs, rows.Err()
+}
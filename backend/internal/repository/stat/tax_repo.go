@@ -0,0 +1,284 @@
+package stat
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/repository/database/postgres"
+	"wbrost-go/internal/repository/user"
+)
+
+// taxProfileJoin - налоговый профиль, действовавший на дату продажи строки отчета (tp.id).
+// Без профиля (tp.id IS NULL) налог считается по ставке users.taxes
+const taxProfileJoin = `
+        LEFT JOIN LATERAL (
+            SELECT t.id
+            FROM tax_profiles t
+            WHERE t.user_id = s.user_id AND t.valid_from <= s.sale_dt::date
+            ORDER BY t.valid_from DESC
+            LIMIT 1
+        ) tp ON TRUE`
+
+// operationsTaxProfileJoin - то же для marketplace_operations o
+const operationsTaxProfileJoin = `
+        LEFT JOIN LATERAL (
+            SELECT t.id
+            FROM tax_profiles t
+            WHERE t.user_id = o.user_id AND t.valid_from <= o.operation_date::date
+            ORDER BY t.valid_from DESC
+            LIMIT 1
+        ) tp ON TRUE`
+
+// taxCalculator - налоговые профили пользователя для расчета налога по периодам их действия
+type taxCalculator struct {
+	db       *postgres.PostgresDB
+	profiles map[int64]entity.TaxProfile
+	legacy   entity.TaxProfile
+}
+
+// newTaxCalculator загружает профили пользователя и его ставку users.taxes
+func newTaxCalculator(db *postgres.PostgresDB, userRepo *user.UserRepository, userID int) (*taxCalculator, error) {
+	u, err := userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
+	}
+
+	profiles, err := userRepo.GetTaxProfiles(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &taxCalculator{
+		db:       db,
+		profiles: make(map[int64]entity.TaxProfile, len(profiles)),
+		legacy:   entity.LegacyTaxProfile(u.Taxes),
+	}
+	for _, profile := range profiles {
+		c.profiles[profile.ID] = profile
+	}
+
+	return c, nil
+}
+
+// profile - профиль по tp.id строки (без профиля - ставка users.taxes)
+func (c *taxCalculator) profile(profileID sql.NullInt64) entity.TaxProfile {
+	if profile, ok := c.profiles[profileID.Int64]; ok && profileID.Valid {
+		return profile
+	}
+	return c.legacy
+}
+
+// wbTaxes - налог по строкам wb_stats с группировкой по выражению groupBy (ключ - его текстовое значение).
+// productsOnly - только строки с nm_id (как в статистике по товарам)
+func (c *taxCalculator) wbTaxes(userID, accountID int, dateFrom, dateTo, groupBy string, productsOnly bool) (map[string]entity.TaxAmount, error) {
+	productsFilter := ""
+	if productsOnly {
+		productsFilter = `
+            AND s.nm_id IS NOT NULL
+            AND s.nm_id != 0`
+	}
+
+	query := `
+        SELECT
+            (` + groupBy + `)::text as group_key,
+            tp.id,
+            SUM(
+                CASE
+                    WHEN s.supplier_oper_name IN (1, 7) THEN COALESCE(s.retail_amount, 0)
+                    WHEN s.supplier_oper_name = 2 THEN -COALESCE(s.retail_amount, 0)
+                    ELSE 0
+                END
+            ) as revenue,
+            SUM(
+                CASE
+                    WHEN s.supplier_oper_name IN (1, 7) THEN COALESCE(s.ppvz_for_pay, 0)
+                    WHEN s.supplier_oper_name = 2 THEN -COALESCE(s.ppvz_for_pay, 0)
+                    ELSE 0
+                END
+            ) as sales_payout,
+            SUM(CASE WHEN s.supplier_oper_name NOT IN (1, 2, 7) THEN COALESCE(s.ppvz_for_pay, 0) ELSE 0 END) as other_payout,
+            SUM(
+                COALESCE(s.delivery_rub, 0) + COALESCE(s.rebill_logistic_cost, 0) + COALESCE(s.storage_fee, 0) +
+                COALESCE(s.penalty, 0) + COALESCE(s.deduction, 0)
+            ) as expenses,` + costPriceSum + ` as cost_of_goods
+        FROM wb_stats s` + costPriceJoin + taxProfileJoin + `
+        WHERE s.user_id = $1
+            AND s.sale_dt BETWEEN $2 AND $3
+            AND ($4 = 0 OR s.account_id = $4)` + productsFilter + `
+        GROUP BY 1, tp.id
+    `
+
+	rows, err := c.db.Query(query, userID, dateFrom, dateTo+" 23:59:59", accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tax base: %w", err)
+	}
+	defer rows.Close()
+
+	taxes := make(map[string]entity.TaxAmount)
+	for rows.Next() {
+		var key sql.NullString
+		var profileID sql.NullInt64
+		var revenue, salesPayout, otherPayout, expenses, costOfGoods float64
+		if err := rows.Scan(&key, &profileID, &revenue, &salesPayout, &otherPayout, &expenses, &costOfGoods); err != nil {
+			return nil, fmt.Errorf("failed to scan tax base: %w", err)
+		}
+
+		amount := taxes[key.String]
+		amount.Add(c.profile(profileID).Calculate(entity.TaxBase{
+			Revenue:    revenue,
+			Payout:     salesPayout + otherPayout,
+			Commission: revenue - salesPayout,
+			Expenses:   expenses + costOfGoods,
+		}))
+		taxes[key.String] = amount
+	}
+
+	return taxes, rows.Err()
+}
+
+// operationsTaxes - налог по операциям остальных площадок (marketplace = "" - по всем)
+// с группировкой по выражению groupBy. Себестоимость товаров этих площадок не учитывается
+func (c *taxCalculator) operationsTaxes(userID, accountID int, marketplace, dateFrom, dateTo, groupBy string) (map[string]entity.TaxAmount, error) {
+	query := `
+        SELECT
+            (` + groupBy + `)::text as group_key,
+            tp.id,
+            SUM(CASE WHEN o.operation_type IN ('sale', 'return') THEN o.revenue ELSE 0 END) as revenue,
+            SUM(o.payout) as payout,
+            SUM(o.commission) as commission,
+            SUM(o.logistics + o.storage + o.penalty + o.other_charges) as expenses
+        FROM marketplace_operations o` + operationsTaxProfileJoin + `
+        WHERE o.user_id = $1
+            AND o.operation_date BETWEEN $2 AND $3
+            AND ($4 = 0 OR o.account_id = $4)
+            AND ` + operationsFilter + `
+        GROUP BY 1, tp.id
+    `
+
+	rows, err := c.db.Query(query, userID, dateFrom, dateTo+" 23:59:59", accountID, marketplace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query operations tax base: %w", err)
+	}
+	defer rows.Close()
+
+	taxes := make(map[string]entity.TaxAmount)
+	for rows.Next() {
+		var key sql.NullString
+		var profileID sql.NullInt64
+		var revenue, payout, commission, expenses float64
+		if err := rows.Scan(&key, &profileID, &revenue, &payout, &commission, &expenses); err != nil {
+			return nil, fmt.Errorf("failed to scan operations tax base: %w", err)
+		}
+
+		amount := taxes[key.String]
+		amount.Add(c.profile(profileID).Calculate(entity.TaxBase{
+			Revenue:    revenue,
+			Payout:     payout,
+			Commission: commission,
+			Expenses:   expenses,
+		}))
+		taxes[key.String] = amount
+	}
+
+	return taxes, rows.Err()
+}
+
+// profileAt - профиль, действующий на дату (без профиля - ставка users.taxes)
+func (c *taxCalculator) profileAt(date time.Time) entity.TaxProfile {
+	current, found := c.legacy, false
+	for _, profile := range c.profiles {
+		if profile.ValidFrom.After(date) {
+			continue
+		}
+		if !found || profile.ValidFrom.After(current.ValidFrom) {
+			current, found = profile, true
+		}
+	}
+	return current
+}
+
+// GetTaxEstimate - оценка налога за год по кварталам по всем площадкам. Налог УСН и НДФЛ
+// считается нарастающим итогом с начала года: к уплате за квартал - прирост налога
+// (убыток квартала уменьшает следующие платежи). НДС - за каждый квартал отдельно
+func (r *AnalyticsRepository) GetTaxEstimate(userID, accountID, year int) (map[string]interface{}, error) {
+	calculator, err := newTaxCalculator(r.db, r.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	dateFrom := fmt.Sprintf("%d-01-01", year)
+	dateTo := fmt.Sprintf("%d-12-31", year)
+
+	wbTaxes, err := calculator.wbTaxes(userID, accountID, dateFrom, dateTo, "EXTRACT(QUARTER FROM s.sale_dt)::int", false)
+	if err != nil {
+		return nil, err
+	}
+	operationsTaxes, err := calculator.operationsTaxes(userID, accountID, "", dateFrom, dateTo, "EXTRACT(QUARTER FROM o.operation_date)::int")
+	if err != nil {
+		return nil, err
+	}
+
+	var cumulative entity.TaxAmount
+	paid := 0.0
+	quarters := make([]map[string]interface{}, 0, 4)
+
+	for quarter := 1; quarter <= 4; quarter++ {
+		key := strconv.Itoa(quarter)
+		start := time.Date(year, time.Month(quarter*3-2), 1, 0, 0, 0, 0, time.UTC)
+		end := start.AddDate(0, 3, -1)
+
+		var amount entity.TaxAmount
+		amount.Add(wbTaxes[key])
+		amount.Add(operationsTaxes[key])
+		cumulative.Add(amount)
+
+		// Налог нарастающим итогом не бывает отрицательным
+		cumulativeTax := math.Max(cumulative.Tax, 0)
+		payable := math.Max(cumulativeTax-paid, 0)
+		paid += payable
+
+		profile := calculator.profileAt(end)
+		quarters = append(quarters, map[string]interface{}{
+			"quarter":        quarter,
+			"date_from":      start.Format("2006-01-02"),
+			"date_to":        end.Format("2006-01-02"),
+			"regime":         profile.Regime,
+			"regime_name":    entity.TaxRegimeNames[profile.Regime],
+			"rate":           profile.Rate,
+			"income":         amount.Income,
+			"expenses":       amount.Expenses,
+			"base":           amount.Base,
+			"tax":            amount.Tax,
+			"cumulative_tax": cumulativeTax,
+			"payable":        payable,
+			"vat":            amount.VAT,
+		})
+	}
+
+	// Минимальный налог УСН "Доходы минус расходы" - 1% от доходов за год
+	profile := calculator.profileAt(time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC))
+	yearTax := math.Max(cumulative.Tax, 0)
+	minimumTax := 0.0
+	if profile.Regime == entity.TaxRegimeUSNIncomeExpense && cumulative.Income > 0 {
+		minimumTax = cumulative.Income * entity.TaxUSNMinimumRate / 100
+	}
+
+	return map[string]interface{}{
+		"year":     year,
+		"quarters": quarters,
+		"summary": map[string]interface{}{
+			"regime":      profile.Regime,
+			"regime_name": entity.TaxRegimeNames[profile.Regime],
+			"income":      cumulative.Income,
+			"expenses":    cumulative.Expenses,
+			"base":        cumulative.Base,
+			"tax":         yearTax,
+			"minimum_tax": minimumTax,
+			"tax_due":     math.Max(yearTax, minimumTax),
+			"vat":         cumulative.VAT,
+		},
+	}, nil
+}
@@ -0,0 +1,259 @@
+package stat
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// unitEconomics - показатели товара за период (деньги в рублях, units - проданные единицы за вычетом возвратов)
+type unitEconomics struct {
+	NmID             int64
+	Name             string
+	VendorCode       string
+	Photo            string
+	Sales            int
+	Returns          int
+	Revenue          float64 // Продажи по розничной цене с учетом скидок за вычетом возвратов
+	Payout           float64 // К перечислению продавцу за проданные товары
+	OtherPayout      float64 // Компенсации и прочие начисления WB
+	Logistics        float64 // Доставка до покупателя и обратно, возмещение издержек по перевозке
+	Storage          float64
+	StorageAllocated float64 // Доля хранения без привязки к товару
+	Penalties        float64 // Штрафы и удержания
+	Additional       float64 // Доплаты
+	CostOfGoods      float64
+	UnitsWithoutCost int     // Проданные единицы без себестоимости (не указана или 0) на дату продажи
+	Tax              float64 // Налог по налоговому профилю вместе с НДС
+	VAT              float64
+}
+
+// GetUnitEconomics - юнит-экономика товаров за период: выручка, комиссия WB, логистика,
+// доля хранения, себестоимость, налог, чистая прибыль, маржа и ROI (всего и на единицу).
+// Хранение без nm_id распределяется между товарами пропорционально проданным единицам
+func (r *AnalyticsRepository) GetUnitEconomics(userID, accountID int, dateFrom, dateTo string) ([]map[string]interface{}, map[string]interface{}, error) {
+	calculator, err := newTaxCalculator(r.db, r.userRepo, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	taxes, err := calculator.wbTaxes(userID, accountID, dateFrom, dateTo, "s.nm_id", true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query := `
+        WITH product AS (
+            SELECT
+                s.nm_id,
+                MAX(s.subject_name) as subject_name,
+                SUM(CASE WHEN s.supplier_oper_name IN (1, 7) THEN COALESCE(s.quantity, 0) ELSE 0 END) as sales,
+                SUM(CASE WHEN s.supplier_oper_name = 2 THEN COALESCE(s.return_amount, 0) ELSE 0 END) as returns,
+                SUM(
+                    CASE
+                        WHEN s.supplier_oper_name IN (1, 7) THEN COALESCE(s.retail_amount, 0)
+                        WHEN s.supplier_oper_name = 2 THEN -COALESCE(s.retail_amount, 0)
+                        ELSE 0
+                    END
+                ) as revenue,
+                SUM(
+                    CASE
+                        WHEN s.supplier_oper_name IN (1, 7) THEN COALESCE(s.ppvz_for_pay, 0)
+                        WHEN s.supplier_oper_name = 2 THEN -COALESCE(s.ppvz_for_pay, 0)
+                        ELSE 0
+                    END
+                ) as payout,
+                SUM(CASE WHEN s.supplier_oper_name NOT IN (1, 2, 7) THEN COALESCE(s.ppvz_for_pay, 0) ELSE 0 END) as other_payout,
+                SUM(COALESCE(s.delivery_rub, 0) + COALESCE(s.rebill_logistic_cost, 0)) as logistics,
+                SUM(COALESCE(s.storage_fee, 0)) as storage,
+                SUM(COALESCE(s.penalty, 0) + COALESCE(s.deduction, 0)) as penalties,
+                SUM(COALESCE(s.additional_payment, 0)) as additional,` + costPriceSum + ` as cost_of_goods,
+                SUM(
+                    CASE
+                        WHEN s.supplier_oper_name IN (1, 7) AND COALESCE(cp.cost_price, 0) = 0
+                        THEN COALESCE(s.quantity, 0)
+                        ELSE 0
+                    END
+                ) as units_without_cost
+            FROM wb_stats s` + costPriceJoin + `
+            WHERE s.user_id = $1
+                AND s.sale_dt BETWEEN $2 AND $3
+                AND ($4 = 0 OR s.account_id = $4)
+                AND s.nm_id IS NOT NULL
+                AND s.nm_id != 0
+            GROUP BY s.nm_id
+        )
+        SELECT
+            p.nm_id,
+            COALESCE(wa.name, p.subject_name, 'Нет названия'),
+            COALESCE(wa.internal_id, ''),
+            COALESCE(wa.photo, ''),
+            p.sales, p.returns, p.revenue, p.payout, p.other_payout,
+            p.logistics, p.storage, p.penalties, p.additional, p.cost_of_goods, p.units_without_cost
+        FROM product p
+        LEFT JOIN LATERAL (
+            SELECT a.name, a.internal_id, a.photo
+            FROM wb_articles a
+            WHERE a.id_user = $1 AND a.articule::bigint = p.nm_id AND a.marketplace = 'wb'
+            LIMIT 1
+        ) wa ON TRUE
+        ORDER BY p.revenue DESC
+    `
+
+	dateToWithTime := dateTo + " 23:59:59"
+
+	rows, err := r.db.Query(query, userID, dateFrom, dateToWithTime, accountID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query unit economics: %w", err)
+	}
+	defer rows.Close()
+
+	var products []unitEconomics
+	totalUnits := 0
+	for rows.Next() {
+		var p unitEconomics
+		if err := rows.Scan(
+			&p.NmID,
+			&p.Name,
+			&p.VendorCode,
+			&p.Photo,
+			&p.Sales,
+			&p.Returns,
+			&p.Revenue,
+			&p.Payout,
+			&p.OtherPayout,
+			&p.Logistics,
+			&p.Storage,
+			&p.Penalties,
+			&p.Additional,
+			&p.CostOfGoods,
+			&p.UnitsWithoutCost,
+		); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan unit economics: %w", err)
+		}
+		if units := p.Sales - p.Returns; units > 0 {
+			totalUnits += units
+		}
+		tax := taxes[strconv.FormatInt(p.NmID, 10)]
+		p.Tax, p.VAT = tax.Total(), tax.VAT
+		products = append(products, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	// Хранение, которое WB не отнес ни к одному товару
+	var unallocatedStorage float64
+	err = r.db.QueryRow(`
+        SELECT COALESCE(SUM(COALESCE(s.storage_fee, 0)), 0)
+        FROM wb_stats s
+        WHERE s.user_id = $1
+            AND s.sale_dt BETWEEN $2 AND $3
+            AND ($4 = 0 OR s.account_id = $4)
+            AND (s.nm_id IS NULL OR s.nm_id = 0)
+    `, userID, dateFrom, dateToWithTime, accountID).Scan(&unallocatedStorage)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get unallocated storage: %w", err)
+	}
+
+	var total unitEconomics
+	productsWithoutCost := 0
+	results := make([]map[string]interface{}, 0, len(products))
+
+	for _, p := range products {
+		if units := p.Sales - p.Returns; units > 0 && totalUnits > 0 {
+			p.StorageAllocated = unallocatedStorage * float64(units) / float64(totalUnits)
+		}
+		if p.UnitsWithoutCost > 0 {
+			productsWithoutCost++
+		}
+
+		item := unitEconomicsValues(p)
+		item["nm_id"] = p.NmID
+		item["name"] = p.Name
+		item["vendor_code"] = p.VendorCode
+		item["photo"] = p.Photo
+		item["cost_price_missing"] = p.UnitsWithoutCost > 0
+		item["units_without_cost_price"] = p.UnitsWithoutCost
+		results = append(results, item)
+
+		total.Sales += p.Sales
+		total.Returns += p.Returns
+		total.Revenue += p.Revenue
+		total.Payout += p.Payout
+		total.OtherPayout += p.OtherPayout
+		total.Logistics += p.Logistics
+		total.Storage += p.Storage
+		total.StorageAllocated += p.StorageAllocated
+		total.Penalties += p.Penalties
+		total.Additional += p.Additional
+		total.CostOfGoods += p.CostOfGoods
+		total.UnitsWithoutCost += p.UnitsWithoutCost
+		total.Tax += p.Tax
+		total.VAT += p.VAT
+	}
+
+	summary := unitEconomicsValues(total)
+	summary["products"] = len(products)
+	summary["products_without_cost_price"] = productsWithoutCost
+	summary["unallocated_storage"] = unallocatedStorage
+
+	return results, summary, nil
+}
+
+// unitEconomicsValues - расчет показателей товара (или итога) всего и на проданную единицу
+func unitEconomicsValues(p unitEconomics) map[string]interface{} {
+	units := p.Sales - p.Returns
+	commission := p.Revenue - p.Payout
+	storage := p.Storage + p.StorageAllocated
+
+	tax := p.Tax
+	netProfit := p.Payout + p.OtherPayout + p.Additional - p.Logistics - storage - p.Penalties - p.CostOfGoods - tax
+
+	// Маржа - от выручки, ROI - от вложений в себестоимость
+	var margin, roi interface{}
+	if p.Revenue != 0 {
+		margin = netProfit / p.Revenue * 100
+	}
+	if p.CostOfGoods > 0 {
+		roi = netProfit / p.CostOfGoods * 100
+	}
+
+	var perUnit interface{}
+	var logisticsPerSale interface{}
+	if units > 0 {
+		n := float64(units)
+		logisticsPerSale = p.Logistics / n
+		perUnit = map[string]interface{}{
+			"revenue":       p.Revenue / n,
+			"wb_commission": commission / n,
+			"logistics":     p.Logistics / n,
+			"storage":       storage / n,
+			"cost_of_goods": p.CostOfGoods / n,
+			"tax":           tax / n,
+			"net_profit":    netProfit / n,
+		}
+	}
+
+	return map[string]interface{}{
+		"sales":              p.Sales,
+		"returns":            p.Returns,
+		"units":              units,
+		"revenue":            p.Revenue,
+		"wb_commission":      commission,
+		"payout":             p.Payout,
+		"other_payout":       p.OtherPayout,
+		"logistics":          p.Logistics,
+		"logistics_per_sale": logisticsPerSale,
+		"storage":            p.Storage,
+		"storage_allocated":  p.StorageAllocated,
+		"storage_total":      storage,
+		"penalties":          p.Penalties,
+		"additional_payment": p.Additional,
+		"cost_of_goods":      p.CostOfGoods,
+		"tax":                tax,
+		"vat":                p.VAT,
+		"net_profit":         netProfit,
+		"margin":             margin,
+		"roi":                roi,
+		"per_unit":           perUnit,
+	}
+}
@@ -18,13 +18,14 @@ func NewWBStatsGetRepository(db *postgres.PostgresDB) *WBStatsGetRepository {
 // Create создает новую запись отчета
 func (r *WBStatsGetRepository) Create(stats *entity.WBStatsGet) error {
 	query := `
-		INSERT INTO wb_stats_get (id_user, status, date_from, date_to, last_error)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO wb_stats_get (id_user, account_id, status, date_from, date_to, last_error)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id, created, updated
 	`
 
 	return r.db.QueryRow(query,
 		stats.UserID,
+		stats.AccountID,
 		stats.Status,
 		stats.DateFrom,
 		stats.DateTo,
@@ -32,16 +33,16 @@ func (r *WBStatsGetRepository) Create(stats *entity.WBStatsGet) error {
 	).Scan(&stats.ID, &stats.Created, &stats.Updated)
 }
 
-// GetByUserID получает отчеты пользователя
-func (r *WBStatsGetRepository) GetByUserID(userID int) ([]entity.WBStatsGet, error) {
+// GetByUserID получает отчеты пользователя (accountID = 0 - по всем кабинетам)
+func (r *WBStatsGetRepository) GetByUserID(userID, accountID int) ([]entity.WBStatsGet, error) {
 	query := `
-		SELECT id, id_user, status, date_from, date_to, created, updated, last_error
+		SELECT id, id_user, account_id, status, date_from, date_to, created, updated, last_error
 		FROM wb_stats_get 
-		WHERE id_user = $1 
+		WHERE id_user = $1 AND ($2 = 0 OR account_id = $2)
 		ORDER BY created DESC
 	`
 
-	rows, err := r.db.Query(query, userID)
+	rows, err := r.db.Query(query, userID, accountID)
 	if err != nil {
 		return nil, err
 	}
@@ -53,6 +54,7 @@ func (r *WBStatsGetRepository) GetByUserID(userID int) ([]entity.WBStatsGet, err
 		err := rows.Scan(
 			&s.ID,
 			&s.UserID,
+			&s.AccountID,
 			&s.Status,
 			&s.DateFrom,
 			&s.DateTo,
@@ -84,7 +86,7 @@ func (r *WBStatsGetRepository) UpdateStatus(id int, status int, errorMsg string)
 // GetPendingOrders возвращает заказы с статусом 0 (в обработке)
 func (r *WBStatsGetRepository) GetPendingOrders() ([]entity.WBStatsGet, error) {
 	query := `
-		SELECT id, id_user, status, date_from, date_to, created, updated, last_error
+		SELECT id, id_user, account_id, status, date_from, date_to, created, updated, last_error
 		FROM wb_stats_get 
 		WHERE status = $1 
 		ORDER BY created ASC
@@ -102,6 +104,7 @@ func (r *WBStatsGetRepository) GetPendingOrders() ([]entity.WBStatsGet, error) {
 		err := rows.Scan(
 			&order.ID,
 			&order.UserID,
+			&order.AccountID,
 			&order.Status,
 			&order.DateFrom,
 			&order.DateTo,
@@ -0,0 +1,166 @@
+package user
+
+import (
+	"fmt"
+	"time"
+	"wbrost-go/internal/entity"
+)
+
+// purgeQueries - удаление всех данных пользователя ($1 - id_user) в порядке зависимостей.
+// Данные организации хранятся под id владельца, поэтому вместе с владельцем
+// удаляется и его организация со всеми участниками и приглашениями
+var purgeQueries = []string{
+	`DELETE FROM wb_stats WHERE user_id = $1`,
+	`DELETE FROM wb_stats_get WHERE id_user = $1`,
+	`DELETE FROM wb_realization_reports WHERE user_id = $1`,
+	`DELETE FROM wb_articles WHERE id_user = $1`,
+	`DELETE FROM cost_price_history WHERE user_id = $1`,
+	`DELETE FROM tax_profiles WHERE user_id = $1`,
+	`DELETE FROM wb_article_barcodes WHERE user_id = $1`,
+	`DELETE FROM wb_article_sizes WHERE user_id = $1`,
+	`DELETE FROM wb_article_photos WHERE user_id = $1`,
+	`DELETE FROM wb_article_characteristics WHERE user_id = $1`,
+	`DELETE FROM wb_article_changes WHERE user_id = $1`,
+	`DELETE FROM wb_article_sync WHERE user_id = $1`,
+	`DELETE FROM wb_stocks WHERE user_id = $1`,
+	`DELETE FROM wb_card_drafts WHERE user_id = $1`,
+	`DELETE FROM wb_card_updates WHERE id_user = $1`,
+	`DELETE FROM wb_articles_get WHERE id_user = $1`,
+	`DELETE FROM ozon_get WHERE id_user = $1`,
+	`DELETE FROM ozon_transactions WHERE user_id = $1`,
+	`DELETE FROM ozon_products WHERE user_id = $1`,
+	`DELETE FROM yandex_get WHERE id_user = $1`,
+	`DELETE FROM marketplace_operations WHERE user_id = $1`,
+	`DELETE FROM seller_accounts WHERE id_user = $1`,
+	`DELETE FROM organization_invites
+	 WHERE organization_id IN (SELECT id FROM organizations WHERE owner_id = $1)
+	    OR invited_by = $1
+	    OR LOWER(username) = (SELECT LOWER(username) FROM users WHERE id_user = $1)
+	    OR LOWER(email) = (SELECT LOWER(email) FROM users WHERE id_user = $1)`,
+	`DELETE FROM organization_members
+	 WHERE organization_id IN (SELECT id FROM organizations WHERE owner_id = $1)
+	    OR id_user = $1`,
+	`DELETE FROM organizations WHERE owner_id = $1`,
+	`DELETE FROM users WHERE id_user = $1`,
+}
+
+// ScheduleDeletion назначает удаление аккаунта на дату deleteAfter.
+// Повторный запрос не сдвигает уже назначенную дату
+func (r *UserRepository) ScheduleDeletion(userID int, deleteAfter time.Time) (time.Time, error) {
+	query := `
+		UPDATE users
+		SET delete_requested_at = COALESCE(delete_requested_at, CURRENT_TIMESTAMP),
+		    delete_after = COALESCE(delete_after, $1),
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id_user = $2
+		RETURNING delete_after
+	`
+
+	var scheduled time.Time
+	if err := r.db.QueryRow(query, deleteAfter, userID).Scan(&scheduled); err != nil {
+		return time.Time{}, fmt.Errorf("failed to schedule deletion: %w", err)
+	}
+
+	return scheduled, nil
+}
+
+// CancelDeletion отменяет запрошенное удаление аккаунта
+func (r *UserRepository) CancelDeletion(userID int) error {
+	query := `
+		UPDATE users
+		SET delete_requested_at = NULL, delete_after = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id_user = $1
+	`
+
+	if _, err := r.db.Exec(query, userID); err != nil {
+		return fmt.Errorf("failed to cancel deletion: %w", err)
+	}
+
+	return nil
+}
+
+// GetDeletion возвращает запрос на удаление аккаунта (nil - удаление не запрошено)
+func (r *UserRepository) GetDeletion(userID int) (*entity.UserDeletion, error) {
+	deletions, err := r.queryDeletions(`
+		SELECT id_user, username, email, delete_requested_at, delete_after
+		FROM users
+		WHERE id_user = $1 AND delete_after IS NOT NULL
+	`, userID)
+	if err != nil || len(deletions) == 0 {
+		return nil, err
+	}
+
+	return &deletions[0], nil
+}
+
+// GetPendingDeletions возвращает всех пользователей, ожидающих удаления
+func (r *UserRepository) GetPendingDeletions() ([]entity.UserDeletion, error) {
+	return r.queryDeletions(`
+		SELECT id_user, username, email, delete_requested_at, delete_after
+		FROM users
+		WHERE delete_after IS NOT NULL
+		ORDER BY delete_after
+	`)
+}
+
+// GetDueDeletions возвращает пользователей, у которых истек льготный период
+func (r *UserRepository) GetDueDeletions(now time.Time) ([]entity.UserDeletion, error) {
+	return r.queryDeletions(`
+		SELECT id_user, username, email, delete_requested_at, delete_after
+		FROM users
+		WHERE delete_after IS NOT NULL AND delete_after <= $1
+		ORDER BY delete_after
+	`, now)
+}
+
+func (r *UserRepository) queryDeletions(query string, args ...interface{}) ([]entity.UserDeletion, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deletions: %w", err)
+	}
+	defer rows.Close()
+
+	var deletions []entity.UserDeletion
+	for rows.Next() {
+		var d entity.UserDeletion
+		if err := rows.Scan(&d.UserID, &d.Username, &d.Email, &d.DeleteRequestedAt, &d.DeleteAfter); err != nil {
+			return nil, fmt.Errorf("failed to scan deletion: %w", err)
+		}
+		deletions = append(deletions, d)
+	}
+
+	return deletions, rows.Err()
+}
+
+// Purge безвозвратно удаляет пользователя и все его данные одной транзакцией.
+// Удаляются только пользователи с назначенным удалением
+func (r *UserRepository) Purge(userID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var scheduled bool
+	err = tx.QueryRow(
+		`SELECT delete_after IS NOT NULL FROM users WHERE id_user = $1 FOR UPDATE`, userID,
+	).Scan(&scheduled)
+	if err != nil {
+		return fmt.Errorf("failed to lock user %d: %w", userID, err)
+	}
+	if !scheduled {
+		return fmt.Errorf("deletion of user %d is not scheduled", userID)
+	}
+
+	for _, query := range purgeQueries {
+		if _, err := tx.Exec(query, userID); err != nil {
+			return fmt.Errorf("failed to purge user %d: %w", userID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit purge of user %d: %w", userID, err)
+	}
+
+	return nil
+}
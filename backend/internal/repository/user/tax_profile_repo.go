@@ -0,0 +1,112 @@
+package user
+
+import (
+	"fmt"
+	"wbrost-go/internal/entity"
+)
+
+// currentTaxRate - ставка профиля, действующего на сегодня ($1 - пользователь)
+const currentTaxRate = `(
+		SELECT ROUND(t.rate)::int
+		FROM tax_profiles t
+		WHERE t.user_id = $1 AND t.valid_from <= CURRENT_DATE
+		ORDER BY t.valid_from DESC
+		LIMIT 1
+	)`
+
+// SaveTaxProfile добавляет налоговый профиль с датой начала действия (профиль на ту же дату
+// заменяется) и обновляет users.taxes - ставку профиля, действующего на сегодня
+func (r *UserRepository) SaveTaxProfile(profile *entity.TaxProfile) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO tax_profiles (user_id, regime, rate, base, vat_rate, valid_from, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id, valid_from) DO UPDATE
+		SET regime = EXCLUDED.regime,
+		    rate = EXCLUDED.rate,
+		    base = EXCLUDED.base,
+		    vat_rate = EXCLUDED.vat_rate,
+		    created_by = EXCLUDED.created_by,
+		    created_at = CURRENT_TIMESTAMP
+		RETURNING id, created_at
+	`, profile.UserID, profile.Regime, profile.Rate, profile.Base, profile.VatRate,
+		profile.ValidFrom, profile.CreatedBy).Scan(&profile.ID, &profile.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save tax profile: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE users SET taxes = COALESCE(`+currentTaxRate+`, taxes)
+		WHERE id_user = $1
+	`, profile.UserID); err != nil {
+		return fmt.Errorf("failed to update current tax rate: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// DeleteTaxProfile удаляет налоговый профиль. Возвращает false, если профиля нет
+func (r *UserRepository) DeleteTaxProfile(userID int, id int64) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM tax_profiles WHERE user_id = $1 AND id = $2`, userID, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete tax profile: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE users SET taxes = COALESCE(`+currentTaxRate+`, taxes)
+		WHERE id_user = $1
+	`, userID); err != nil {
+		return false, fmt.Errorf("failed to update current tax rate: %w", err)
+	}
+
+	return true, tx.Commit()
+}
+
+// GetTaxProfiles возвращает налоговые профили пользователя (новые первыми)
+func (r *UserRepository) GetTaxProfiles(userID int) ([]entity.TaxProfile, error) {
+	rows, err := r.db.Query(`
+		SELECT id, user_id, regime, rate, base, vat_rate, valid_from, created_by, created_at
+		FROM tax_profiles
+		WHERE user_id = $1
+		ORDER BY valid_from DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tax profiles: %w", err)
+	}
+	defer rows.Close()
+
+	var profiles []entity.TaxProfile
+	for rows.Next() {
+		var profile entity.TaxProfile
+		if err := rows.Scan(
+			&profile.ID,
+			&profile.UserID,
+			&profile.Regime,
+			&profile.Rate,
+			&profile.Base,
+			&profile.VatRate,
+			&profile.ValidFrom,
+			&profile.CreatedBy,
+			&profile.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan tax profile: %w", err)
+		}
+		profiles = append(profiles, profile)
+	}
+
+	return profiles, rows.Err()
+}
@@ -4,17 +4,98 @@ import (
 	"database/sql"
 	"fmt"
 	"time"
+	"wbrost-go/internal/crypto"
 	"wbrost-go/internal/entity"
 	"wbrost-go/internal/repository/database/postgres"
 )
 
+// UserRepository хранит API ключи маркетплейсов (wb_key, ozon_key) зашифрованными:
+// шифрование при записи и расшифровка при чтении прозрачны для остального кода
 type UserRepository struct {
-	db *postgres.PostgresDB
+	db      *postgres.PostgresDB
+	keyring *crypto.Keyring
 }
 
-func NewUserRepository(db *postgres.PostgresDB) *UserRepository {
-	return &UserRepository{db: db}
+func NewUserRepository(db *postgres.PostgresDB, keyring *crypto.Keyring) *UserRepository {
+	return &UserRepository{db: db, keyring: keyring}
 }
+
+// userColumns - колонки users в порядке сканирования r.scanUser
+const userColumns = `id_user, taxes, username, password, email, admin, block, pro,
+        name, phone, wb_key, ozon_key, u2782212_wbrosus, ozon_status,
+        created_at, updated_at, del, last_login,
+        wb_key_expires_at, wb_key_scopes, wb_seller_id, wb_seller_uuid,
+        ozon_client_id`
+
+// rowScanner - общий интерфейс *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanUser сканирует строку, выбранную через userColumns, и расшифровывает ключи
+func (r *UserRepository) scanUser(row rowScanner, user *entity.Users) error {
+	err := row.Scan(
+		&user.ID,
+		&user.Taxes,
+		&user.Username,
+		&user.PasswordHash,
+		&user.Email,
+		&user.Admin,
+		&user.Block,
+		&user.Pro,
+		&user.Name,
+		&user.Phone,
+		&user.WbKey,
+		&user.OzonKey,
+		&user.U2782212Wbrosus,
+		&user.OzonStatus,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.Del,
+		&user.LastLogin,
+		&user.WbKeyExpiresAt,
+		&user.WbKeyScopes,
+		&user.WbSellerID,
+		&user.WbSellerUUID,
+		&user.OzonClientID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if user.WbKey, err = r.decryptKey(user.WbKey); err != nil {
+		return fmt.Errorf("wb_key of user %d: %w", user.ID, err)
+	}
+	if user.OzonKey, err = r.decryptKey(user.OzonKey); err != nil {
+		return fmt.Errorf("ozon_key of user %d: %w", user.ID, err)
+	}
+
+	return nil
+}
+
+// encryptKey шифрует API ключ текущей версией мастер-ключа
+func (r *UserRepository) encryptKey(key sql.NullString) (interface{}, error) {
+	if !key.Valid || key.String == "" {
+		return nil, nil
+	}
+
+	return r.keyring.Encrypt(key.String)
+}
+
+// decryptKey расшифровывает API ключ (значения в открытом виде возвращаются как есть)
+func (r *UserRepository) decryptKey(key sql.NullString) (sql.NullString, error) {
+	if !key.Valid || key.String == "" {
+		return key, nil
+	}
+
+	plain, err := r.keyring.Decrypt(key.String)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: plain, Valid: true}, nil
+}
+
 func (r *UserRepository) GetCountAllUsers() (int, error) {
 	var count int
 	err := r.db.QueryRow(
@@ -25,10 +106,7 @@ func (r *UserRepository) GetCountAllUsers() (int, error) {
 }
 func (r *UserRepository) GetAll(page, pageSize int) ([]entity.Users, error) {
 	offset := (page - 1) * pageSize
-	query := `SELECT 
-        id_user, taxes, username, password, email, admin, block, pro, 
-        name, phone, wb_key, ozon_key, u2782212_wbrosus, ozon_status,
-        created_at, updated_at, del, last_login
+	query := `SELECT ` + userColumns + `
         FROM users where del = 0
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2`
@@ -41,26 +119,7 @@ LIMIT $1 OFFSET $2`
 	var users []entity.Users
 	for rows.Next() {
 		var a entity.Users
-		err := rows.Scan(
-			&a.ID,
-			&a.Taxes,
-			&a.Username,
-			&a.PasswordHash,
-			&a.Email,
-			&a.Admin,
-			&a.Block,
-			&a.Pro,
-			&a.Name,
-			&a.Phone,
-			&a.WbKey,
-			&a.OzonKey,
-			&a.U2782212Wbrosus,
-			&a.OzonStatus,
-			&a.CreatedAt,
-			&a.UpdatedAt,
-			&a.Del,
-			&a.LastLogin,
-		)
+		err := r.scanUser(rows, &a)
 		if err != nil {
 			return nil, err
 		}
@@ -71,10 +130,7 @@ LIMIT $1 OFFSET $2`
 }
 
 func (r *UserRepository) GetByUsername(username string) (*entity.Users, error) {
-	query := `SELECT 
-        id_user, taxes, username, password, email, admin, block, pro, 
-        name, phone, wb_key, ozon_key, u2782212_wbrosus, ozon_status,
-        created_at, updated_at, del, last_login
+	query := `SELECT ` + userColumns + `
         FROM users 
         WHERE username = $1 AND del = 0`
 
@@ -82,26 +138,7 @@ func (r *UserRepository) GetByUsername(username string) (*entity.Users, error) {
 
 	var user entity.Users
 
-	err := row.Scan(
-		&user.ID,
-		&user.Taxes,
-		&user.Username,
-		&user.PasswordHash,
-		&user.Email,
-		&user.Admin,
-		&user.Block,
-		&user.Pro,
-		&user.Name,
-		&user.Phone,
-		&user.WbKey,
-		&user.OzonKey,
-		&user.U2782212Wbrosus,
-		&user.OzonStatus,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-		&user.Del,
-		&user.LastLogin,
-	)
+	err := r.scanUser(row, &user)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -114,10 +151,7 @@ func (r *UserRepository) GetByUsername(username string) (*entity.Users, error) {
 }
 
 func (r *UserRepository) GetByUserId(userId int) (*entity.Users, error) {
-	query := `SELECT 
-        id_user, taxes, username, password, email, admin, block, pro, 
-        name, phone, wb_key, ozon_key, u2782212_wbrosus, ozon_status,
-        created_at, updated_at, del, last_login
+	query := `SELECT ` + userColumns + `
         FROM users 
         WHERE id = $1 AND del = 0`
 
@@ -125,26 +159,7 @@ func (r *UserRepository) GetByUserId(userId int) (*entity.Users, error) {
 
 	var user entity.Users
 
-	err := row.Scan(
-		&user.ID,
-		&user.Taxes,
-		&user.Username,
-		&user.PasswordHash,
-		&user.Email,
-		&user.Admin,
-		&user.Block,
-		&user.Pro,
-		&user.Name,
-		&user.Phone,
-		&user.WbKey,
-		&user.OzonKey,
-		&user.U2782212Wbrosus,
-		&user.OzonStatus,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-		&user.Del,
-		&user.LastLogin,
-	)
+	err := r.scanUser(row, &user)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -209,10 +224,7 @@ func (r *UserRepository) UpdateUserDel(userId, value int) error {
 }
 
 func (r *UserRepository) GetByEmail(email string) (*entity.Users, error) {
-	query := `SELECT 
-        id_user, taxes, username, password, email, admin, block, pro, 
-        name, phone, wb_key, ozon_key, u2782212_wbrosus, ozon_status,
-        created_at, updated_at, del, last_login
+	query := `SELECT ` + userColumns + `
         FROM users 
         WHERE email = $1 AND del = 0`
 
@@ -220,26 +232,7 @@ func (r *UserRepository) GetByEmail(email string) (*entity.Users, error) {
 
 	var user entity.Users
 
-	err := row.Scan(
-		&user.ID,
-		&user.Taxes,
-		&user.Username,
-		&user.PasswordHash,
-		&user.Email,
-		&user.Admin,
-		&user.Block,
-		&user.Pro,
-		&user.Name,
-		&user.Phone,
-		&user.WbKey,
-		&user.OzonKey,
-		&user.U2782212Wbrosus,
-		&user.OzonStatus,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-		&user.Del,
-		&user.LastLogin,
-	)
+	err := r.scanUser(row, &user)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -291,7 +284,16 @@ func (r *UserRepository) Create(user *entity.Users) (*entity.Users, error) {
 		user.OzonKey = sql.NullString{String: "", Valid: false}
 	}
 
-	err := r.db.QueryRow(
+	wbKeyValue, err := r.encryptKey(user.WbKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt wb key: %w", err)
+	}
+	ozonKeyValue, err := r.encryptKey(user.OzonKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt ozon key: %w", err)
+	}
+
+	err = r.db.QueryRow(
 		query,
 		user.Username,
 		user.PasswordHash,
@@ -302,8 +304,8 @@ func (r *UserRepository) Create(user *entity.Users) (*entity.Users, error) {
 		user.Taxes,
 		user.Block,
 		user.Phone,
-		user.WbKey,
-		user.OzonKey,
+		wbKeyValue,
+		ozonKeyValue,
 		user.U2782212Wbrosus,
 		user.OzonStatus,
 		user.Del,
@@ -318,30 +320,11 @@ func (r *UserRepository) Create(user *entity.Users) (*entity.Users, error) {
 }
 
 func (r *UserRepository) GetByID(id int) (*entity.Users, error) {
-	query := `SELECT * FROM users WHERE id_user = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE id_user = $1`
 	row := r.db.QueryRow(query, id)
 
 	var user entity.Users
-	err := row.Scan(
-		&user.ID,
-		&user.Taxes,
-		&user.Username,
-		&user.PasswordHash,
-		&user.Email,
-		&user.Admin,
-		&user.Block,
-		&user.Pro,
-		&user.Name,
-		&user.Phone,
-		&user.WbKey,
-		&user.OzonKey,
-		&user.U2782212Wbrosus,
-		&user.OzonStatus,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-		&user.Del,
-		&user.LastLogin,
-	)
+	err := r.scanUser(row, &user)
 
 	if err != nil {
 		return nil, err
@@ -358,24 +341,45 @@ func (r *UserRepository) UpdateUser(user *entity.Users) error {
             taxes = $4,
             wb_key = $5,
             password = $6,
+            wb_key_expires_at = $7,
+            wb_key_scopes = $8,
+            wb_seller_id = $9,
+            wb_seller_uuid = $10,
+            ozon_key = $11,
+            ozon_client_id = $12,
+            ozon_status = $13,
             updated_at = CURRENT_TIMESTAMP
-        WHERE id_user = $7
+        WHERE id_user = $14
     `
 
 	// Подготавливаем значения для NULL полей
 	nameValue := getNullStringValue(user.Name)
 	emailValue := getNullStringValue(user.Email)
 	phoneValue := getNullStringValue(user.Phone)
-	wbKeyValue := getNullStringValue(user.WbKey)
+	wbKeyValue, err := r.encryptKey(user.WbKey)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt wb key: %w", err)
+	}
+	ozonKeyValue, err := r.encryptKey(user.OzonKey)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt ozon key: %w", err)
+	}
 
 	// Выполняем запрос
-	_, err := r.db.Exec(query,
+	_, err = r.db.Exec(query,
 		nameValue,
 		emailValue,
 		phoneValue,
 		user.Taxes,
 		wbKeyValue,
 		user.PasswordHash,
+		user.WbKeyExpiresAt,
+		user.WbKeyScopes,
+		user.WbSellerID,
+		user.WbSellerUUID,
+		ozonKeyValue,
+		getNullStringValue(user.OzonClientID),
+		user.OzonStatus,
 		user.ID,
 	)
 
@@ -386,6 +390,107 @@ func (r *UserRepository) UpdateUser(user *entity.Users) error {
 	return nil
 }
 
+// UpdateWbKeyInfo сохраняет данные, извлеченные из API ключа WB
+func (r *UserRepository) UpdateWbKeyInfo(user *entity.Users) error {
+	query := `
+		UPDATE users
+		SET wb_key_expires_at = $1, wb_key_scopes = $2, wb_seller_id = $3, wb_seller_uuid = $4
+		WHERE id_user = $5
+	`
+
+	_, err := r.db.Exec(query, user.WbKeyExpiresAt, user.WbKeyScopes, user.WbSellerID, user.WbSellerUUID, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update wb key info: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateOzonStatus сохраняет результат проверки ключа Ozon
+func (r *UserRepository) UpdateOzonStatus(userID, status int) error {
+	_, err := r.db.Exec("UPDATE users SET ozon_status = $1 WHERE id_user = $2", status, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update ozon status: %w", err)
+	}
+
+	return nil
+}
+
+// ReencryptKeys перешифровывает все API ключи текущей версией мастер-ключа.
+// Значения в открытом виде и зашифрованные старыми версиями переписываются,
+// уже актуальные пропускаются. При dryRun только считает, что будет изменено
+func (r *UserRepository) ReencryptKeys(dryRun bool) (int, error) {
+	rows, err := r.db.Query(`
+		SELECT id_user, wb_key, ozon_key
+		FROM users
+		WHERE COALESCE(wb_key, '') != '' OR COALESCE(ozon_key, '') != ''
+		ORDER BY id_user
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to select keys: %w", err)
+	}
+
+	type storedKeys struct {
+		userID  int
+		wbKey   sql.NullString
+		ozonKey sql.NullString
+	}
+
+	var pending []storedKeys
+	for rows.Next() {
+		var k storedKeys
+		if err := rows.Scan(&k.userID, &k.wbKey, &k.ozonKey); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan keys: %w", err)
+		}
+
+		wbCurrent := !k.wbKey.Valid || k.wbKey.String == "" || r.keyring.IsCurrent(k.wbKey.String)
+		ozonCurrent := !k.ozonKey.Valid || k.ozonKey.String == "" || r.keyring.IsCurrent(k.ozonKey.String)
+		if !wbCurrent || !ozonCurrent {
+			pending = append(pending, k)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if dryRun {
+		return len(pending), nil
+	}
+
+	updated := 0
+	for _, k := range pending {
+		wbPlain, err := r.decryptKey(k.wbKey)
+		if err != nil {
+			return updated, fmt.Errorf("wb_key of user %d: %w", k.userID, err)
+		}
+		ozonPlain, err := r.decryptKey(k.ozonKey)
+		if err != nil {
+			return updated, fmt.Errorf("ozon_key of user %d: %w", k.userID, err)
+		}
+
+		wbValue, err := r.encryptKey(wbPlain)
+		if err != nil {
+			return updated, err
+		}
+		ozonValue, err := r.encryptKey(ozonPlain)
+		if err != nil {
+			return updated, err
+		}
+
+		if _, err := r.db.Exec(
+			"UPDATE users SET wb_key = $1, ozon_key = $2 WHERE id_user = $3",
+			wbValue, ozonValue, k.userID,
+		); err != nil {
+			return updated, fmt.Errorf("failed to update keys of user %d: %w", k.userID, err)
+		}
+		updated++
+	}
+
+	return updated, nil
+}
+
 // Вспомогательная функция для работы с NULL
 func getNullStringValue(ns sql.NullString) interface{} {
 	if ns.Valid {
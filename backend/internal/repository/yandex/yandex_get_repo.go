@@ -0,0 +1,110 @@
+package yandex
+
+import (
+	"fmt"
+	"time"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/repository/database/postgres"
+)
+
+type YandexGetRepository struct {
+	db *postgres.PostgresDB
+}
+
+func NewYandexGetRepository(db *postgres.PostgresDB) *YandexGetRepository {
+	return &YandexGetRepository{db: db}
+}
+
+// Create создает новое задание на загрузку данных Яндекс Маркета
+func (r *YandexGetRepository) Create(job *entity.YandexGet) error {
+	query := `
+		INSERT INTO yandex_get (id_user, account_id, type, status, date_from, date_to, last_error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created, updated
+	`
+
+	return r.db.QueryRow(query,
+		job.UserID,
+		job.AccountID,
+		job.Type,
+		job.Status,
+		job.DateFrom,
+		job.DateTo,
+		job.LastError,
+	).Scan(&job.ID, &job.Created, &job.Updated)
+}
+
+// GetByUserID получает задания пользователя (accountID = 0 - по всем кабинетам)
+func (r *YandexGetRepository) GetByUserID(userID, accountID int) ([]entity.YandexGet, error) {
+	query := `
+		SELECT id, id_user, account_id, type, status, date_from, date_to, created, updated, last_error
+		FROM yandex_get
+		WHERE id_user = $1 AND ($2 = 0 OR account_id = $2)
+		ORDER BY created DESC
+	`
+
+	return r.query(query, userID, accountID)
+}
+
+// GetPendingJobs возвращает задания со статусом 0 (в обработке)
+func (r *YandexGetRepository) GetPendingJobs() ([]entity.YandexGet, error) {
+	query := `
+		SELECT id, id_user, account_id, type, status, date_from, date_to, created, updated, last_error
+		FROM yandex_get
+		WHERE status = $1
+		ORDER BY created ASC
+	`
+
+	jobs, err := r.query(query, entity.StatusWait)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending yandex jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+func (r *YandexGetRepository) query(query string, args ...interface{}) ([]entity.YandexGet, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []entity.YandexGet
+	for rows.Next() {
+		var j entity.YandexGet
+		err := rows.Scan(
+			&j.ID,
+			&j.UserID,
+			&j.AccountID,
+			&j.Type,
+			&j.Status,
+			&j.DateFrom,
+			&j.DateTo,
+			&j.Created,
+			&j.Updated,
+			&j.LastError,
+		)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+
+	return jobs, nil
+}
+
+// UpdateStatus обновляет статус задания
+func (r *YandexGetRepository) UpdateStatus(jobID int, status int, errorMsg string) error {
+	query := `
+		UPDATE yandex_get
+		SET status = $1, last_error = $2, updated = $3
+		WHERE id = $4
+	`
+
+	_, err := r.db.Exec(query, status, errorMsg, time.Now(), jobID)
+	if err != nil {
+		return fmt.Errorf("failed to update yandex job status: %w", err)
+	}
+
+	return nil
+}
@@ -9,6 +9,12 @@ func SetupRoutes(
 	authHandler *handler.AuthHandler,
 	wbStatsHandler *handler.WBStatsHandler,
 	wbArticlesHandler *handler.WBArticlesHandler,
+	sellerAccountsHandler *handler.SellerAccountsHandler,
+	organizationsHandler *handler.OrganizationsHandler,
+	ozonHandler *handler.OzonHandler,
+	yandexHandler *handler.YandexHandler,
+	profileHandler *handler.ProfileHandler,
+	auditHandler *handler.AuditHandler,
 ) http.Handler {
 	mux := http.NewServeMux()
 
@@ -21,9 +27,121 @@ func SetupRoutes(
 	mux.HandleFunc("/api/profile/apikeys/status", authHandler.GetApiKeysStatus)
 	mux.HandleFunc("/api/profile/update", authHandler.UpdateProfile)
 
+	// Выгрузка данных и удаление аккаунта
+	mux.HandleFunc("/api/profile", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			profileHandler.GetDeletion(w, r)
+		case http.MethodDelete:
+			profileHandler.DeleteProfile(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/profile/export", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			profileHandler.ExportProfile(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/profile/delete/cancel", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			profileHandler.CancelDeletion(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
 	// Обновление пользователя из админки (заблокировать, удалить, выдать права или забрать PRO)
 	mux.HandleFunc("/api/user/update", authHandler.UpdateUserParams)
 
+	mux.HandleFunc("/api/admin/deletions", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			profileHandler.GetPendingDeletions(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Журнал действий администраторов и пользователей
+	mux.HandleFunc("/api/admin/audit", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			auditHandler.GetAuditLog(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Кабинеты продавца Роуты
+	mux.HandleFunc("/api/accounts", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			sellerAccountsHandler.GetAccounts(w, r)
+		case http.MethodPost:
+			sellerAccountsHandler.CreateAccount(w, r)
+		case http.MethodPut:
+			sellerAccountsHandler.UpdateAccount(w, r)
+		case http.MethodDelete:
+			sellerAccountsHandler.DeleteAccount(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Организации Роуты
+	mux.HandleFunc("/api/organizations", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			organizationsHandler.GetOrganizations(w, r)
+		case http.MethodPut:
+			organizationsHandler.UpdateOrganization(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/organizations/members", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			organizationsHandler.GetMembers(w, r)
+		case http.MethodPut:
+			organizationsHandler.UpdateMember(w, r)
+		case http.MethodDelete:
+			organizationsHandler.RemoveMember(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/organizations/invites", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			organizationsHandler.GetMyInvites(w, r)
+		case http.MethodPost:
+			organizationsHandler.CreateInvite(w, r)
+		case http.MethodDelete:
+			organizationsHandler.RevokeInvite(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/organizations/invites/respond", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			organizationsHandler.RespondInvite(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
 	// WB-Отчеты Роуты
 	mux.HandleFunc("/api/wb/stats", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
@@ -36,6 +154,24 @@ func SetupRoutes(
 		}
 	})
 
+	mux.HandleFunc("/api/wb/realization-reports", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			wbStatsHandler.GetRealizationReports(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/wb/realization-reports/payout", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			wbStatsHandler.SaveReportPayout(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
 	// Статистика Роуты
 	mux.HandleFunc("/api/stat/details", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
@@ -46,6 +182,82 @@ func SetupRoutes(
 		}
 	})
 
+	mux.HandleFunc("/api/stat/sizes", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			wbStatsHandler.GetSizeDetails(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/stat/unit-economics", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			wbStatsHandler.GetUnitEconomics(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/stat/tax-estimate", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			wbStatsHandler.GetTaxEstimate(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/stat/abc-xyz", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			wbStatsHandler.GetABCXYZ(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/stat/forecast", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			wbStatsHandler.GetDemandForecast(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/stat/returns", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			wbStatsHandler.GetReturnAnalytics(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/stat/logistics", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			wbStatsHandler.GetLogisticsAnalytics(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/tax-profiles", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			wbStatsHandler.GetTaxProfiles(w, r)
+		case http.MethodPost:
+			wbStatsHandler.SaveTaxProfile(w, r)
+		case http.MethodDelete:
+			wbStatsHandler.DeleteTaxProfile(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
 	// Карточки товаров Роуты
 	mux.HandleFunc("/api/articles", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
@@ -56,6 +268,15 @@ func SetupRoutes(
 		}
 	})
 
+	mux.HandleFunc("/api/articles/filters", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			wbArticlesHandler.GetArticleFilters(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
 	mux.HandleFunc("/api/articles/request", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
@@ -67,6 +288,8 @@ func SetupRoutes(
 
 	mux.HandleFunc("/api/articles/cost-price", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
+		case http.MethodGet:
+			wbArticlesHandler.GetCostPriceHistory(w, r)
 		case http.MethodPost:
 			wbArticlesHandler.UpdateCostPrice(w, r)
 		default:
@@ -74,6 +297,48 @@ func SetupRoutes(
 		}
 	})
 
+	// Черновики изменений карточек WB и их отправка в API контента
+	mux.HandleFunc("/api/articles/drafts", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			wbArticlesHandler.GetCardDrafts(w, r)
+		case http.MethodPost:
+			wbArticlesHandler.SaveCardDraft(w, r)
+		case http.MethodDelete:
+			wbArticlesHandler.DeleteCardDraft(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/articles/drafts/submit", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			wbArticlesHandler.SubmitCardDrafts(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/articles/subject-characteristics", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			wbArticlesHandler.GetSubjectCharacteristics(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Журнал изменений карточек (сопоставление продаж с правками карточек)
+	mux.HandleFunc("/api/articles/changes", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			wbArticlesHandler.GetArticleChanges(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
 	mux.HandleFunc("/api/dashboard/stats", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
@@ -83,6 +348,57 @@ func SetupRoutes(
 		}
 	})
 
+	mux.HandleFunc("/api/dashboard/accounts", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			wbStatsHandler.GetAccountsDashboard(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Ozon Роуты
+	mux.HandleFunc("/api/ozon/jobs", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			ozonHandler.GetJobs(w, r)
+		case http.MethodPost:
+			ozonHandler.CreateJob(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/ozon/products", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			ozonHandler.GetProducts(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/ozon/stats", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			ozonHandler.GetStats(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Яндекс Маркет Роуты
+	mux.HandleFunc("/api/yandex/jobs", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			yandexHandler.GetJobs(w, r)
+		case http.MethodPost:
+			yandexHandler.CreateJob(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
 	mux.HandleFunc("/api/site/users", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
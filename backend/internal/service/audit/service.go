@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"database/sql"
+	"fmt"
+	"wbrost-go/internal/crypto"
+	"wbrost-go/internal/entity"
+	auditrepo "wbrost-go/internal/repository/audit"
+)
+
+// secretFields - поля состояния, которые нельзя писать в журнал как есть:
+// ключи сохраняются маской, пароль - только отметкой об изменении
+var secretFields = map[string]bool{
+	"wb_key":   true,
+	"ozon_key": true,
+	"api_key":  true,
+	"password": true,
+}
+
+// redactedValue - значение пароля в журнале
+const redactedValue = "[redacted]"
+
+type AuditService struct {
+	repo *auditrepo.AuditRepository
+}
+
+func NewAuditService(repo *auditrepo.AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// Record пишет действие в журнал. Ошибка записи не должна отменять само действие,
+// поэтому она только логируется
+func (s *AuditService) Record(meta entity.AuditMeta, entry entity.AuditLog) {
+	entry.ActorID = sql.NullInt64{Int64: int64(meta.ActorID), Valid: meta.ActorID != 0}
+	entry.IP = sql.NullString{String: meta.IP, Valid: meta.IP != ""}
+	entry.UserAgent = sql.NullString{String: truncate(meta.UserAgent, 500), Valid: meta.UserAgent != ""}
+	entry.Before = redact(entry.Before)
+	entry.After = redact(entry.After)
+
+	if err := s.repo.Create(&entry); err != nil {
+		fmt.Printf("⚠️ Audit %s by user %d: %v\n", entry.Action, meta.ActorID, err)
+	}
+}
+
+// List - журнал по фильтрам с общим количеством записей
+func (s *AuditService) List(filter entity.AuditFilter) ([]entity.AuditLog, int, error) {
+	return s.repo.List(filter)
+}
+
+// redact возвращает копию состояния со скрытыми секретами
+func redact(state map[string]interface{}) map[string]interface{} {
+	if state == nil {
+		return nil
+	}
+
+	result := make(map[string]interface{}, len(state))
+	for key, value := range state {
+		if !secretFields[key] {
+			result[key] = value
+			continue
+		}
+
+		secret, _ := value.(string)
+		switch {
+		case secret == "":
+			result[key] = ""
+		case key == "password":
+			result[key] = redactedValue
+		default:
+			result[key] = crypto.Mask(secret)
+		}
+	}
+
+	return result
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
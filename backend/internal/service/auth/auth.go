@@ -2,9 +2,14 @@ package auth
 
 import (
 	"database/sql"
+	"fmt"
+	"strconv"
 	"time"
+	"wbrost-go/internal/api/wb"
 	"wbrost-go/internal/entity"
+	"wbrost-go/internal/repository/account"
 	"wbrost-go/internal/repository/user"
+	"wbrost-go/internal/service/audit"
 )
 
 type CreateUserDTO struct {
@@ -17,11 +22,13 @@ type CreateUserDTO struct {
 }
 
 type AuthService struct {
-	userRepo *user.UserRepository
+	userRepo     *user.UserRepository
+	accountRepo  *account.SellerAccountRepository
+	auditService *audit.AuditService
 }
 
-func NewAuthService(userRepo *user.UserRepository) *AuthService {
-	return &AuthService{userRepo: userRepo}
+func NewAuthService(userRepo *user.UserRepository, accountRepo *account.SellerAccountRepository, auditService *audit.AuditService) *AuthService {
+	return &AuthService{userRepo: userRepo, accountRepo: accountRepo, auditService: auditService}
 }
 
 func (s *AuthService) GetUserByUsername(username string) (*entity.Users, error) {
@@ -58,28 +65,182 @@ func (s *AuthService) CreateUser(dto CreateUserDTO) (*entity.Users, error) {
 	return s.userRepo.Create(user)
 }
 
-func (s *AuthService) UpdateUserFromParams(UserId int, ActionType string, Value int) error {
+// SetWbKey устанавливает пользователю API ключ WB вместе с данными из его payload
+// (срок действия, категории доступа, продавец). Пустой ключ очищает все поля
+func (s *AuthService) SetWbKey(user *entity.Users, wbKey string) error {
+	if wbKey == "" {
+		user.WbKey = sql.NullString{}
+		user.WbKeyExpiresAt = sql.NullTime{}
+		user.WbKeyScopes = sql.NullInt64{}
+		user.WbSellerID = sql.NullInt64{}
+		user.WbSellerUUID = sql.NullString{}
+		return nil
+	}
+
+	info, err := wb.ParseToken(wbKey)
+	if err != nil {
+		return err
+	}
+
+	user.WbKey = sql.NullString{String: wbKey, Valid: true}
+	applyWbTokenInfo(user, info)
+	return nil
+}
+
+// SyncWbKeyInfo заполняет данные токена WB у пользователей, сохранивших ключ
+// до появления этих полей. Возвращает разобранный токен
+func (s *AuthService) SyncWbKeyInfo(user *entity.Users) (*wb.TokenInfo, error) {
+	info, err := wb.ParseToken(user.WbKey.String)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.WbKeyScopes.Valid || user.WbKeyScopes.Int64 != info.Scopes {
+		applyWbTokenInfo(user, info)
+		if err := s.userRepo.UpdateWbKeyInfo(user); err != nil {
+			return info, err
+		}
+	}
+
+	return info, nil
+}
+
+// DefaultAccountName - название кабинета, созданного из ключа в профиле
+const DefaultAccountName = "Основной кабинет"
+
+// SyncDefaultAccount переносит ключ WB из профиля в кабинет по умолчанию:
+// создает его при первом сохранении ключа, обновляет ключ при изменении.
+// При очистке ключа кабинет остается (вместе с его данными), но без ключа
+func (s *AuthService) SyncDefaultAccount(user *entity.Users) error {
+	accounts, err := s.accountRepo.GetByUserID(user.ID)
+	if err != nil {
+		return err
+	}
+
+	var defaultAccount *entity.SellerAccount
+	for i := range accounts {
+		if accounts[i].IsDefault == 1 && accounts[i].Marketplace == entity.MarketplaceWB {
+			defaultAccount = &accounts[i]
+			break
+		}
+	}
+
+	if defaultAccount == nil {
+		if !user.WbKey.Valid || user.WbKey.String == "" {
+			return nil
+		}
+		defaultAccount = &entity.SellerAccount{
+			UserID:      user.ID,
+			Marketplace: entity.MarketplaceWB,
+			Name:        DefaultAccountName,
+			IsDefault:   1,
+		}
+		applyAccountKey(defaultAccount, user)
+		return s.accountRepo.Create(defaultAccount)
+	}
+
+	if defaultAccount.APIKey.String == user.WbKey.String {
+		return nil
+	}
+
+	applyAccountKey(defaultAccount, user)
+	return s.accountRepo.Update(defaultAccount)
+}
+
+func applyAccountKey(a *entity.SellerAccount, user *entity.Users) {
+	a.APIKey = user.WbKey
+	a.KeyExpiresAt = user.WbKeyExpiresAt
+	a.KeyScopes = user.WbKeyScopes
+	a.SellerID = user.WbSellerID
+	a.SellerUUID = user.WbSellerUUID
+}
+
+func applyWbTokenInfo(user *entity.Users, info *wb.TokenInfo) {
+	user.WbKeyExpiresAt = sql.NullTime{Time: info.ExpiresAt, Valid: !info.ExpiresAt.IsZero()}
+	user.WbKeyScopes = sql.NullInt64{Int64: info.Scopes, Valid: true}
+	user.WbSellerID = sql.NullInt64{Int64: info.SellerID, Valid: info.SellerID != 0}
+	user.WbSellerUUID = sql.NullString{String: info.SellerUUID, Valid: info.SellerUUID != ""}
+}
+
+// UpdateUserFromParams применяет действие админа к пользователю и пишет его в журнал
+func (s *AuthService) UpdateUserFromParams(meta entity.AuditMeta, UserId int, ActionType string, Value int) error {
+	target, err := s.userRepo.GetByID(UserId)
+	if err != nil {
+		return fmt.Errorf("user %d not found: %w", UserId, err)
+	}
+
+	var action string
+	var before int
+
 	switch ActionType {
 	case "admin":
-		err := s.userRepo.UpdateUserAdmin(UserId, Value)
-		if err != nil {
-			return err
-		}
+		action, before = entity.AuditUserAdmin, target.Admin
+		err = s.userRepo.UpdateUserAdmin(UserId, Value)
 	case "pro":
-		err := s.userRepo.UpdateUserPro(UserId, Value)
-		if err != nil {
-			return err
-		}
+		action, before = entity.AuditUserPro, target.Pro
+		err = s.userRepo.UpdateUserPro(UserId, Value)
 	case "block":
-		err := s.userRepo.UpdateUserBlock(UserId, Value)
-		if err != nil {
-			return err
-		}
+		action, before = entity.AuditUserBlock, target.Block
+		err = s.userRepo.UpdateUserBlock(UserId, Value)
 	case "del":
-		err := s.userRepo.UpdateUserDel(UserId, Value)
-		if err != nil {
-			return err
-		}
+		action, before = entity.AuditUserDel, target.Del
+		err = s.userRepo.UpdateUserDel(UserId, Value)
+	default:
+		return nil
 	}
+	if err != nil {
+		return err
+	}
+
+	s.auditService.Record(meta, entity.AuditLog{
+		TargetUserID: sql.NullInt64{Int64: int64(UserId), Valid: true},
+		TargetType:   entity.AuditTargetUser,
+		TargetID:     sql.NullString{String: strconv.Itoa(UserId), Valid: true},
+		Action:       action,
+		Before:       map[string]interface{}{ActionType: before},
+		After:        map[string]interface{}{ActionType: Value},
+	})
 	return nil
 }
+
+// RecordProfileUpdate пишет в журнал измененные поля профиля (ключи и пароль - без значений)
+func (s *AuthService) RecordProfileUpdate(meta entity.AuditMeta, before, after *entity.Users) {
+	oldState := profileState(before)
+	newState := profileState(after)
+
+	changedBefore := map[string]interface{}{}
+	changedAfter := map[string]interface{}{}
+	for key, value := range newState {
+		if oldState[key] != value {
+			changedBefore[key] = oldState[key]
+			changedAfter[key] = value
+		}
+	}
+
+	if len(changedAfter) == 0 {
+		return
+	}
+
+	s.auditService.Record(meta, entity.AuditLog{
+		TargetUserID: sql.NullInt64{Int64: int64(after.ID), Valid: true},
+		TargetType:   entity.AuditTargetUser,
+		TargetID:     sql.NullString{String: strconv.Itoa(after.ID), Valid: true},
+		Action:       entity.AuditProfileUpdate,
+		Before:       changedBefore,
+		After:        changedAfter,
+	})
+}
+
+// profileState - поля профиля, изменения которых попадают в журнал
+func profileState(u *entity.Users) map[string]interface{} {
+	return map[string]interface{}{
+		"name":           u.Name.String,
+		"email":          u.Email.String,
+		"phone":          u.Phone.String,
+		"taxes":          u.Taxes,
+		"wb_key":         u.WbKey.String,
+		"ozon_key":       u.OzonKey.String,
+		"ozon_client_id": u.OzonClientID.String,
+		"password":       u.PasswordHash,
+	}
+}
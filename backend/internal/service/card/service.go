@@ -0,0 +1,396 @@
+package card
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+	"wbrost-go/internal/api/wb"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/repository/article"
+)
+
+// Ограничения API контента WB
+const (
+	maxTitleLength       = 60
+	maxDescriptionLength = 5000
+	maxVendorCodeLength  = 72
+)
+
+var (
+	ErrCardNotFound  = errors.New("Карточка не найдена")
+	ErrDraftNotFound = errors.New("Черновик не найден")
+	ErrDraftLocked   = errors.New("Черновик уже отправлен в WB, дождитесь результата")
+)
+
+// CardService - черновики изменений карточек WB: проверка и постановка в задание отправки
+type CardService struct {
+	articleRepo *article.WBArticlesRepository
+	updateRepo  *article.WBCardUpdateRepository
+}
+
+func NewCardService(articleRepo *article.WBArticlesRepository, updateRepo *article.WBCardUpdateRepository) *CardService {
+	return &CardService{
+		articleRepo: articleRepo,
+		updateRepo:  updateRepo,
+	}
+}
+
+// SubmitResult - результат отправки черновиков: созданные задания (по кабинетам)
+// и черновики, не прошедшие проверку (nm_id -> ошибки)
+type SubmitResult struct {
+	Updates []entity.WBCardUpdate
+	Queued  int
+	Invalid map[int64][]string
+}
+
+// SaveDraft проверяет и сохраняет черновик. Черновик с ошибками тоже сохраняется
+// (ошибки - в draft.Errors), но отправить его нельзя
+func (s *CardService) SaveDraft(draft *entity.WBCardDraft) error {
+	existing, err := s.updateRepo.GetDraft(draft.UserID, draft.NmID)
+	if err != nil {
+		return err
+	}
+	if existing != nil && !existing.Editable() {
+		return ErrDraftLocked
+	}
+
+	card, err := s.articleRepo.GetByNmID(draft.UserID, draft.NmID)
+	if err != nil {
+		return fmt.Errorf("failed to get article: %w", err)
+	}
+	if card == nil {
+		return ErrCardNotFound
+	}
+
+	draft.AccountID = card.AccountID
+	if draft.Errors, err = s.Validate(card, draft); err != nil {
+		return err
+	}
+
+	return s.updateRepo.SaveDraft(draft)
+}
+
+// ListDrafts возвращает черновики пользователя (status = "" - все)
+func (s *CardService) ListDrafts(userID int, status string) ([]entity.WBCardDraft, error) {
+	return s.updateRepo.ListDrafts(userID, status)
+}
+
+// GetDraft возвращает черновик карточки (nil - черновика нет)
+func (s *CardService) GetDraft(userID int, nmID int64) (*entity.WBCardDraft, error) {
+	return s.updateRepo.GetDraft(userID, nmID)
+}
+
+// DeleteDraft удаляет черновик карточки, если он не отправлен в WB
+func (s *CardService) DeleteDraft(userID int, nmID int64) error {
+	existing, err := s.updateRepo.GetDraft(userID, nmID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrDraftNotFound
+	}
+	if !existing.Editable() {
+		return ErrDraftLocked
+	}
+
+	deleted, err := s.updateRepo.DeleteDraft(userID, nmID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrDraftLocked
+	}
+	return nil
+}
+
+// SubjectCharacteristics возвращает характеристики предмета (обязательные - первыми)
+func (s *CardService) SubjectCharacteristics(subjectID int) ([]entity.WBSubjectCharacteristic, error) {
+	return s.updateRepo.GetSubjectCharacteristics(subjectID)
+}
+
+// Submit повторно проверяет черновики nmIDs (пусто - все черновики со статусом draft)
+// и ставит прошедшие проверку в задания отправки, по одному на кабинет
+func (s *CardService) Submit(userID int, nmIDs []int64) (*SubmitResult, error) {
+	drafts, err := s.updateRepo.ListDrafts(userID, entity.CardDraftStatusDraft)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make(map[int64]bool, len(nmIDs))
+	for _, nmID := range nmIDs {
+		selected[nmID] = true
+	}
+
+	result := &SubmitResult{Invalid: make(map[int64][]string)}
+	byAccount := make(map[sql.NullInt64][]int64)
+	var accounts []sql.NullInt64
+
+	for i := range drafts {
+		draft := &drafts[i]
+		if len(selected) > 0 && !selected[draft.NmID] {
+			continue
+		}
+
+		card, err := s.articleRepo.GetByNmID(userID, draft.NmID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get article: %w", err)
+		}
+
+		issues := []string{ErrCardNotFound.Error()}
+		if card != nil {
+			if issues, err = s.Validate(card, draft); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(issues) > 0 {
+			result.Invalid[draft.NmID] = issues
+			if err := s.updateRepo.SetDraftResult(draft.ID, entity.CardDraftStatusDraft, issues); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if _, ok := byAccount[draft.AccountID]; !ok {
+			accounts = append(accounts, draft.AccountID)
+		}
+		byAccount[draft.AccountID] = append(byAccount[draft.AccountID], draft.NmID)
+	}
+
+	for _, accountID := range accounts {
+		update := entity.WBCardUpdate{
+			UserID:    userID,
+			AccountID: accountID,
+			Status:    sql.NullInt64{Int64: entity.ArticlesStatusWait, Valid: true},
+		}
+		queued, err := s.updateRepo.CreateUpdate(&update, byAccount[accountID])
+		if err != nil {
+			return nil, err
+		}
+		result.Updates = append(result.Updates, update)
+		result.Queued += queued
+	}
+
+	return result, nil
+}
+
+// Validate проверяет черновик по ограничениям WB и характеристикам предмета карточки.
+// Возвращает список ошибок (пустой - черновик можно отправлять)
+func (s *CardService) Validate(card *entity.WBArticles, draft *entity.WBCardDraft) ([]string, error) {
+	issues := []string{}
+
+	if card.ArchivedAt.Valid {
+		issues = append(issues, fmt.Sprintf("Карточка в архиве (%s)", card.ArchiveReason.String))
+	}
+
+	if draft.Title.Valid {
+		if draft.Title.String == "" {
+			issues = append(issues, "Название не может быть пустым")
+		} else if utf8.RuneCountInString(draft.Title.String) > maxTitleLength {
+			issues = append(issues, fmt.Sprintf("Название длиннее %d символов", maxTitleLength))
+		}
+	}
+	if draft.Description.Valid && utf8.RuneCountInString(draft.Description.String) > maxDescriptionLength {
+		issues = append(issues, fmt.Sprintf("Описание длиннее %d символов", maxDescriptionLength))
+	}
+	if draft.VendorCode.Valid {
+		if draft.VendorCode.String == "" {
+			issues = append(issues, "Артикул продавца не может быть пустым")
+		} else if utf8.RuneCountInString(draft.VendorCode.String) > maxVendorCodeLength {
+			issues = append(issues, fmt.Sprintf("Артикул продавца длиннее %d символов", maxVendorCodeLength))
+		}
+	}
+
+	dimensions := []struct {
+		name  string
+		value sql.NullFloat64
+	}{
+		{"Длина упаковки", draft.Length},
+		{"Ширина упаковки", draft.Width},
+		{"Высота упаковки", draft.Height},
+		{"Вес с упаковкой", draft.WeightBrutto},
+	}
+	for _, d := range dimensions {
+		if d.value.Valid && d.value.Float64 <= 0 {
+			issues = append(issues, d.name+": значение должно быть больше нуля")
+		}
+	}
+
+	// Характеристики проверяются по справочнику предмета (загружается вместе с карточками)
+	subjectCharcs, err := s.updateRepo.GetSubjectCharacteristics(int(card.SubjectID.Int64))
+	if err != nil {
+		return nil, err
+	}
+	if len(subjectCharcs) == 0 {
+		return append(issues, "Характеристики предмета не загружены: обновите карточки"), nil
+	}
+
+	charcs := make(map[int]entity.WBSubjectCharacteristic, len(subjectCharcs))
+	for _, c := range subjectCharcs {
+		charcs[c.CharcID] = c
+	}
+
+	for _, c := range draft.Characteristics {
+		charc, ok := charcs[c.ID]
+		if !ok {
+			issues = append(issues, fmt.Sprintf("Характеристика %d не относится к предмету %s", c.ID, card.SubjectName.String))
+			continue
+		}
+		if issue := checkValue(charc, c.Value); issue != "" {
+			issues = append(issues, issue)
+		}
+	}
+
+	stored, err := s.articleRepo.GetCharacteristics(card.UserID, []int64{draft.NmID})
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[int]json.RawMessage)
+	for _, c := range MergeCharacteristics(stored[draft.NmID], draft.Characteristics) {
+		values[c.ID] = c.Value
+	}
+	for _, c := range subjectCharcs {
+		if c.Required && isEmptyValue(values[c.CharcID]) {
+			issues = append(issues, fmt.Sprintf("Не заполнена обязательная характеристика «%s»", c.Name))
+		}
+	}
+
+	return issues, nil
+}
+
+// checkValue - тип значения (число или строки) и количество значений характеристики
+func checkValue(charc entity.WBSubjectCharacteristic, value json.RawMessage) string {
+	if isEmptyValue(value) {
+		if charc.Required {
+			return fmt.Sprintf("Не заполнена обязательная характеристика «%s»", charc.Name)
+		}
+		return ""
+	}
+
+	if charc.CharcType == entity.SubjectCharcTypeNumber {
+		var number float64
+		if json.Unmarshal(value, &number) != nil {
+			return fmt.Sprintf("Характеристика «%s» должна быть числом", charc.Name)
+		}
+		return ""
+	}
+
+	var list []string
+	if json.Unmarshal(value, &list) != nil {
+		var single string
+		if json.Unmarshal(value, &single) != nil {
+			return fmt.Sprintf("Характеристика «%s» должна быть строкой или списком строк", charc.Name)
+		}
+		list = []string{single}
+	}
+	if charc.MaxCount > 0 && len(list) > charc.MaxCount {
+		return fmt.Sprintf("У характеристики «%s» не больше %d значений", charc.Name, charc.MaxCount)
+	}
+	return ""
+}
+
+// isEmptyValue - значение не задано: null, пустая строка или пустой список
+func isEmptyValue(value json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(value)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) || bytes.Equal(trimmed, []byte(`""`)) {
+		return true
+	}
+
+	var list []string
+	if json.Unmarshal(trimmed, &list) == nil {
+		for _, v := range list {
+			if v != "" {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// MergeCharacteristics - сохраненные характеристики карточки с изменениями черновика
+// (пустое значение в черновике удаляет характеристику)
+func MergeCharacteristics(stored []entity.WBArticleCharacteristic, changes []entity.CardDraftCharacteristic) []wb.CardUpdateCharacteristic {
+	changed := make(map[int]json.RawMessage, len(changes))
+	for _, c := range changes {
+		changed[c.ID] = c.Value
+	}
+
+	merged := make([]wb.CardUpdateCharacteristic, 0, len(stored)+len(changes))
+	seen := make(map[int]bool, len(stored))
+	for _, c := range stored {
+		seen[c.CharacteristicID] = true
+		value := c.Value
+		if v, ok := changed[c.CharacteristicID]; ok {
+			value = v
+		}
+		if !isEmptyValue(value) {
+			merged = append(merged, wb.CardUpdateCharacteristic{ID: c.CharacteristicID, Value: value})
+		}
+	}
+	for _, c := range changes {
+		if !seen[c.ID] && !isEmptyValue(c.Value) {
+			merged = append(merged, wb.CardUpdateCharacteristic{ID: c.ID, Value: c.Value})
+		}
+	}
+	return merged
+}
+
+// BuildUpdate собирает карточку для отправки в WB: сохраненная карточка целиком
+// (WB удаляет неотправленные размеры и характеристики) с изменениями черновика
+func BuildUpdate(card *entity.WBArticles, sizes []entity.WBArticleSize, characteristics []entity.WBArticleCharacteristic, draft *entity.WBCardDraft) (wb.CardUpdate, error) {
+	nmID, err := strconv.Atoi(card.Articule)
+	if err != nil {
+		return wb.CardUpdate{}, fmt.Errorf("invalid nm_id %q: %w", card.Articule, err)
+	}
+
+	update := wb.CardUpdate{
+		NmID:        nmID,
+		VendorCode:  pick(draft.VendorCode, card.InternalID),
+		Brand:       card.Brand.String,
+		Title:       pick(draft.Title, card.Name),
+		Description: pick(draft.Description, card.Description),
+		Dimensions: wb.CardUpdateDimensions{
+			Length:       pickFloat(draft.Length, card.Length),
+			Width:        pickFloat(draft.Width, card.Width),
+			Height:       pickFloat(draft.Height, card.Height),
+			WeightBrutto: pickFloat(draft.WeightBrutto, card.WeightBrutto),
+		},
+		Characteristics: MergeCharacteristics(characteristics, draft.Characteristics),
+		Sizes:           make([]wb.CardUpdateSize, 0, len(sizes)),
+	}
+
+	for _, size := range sizes {
+		skus := size.Skus
+		if skus == nil {
+			skus = []string{}
+		}
+		update.Sizes = append(update.Sizes, wb.CardUpdateSize{
+			ChrtID:   int(size.ChrtID),
+			TechSize: size.TechSize,
+			WbSize:   size.WbSize,
+			Skus:     skus,
+		})
+	}
+
+	return update, nil
+}
+
+// pick - значение черновика, если оно задано, иначе значение карточки
+func pick(draft, card sql.NullString) string {
+	if draft.Valid {
+		return draft.String
+	}
+	return card.String
+}
+
+func pickFloat(draft, card sql.NullFloat64) float64 {
+	if draft.Valid {
+		return draft.Float64
+	}
+	return card.Float64
+}
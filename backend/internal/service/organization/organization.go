@@ -0,0 +1,312 @@
+package organization
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/repository/organization"
+	"wbrost-go/internal/repository/user"
+)
+
+var (
+	ErrNoAccess        = errors.New("Нет доступа к организации")
+	ErrForbidden       = errors.New("Недостаточно прав для этого действия")
+	ErrInvalidRole     = errors.New("Недопустимая роль")
+	ErrInviteNotFound  = errors.New("Приглашение не найдено")
+	ErrAlreadyMember   = errors.New("Пользователь уже состоит в организации")
+	ErrOwnerImmutable  = errors.New("Роль владельца изменить нельзя")
+	ErrInviteRecipient = errors.New("Укажите email или username приглашаемого")
+)
+
+// Access - пользователь запроса в контексте выбранной организации
+type Access struct {
+	User         *entity.Users
+	Organization *entity.Organization
+	Role         string
+	Owner        *entity.Users // Владелец организации: под его id_user хранятся кабинеты и данные
+}
+
+// Can проверяет право роли пользователя
+func (a *Access) Can(perm entity.Permission) bool {
+	return entity.RoleAllows(a.Role, perm)
+}
+
+// OwnerID - id_user, под которым хранятся данные организации
+func (a *Access) OwnerID() int {
+	return a.Organization.OwnerID
+}
+
+// Membership - организация пользователя и его роль в ней
+type Membership struct {
+	Organization *entity.Organization
+	Role         string
+}
+
+type OrganizationService struct {
+	orgRepo  *organization.OrganizationRepository
+	userRepo *user.UserRepository
+}
+
+func NewOrganizationService(orgRepo *organization.OrganizationRepository, userRepo *user.UserRepository) *OrganizationService {
+	return &OrganizationService{orgRepo: orgRepo, userRepo: userRepo}
+}
+
+// EnsurePersonal возвращает собственную организацию пользователя, создавая ее при первом обращении
+func (s *OrganizationService) EnsurePersonal(u *entity.Users) (*entity.Organization, error) {
+	org, err := s.orgRepo.GetByOwnerID(u.ID)
+	if err != nil || org != nil {
+		return org, err
+	}
+
+	name := u.Username
+	if u.Name.Valid && u.Name.String != "" {
+		name = u.Name.String
+	}
+
+	org = &entity.Organization{Name: name, OwnerID: u.ID}
+	if err := s.orgRepo.CreateWithOwner(org); err != nil {
+		return nil, err
+	}
+
+	return org, nil
+}
+
+// Access определяет роль пользователя в организации. organizationID = 0 - собственная организация
+func (s *OrganizationService) Access(u *entity.Users, organizationID int) (*Access, error) {
+	if organizationID == 0 {
+		org, err := s.EnsurePersonal(u)
+		if err != nil {
+			return nil, err
+		}
+		return &Access{User: u, Organization: org, Role: entity.RoleOwner, Owner: u}, nil
+	}
+
+	org, err := s.orgRepo.GetByID(organizationID)
+	if err != nil {
+		return nil, ErrNoAccess
+	}
+
+	member, err := s.orgRepo.GetMember(org.ID, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrNoAccess
+	}
+
+	owner := u
+	if org.OwnerID != u.ID {
+		owner, err = s.userRepo.GetByID(org.OwnerID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Access{User: u, Organization: org, Role: member.Role, Owner: owner}, nil
+}
+
+// GetMemberships возвращает организации пользователя (собственная - первой)
+func (s *OrganizationService) GetMemberships(u *entity.Users) ([]Membership, error) {
+	if _, err := s.EnsurePersonal(u); err != nil {
+		return nil, err
+	}
+
+	members, err := s.orgRepo.GetMembershipsByUserID(u.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	memberships := make([]Membership, 0, len(members))
+	for _, m := range members {
+		org, err := s.orgRepo.GetByID(m.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		memberships = append(memberships, Membership{Organization: org, Role: m.Role})
+	}
+
+	return memberships, nil
+}
+
+// Rename переименовывает организацию (только владелец)
+func (s *OrganizationService) Rename(access *Access, name string) error {
+	if access.Role != entity.RoleOwner {
+		return ErrForbidden
+	}
+	access.Organization.Name = name
+	return s.orgRepo.UpdateName(access.Organization.ID, name)
+}
+
+// GetMembers возвращает участников организации вместе с пользователями
+func (s *OrganizationService) GetMembers(access *Access) ([]entity.OrganizationMember, map[int]*entity.Users, error) {
+	members, err := s.orgRepo.GetMembers(access.Organization.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	users := make(map[int]*entity.Users, len(members))
+	for _, m := range members {
+		u, err := s.userRepo.GetByID(m.UserID)
+		if err != nil {
+			continue
+		}
+		users[m.UserID] = u
+	}
+
+	return members, users, nil
+}
+
+// GetPendingInvites возвращает ожидающие приглашения организации
+func (s *OrganizationService) GetPendingInvites(access *Access) ([]entity.OrganizationInvite, error) {
+	if !access.Can(entity.PermManageMembers) {
+		return nil, ErrForbidden
+	}
+	return s.orgRepo.GetPendingInvitesByOrganization(access.Organization.ID)
+}
+
+// Invite приглашает пользователя по email или username
+func (s *OrganizationService) Invite(access *Access, email, username, role string) (*entity.OrganizationInvite, error) {
+	if !access.Can(entity.PermManageMembers) {
+		return nil, ErrForbidden
+	}
+	if !entity.ValidInviteRole(role) {
+		return nil, ErrInvalidRole
+	}
+
+	email = strings.TrimSpace(email)
+	username = strings.TrimSpace(username)
+	if email == "" && username == "" {
+		return nil, ErrInviteRecipient
+	}
+
+	// Если пользователь уже зарегистрирован - проверяем, что он еще не участник
+	var invitee *entity.Users
+	if username != "" {
+		invitee, _ = s.userRepo.GetByUsername(username)
+	} else {
+		invitee, _ = s.userRepo.GetByEmail(email)
+	}
+	if invitee != nil {
+		member, err := s.orgRepo.GetMember(access.Organization.ID, invitee.ID)
+		if err != nil {
+			return nil, err
+		}
+		if member != nil {
+			return nil, ErrAlreadyMember
+		}
+	}
+
+	invite := &entity.OrganizationInvite{
+		OrganizationID: access.Organization.ID,
+		Email:          sql.NullString{String: email, Valid: email != ""},
+		Username:       sql.NullString{String: username, Valid: username != ""},
+		Role:           role,
+		InvitedBy:      access.User.ID,
+		Status:         entity.InviteStatusPending,
+	}
+	if err := s.orgRepo.CreateInvite(invite); err != nil {
+		return nil, err
+	}
+
+	return invite, nil
+}
+
+// RevokeInvite отзывает приглашение
+func (s *OrganizationService) RevokeInvite(access *Access, inviteID int) error {
+	if !access.Can(entity.PermManageMembers) {
+		return ErrForbidden
+	}
+
+	invite, err := s.orgRepo.GetInviteByID(inviteID)
+	if err != nil || invite.OrganizationID != access.Organization.ID || invite.Status != entity.InviteStatusPending {
+		return ErrInviteNotFound
+	}
+
+	return s.orgRepo.UpdateInviteStatus(invite.ID, entity.InviteStatusRevoked)
+}
+
+// GetInvitesFor возвращает приглашения, адресованные пользователю
+func (s *OrganizationService) GetInvitesFor(u *entity.Users) ([]entity.OrganizationInvite, error) {
+	email := ""
+	if u.Email.Valid {
+		email = u.Email.String
+	}
+	return s.orgRepo.GetPendingInvitesFor(email, u.Username)
+}
+
+// GetOrganization возвращает организацию по ID
+func (s *OrganizationService) GetOrganization(id int) (*entity.Organization, error) {
+	return s.orgRepo.GetByID(id)
+}
+
+// RespondInvite принимает или отклоняет приглашение
+func (s *OrganizationService) RespondInvite(u *entity.Users, inviteID int, accept bool) error {
+	invite, err := s.orgRepo.GetInviteByID(inviteID)
+	if err != nil || invite.Status != entity.InviteStatusPending || !inviteAddressedTo(invite, u) {
+		return ErrInviteNotFound
+	}
+
+	if !accept {
+		return s.orgRepo.UpdateInviteStatus(invite.ID, entity.InviteStatusDeclined)
+	}
+
+	member, err := s.orgRepo.GetMember(invite.OrganizationID, u.ID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		member = &entity.OrganizationMember{
+			OrganizationID: invite.OrganizationID,
+			UserID:         u.ID,
+			Role:           invite.Role,
+		}
+		if err := s.orgRepo.AddMember(member); err != nil {
+			return err
+		}
+	}
+
+	return s.orgRepo.UpdateInviteStatus(invite.ID, entity.InviteStatusAccepted)
+}
+
+func inviteAddressedTo(invite *entity.OrganizationInvite, u *entity.Users) bool {
+	if invite.Username.Valid && strings.EqualFold(invite.Username.String, u.Username) {
+		return true
+	}
+	return invite.Email.Valid && u.Email.Valid && strings.EqualFold(invite.Email.String, u.Email.String)
+}
+
+// UpdateMemberRole меняет роль участника (только владелец, роль владельца не меняется)
+func (s *OrganizationService) UpdateMemberRole(access *Access, userID int, role string) error {
+	if !access.Can(entity.PermManageMembers) {
+		return ErrForbidden
+	}
+	if !entity.ValidInviteRole(role) {
+		return ErrInvalidRole
+	}
+	if userID == access.Organization.OwnerID {
+		return ErrOwnerImmutable
+	}
+
+	member, err := s.orgRepo.GetMember(access.Organization.ID, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return ErrNoAccess
+	}
+
+	return s.orgRepo.UpdateMemberRole(access.Organization.ID, userID, role)
+}
+
+// RemoveMember исключает участника. Участник может выйти сам, владелец - исключить любого, кроме себя
+func (s *OrganizationService) RemoveMember(access *Access, userID int) error {
+	if userID == access.Organization.OwnerID {
+		return ErrOwnerImmutable
+	}
+	if userID != access.User.ID && !access.Can(entity.PermManageMembers) {
+		return ErrForbidden
+	}
+
+	return s.orgRepo.RemoveMember(access.Organization.ID, userID)
+}
@@ -0,0 +1,20 @@
+package ozon
+
+import (
+	"database/sql"
+	"time"
+)
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// parseTime разбирает даты Ozon ("2006-01-02 15:04:05" в операциях, RFC3339 в карточках)
+func parseTime(s string) sql.NullTime {
+	for _, layout := range []string{"2006-01-02 15:04:05", time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return sql.NullTime{Time: t, Valid: true}
+		}
+	}
+	return sql.NullTime{}
+}
@@ -0,0 +1,199 @@
+package ozon
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+	ozonapi "wbrost-go/internal/api/ozon"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/marketplace"
+)
+
+// Provider - реализация marketplace.Provider для Ozon Seller API
+type Provider struct {
+	client *ozonapi.Client
+}
+
+var _ marketplace.Provider = (*Provider)(nil)
+
+// NewProvider создает провайдера Ozon по паре Client-Id + Api-Key
+func NewProvider(clientID, apiKey string) *Provider {
+	return &Provider{client: ozonapi.NewOzonClient(clientID, apiKey)}
+}
+
+func (p *Provider) Marketplace() string {
+	return entity.MarketplaceOzon
+}
+
+// ValidateCredentials проверяет ключ запросом одной страницы товаров
+func (p *Provider) ValidateCredentials() error {
+	isValid, err := p.client.CheckKey()
+	if err != nil {
+		return err
+	}
+	if !isValid {
+		return marketplace.ErrInvalidCredentials
+	}
+	return nil
+}
+
+// ListProducts загружает список товаров (включая архивные) и их карточки
+func (p *Provider) ListProducts() ([]marketplace.Product, error) {
+	var productIDs []int64
+	lastID := ""
+
+	for {
+		resp, err := p.client.ProductList(lastID, ozonapi.ProductListLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range resp.Result.Items {
+			productIDs = append(productIDs, item.ProductID)
+		}
+
+		if len(resp.Result.Items) < ozonapi.ProductListLimit || resp.Result.LastID == "" {
+			break
+		}
+		lastID = resp.Result.LastID
+		time.Sleep(pageDelay)
+	}
+
+	products := make([]marketplace.Product, 0, len(productIDs))
+	for start := 0; start < len(productIDs); start += ozonapi.ProductInfoLimit {
+		end := start + ozonapi.ProductInfoLimit
+		if end > len(productIDs) {
+			end = len(productIDs)
+		}
+
+		infos, err := p.client.ProductInfoList(productIDs[start:end])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, info := range infos {
+			product := marketplace.Product{
+				ExternalID: strconv.FormatInt(info.ID, 10),
+				OfferID:    info.OfferID,
+				Name:       info.Name,
+				Archived:   info.IsArchived || info.IsAutoarchive,
+				Raw:        info,
+			}
+			if len(info.Barcodes) > 0 {
+				product.Barcode = info.Barcodes[0]
+			}
+			if len(info.PrimaryImage) > 0 {
+				product.Photo = info.PrimaryImage[0]
+			} else if len(info.Images) > 0 {
+				product.Photo = info.Images[0]
+			}
+			if price, err := strconv.ParseFloat(info.Price, 64); err == nil {
+				product.Price = price
+			}
+			products = append(products, product)
+		}
+
+		time.Sleep(pageDelay)
+	}
+
+	return products, nil
+}
+
+// FetchOperations загружает финансовые операции за период.
+// Ozon отдает не больше месяца за запрос, поэтому период режется на месяцы
+func (p *Provider) FetchOperations(dateFrom, dateTo time.Time) ([]marketplace.Operation, error) {
+	var operations []marketplace.Operation
+	currentStart := dateFrom
+
+	for !currentStart.After(dateTo) {
+		currentEnd := currentStart.AddDate(0, 1, -1)
+		if currentEnd.After(dateTo) {
+			currentEnd = dateTo
+		}
+
+		fmt.Printf("📅 Ozon: операции %s - %s\n", currentStart.Format("2006-01-02"), currentEnd.Format("2006-01-02"))
+
+		periodEnd := currentEnd.Add(24*time.Hour - time.Millisecond)
+		for page := 1; ; page++ {
+			resp, err := p.client.TransactionList(currentStart, periodEnd, page, ozonapi.TransactionPageLimit)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, op := range resp.Result.Operations {
+				operations = append(operations, mapOperation(op))
+			}
+
+			if page >= resp.Result.PageCount {
+				break
+			}
+			time.Sleep(pageDelay)
+		}
+
+		currentStart = currentEnd.AddDate(0, 0, 1)
+	}
+
+	return operations, nil
+}
+
+// FetchOrders - заказы (отправления FBO/FBS) пока не загружаются
+func (p *Provider) FetchOrders(dateFrom, dateTo time.Time) ([]marketplace.Order, error) {
+	return nil, marketplace.ErrNotSupported
+}
+
+// mapOperation переводит операцию Ozon в нормализованную.
+// Ozon отдает расходы отрицательными суммами, в нормализованной операции они положительные
+func mapOperation(op ozonapi.Transaction) marketplace.Operation {
+	result := marketplace.Operation{
+		ExternalID: strconv.FormatInt(op.OperationID, 10),
+		Type:       operationType(op.Type),
+		Name:       op.OperationTypeName,
+		Quantity:   len(op.Items),
+		Raw:        op,
+	}
+
+	if date := parseTime(op.OperationDate); date.Valid {
+		result.Date = date.Time
+	}
+
+	if len(op.Items) > 0 {
+		if op.Items[0].Sku != 0 {
+			result.ProductID = strconv.FormatInt(op.Items[0].Sku, 10)
+		}
+		result.ProductName = op.Items[0].Name
+	}
+
+	services := 0.0
+	for _, service := range op.Services {
+		services += service.Price
+	}
+
+	result.Revenue = op.AccrualsForSale
+	result.Commission = -op.SaleCommission
+	result.Payout = op.AccrualsForSale + op.SaleCommission
+	result.Logistics = -(op.DeliveryCharge + op.ReturnDeliveryCharge)
+	result.OtherCharges = -services
+
+	// Суммы без детализации (компенсации, прочие начисления) относим к выплате,
+	// чтобы итог операции совпадал с amount
+	net := result.Payout - result.Logistics - result.OtherCharges
+	result.Payout += op.Amount - net
+
+	return result
+}
+
+// operationType - тип нормализованной операции по типу операции Ozon
+func operationType(ozonType string) string {
+	switch ozonType {
+	case "orders":
+		return entity.OperationSale
+	case "returns":
+		return entity.OperationReturn
+	case "transferDelivery":
+		return entity.OperationLogistics
+	case "compensation":
+		return entity.OperationCompensation
+	default:
+		return entity.OperationOther
+	}
+}
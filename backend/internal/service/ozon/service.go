@@ -0,0 +1,264 @@
+package ozon
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	ozonapi "wbrost-go/internal/api/ozon"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/marketplace"
+	"wbrost-go/internal/repository/operation"
+	ozonrepo "wbrost-go/internal/repository/ozon"
+	"wbrost-go/internal/repository/user"
+)
+
+// pageDelay - пауза между запросами к Ozon API
+const pageDelay = 500 * time.Millisecond
+
+type OzonService struct {
+	userRepo        *user.UserRepository
+	jobRepo         *ozonrepo.OzonGetRepository
+	transactionRepo *ozonrepo.TransactionRepository
+	productRepo     *ozonrepo.ProductRepository
+	operationRepo   *operation.OperationRepository
+}
+
+func NewOzonService(
+	userRepo *user.UserRepository,
+	jobRepo *ozonrepo.OzonGetRepository,
+	transactionRepo *ozonrepo.TransactionRepository,
+	productRepo *ozonrepo.ProductRepository,
+	operationRepo *operation.OperationRepository,
+) *OzonService {
+	return &OzonService{
+		userRepo:        userRepo,
+		jobRepo:         jobRepo,
+		transactionRepo: transactionRepo,
+		productRepo:     productRepo,
+		operationRepo:   operationRepo,
+	}
+}
+
+// ProcessPendingJobs обрабатывает задания ozon_get со статусом 0
+func (s *OzonService) ProcessPendingJobs() error {
+	jobs, err := s.jobRepo.GetPendingJobs()
+	if err != nil {
+		return err
+	}
+
+	if len(jobs) == 0 {
+		fmt.Println("No pending ozon jobs found")
+		return nil
+	}
+
+	for _, job := range jobs {
+		fmt.Printf("Processing ozon job ID: %d (%s) for user %d\n", job.ID, job.Type, job.UserID)
+
+		user, err := s.userRepo.GetByID(job.UserID)
+		if err != nil {
+			s.updateJobStatus(job.ID, entity.StatusError, "User not found")
+			continue
+		}
+
+		if !user.OzonKey.Valid || user.OzonKey.String == "" || !user.OzonClientID.Valid || user.OzonClientID.String == "" {
+			s.updateJobStatus(job.ID, entity.StatusError, "Ozon key not found")
+			continue
+		}
+
+		provider := NewProvider(user.OzonClientID.String, user.OzonKey.String)
+
+		// Сначала проверяем ключ, чтобы не гонять задание с отозванным ключом
+		if err := provider.ValidateCredentials(); err != nil {
+			if errors.Is(err, marketplace.ErrInvalidCredentials) {
+				s.setKeyStatus(user.ID, entity.OzonStatusInvalid)
+				s.updateJobStatus(job.ID, entity.StatusError, "Ключ Ozon недействителен")
+				continue
+			}
+			// Сетевая ошибка или сбой Ozon - оставляем задание на следующий запуск
+			s.updateJobStatus(job.ID, entity.StatusWait, err.Error())
+			continue
+		}
+		s.setKeyStatus(user.ID, entity.OzonStatusActive)
+
+		var result string
+		switch job.Type {
+		case entity.OzonJobTransactions:
+			result, err = s.loadTransactions(provider, &job)
+		case entity.OzonJobProducts:
+			result, err = s.loadProducts(provider, &job)
+		default:
+			err = fmt.Errorf("unknown ozon job type: %s", job.Type)
+		}
+
+		if err != nil {
+			s.updateJobStatus(job.ID, entity.StatusError, err.Error())
+			continue
+		}
+
+		s.updateJobStatus(job.ID, entity.StatusSuccess, result)
+	}
+
+	return nil
+}
+
+// loadTransactions загружает финансовые операции за период задания
+// в ozon_transactions и в нормализованные операции
+func (s *OzonService) loadTransactions(provider *Provider, job *entity.OzonGet) (string, error) {
+	dateFrom, err := time.Parse("2006-01-02", job.DateFrom.String)
+	if err != nil {
+		return "", fmt.Errorf("invalid date_from format: %w", err)
+	}
+
+	dateTo, err := time.Parse("2006-01-02", job.DateTo.String)
+	if err != nil {
+		return "", fmt.Errorf("invalid date_to format: %w", err)
+	}
+
+	operations, err := provider.FetchOperations(dateFrom, dateTo)
+	if err != nil {
+		return "", err
+	}
+
+	inserted, skipped := 0, 0
+	for i := range operations {
+		op, ok := operations[i].Raw.(ozonapi.Transaction)
+		if !ok {
+			continue
+		}
+
+		saved, err := s.transactionRepo.Create(mapTransaction(job.UserID, op))
+		if err != nil {
+			return "", err
+		}
+		if saved {
+			inserted++
+		} else {
+			skipped++
+		}
+
+		if _, err := s.operationRepo.Create(operations[i].Entity(job.UserID, sql.NullInt64{}, provider.Marketplace())); err != nil {
+			return "", err
+		}
+	}
+
+	fmt.Printf("✅ Ozon: сохранено %d операций, пропущено дублей %d\n", inserted, skipped)
+	return "", nil
+}
+
+// loadProducts загружает список товаров (включая архивные) и их карточки
+func (s *OzonService) loadProducts(provider *Provider, job *entity.OzonGet) (string, error) {
+	products, err := provider.ListProducts()
+	if err != nil {
+		return "", err
+	}
+
+	saved := 0
+	for _, product := range products {
+		info, ok := product.Raw.(ozonapi.ProductInfo)
+		if !ok {
+			continue
+		}
+		if err := s.productRepo.CreateOrUpdate(mapProduct(job.UserID, info)); err != nil {
+			return "", err
+		}
+		saved++
+	}
+
+	fmt.Printf("✅ Ozon: сохранено %d товаров\n", saved)
+	return "", nil
+}
+
+// mapTransaction преобразует операцию Ozon в строку ozon_transactions
+func mapTransaction(userID int, op ozonapi.Transaction) *entity.OzonTransaction {
+	t := &entity.OzonTransaction{
+		UserID:               userID,
+		OperationID:          op.OperationID,
+		OperationType:        nullString(op.OperationType),
+		OperationTypeName:    nullString(op.OperationTypeName),
+		OperationDate:        parseTime(op.OperationDate),
+		Type:                 nullString(op.Type),
+		PostingNumber:        nullString(op.Posting.PostingNumber),
+		DeliverySchema:       nullString(op.Posting.DeliverySchema),
+		OrderDate:            parseTime(op.Posting.OrderDate),
+		ItemsCount:           len(op.Items),
+		AccrualsForSale:      op.AccrualsForSale,
+		SaleCommission:       op.SaleCommission,
+		DeliveryCharge:       op.DeliveryCharge,
+		ReturnDeliveryCharge: op.ReturnDeliveryCharge,
+		Amount:               op.Amount,
+	}
+
+	if op.Posting.WarehouseID != 0 {
+		t.WarehouseID.Int64, t.WarehouseID.Valid = op.Posting.WarehouseID, true
+	}
+
+	if len(op.Items) > 0 {
+		t.Sku.Int64, t.Sku.Valid = op.Items[0].Sku, op.Items[0].Sku != 0
+		t.ItemName = nullString(op.Items[0].Name)
+	}
+
+	for _, service := range op.Services {
+		t.ServicesAmount += service.Price
+	}
+
+	t.Items, _ = json.Marshal(op.Items)
+	t.Services, _ = json.Marshal(op.Services)
+
+	return t
+}
+
+// mapProduct преобразует карточку Ozon в строку ozon_products
+func mapProduct(userID int, info ozonapi.ProductInfo) *entity.OzonProduct {
+	p := &entity.OzonProduct{
+		UserID:    userID,
+		ProductID: info.ID,
+		OfferID:   nullString(info.OfferID),
+		Name:      nullString(info.Name),
+	}
+
+	if info.IsArchived || info.IsAutoarchive {
+		p.Archived = 1
+	}
+
+	for _, source := range info.Sources {
+		if source.Sku != 0 {
+			p.Sku.Int64, p.Sku.Valid = source.Sku, true
+			break
+		}
+	}
+
+	if len(info.Barcodes) > 0 {
+		p.Barcode = nullString(info.Barcodes[0])
+	}
+
+	// Главное фото, если его нет - первое из галереи
+	if len(info.PrimaryImage) > 0 {
+		p.Photo = nullString(info.PrimaryImage[0])
+	} else if len(info.Images) > 0 {
+		p.Photo = nullString(info.Images[0])
+	}
+
+	if price, err := strconv.ParseFloat(info.Price, 64); err == nil {
+		p.Price.Float64, p.Price.Valid = price, true
+	}
+
+	return p
+}
+
+func (s *OzonService) setKeyStatus(userID, status int) {
+	if err := s.userRepo.UpdateOzonStatus(userID, status); err != nil {
+		fmt.Printf("Failed to update ozon key status for user %d: %v\n", userID, err)
+	}
+}
+
+func (s *OzonService) updateJobStatus(jobID int, status int, errorMsg string) {
+	err := s.jobRepo.UpdateStatus(jobID, status, errorMsg)
+	if err != nil {
+		fmt.Printf("Failed to update ozon job %d status: %v\n", jobID, err)
+	} else {
+		fmt.Printf("Ozon job %d updated to status %d\n", jobID, status)
+	}
+}
@@ -0,0 +1,186 @@
+package profile
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+	"wbrost-go/internal/crypto"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/repository/account"
+	"wbrost-go/internal/repository/export"
+	"wbrost-go/internal/repository/user"
+)
+
+// ProfileService - выгрузка данных пользователя и удаление аккаунта по его запросу
+type ProfileService struct {
+	userRepo    *user.UserRepository
+	accountRepo *account.SellerAccountRepository
+	exportRepo  *export.ExportRepository
+	graceDays   int
+}
+
+func NewProfileService(
+	userRepo *user.UserRepository,
+	accountRepo *account.SellerAccountRepository,
+	exportRepo *export.ExportRepository,
+	graceDays int,
+) *ProfileService {
+	return &ProfileService{
+		userRepo:    userRepo,
+		accountRepo: accountRepo,
+		exportRepo:  exportRepo,
+		graceDays:   graceDays,
+	}
+}
+
+// ExportFileName - имя архива выгрузки
+func ExportFileName(u *entity.Users, now time.Time) string {
+	return fmt.Sprintf("wbrost-export-%s-%s.zip", u.Username, now.Format("20060102"))
+}
+
+// Export пишет в w zip-архив с данными пользователя: профиль и кабинеты (JSON),
+// карточки, себестоимость, строки отчетов WB и операции маркетплейсов (CSV).
+// API ключи в архив попадают только маской
+func (s *ProfileService) Export(u *entity.Users, w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	if err := writeJSON(archive, "profile.json", profileData(u)); err != nil {
+		return err
+	}
+
+	accounts, err := s.accountRepo.GetByUserID(u.ID)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(archive, "accounts.json", accountsData(accounts)); err != nil {
+		return err
+	}
+
+	files := []struct {
+		name  string
+		write func(io.Writer, int) (int, error)
+	}{
+		{"articles.csv", s.exportRepo.WriteArticles},
+		{"article_sizes.csv", s.exportRepo.WriteArticleSizes},
+		{"article_characteristics.csv", s.exportRepo.WriteArticleCharacteristics},
+		{"article_changes.csv", s.exportRepo.WriteArticleChanges},
+		{"cost_prices.csv", s.exportRepo.WriteCostPrices},
+		{"cost_price_history.csv", s.exportRepo.WriteCostPriceHistory},
+		{"tax_profiles.csv", s.exportRepo.WriteTaxProfiles},
+		{"wb_stats.csv", s.exportRepo.WriteStats},
+		{"operations.csv", s.exportRepo.WriteOperations},
+	}
+
+	for _, file := range files {
+		fw, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		count, err := file.write(fw, u.ID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file.name, err)
+		}
+		fmt.Printf("📦 Выгрузка пользователя %d: %s - %d строк\n", u.ID, file.name, count)
+	}
+
+	return archive.Close()
+}
+
+func writeJSON(archive *zip.Writer, name string, data interface{}) error {
+	fw, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(fw)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+func profileData(u *entity.Users) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                u.ID,
+		"username":          u.Username,
+		"name":              u.Name.String,
+		"email":             u.Email.String,
+		"phone":             u.Phone.String,
+		"taxes":             u.Taxes,
+		"pro":               u.Pro,
+		"wb_key":            crypto.Mask(u.WbKey.String),
+		"wb_key_expires_at": u.WbKeyExpiresAt,
+		"wb_seller_id":      u.WbSellerID.Int64,
+		"ozon_key":          crypto.Mask(u.OzonKey.String),
+		"ozon_client_id":    u.OzonClientID.String,
+		"created_at":        u.CreatedAt,
+		"last_login":        u.LastLogin,
+	}
+}
+
+func accountsData(accounts []entity.SellerAccount) []map[string]interface{} {
+	data := make([]map[string]interface{}, len(accounts))
+	for i, a := range accounts {
+		data[i] = map[string]interface{}{
+			"id":             a.ID,
+			"marketplace":    a.Marketplace,
+			"name":           a.Name,
+			"api_key":        crypto.Mask(a.APIKey.String),
+			"key_expires_at": a.KeyExpiresAt,
+			"seller_id":      a.SellerID.Int64,
+			"business_id":    a.BusinessID.Int64,
+			"campaign_id":    a.CampaignID.Int64,
+			"is_default":     a.IsDefault,
+			"created_at":     a.CreatedAt,
+		}
+	}
+	return data
+}
+
+// ScheduleDeletion назначает удаление аккаунта через льготный период.
+// Возвращает дату, после которой данные будут удалены
+func (s *ProfileService) ScheduleDeletion(u *entity.Users) (time.Time, error) {
+	return s.userRepo.ScheduleDeletion(u.ID, time.Now().AddDate(0, 0, s.graceDays))
+}
+
+// CancelDeletion отменяет удаление аккаунта (до истечения льготного периода)
+func (s *ProfileService) CancelDeletion(u *entity.Users) error {
+	return s.userRepo.CancelDeletion(u.ID)
+}
+
+// GetDeletion - назначенное удаление аккаунта пользователя (nil - не назначено)
+func (s *ProfileService) GetDeletion(u *entity.Users) (*entity.UserDeletion, error) {
+	return s.userRepo.GetDeletion(u.ID)
+}
+
+// GetPendingDeletions - все аккаунты, ожидающие удаления (для админки)
+func (s *ProfileService) GetPendingDeletions() ([]entity.UserDeletion, error) {
+	return s.userRepo.GetPendingDeletions()
+}
+
+// PurgeDueDeletions удаляет аккаунты с истекшим льготным периодом.
+// Ошибка одного пользователя не останавливает остальных
+func (s *ProfileService) PurgeDueDeletions() (int, error) {
+	deletions, err := s.userRepo.GetDueDeletions(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	if len(deletions) == 0 {
+		fmt.Println("No due deletions found")
+		return 0, nil
+	}
+
+	purged := 0
+	for _, d := range deletions {
+		if err := s.userRepo.Purge(d.UserID); err != nil {
+			fmt.Printf("❌ Ошибка удаления пользователя %d (%s): %v\n", d.UserID, d.Username, err)
+			continue
+		}
+		fmt.Printf("🗑 Пользователь %d (%s) удален вместе с данными (запрос от %s)\n",
+			d.UserID, d.Username, d.DeleteRequestedAt.Format("2006-01-02"))
+		purged++
+	}
+
+	return purged, nil
+}
@@ -6,56 +6,19 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
 	"time"
 	"wbrost-go/internal/api/wb"
-	"wbrost-go/internal/entity"
 )
 
-func (s *WBService) getWBData(order *entity.WBStatsGet, user *entity.Users) ([]interface{}, error) {
-	if !user.WbKey.Valid || user.WbKey.String == "" {
-		return nil, fmt.Errorf("токен WB не указан для пользователя %d", user.ID)
-	}
-
-	token := user.WbKey.String
-
-	// Проверяем формат токена
-	parts := strings.Split(token, ".")
-	if len(parts) != 3 {
-		return nil, fmt.Errorf("неверный формат токена. Ожидается JWT токен")
-	}
-
-	client := wb.NewWBClient(token)
-
-	// Сначала проверяем токен через WB API
-	fmt.Println("🔐 Проверка токена...")
-	isValid, err := client.CheckToken()
-	if err != nil {
-		return nil, fmt.Errorf("ошибка проверки токена: %v", err)
-	}
-
-	if !isValid {
-		return nil, fmt.Errorf("токен недействителен или истек")
-	}
-
-	fmt.Println("✅ Токен валиден")
-
+// fetchReport получает строки детализации отчета реализации за период.
+// Большие периоды запрашиваются по кварталам
+func (p *Provider) fetchReport(dateFrom, dateTo time.Time) ([]interface{}, error) {
 	var allData []interface{}
 
-	dateFrom, err := time.Parse("2006-01-02", order.DateFrom)
-	if err != nil {
-		return nil, fmt.Errorf("invalid date_from format: %w", err)
-	}
-
-	dateTo, err := time.Parse("2006-01-02", order.DateTo)
-	if err != nil {
-		return nil, fmt.Errorf("invalid date_to format: %w", err)
-	}
-
 	// Рассчитываем длительность периода
 	days := int(dateTo.Sub(dateFrom).Hours() / 24)
 	fmt.Printf("📅 Период: %s - %s (%d дней)\n",
-		order.DateFrom, order.DateTo, days)
+		dateFrom.Format("2006-01-02"), dateTo.Format("2006-01-02"), days)
 
 	// Для периодов больше 90 дней разбиваем на кварталы (3 месяца)
 	if days > 90 {
@@ -86,8 +49,7 @@ func (s *WBService) getWBData(order *entity.WBStatsGet, user *entity.Users) ([]i
 				fmt.Println("📊 Используем новую версию API (после 29.01.2024)")
 			}
 
-			data, err := s.getReportByPeriod(
-				client,
+			data, err := p.getReportByPeriod(
 				currentStart.Format("2006-01-02"),
 				currentEnd.Format("2006-01-02"),
 				useNewAPI,
@@ -129,8 +91,7 @@ func (s *WBService) getWBData(order *entity.WBStatsGet, user *entity.Users) ([]i
 			fmt.Println("📊 Используем новую версию API (после 29.01.2024)")
 		}
 
-		data, err := s.getReportByPeriod(
-			client,
+		data, err := p.getReportByPeriod(
 			dateFrom.Format("2006-01-02"),
 			dateTo.Format("2006-01-02"),
 			useNewAPI,
@@ -147,12 +108,12 @@ func (s *WBService) getWBData(order *entity.WBStatsGet, user *entity.Users) ([]i
 	return allData, nil
 }
 
-func (s *WBService) safeRequest(client *wb.Client, url string) (*http.Response, error) {
+func (p *Provider) safeRequest(url string) (*http.Response, error) {
 	maxRetries := 5
 
 	for attempt := 0; attempt <= maxRetries; attempt++ {
 		// Ждем разрешения от rate limiter
-		if err := s.rateLimiter.Wait(); err != nil {
+		if err := p.rateLimiter.Wait(); err != nil {
 			return nil, fmt.Errorf("rate limiter error: %w", err)
 		}
 
@@ -161,16 +122,16 @@ func (s *WBService) safeRequest(client *wb.Client, url string) (*http.Response,
 			return nil, fmt.Errorf("failed to create request: %w", err)
 		}
 
-		req.Header.Set("Authorization", client.Token)
+		req.Header.Set("Authorization", p.client.Token)
 		req.Header.Set("Content-Type", "application/json")
 
-		resp, err := client.Client.Do(req)
+		resp, err := p.client.Client.Do(req)
 		if err != nil {
 			return nil, fmt.Errorf("failed to execute request: %w", err)
 		}
 
 		// ОБРАБАТЫВАЕМ ЗАГОЛОВКИ ДЛЯ RATE LIMITING
-		s.rateLimiter.ProcessHeaders(resp.Header, resp.StatusCode)
+		p.rateLimiter.ProcessHeaders(resp.Header, resp.StatusCode)
 
 		// Проверяем статус код
 		switch resp.StatusCode {
@@ -215,11 +176,11 @@ func (s *WBService) safeRequest(client *wb.Client, url string) (*http.Response,
 
 	return nil, fmt.Errorf("max retries (%d) exceeded", maxRetries)
 }
-func (s *WBService) getReportByPeriod(client *wb.Client, dateFrom, dateTo string, useNewAPI bool) ([]interface{}, error) {
+func (p *Provider) getReportByPeriod(dateFrom, dateTo string, useNewAPI bool) ([]interface{}, error) {
 	// Используем пагинированную версию
-	return s.getReportByPeriodWithPagination(client, dateFrom, dateTo, useNewAPI)
+	return p.getReportByPeriodWithPagination(dateFrom, dateTo, useNewAPI)
 }
-func (s *WBService) getReportByPeriodWithPagination(client *wb.Client, dateFrom, dateTo string, useNewAPI bool) ([]interface{}, error) {
+func (p *Provider) getReportByPeriodWithPagination(dateFrom, dateTo string, useNewAPI bool) ([]interface{}, error) {
 	var allData []interface{}
 	var lastRrdID int64 = 0
 	maxPages := 50 // Максимальное количество страниц пагинации
@@ -237,11 +198,11 @@ func (s *WBService) getReportByPeriodWithPagination(client *wb.Client, dateFrom,
 		fmt.Printf("📄 Страница %d: запрос данных с rrdid=%d\n", page, lastRrdID)
 
 		// Ждем разрешения от rate limiter
-		if err := s.rateLimiter.Wait(); err != nil {
+		if err := p.rateLimiter.Wait(); err != nil {
 			return nil, fmt.Errorf("rate limiter error: %w", err)
 		}
 
-		resp, err := s.safeRequest(client, url)
+		resp, err := p.safeRequest(url)
 		if err != nil {
 			return nil, err
 		}
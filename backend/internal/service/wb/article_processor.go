@@ -2,10 +2,7 @@ package wb
 
 import (
 	"database/sql"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"strconv"
 	"strings"
 	"time"
@@ -34,13 +31,24 @@ func (s *WBService) ProcessPendingArticles() error {
 			continue
 		}
 
+		if err := s.applyAccountKey(user, articleReq.AccountID); err != nil {
+			s.updateArticleStatus(&articleReq, entity.ArticlesStatusError, "Seller account not found")
+			continue
+		}
+
 		if !user.WbKey.Valid || user.WbKey.String == "" {
 			s.updateArticleStatus(&articleReq, entity.ArticlesStatusError, "WB key not found")
 			continue
 		}
 
+		// Проверяем формат, срок действия и категорию "Контент" токена
+		if errMsg := checkTokenScope(user.WbKey.String, wb.ScopeContent); errMsg != "" {
+			s.updateArticleStatus(&articleReq, entity.ArticlesStatusError, errMsg)
+			continue
+		}
+
 		// Обрабатываем запрос
-		result := s.processArticleRequest(user)
+		result := s.processArticleRequest(user, articleReq.AccountID, articleReq.FullSync)
 
 		if result.Status {
 			s.updateArticleStatus(&articleReq, entity.ArticlesStatusSuccess, result.Error)
@@ -65,153 +73,140 @@ func (s *WBService) updateArticleStatus(article *entity.WBArticlesGet, status in
 	}
 }
 
-func (s *WBService) processArticleRequest(user *entity.Users) ProcessResult {
-	// Получаем данные карточек от WB API
-	articlesData, err := s.getWBArticles(user)
+// processArticleRequest загружает карточки, измененные после сохраненного курсора,
+// или все карточки при полной сверке (по запросу, без курсора или раз в articlesFullSyncInterval)
+func (s *WBService) processArticleRequest(user *entity.Users, accountID sql.NullInt64, fullSync bool) ProcessResult {
+	state, err := s.articleRepo.GetSyncState(user.ID, int(accountID.Int64))
 	if err != nil {
 		return ProcessResult{
 			Status: false,
-			Error:  fmt.Sprintf("Failed to get WB articles: %v", err),
-			Retake: false,
+			Error:  fmt.Sprintf("Failed to get sync state: %v", err),
+			Retake: true,
 		}
 	}
 
-	// Обрабатываем и сохраняем данные
-	success, message := s.saveArticles(articlesData, user.ID)
-
-	return ProcessResult{
-		Status: success,
-		Error:  message,
-		Retake: false,
+	full := fullSync || needFullSync(state)
+	since := wb.ArticleRequestCursor{}
+	if !full {
+		since = wb.ArticleRequestCursor{UpdatedAt: state.CursorUpdatedAt.String, NmID: int(state.CursorNmID.Int64)}
 	}
-}
 
-func (s *WBService) getWBArticles(user *entity.Users) ([]wb.Article, error) {
-	if !user.WbKey.Valid || user.WbKey.String == "" {
-		return nil, fmt.Errorf("токен WB не указан для пользователя %d", user.ID)
-	}
-
-	token := user.WbKey.String
-	client := wb.NewWBClient(token)
-
-	// Проверяем токен
-	isValid, err := client.CheckToken()
+	// Получаем карточки через провайдера WB
+	provider := NewProvider(user.WbKey.String, s.rateLimiter)
+	cards, cursor, err := provider.ListCards(since)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка проверки токена: %v", err)
-	}
-
-	if !isValid {
-		return nil, fmt.Errorf("токен недействителен или истек")
-	}
-
-	// Получаем карточки товаров
-	return s.fetchArticlesFromWB(client)
-}
-
-func (s *WBService) fetchArticlesFromWB(client *wb.Client) ([]wb.Article, error) {
-	var allCards []wb.Article
-	var cursorUpdatedAt string
-	var cursorNmID int
-
-	limit := 100 // Максимальный лимит за один запрос
-	totalProcessed := 0
-
-	for {
-		// Формируем тело запроса
-		request := wb.ArticleRequest{
-			Settings: wb.ArticleRequestSettings{
-				Cursor: wb.ArticleRequestCursor{
-					Limit: limit,
-				},
-				Filter: struct {
-					WithPhoto int `json:"withPhoto"`
-				}{
-					WithPhoto: -1,
-				},
-			},
-		}
-
-		// Добавляем курсор, если он есть (для пагинации)
-		if cursorUpdatedAt != "" && cursorNmID > 0 {
-			request.Settings.Cursor.UpdatedAt = cursorUpdatedAt
-			request.Settings.Cursor.NmID = cursorNmID
+		return ProcessResult{
+			Status: false,
+			Error:  fmt.Sprintf("Failed to get WB articles: %v", err),
+			Retake: false,
 		}
+	}
 
-		jsonBody, err := json.Marshal(request)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	// Пустой полный список - скорее сбой API, чем удаление всех карточек: ничего не архивируем
+	if full && len(cards) == 0 {
+		return ProcessResult{
+			Status: false,
+			Error:  "No articles data received",
+			Retake: false,
 		}
+	}
 
-		url := wb.URLFor(wb.CardsList)
-		req, err := http.NewRequest("POST", url, strings.NewReader(string(jsonBody)))
-		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
+	// Обрабатываем и сохраняем данные
+	countSaved, countUnsaved := s.saveArticles(cards, user.ID, accountID)
+	if len(cards) > 0 && countSaved == 0 {
+		return ProcessResult{
+			Status: false,
+			Error:  fmt.Sprintf("Total: %d, Saved: 0, Not saved: %d", len(cards), countUnsaved),
+			Retake: false,
 		}
+	}
 
-		req.Header.Set("Authorization", client.Token)
-		req.Header.Set("Content-Type", "application/json")
-
-		resp, err := client.Client.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("failed to make request: %w", err)
+	countArchived := s.archiveCards(provider, user.ID, accountID, cards, full)
+	s.refreshSubjectCharacteristics(provider, cards)
+	s.refreshStocks(provider, user, accountID)
+
+	// Курсор сдвигается только если сохранены все карточки, иначе они загрузятся повторно
+	if countUnsaved == 0 {
+		now := sql.NullTime{Time: time.Now(), Valid: true}
+		state.CursorUpdatedAt = sql.NullString{String: cursor.UpdatedAt, Valid: cursor.UpdatedAt != ""}
+		state.CursorNmID = sql.NullInt64{Int64: int64(cursor.NmID), Valid: cursor.NmID != 0}
+		state.LastSyncAt = now
+		if full {
+			state.LastFullSyncAt = now
 		}
-
-		body, err := io.ReadAll(resp.Body)
-		resp.Body.Close()
-
-		if err != nil {
-			return nil, fmt.Errorf("failed to read response: %w", err)
+		if err := s.articleRepo.SaveSyncState(state); err != nil {
+			fmt.Printf("Error saving sync state for user %d: %v\n", user.ID, err)
 		}
+	}
 
-		if resp.StatusCode != 200 {
-			fmt.Printf("Response body: %s\n", string(body))
-			return nil, fmt.Errorf("WB API error: status %d", resp.StatusCode)
-		}
+	mode := "incremental"
+	if full {
+		mode = "full"
+	}
 
-		// Парсим ответ
-		var response wb.ArticleResponse
-		if err := json.Unmarshal(body, &response); err != nil {
-			return nil, fmt.Errorf("failed to parse response: %w", err)
-		}
+	return ProcessResult{
+		Status: true,
+		Error: fmt.Sprintf("Mode: %s, Total: %d, Saved: %d, Not saved: %d, Archived: %d",
+			mode, len(cards), countSaved, countUnsaved, countArchived),
+		Retake: false,
+	}
+}
 
-		// Добавляем полученные карточки
-		allCards = append(allCards, response.Cards...)
-		totalProcessed += len(response.Cards)
+// refreshStocks обновляет снимок остатков кабинета на складах WB (нужен для прогноза спроса).
+// Без доступа токена к статистике остатки не загружаются
+func (s *WBService) refreshStocks(provider *Provider, user *entity.Users, accountID sql.NullInt64) {
+	if errMsg := checkTokenScope(user.WbKey.String, wb.ScopeStatistics); errMsg != "" {
+		return
+	}
 
-		fmt.Printf("Получено %d карточек (всего: %d)\n", len(response.Cards), totalProcessed)
+	rows, err := provider.Stocks()
+	if err != nil {
+		fmt.Printf("Failed to get stocks for user %d: %v\n", user.ID, err)
+		return
+	}
 
-		// Проверяем, нужно ли продолжать пагинацию
-		if len(response.Cards) < limit || response.Cursor.Total < limit {
-			fmt.Printf("Получены все карточки. Всего: %d\n", totalProcessed)
-			break
+	stocks := make([]entity.WBStock, 0, len(rows))
+	for _, row := range rows {
+		if row.NmID == 0 {
+			continue
 		}
-
-		// Обновляем курсор для следующего запроса
-		cursorUpdatedAt = response.Cursor.UpdatedAt
-		cursorNmID = response.Cursor.NmID
-
-		// Небольшая задержка между запросами, чтобы не превысить лимиты
-		time.Sleep(100 * time.Millisecond)
+		stocks = append(stocks, entity.WBStock{
+			UserID:          user.ID,
+			AccountID:       accountID,
+			NmID:            row.NmID,
+			Barcode:         row.Barcode,
+			TechSize:        row.TechSize,
+			WarehouseName:   row.WarehouseName,
+			Quantity:        row.Quantity,
+			InWayToClient:   row.InWayToClient,
+			InWayFromClient: row.InWayFromClient,
+			QuantityFull:    row.QuantityFull,
+		})
 	}
 
-	return allCards, nil
-}
-
-func (s *WBService) saveArticles(cards []wb.Article, userID int) (bool, string) {
-	if len(cards) == 0 {
-		return false, "No articles data received"
+	if err := s.articleRepo.ReplaceStocks(user.ID, int(accountID.Int64), stocks); err != nil {
+		fmt.Printf("Failed to save stocks for user %d: %v\n", user.ID, err)
 	}
+}
 
+// saveArticles сохраняет карточки с размерами и контентом и записывает изменения в журнал.
+// Возвращает количество сохраненных и несохраненных карточек
+func (s *WBService) saveArticles(cards []wb.Article, userID int, accountID sql.NullInt64) (int, int) {
 	countSaved := 0
 	countUnsaved := 0
 	countTotal := len(cards)
 
+	if countTotal == 0 {
+		return 0, 0
+	}
+
 	fmt.Printf("📦 Получено %d карточек товаров от WB API\n", countTotal)
 
 	for _, card := range cards {
 		// Создаем запись для базы данных (используем WBArticleDB)
 		article := &entity.WBArticles{
 			UserID:    userID,
+			AccountID: accountID,
 			Articule:  strconv.Itoa(card.NmID),
 			Created:   sql.NullTime{Time: time.Now(), Valid: true},
 			Updated:   sql.NullTime{Time: time.Now(), Valid: true},
@@ -223,20 +218,24 @@ func (s *WBService) saveArticles(cards []wb.Article, userID int) (bool, string)
 			article.Name = sql.NullString{String: card.Title, Valid: true}
 		}
 
+		// Артикул продавца
 		if card.VendorCode != "" {
 			article.InternalID = sql.NullString{String: card.VendorCode, Valid: true}
 		}
 
 		if card.NmUUID != "" {
-			article.InternalID = sql.NullString{String: card.NmUUID, Valid: true}
+			article.NmUUID = sql.NullString{String: card.NmUUID, Valid: true}
 		}
 
-		// Берем первую фотографию, если есть
+		fillCardDetails(article, card)
+
+		// Первая фотография - обложка карточки, все фотографии сохраняются отдельно
 		if len(card.Photos) > 0 && card.Photos[0].Big != "" {
 			article.Photo = sql.NullString{String: card.Photos[0].Big, Valid: true}
 		}
 
-		// Обрабатываем размеры
+		// Поля первого размера в карточке оставлены для совместимости,
+		// все размеры с баркодами сохраняются в wb_article_sizes
 		if len(card.Sizes) > 0 {
 			size := card.Sizes[0]
 			if size.TechSize != "" {
@@ -253,6 +252,12 @@ func (s *WBService) saveArticles(cards []wb.Article, userID int) (bool, string)
 			}
 		}
 
+		// Состояние до сохранения - для журнала изменений (nil - новая карточка)
+		snapshot, err := s.articleRepo.GetSnapshot(userID, int64(card.NmID))
+		if err != nil {
+			fmt.Printf("Error getting snapshot of article %d: %v\n", card.NmID, err)
+		}
+
 		// Сохраняем в БД
 		if err := s.articleRepo.CreateOrUpdate(article); err != nil {
 			fmt.Printf("Error saving article %d: %v\n", card.NmID, err)
@@ -260,12 +265,124 @@ func (s *WBService) saveArticles(cards []wb.Article, userID int) (bool, string)
 			continue
 		}
 
+		if err := s.articleRepo.SaveSizes(userID, int64(card.NmID), articleSizes(card)); err != nil {
+			fmt.Printf("Error saving sizes of article %d: %v\n", card.NmID, err)
+			countUnsaved++
+			continue
+		}
+
+		if err := s.articleRepo.SaveContent(userID, int64(card.NmID), articlePhotos(card), articleCharacteristics(card)); err != nil {
+			fmt.Printf("Error saving content of article %d: %v\n", card.NmID, err)
+			countUnsaved++
+			continue
+		}
+
+		if snapshot != nil {
+			changes := articleChanges(*snapshot, cardSnapshot(card), userID, accountID, int64(card.NmID), article.CardUpdatedAt)
+			if err := s.articleRepo.AddChanges(changes); err != nil {
+				fmt.Printf("Error saving changes of article %d: %v\n", card.NmID, err)
+			}
+		}
+
 		countSaved++
 	}
 
-	message := fmt.Sprintf("Total: %d, Saved: %d, Not saved: %d", countTotal, countSaved, countUnsaved)
-	success := countSaved > 0
+	fmt.Printf("✅ Результат обработки карточек: Total: %d, Saved: %d, Not saved: %d\n", countTotal, countSaved, countUnsaved)
+	return countSaved, countUnsaved
+}
 
-	fmt.Printf("✅ Результат обработки карточек: %s\n", message)
-	return success, message
+// articleSizes - все размеры карточки с баркодами
+func articleSizes(card wb.Article) []entity.WBArticleSize {
+	sizes := make([]entity.WBArticleSize, 0, len(card.Sizes))
+	for _, size := range card.Sizes {
+		if size.ChrtID == 0 {
+			continue
+		}
+
+		skus := make([]string, 0, len(size.Skus))
+		for _, sku := range size.Skus {
+			if sku = strings.TrimSpace(sku); sku != "" {
+				skus = append(skus, sku)
+			}
+		}
+
+		sizes = append(sizes, entity.WBArticleSize{
+			NmID:     int64(card.NmID),
+			ChrtID:   int64(size.ChrtID),
+			TechSize: size.TechSize,
+			WbSize:   size.WbSize,
+			Skus:     skus,
+		})
+	}
+	return sizes
+}
+
+// fillCardDetails - бренд, предмет, склейка, описание, габариты и даты карточки
+func fillCardDetails(article *entity.WBArticles, card wb.Article) {
+	if card.Brand != "" {
+		article.Brand = sql.NullString{String: card.Brand, Valid: true}
+	}
+	if card.SubjectID != 0 {
+		article.SubjectID = sql.NullInt64{Int64: int64(card.SubjectID), Valid: true}
+	}
+	if card.SubjectName != "" {
+		article.SubjectName = sql.NullString{String: card.SubjectName, Valid: true}
+	}
+	if card.ImtID != 0 {
+		article.ImtID = sql.NullInt64{Int64: int64(card.ImtID), Valid: true}
+	}
+	if card.Description != "" {
+		article.Description = sql.NullString{String: card.Description, Valid: true}
+	}
+
+	// Габариты без размеров в ответе не заполняются
+	dims := card.Dimensions
+	if dims.Length > 0 || dims.Width > 0 || dims.Height > 0 || dims.WeightBrutto > 0 {
+		article.Length = sql.NullFloat64{Float64: dims.Length, Valid: true}
+		article.Width = sql.NullFloat64{Float64: dims.Width, Valid: true}
+		article.Height = sql.NullFloat64{Float64: dims.Height, Valid: true}
+		article.WeightBrutto = sql.NullFloat64{Float64: dims.WeightBrutto, Valid: true}
+		article.DimensionsValid = sql.NullBool{Bool: dims.IsValid, Valid: true}
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, card.CreatedAt); err == nil {
+		article.CardCreatedAt = sql.NullTime{Time: t, Valid: true}
+	}
+	if t, err := time.Parse(time.RFC3339Nano, card.UpdatedAt); err == nil {
+		article.CardUpdatedAt = sql.NullTime{Time: t, Valid: true}
+	}
+}
+
+// articlePhotos - все фотографии карточки по порядку
+func articlePhotos(card wb.Article) []entity.WBArticlePhoto {
+	photos := make([]entity.WBArticlePhoto, 0, len(card.Photos))
+	for i, photo := range card.Photos {
+		photos = append(photos, entity.WBArticlePhoto{
+			NmID:     int64(card.NmID),
+			Position: i + 1,
+			Big:      photo.Big,
+			C246x328: photo.C246x328,
+			C516x688: photo.C516x688,
+			Square:   photo.Square,
+			Tm:       photo.Tm,
+		})
+	}
+	return photos
+}
+
+// articleCharacteristics - характеристики карточки (значение - как пришло из API)
+func articleCharacteristics(card wb.Article) []entity.WBArticleCharacteristic {
+	characteristics := make([]entity.WBArticleCharacteristic, 0, len(card.Characteristics))
+	for _, c := range card.Characteristics {
+		if c.ID == 0 {
+			continue
+		}
+		characteristics = append(characteristics, entity.WBArticleCharacteristic{
+			NmID:             int64(card.NmID),
+			CharacteristicID: c.ID,
+			Name:             c.Name,
+			Value:            c.Value,
+		})
+	}
+	return characteristics
 }
@@ -0,0 +1,137 @@
+package wb
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"wbrost-go/internal/api/wb"
+	"wbrost-go/internal/entity"
+)
+
+// articlesFullSyncInterval - как часто инкрементальная синхронизация дополняется полной сверкой
+const articlesFullSyncInterval = 24 * time.Hour
+
+// needFullSync - полная сверка нужна без сохраненного курсора или если прошлая была давно
+func needFullSync(state *entity.WBArticleSync) bool {
+	if !state.CursorUpdatedAt.Valid || state.CursorUpdatedAt.String == "" || !state.LastFullSyncAt.Valid {
+		return true
+	}
+	return time.Since(state.LastFullSyncAt.Time) >= articlesFullSyncInterval
+}
+
+// archiveCards переносит в архив карточки из корзины WB, а при полной сверке - и карточки,
+// которых больше нет в списке. Возвращает количество архивированных карточек
+func (s *WBService) archiveCards(provider *Provider, userID int, accountID sql.NullInt64, cards []wb.Article, full bool) int {
+	trash, err := provider.ListTrashNmIDs()
+	if err != nil {
+		// Без корзины нельзя отличить удаленные карточки от перенесенных в корзину
+		fmt.Printf("Error getting trash cards for user %d: %v\n", userID, err)
+		return 0
+	}
+
+	trashIDs := make([]int64, 0, len(trash))
+	for _, nmID := range trash {
+		trashIDs = append(trashIDs, int64(nmID))
+	}
+
+	changes, err := s.articleRepo.SetArchived(userID, accountID, trashIDs, entity.ArchiveReasonTrash)
+	if err != nil {
+		fmt.Printf("Error archiving trash cards for user %d: %v\n", userID, err)
+		return 0
+	}
+
+	if full {
+		seen := make(map[int64]bool, len(cards)+len(trashIDs))
+		for _, card := range cards {
+			seen[int64(card.NmID)] = true
+		}
+		for _, nmID := range trashIDs {
+			seen[nmID] = true
+		}
+
+		stored, err := s.articleRepo.GetNmIDs(userID, accountID)
+		if err != nil {
+			fmt.Printf("Error getting stored cards for user %d: %v\n", userID, err)
+		} else {
+			var missing []int64
+			for _, nmID := range stored {
+				if !seen[nmID] {
+					missing = append(missing, nmID)
+				}
+			}
+
+			deleted, err := s.articleRepo.SetArchived(userID, accountID, missing, entity.ArchiveReasonDeleted)
+			if err != nil {
+				fmt.Printf("Error archiving deleted cards for user %d: %v\n", userID, err)
+			}
+			changes = append(changes, deleted...)
+		}
+	}
+
+	if err := s.articleRepo.AddChanges(changes); err != nil {
+		fmt.Printf("Error saving card status changes for user %d: %v\n", userID, err)
+	}
+
+	return len(changes)
+}
+
+// cardSnapshot - состояние загруженной карточки в том же виде, что и сохраненное
+func cardSnapshot(card wb.Article) entity.WBArticleSnapshot {
+	snapshot := entity.WBArticleSnapshot{
+		Title:      card.Title,
+		VendorCode: card.VendorCode,
+		Brand:      card.Brand,
+		Subject:    card.SubjectName,
+		Status:     entity.ArticleStatusActive,
+	}
+
+	for _, photo := range card.Photos {
+		snapshot.Photos = append(snapshot.Photos, photo.Big)
+	}
+	for _, size := range articleSizes(card) {
+		snapshot.Sizes = append(snapshot.Sizes, size.TechSize)
+		snapshot.Barcodes = append(snapshot.Barcodes, size.Skus...)
+	}
+
+	return snapshot
+}
+
+// articleChanges сравнивает сохраненную карточку с загруженной и возвращает изменения для журнала
+func articleChanges(old, current entity.WBArticleSnapshot, userID int, accountID sql.NullInt64, nmID int64, cardUpdatedAt sql.NullTime) []entity.WBArticleChange {
+	var changes []entity.WBArticleChange
+	add := func(field, oldValue, newValue string) {
+		if oldValue == newValue {
+			return
+		}
+		changes = append(changes, entity.WBArticleChange{
+			UserID:        userID,
+			AccountID:     accountID,
+			NmID:          nmID,
+			Field:         field,
+			OldValue:      sql.NullString{String: oldValue, Valid: oldValue != ""},
+			NewValue:      sql.NullString{String: newValue, Valid: newValue != ""},
+			CardUpdatedAt: cardUpdatedAt,
+		})
+	}
+
+	add(entity.ArticleChangeTitle, old.Title, current.Title)
+	add(entity.ArticleChangeVendorCode, old.VendorCode, current.VendorCode)
+	add(entity.ArticleChangeBrand, old.Brand, current.Brand)
+	add(entity.ArticleChangeSubject, old.Subject, current.Subject)
+	// Порядок фотографий важен (первая - обложка), размеры и баркоды сравниваются как множества
+	add(entity.ArticleChangePhotos, strings.Join(old.Photos, "\n"), strings.Join(current.Photos, "\n"))
+	add(entity.ArticleChangeSizes, sortedJoin(old.Sizes), sortedJoin(current.Sizes))
+	add(entity.ArticleChangeBarcodes, sortedJoin(old.Barcodes), sortedJoin(current.Barcodes))
+	add(entity.ArticleChangeStatus, old.Status, current.Status)
+
+	return changes
+}
+
+// sortedJoin - значения через запятую по алфавиту
+func sortedJoin(values []string) string {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ", ")
+}
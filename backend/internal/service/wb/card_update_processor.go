@@ -0,0 +1,289 @@
+package wb
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"wbrost-go/internal/api/wb"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/service/card"
+)
+
+// cardUpdateCheckDelay - через сколько после отправки проверяются ошибки WB по карточкам
+// (WB применяет изменения асинхронно)
+const cardUpdateCheckDelay = 2 * time.Minute
+
+// subjectCharacteristicsTTL - как часто обновляется справочник характеристик предмета
+const subjectCharacteristicsTTL = 7 * 24 * time.Hour
+
+// ProcessPendingCardUpdates отправляет в WB задания с черновиками карточек
+// и проверяет результат ранее отправленных заданий
+func (s *WBService) ProcessPendingCardUpdates() error {
+	updates, err := s.cardUpdateRepo.GetPendingUpdates()
+	if err != nil {
+		return fmt.Errorf("failed to get pending card updates: %w", err)
+	}
+
+	for _, update := range updates {
+		fmt.Printf("Sending card update ID: %d for user %d\n", update.ID, update.UserID)
+		provider, errMsg := s.cardUpdateProvider(update)
+		if errMsg != "" {
+			s.failCardUpdate(update, errMsg)
+			continue
+		}
+		s.sendCardUpdate(provider, update)
+	}
+
+	sent, err := s.cardUpdateRepo.GetSentUpdates(time.Now().UTC().Add(-cardUpdateCheckDelay))
+	if err != nil {
+		return fmt.Errorf("failed to get sent card updates: %w", err)
+	}
+
+	for _, update := range sent {
+		fmt.Printf("Checking card update ID: %d for user %d\n", update.ID, update.UserID)
+		provider, errMsg := s.cardUpdateProvider(update)
+		if errMsg != "" {
+			s.updateCardUpdateStatus(update.ID, entity.ArticlesStatusError, errMsg)
+			continue
+		}
+		s.checkCardUpdate(provider, update)
+	}
+
+	return nil
+}
+
+// cardUpdateProvider - провайдер WB с ключом кабинета задания (или текст ошибки)
+func (s *WBService) cardUpdateProvider(update entity.WBCardUpdate) (*Provider, string) {
+	user, err := s.userRepo.GetByID(update.UserID)
+	if err != nil {
+		return nil, "User not found"
+	}
+
+	if err := s.applyAccountKey(user, update.AccountID); err != nil {
+		return nil, "Seller account not found"
+	}
+
+	if !user.WbKey.Valid || user.WbKey.String == "" {
+		return nil, "WB key not found"
+	}
+
+	if errMsg := checkTokenScope(user.WbKey.String, wb.ScopeContent); errMsg != "" {
+		return nil, errMsg
+	}
+
+	// Изменение карточек недоступно токену только на чтение
+	if info, err := wb.ParseToken(user.WbKey.String); err == nil && info.ReadOnly() {
+		return nil, "WB token is read-only"
+	}
+
+	return NewProvider(user.WbKey.String, s.rateLimiter), ""
+}
+
+// sendCardUpdate собирает карточки задания с изменениями черновиков и отправляет их в WB
+func (s *WBService) sendCardUpdate(provider *Provider, update entity.WBCardUpdate) {
+	drafts, err := s.cardUpdateRepo.GetUpdateDrafts(update.ID)
+	if err != nil {
+		s.updateCardUpdateStatus(update.ID, entity.ArticlesStatusError, err.Error())
+		return
+	}
+
+	var cards []wb.CardUpdate
+	for i := range drafts {
+		draft := &drafts[i]
+		cardUpdate, err := s.buildCardUpdate(draft)
+		if err != nil {
+			s.setDraftResult(draft, entity.CardDraftStatusError, []string{err.Error()})
+			continue
+		}
+		cards = append(cards, cardUpdate)
+	}
+
+	if len(cards) == 0 {
+		s.updateCardUpdateStatus(update.ID, entity.ArticlesStatusError, "No cards to update")
+		return
+	}
+
+	// Задание отмечается отправленным до запроса в WB, иначе сбой записи статуса после отправки
+	// оставит его в очереди и карточки уйдут повторно. sent_at хранится в UTC, как и updatedAt в списке ошибок WB
+	if err := s.cardUpdateRepo.MarkSent(update.ID, time.Now().UTC()); err != nil {
+		fmt.Printf("Failed to mark card update %d as sent: %v\n", update.ID, err)
+		return
+	}
+
+	if err := provider.UpdateCards(cards); err != nil {
+		s.failCardUpdate(update, fmt.Sprintf("Failed to update WB cards: %v", err))
+		return
+	}
+	fmt.Printf("Card update %d sent: %d cards\n", update.ID, len(cards))
+}
+
+// buildCardUpdate - карточка для отправки: сохраненная карточка с размерами и характеристиками
+// и изменениями черновика
+func (s *WBService) buildCardUpdate(draft *entity.WBCardDraft) (wb.CardUpdate, error) {
+	article, err := s.articleRepo.GetByNmID(draft.UserID, draft.NmID)
+	if err != nil {
+		return wb.CardUpdate{}, err
+	}
+	if article == nil {
+		return wb.CardUpdate{}, card.ErrCardNotFound
+	}
+
+	nmIDs := []int64{draft.NmID}
+	sizes, err := s.articleRepo.GetSizes(draft.UserID, nmIDs)
+	if err != nil {
+		return wb.CardUpdate{}, err
+	}
+	characteristics, err := s.articleRepo.GetCharacteristics(draft.UserID, nmIDs)
+	if err != nil {
+		return wb.CardUpdate{}, err
+	}
+
+	return card.BuildUpdate(article, sizes[draft.NmID], characteristics[draft.NmID], draft)
+}
+
+// checkCardUpdate сверяет отправленные черновики со списком ошибок WB: карточки с ошибками
+// отклонены, остальные приняты. После применения запускается загрузка изменений карточек
+func (s *WBService) checkCardUpdate(provider *Provider, update entity.WBCardUpdate) {
+	cardErrors, err := provider.CardErrors()
+	if err != nil {
+		// Задание остается отправленным и проверится при следующем запуске
+		fmt.Printf("Failed to get WB card errors for update %d: %v\n", update.ID, err)
+		return
+	}
+
+	drafts, err := s.cardUpdateRepo.GetUpdateDrafts(update.ID)
+	if err != nil {
+		fmt.Printf("Failed to get drafts of card update %d: %v\n", update.ID, err)
+		return
+	}
+
+	// Ошибки, появившиеся после отправки, по артикулу продавца
+	// (WB возвращает ошибки без nmID, objectID - это предмет)
+	byVendorCode := make(map[string][]string)
+	for _, e := range cardErrors {
+		if t, ok := parseReportTime(e.UpdatedAt); ok && update.SentAt.Valid && t.Before(update.SentAt.Time.Add(-time.Minute)) {
+			continue
+		}
+		byVendorCode[e.VendorCode] = append(byVendorCode[e.VendorCode], e.Errors...)
+	}
+
+	countApplied := 0
+	countRejected := 0
+	for i := range drafts {
+		draft := &drafts[i]
+		if draft.Status != entity.CardDraftStatusSent {
+			continue
+		}
+
+		vendorCode := draft.VendorCode.String
+		if !draft.VendorCode.Valid {
+			if article, err := s.articleRepo.GetByNmID(draft.UserID, draft.NmID); err == nil && article != nil {
+				vendorCode = article.InternalID.String
+			}
+		}
+
+		if errs := byVendorCode[vendorCode]; vendorCode != "" && len(errs) > 0 {
+			s.setDraftResult(draft, entity.CardDraftStatusError, errs)
+			countRejected++
+		} else {
+			s.setDraftResult(draft, entity.CardDraftStatusApplied, nil)
+			countApplied++
+		}
+	}
+
+	message := fmt.Sprintf("Applied: %d, Rejected: %d", countApplied, countRejected)
+	status := entity.ArticlesStatusSuccess
+	if countApplied == 0 && countRejected > 0 {
+		status = entity.ArticlesStatusError
+	}
+	s.updateCardUpdateStatus(update.ID, status, message)
+
+	// Загружаем примененные изменения, чтобы карточки в приложении совпадали с WB
+	if countApplied > 0 {
+		sync := &entity.WBArticlesGet{
+			UserID:    update.UserID,
+			AccountID: update.AccountID,
+			Status:    sql.NullInt64{Int64: entity.ArticlesStatusWait, Valid: true},
+		}
+		if err := s.articlesGetRepo.Create(sync); err != nil {
+			fmt.Printf("Failed to queue articles sync after card update %d: %v\n", update.ID, err)
+		}
+	}
+}
+
+// failCardUpdate - задание не отправлено: ошибка у задания и у всех его черновиков
+// (в очереди или уже отмеченных отправленными перед запросом в WB)
+func (s *WBService) failCardUpdate(update entity.WBCardUpdate, errMsg string) {
+	drafts, err := s.cardUpdateRepo.GetUpdateDrafts(update.ID)
+	if err != nil {
+		fmt.Printf("Failed to get drafts of card update %d: %v\n", update.ID, err)
+	}
+	for i := range drafts {
+		if drafts[i].Status == entity.CardDraftStatusQueued || drafts[i].Status == entity.CardDraftStatusSent {
+			s.setDraftResult(&drafts[i], entity.CardDraftStatusError, []string{errMsg})
+		}
+	}
+	s.updateCardUpdateStatus(update.ID, entity.ArticlesStatusError, errMsg)
+}
+
+func (s *WBService) setDraftResult(draft *entity.WBCardDraft, status string, errs []string) {
+	if err := s.cardUpdateRepo.SetDraftResult(draft.ID, status, errs); err != nil {
+		fmt.Printf("Failed to update card draft %d: %v\n", draft.ID, err)
+	}
+}
+
+func (s *WBService) updateCardUpdateStatus(updateID int, status int, errorMsg string) {
+	if err := s.cardUpdateRepo.UpdateStatus(updateID, status, errorMsg); err != nil {
+		fmt.Printf("Failed to update card update %d status: %v\n", updateID, err)
+	} else {
+		fmt.Printf("Card update %d updated to status %d\n", updateID, status)
+	}
+}
+
+// refreshSubjectCharacteristics обновляет справочник характеристик предметов загруженных карточек
+// (нужен для проверки черновиков)
+func (s *WBService) refreshSubjectCharacteristics(provider *Provider, cards []wb.Article) {
+	seen := make(map[int64]bool)
+	var subjectIDs []int64
+	for _, c := range cards {
+		if id := int64(c.SubjectID); id != 0 && !seen[id] {
+			seen[id] = true
+			subjectIDs = append(subjectIDs, id)
+		}
+	}
+	if len(subjectIDs) == 0 {
+		return
+	}
+
+	stale, err := s.cardUpdateRepo.GetStaleSubjects(subjectIDs, time.Now().Add(-subjectCharacteristicsTTL))
+	if err != nil {
+		fmt.Printf("Failed to get stale subjects: %v\n", err)
+		return
+	}
+
+	for _, subjectID := range stale {
+		charcs, err := provider.SubjectCharacteristics(int(subjectID))
+		if err != nil {
+			fmt.Printf("Failed to get characteristics of subject %d: %v\n", subjectID, err)
+			continue
+		}
+
+		characteristics := make([]entity.WBSubjectCharacteristic, 0, len(charcs))
+		for _, c := range charcs {
+			characteristics = append(characteristics, entity.WBSubjectCharacteristic{
+				SubjectID: int(subjectID),
+				CharcID:   c.CharcID,
+				Name:      c.Name,
+				Required:  c.Required,
+				UnitName:  c.UnitName,
+				MaxCount:  c.MaxCount,
+				CharcType: c.CharcType,
+			})
+		}
+
+		if err := s.cardUpdateRepo.SaveSubjectCharacteristics(int(subjectID), characteristics); err != nil {
+			fmt.Printf("Failed to save characteristics of subject %d: %v\n", subjectID, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -10,7 +10,7 @@ import (
 	"wbrost-go/internal/entity"
 )
 
-func (s *WBService) convertSupplierOperName(supplierName interface{}) int64 {
+func convertSupplierOperName(supplierName interface{}) int64 {
 	if supplierName == nil {
 		return 0
 	}
@@ -74,62 +74,83 @@ func (s *WBService) convertSupplierOperName(supplierName interface{}) int64 {
 	}
 }
 
-func (s *WBService) saveStats(reportData []interface{}, userID int) (bool, string) {
+func (s *WBService) saveStats(reportData []interface{}, userID int, accountID sql.NullInt64) (bool, string) {
 	if len(reportData) == 0 {
 		return false, "No data"
 	}
 
-	countSaved := 0
-	countUnsaved := 0
+	countInserted := 0
+	countFailed := 0
 	countTotal := len(reportData)
 
 	fmt.Printf("📊 Получено %d записей от WB API\n", countTotal)
 
+	batch := make([]*entity.Stat, 0, statBatchSize)
 	for _, item := range reportData {
 		order, ok := item.(map[string]interface{})
 		if !ok {
-			countUnsaved++
+			countFailed++
 			continue
 		}
 
 		stat := s.mapToStat(order, userID)
 		if stat == nil {
-			countUnsaved++
+			countFailed++
 			continue
 		}
+		stat.AccountID = accountID
 
-		// Генерируем хеш для проверки дубликатов
-		hash := s.generateHash(stat)
-		stat.HashInfo = hash
+		// Хеш - ключ дедупликации строк без rrd_id
+		stat.HashInfo = s.generateHash(stat)
 
-		// Проверяем существование
-		exists, err := s.statRepo.ExistsByHash(hash)
-		if err != nil {
-			fmt.Printf("Error checking hash: %v\n", err)
-			countUnsaved++
-			continue
+		batch = append(batch, stat)
+		if len(batch) == statBatchSize {
+			inserted, failed := s.insertStatBatch(batch)
+			countInserted += inserted
+			countFailed += failed
+			batch = batch[:0]
 		}
+	}
 
-		if exists {
-			countUnsaved++
-			continue
-		}
+	inserted, failed := s.insertStatBatch(batch)
+	countInserted += inserted
+	countFailed += failed
+
+	countDuplicates := countTotal - countInserted - countFailed
+
+	message := fmt.Sprintf("Total: %d, Inserted: %d, Duplicates: %d, Failed: %d", countTotal, countInserted, countDuplicates, countFailed)
+	success := countInserted > 0 || countDuplicates > 0
+
+	fmt.Printf("✅ Результат: %s\n", message)
+	return success, message
+}
+
+// insertStatBatch сохраняет пачку строк одним COPY. Если пачка не прошла целиком,
+// строки сохраняются по одной, чтобы отделить ошибочные. Возвращает (вставлено, ошибок)
+func (s *WBService) insertStatBatch(batch []*entity.Stat) (int, int) {
+	if len(batch) == 0 {
+		return 0, 0
+	}
 
-		// Сохраняем
-		if err := s.statRepo.Create(stat); err != nil {
+	inserted, err := s.statRepo.InsertBatch(batch)
+	if err == nil {
+		return inserted, 0
+	}
+
+	fmt.Printf("Error saving batch of %d stats, retrying one by one: %v\n", len(batch), err)
+
+	inserted, failed := 0, 0
+	for _, stat := range batch {
+		n, err := s.statRepo.InsertBatch([]*entity.Stat{stat})
+		if err != nil {
 			fmt.Printf("Error saving stat: %v\n", err)
-			countUnsaved++
+			failed++
 			continue
 		}
-
-		countSaved++
+		inserted += n
 	}
 
-	message := fmt.Sprintf("Total: %d, Saved: %d, Not saved (duplicates or errors): %d", countTotal, countSaved, countUnsaved)
-	success := countSaved > 0
-
-	fmt.Printf("✅ Результат: %s\n", message)
-	return success, message
+	return inserted, failed
 }
 
 func (s *WBService) mapToStat(data map[string]interface{}, userID int) *entity.Stat {
@@ -224,62 +245,33 @@ func (s *WBService) mapToStat(data map[string]interface{}, userID int) *entity.S
 		}
 	})
 
-	// Строковые поля, которые хранятся как VARCHAR
+	// Денежные и процентные поля (NUMERIC). WB отдает их числами, старые отчеты - строками
 	setValue(data["ppvz_for_pay"], func(v interface{}) {
-		if str, ok := v.(string); ok {
-			stat.PpvzForPay = sql.NullString{String: str, Valid: true}
-		} else if num, ok := v.(float64); ok {
-			// Конвертируем число в строку
-			stat.PpvzForPay = sql.NullString{String: fmt.Sprintf("%.2f", num), Valid: true}
-		}
+		stat.PpvzForPay = parseMoney(v)
 	})
 
 	setValue(data["rebill_logistic_cost"], func(v interface{}) {
-		if str, ok := v.(string); ok {
-			stat.RebillLogisticCost = sql.NullString{String: str, Valid: true}
-		} else if num, ok := v.(float64); ok {
-			stat.RebillLogisticCost = sql.NullString{String: fmt.Sprintf("%.2f", num), Valid: true}
-		}
+		stat.RebillLogisticCost = parseMoney(v)
 	})
 
 	setValue(data["ppvz_spp_prc"], func(v interface{}) {
-		if str, ok := v.(string); ok {
-			stat.PpvzSppPrc = sql.NullString{String: str, Valid: true}
-		} else if num, ok := v.(float64); ok {
-			stat.PpvzSppPrc = sql.NullString{String: fmt.Sprintf("%.2f", num), Valid: true}
-		}
+		stat.PpvzSppPrc = parseMoney(v)
 	})
 
 	setValue(data["ppvz_kvw_prc_base"], func(v interface{}) {
-		if str, ok := v.(string); ok {
-			stat.PpvzKvwPrcBase = sql.NullString{String: str, Valid: true}
-		} else if num, ok := v.(float64); ok {
-			stat.PpvzKvwPrcBase = sql.NullString{String: fmt.Sprintf("%.2f", num), Valid: true}
-		}
+		stat.PpvzKvwPrcBase = parseMoney(v)
 	})
 
 	setValue(data["ppvz_kvw_prc"], func(v interface{}) {
-		if str, ok := v.(string); ok {
-			stat.PpvzKvwPrc = sql.NullString{String: str, Valid: true}
-		} else if num, ok := v.(float64); ok {
-			stat.PpvzKvwPrc = sql.NullString{String: fmt.Sprintf("%.2f", num), Valid: true}
-		}
+		stat.PpvzKvwPrc = parseMoney(v)
 	})
 
 	setValue(data["ppvz_vw_nds"], func(v interface{}) {
-		if str, ok := v.(string); ok {
-			stat.PpvzVwNds = sql.NullString{String: str, Valid: true}
-		} else if num, ok := v.(float64); ok {
-			stat.PpvzVwNds = sql.NullString{String: fmt.Sprintf("%.2f", num), Valid: true}
-		}
+		stat.PpvzVwNds = parseMoney(v)
 	})
 
 	setValue(data["ppvz_vw"], func(v interface{}) {
-		if str, ok := v.(string); ok {
-			stat.PpvzVw = sql.NullString{String: str, Valid: true}
-		} else if num, ok := v.(float64); ok {
-			stat.PpvzVw = sql.NullString{String: fmt.Sprintf("%.2f", num), Valid: true}
-		}
+		stat.PpvzVw = parseMoney(v)
 	})
 
 	// Целочисленные поля (bigint)
@@ -343,6 +335,12 @@ func (s *WBService) mapToStat(data map[string]interface{}, userID int) *entity.S
 		}
 	})
 
+	setValue(data["rrd_id"], func(v interface{}) {
+		if num, ok := v.(float64); ok && num > 0 {
+			stat.RrdID = sql.NullInt64{Int64: int64(num), Valid: true}
+		}
+	})
+
 	setValue(data["rid"], func(v interface{}) {
 		if num, ok := v.(float64); ok {
 			stat.Rid = sql.NullInt64{Int64: int64(num), Valid: true}
@@ -357,7 +355,7 @@ func (s *WBService) mapToStat(data map[string]interface{}, userID int) *entity.S
 	// supplier_oper_name - особый случай, в БД это integer
 	setValue(data["supplier_oper_name"], func(v interface{}) {
 		// Преобразуем как в Yii2
-		supplierType := s.convertSupplierOperName(v)
+		supplierType := convertSupplierOperName(v)
 		stat.SupplierOperName = sql.NullInt64{Int64: supplierType, Valid: true}
 
 		// Отладка
@@ -550,9 +548,7 @@ func (s *WBService) generateHash(stat *entity.Stat) string {
 
 	// rebill_logistic_cost
 	if stat.RebillLogisticCost.Valid {
-		// Убираем запятые как в PHP
-		value := strings.ReplaceAll(stat.RebillLogisticCost.String, ",", "")
-		hashParts = append(hashParts, value)
+		hashParts = append(hashParts, fmt.Sprintf("%.2f", stat.RebillLogisticCost.Float64))
 	} else {
 		hashParts = append(hashParts, "0")
 	}
@@ -594,40 +590,35 @@ func (s *WBService) generateHash(stat *entity.Stat) string {
 
 	// ppvz_vw_nds
 	if stat.PpvzVwNds.Valid {
-		value := strings.ReplaceAll(stat.PpvzVwNds.String, ",", "")
-		hashParts = append(hashParts, value)
+		hashParts = append(hashParts, fmt.Sprintf("%.2f", stat.PpvzVwNds.Float64))
 	} else {
 		hashParts = append(hashParts, "0")
 	}
 
 	// ppvz_vw
 	if stat.PpvzVw.Valid {
-		value := strings.ReplaceAll(stat.PpvzVw.String, ",", "")
-		hashParts = append(hashParts, value)
+		hashParts = append(hashParts, fmt.Sprintf("%.2f", stat.PpvzVw.Float64))
 	} else {
 		hashParts = append(hashParts, "0")
 	}
 
 	// ppvz_spp_prc
 	if stat.PpvzSppPrc.Valid {
-		value := strings.ReplaceAll(stat.PpvzSppPrc.String, ",", "")
-		hashParts = append(hashParts, value)
+		hashParts = append(hashParts, fmt.Sprintf("%.2f", stat.PpvzSppPrc.Float64))
 	} else {
 		hashParts = append(hashParts, "0")
 	}
 
 	// ppvz_kvw_prc_base
 	if stat.PpvzKvwPrcBase.Valid {
-		value := strings.ReplaceAll(stat.PpvzKvwPrcBase.String, ",", "")
-		hashParts = append(hashParts, value)
+		hashParts = append(hashParts, fmt.Sprintf("%.2f", stat.PpvzKvwPrcBase.Float64))
 	} else {
 		hashParts = append(hashParts, "0")
 	}
 
 	// ppvz_kvw_prc
 	if stat.PpvzKvwPrc.Valid {
-		value := strings.ReplaceAll(stat.PpvzKvwPrc.String, ",", "")
-		hashParts = append(hashParts, value)
+		hashParts = append(hashParts, fmt.Sprintf("%.2f", stat.PpvzKvwPrc.Float64))
 	} else {
 		hashParts = append(hashParts, "0")
 	}
@@ -705,8 +696,7 @@ func (s *WBService) generateHash(stat *entity.Stat) string {
 
 	// ppvz_for_pay
 	if stat.PpvzForPay.Valid {
-		value := strings.ReplaceAll(stat.PpvzForPay.String, ",", "")
-		hashParts = append(hashParts, value)
+		hashParts = append(hashParts, fmt.Sprintf("%.2f", stat.PpvzForPay.Float64))
 	} else {
 		hashParts = append(hashParts, "0")
 	}
@@ -788,3 +778,23 @@ func (s *WBService) GetSupplierType(supplierType interface{}) string {
 		return fmt.Sprintf("%v", v)
 	}
 }
+
+// parseMoney разбирает денежное значение отчета: число или строку ("1234.5", "-12,30").
+// Запятая без точки - десятичный разделитель, с точкой - разделитель тысяч
+func parseMoney(v interface{}) sql.NullFloat64 {
+	switch value := v.(type) {
+	case float64:
+		return sql.NullFloat64{Float64: value, Valid: true}
+	case string:
+		str := strings.ReplaceAll(strings.TrimSpace(value), " ", "")
+		if strings.Contains(str, ".") {
+			str = strings.ReplaceAll(str, ",", "")
+		} else {
+			str = strings.ReplaceAll(str, ",", ".")
+		}
+		if num, err := strconv.ParseFloat(str, 64); err == nil {
+			return sql.NullFloat64{Float64: num, Valid: true}
+		}
+	}
+	return sql.NullFloat64{}
+}
@@ -1,13 +1,23 @@
 package wb
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"time"
+	"wbrost-go/internal/api/wb"
 	"wbrost-go/internal/entity"
+	"wbrost-go/internal/marketplace"
 )
 
 // ProcessPendingOrders обрабатывает все ожидающие заказы
 func (s *WBService) ProcessPendingOrders() error {
+	// Секции wb_stats на ближайшие месяцы, чтобы новые строки не копились в default-секции
+	now := time.Now()
+	if err := s.statRepo.EnsurePartitions(now, now.AddDate(0, statPartitionsAhead, 0)); err != nil {
+		fmt.Printf("⚠️ %v\n", err)
+	}
+
 	orders, err := s.statsGetRepo.GetPendingOrders()
 	if err != nil {
 		return fmt.Errorf("failed to get pending orders: %w", err)
@@ -27,14 +37,19 @@ func (s *WBService) ProcessPendingOrders() error {
 			continue
 		}
 
+		if err := s.applyAccountKey(user, order.AccountID); err != nil {
+			s.updateOrderStatus(&order, entity.StatusError, "Seller account not found")
+			continue
+		}
+
 		if !user.WbKey.Valid || user.WbKey.String == "" {
 			s.updateOrderStatus(&order, entity.StatusError, "WB key not found")
 			continue
 		}
 
-		// Проверяем формат токена
-		if !s.isValidTokenFormat(user.WbKey.String) {
-			s.updateOrderStatus(&order, entity.StatusError, "Invalid WB token format")
+		// Проверяем формат, срок действия и категорию "Статистика" токена
+		if errMsg := checkTokenScope(user.WbKey.String, wb.ScopeStatistics); errMsg != "" {
+			s.updateOrderStatus(&order, entity.StatusError, errMsg)
 			continue
 		}
 
@@ -65,9 +80,29 @@ func (s *WBService) updateOrderStatus(order *entity.WBStatsGet, status int, erro
 }
 
 func (s *WBService) processOrder(order *entity.WBStatsGet, user *entity.Users) ProcessResult {
-	// Получаем данные от WB API
-	reportData, err := s.getWBData(order, user)
+	dateFrom, err := time.Parse("2006-01-02", order.DateFrom)
+	if err != nil {
+		return ProcessResult{Status: false, Error: fmt.Sprintf("invalid date_from format: %v", err)}
+	}
+
+	dateTo, err := time.Parse("2006-01-02", order.DateTo)
+	if err != nil {
+		return ProcessResult{Status: false, Error: fmt.Sprintf("invalid date_to format: %v", err)}
+	}
+
+	// Получаем данные через провайдера WB
+	provider := NewProvider(user.WbKey.String, s.rateLimiter)
+	operations, err := provider.FetchOperations(dateFrom, dateTo)
 	if err != nil {
+		// Лимит запросов - повторим задание при следующем запуске
+		if errors.Is(err, marketplace.ErrRateLimited) {
+			return ProcessResult{
+				Status: false,
+				Error:  entity.TooManyRequests,
+				Retake: true,
+			}
+		}
+
 		// Проверяем, является ли ошибка 404 "path not found"
 		if strings.Contains(err.Error(), "path not found") {
 			return ProcessResult{
@@ -84,21 +119,17 @@ func (s *WBService) processOrder(order *entity.WBStatsGet, user *entity.Users) P
 		}
 	}
 
-	// Проверяем на лимит запросов
-	if len(reportData) > 0 {
-		if report, ok := reportData[0].(map[string]interface{}); ok {
-			if title, ok := report["title"].(string); ok && title == entity.TooManyRequests {
-				return ProcessResult{
-					Status: false,
-					Error:  entity.TooManyRequests,
-					Retake: true,
-				}
-			}
-		}
+	// Строки отчета в исходном виде - для wb_stats
+	reportData := make([]interface{}, len(operations))
+	for i, op := range operations {
+		reportData[i] = op.Raw
 	}
 
 	// Обрабатываем и сохраняем данные
-	success, message := s.saveStats(reportData, user.ID)
+	success, message := s.saveStats(reportData, user.ID, order.AccountID)
+
+	// Нормализованные операции - для сводной аналитики по всем маркетплейсам
+	s.saveOperations(provider.Marketplace(), operations, user.ID, order.AccountID)
 
 	return ProcessResult{
 		Status: success,
@@ -107,23 +138,21 @@ func (s *WBService) processOrder(order *entity.WBStatsGet, user *entity.Users) P
 	}
 }
 
-func (s *WBService) isValidTokenFormat(token string) bool {
-	if token == "" {
-		return false
+// checkTokenScope разбирает токен WB и проверяет, что он не истек и выдан на нужную категорию.
+// Возвращает текст ошибки для задания или пустую строку
+func checkTokenScope(token string, scope wb.Scope) string {
+	info, err := wb.ParseToken(token)
+	if err != nil {
+		return "Invalid WB token format: " + err.Error()
 	}
 
-	// JWT токен состоит из 3 частей, разделенных точками
-	parts := strings.Split(token, ".")
-	if len(parts) != 3 {
-		return false
+	if info.Expired(time.Now()) {
+		return fmt.Sprintf("WB token expired at %s", info.ExpiresAt.Format("2006-01-02"))
 	}
 
-	// Каждая часть должна быть не пустой
-	for _, part := range parts {
-		if part == "" {
-			return false
-		}
+	if !info.HasScope(scope) {
+		return fmt.Sprintf("WB token has no access to category \"%s\"", wb.ScopeNames[scope])
 	}
 
-	return true
+	return ""
 }
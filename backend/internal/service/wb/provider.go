@@ -0,0 +1,534 @@
+package wb
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"wbrost-go/internal/api/wb"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/marketplace"
+)
+
+// Provider - реализация marketplace.Provider для Wildberries
+type Provider struct {
+	client      *wb.Client
+	rateLimiter *WBRateLimiter
+}
+
+var _ marketplace.Provider = (*Provider)(nil)
+
+// NewProvider создает провайдера WB. rateLimiter может быть общим для сервиса
+// (nil - свой лимитер)
+func NewProvider(token string, rateLimiter *WBRateLimiter) *Provider {
+	if rateLimiter == nil {
+		rateLimiter = NewWBRateLimiter()
+	}
+
+	return &Provider{
+		client:      wb.NewWBClient(token),
+		rateLimiter: rateLimiter,
+	}
+}
+
+func (p *Provider) Marketplace() string {
+	return entity.MarketplaceWB
+}
+
+// ValidateCredentials проверяет формат, срок действия и работоспособность токена
+func (p *Provider) ValidateCredentials() error {
+	info, err := wb.ParseToken(p.client.Token)
+	if err != nil {
+		return err
+	}
+
+	if info.Expired(time.Now()) {
+		return marketplace.ErrInvalidCredentials
+	}
+
+	isValid, err := p.client.CheckToken()
+	if err != nil {
+		return err
+	}
+	if !isValid {
+		return marketplace.ErrInvalidCredentials
+	}
+
+	return nil
+}
+
+// ListProducts возвращает карточки товаров (категория токена "Контент")
+func (p *Provider) ListProducts() ([]marketplace.Product, error) {
+	cards, _, err := p.ListCards(wb.ArticleRequestCursor{})
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]marketplace.Product, len(cards))
+	for i, card := range cards {
+		product := marketplace.Product{
+			ExternalID: strconv.Itoa(card.NmID),
+			OfferID:    card.VendorCode,
+			Name:       card.Title,
+			Raw:        card,
+		}
+
+		if len(card.Photos) > 0 {
+			product.Photo = card.Photos[0].Big
+		}
+
+		if len(card.Sizes) > 0 {
+			product.Size = card.Sizes[0].TechSize
+			if len(card.Sizes[0].Skus) > 0 {
+				product.Barcode = card.Sizes[0].Skus[0]
+			}
+		}
+
+		products[i] = product
+	}
+
+	return products, nil
+}
+
+// ListCards возвращает карточки, измененные после курсора since (пустой курсор - все карточки),
+// и курсор последней полученной карточки для следующей синхронизации
+func (p *Provider) ListCards(since wb.ArticleRequestCursor) ([]wb.Article, wb.ArticleRequestCursor, error) {
+	// Проверяем токен (ping домена контента)
+	isValid, err := p.client.CheckTokenFor(wb.ScopeContent)
+	if err != nil {
+		return nil, since, fmt.Errorf("ошибка проверки токена: %v", err)
+	}
+
+	if !isValid {
+		return nil, since, marketplace.ErrInvalidCredentials
+	}
+
+	return p.fetchArticles(since)
+}
+
+// FetchOperations возвращает строки детализации отчета реализации
+// (категория токена "Статистика") в нормализованном виде
+func (p *Provider) FetchOperations(dateFrom, dateTo time.Time) ([]marketplace.Operation, error) {
+	// Проверяем токен (ping домена статистики)
+	fmt.Println("🔐 Проверка токена...")
+	isValid, err := p.client.CheckTokenFor(wb.ScopeStatistics)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка проверки токена: %v", err)
+	}
+
+	if !isValid {
+		return nil, marketplace.ErrInvalidCredentials
+	}
+
+	fmt.Println("✅ Токен валиден")
+
+	rows, err := p.fetchReport(dateFrom, dateTo)
+	if err != nil {
+		return nil, err
+	}
+
+	// WB отвечает на превышение лимита строкой {"title": "too many requests"}
+	if len(rows) > 0 {
+		if report, ok := rows[0].(map[string]interface{}); ok {
+			if title, ok := report["title"].(string); ok && title == entity.TooManyRequests {
+				return nil, marketplace.ErrRateLimited
+			}
+		}
+	}
+
+	operations := make([]marketplace.Operation, 0, len(rows))
+	for _, item := range rows {
+		row, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		operations = append(operations, mapOperation(row))
+	}
+
+	return operations, nil
+}
+
+// FetchOrders возвращает заказы за период. WB отдает заказы, измененные
+// начиная с dateFrom, поэтому лишние отсекаются по дате заказа
+func (p *Provider) FetchOrders(dateFrom, dateTo time.Time) ([]marketplace.Order, error) {
+	url := fmt.Sprintf("%s?dateFrom=%s&flag=0", wb.URLFor(wb.Orders), dateFrom.Format("2006-01-02"))
+
+	resp, err := p.safeRequest(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("WB API error: status %d", resp.StatusCode)
+	}
+
+	var rows []wb.Order
+	if err := json.Unmarshal(body, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	periodEnd := dateTo.AddDate(0, 0, 1)
+	orders := make([]marketplace.Order, 0, len(rows))
+	for _, row := range rows {
+		date, ok := parseReportTime(row.Date)
+		if !ok || date.Before(dateFrom) || !date.Before(periodEnd) {
+			continue
+		}
+
+		orders = append(orders, marketplace.Order{
+			ExternalID: row.Srid,
+			Date:       date,
+			ProductID:  strconv.FormatInt(row.NmID, 10),
+			OfferID:    row.SupplierArticle,
+			Quantity:   1,
+			Price:      row.PriceWithDisc,
+			Warehouse:  row.WarehouseName,
+			Region:     row.RegionName,
+			Cancelled:  row.IsCancel,
+			Raw:        row,
+		})
+	}
+
+	return orders, nil
+}
+
+// stocksDateFrom - дата в прошлом: WB возвращает текущие остатки по всем товарам,
+// измененные после нее, то есть все остатки
+const stocksDateFrom = "2019-06-20"
+
+// Stocks загружает текущие остатки на складах WB
+func (p *Provider) Stocks() ([]wb.Stock, error) {
+	url := fmt.Sprintf("%s?dateFrom=%s", wb.URLFor(wb.Stocks), stocksDateFrom)
+
+	resp, err := p.safeRequest(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("WB API error: status %d", resp.StatusCode)
+	}
+
+	var stocks []wb.Stock
+	if err := json.Unmarshal(body, &stocks); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return stocks, nil
+}
+
+// mapOperation переводит строку отчета реализации в нормализованную операцию
+func mapOperation(row map[string]interface{}) marketplace.Operation {
+	code := convertSupplierOperName(row["supplier_oper_name"])
+
+	op := marketplace.Operation{
+		Type:        operationType(code),
+		Name:        stringField(row, "supplier_oper_name"),
+		ProductName: stringField(row, "subject_name"),
+		OfferID:     stringField(row, "sa_name"),
+		Quantity:    int(numberField(row, "quantity")),
+		Raw:         row,
+	}
+
+	if nmID := int64(numberField(row, "nm_id")); nmID != 0 {
+		op.ProductID = strconv.FormatInt(nmID, 10)
+	}
+
+	// rrd_id - номер строки отчета, уникален в WB. Без него - хеш строки
+	if rrdID := int64(numberField(row, "rrd_id")); rrdID != 0 {
+		op.ExternalID = strconv.FormatInt(rrdID, 10)
+	} else {
+		data, _ := json.Marshal(row)
+		op.ExternalID = fmt.Sprintf("%x", sha256.Sum256(data))
+	}
+
+	if date, ok := parseReportTime(stringField(row, "sale_dt")); ok {
+		op.Date = date
+	} else if date, ok := parseReportTime(stringField(row, "rr_dt")); ok {
+		op.Date = date
+	}
+
+	revenue := math.Abs(numberField(row, "retail_amount"))
+	payout := numberField(row, "ppvz_for_pay")
+	if op.Type == entity.OperationReturn {
+		revenue, payout = -revenue, -math.Abs(payout)
+	}
+
+	if op.Type == entity.OperationSale || op.Type == entity.OperationReturn {
+		op.Revenue = revenue
+		op.Commission = revenue - payout
+	}
+	op.Payout = payout + numberField(row, "additional_payment")
+	op.Logistics = numberField(row, "delivery_rub") + numberField(row, "rebill_logistic_cost")
+	op.Storage = numberField(row, "storage_fee")
+	op.Penalty = numberField(row, "penalty")
+	op.OtherCharges = numberField(row, "deduction") + numberField(row, "acceptance")
+
+	return op
+}
+
+// operationType - тип нормализованной операции по коду supplier_oper_name
+func operationType(code int64) string {
+	switch code {
+	case 1, 7: // Продажа, Коррекция продаж
+		return entity.OperationSale
+	case 2: // Возврат
+		return entity.OperationReturn
+	case 3, 11, 18: // Логистика, Коррекция логистики, Возмещение издержек по перевозке
+		return entity.OperationLogistics
+	case 6, 9: // Хранение, Пересчет хранения
+		return entity.OperationStorage
+	case 5: // Штраф
+		return entity.OperationPenalty
+	case 13, 14, 15, 16, 17: // Компенсации
+		return entity.OperationCompensation
+	default:
+		return entity.OperationOther
+	}
+}
+
+// numberField - числовое поле строки отчета (WB отдает часть сумм строками)
+func numberField(row map[string]interface{}, key string) float64 {
+	switch v := row[key].(type) {
+	case float64:
+		return v
+	case string:
+		if num, err := strconv.ParseFloat(v, 64); err == nil {
+			return num
+		}
+	}
+	return 0
+}
+
+func stringField(row map[string]interface{}, key string) string {
+	if str, ok := row[key].(string); ok {
+		return str
+	}
+	return ""
+}
+
+// parseReportTime разбирает даты отчетов WB
+func parseReportTime(value string) (time.Time, bool) {
+	formats := []string{
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04:05Z",
+		"2006-01-02 15:04:05",
+		time.RFC3339,
+		"2006-01-02",
+	}
+
+	for _, format := range formats {
+		if t, err := time.Parse(format, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// fetchArticles получает карточки товаров постранично (курсор updatedAt + nmID) по возрастанию
+// updatedAt начиная с курсора since. Возвращает курсор последней полученной карточки
+// (since, если новых карточек нет)
+func (p *Provider) fetchArticles(since wb.ArticleRequestCursor) ([]wb.Article, wb.ArticleRequestCursor, error) {
+	var allCards []wb.Article
+	cursorUpdatedAt := since.UpdatedAt
+	cursorNmID := since.NmID
+	last := since
+
+	limit := 100 // Максимальный лимит за один запрос
+	totalProcessed := 0
+
+	for {
+		// Формируем тело запроса
+		request := wb.ArticleRequest{
+			Settings: wb.ArticleRequestSettings{
+				// По возрастанию: последняя карточка - самое свежее изменение, ее курсор сохраняется
+				Sort: &wb.ArticleRequestSort{Ascending: true},
+				Cursor: wb.ArticleRequestCursor{
+					Limit: limit,
+				},
+				Filter: struct {
+					WithPhoto int `json:"withPhoto"`
+				}{
+					WithPhoto: -1,
+				},
+			},
+		}
+
+		// Добавляем курсор, если он есть (для пагинации)
+		if cursorUpdatedAt != "" && cursorNmID > 0 {
+			request.Settings.Cursor.UpdatedAt = cursorUpdatedAt
+			request.Settings.Cursor.NmID = cursorNmID
+		}
+
+		var response wb.ArticleResponse
+		if err := p.postContent(wb.CardsList, request, &response); err != nil {
+			return nil, since, err
+		}
+
+		// Добавляем полученные карточки
+		allCards = append(allCards, response.Cards...)
+		totalProcessed += len(response.Cards)
+
+		fmt.Printf("Получено %d карточек (всего: %d)\n", len(response.Cards), totalProcessed)
+
+		if len(response.Cards) > 0 && response.Cursor.UpdatedAt != "" {
+			last = wb.ArticleRequestCursor{UpdatedAt: response.Cursor.UpdatedAt, NmID: response.Cursor.NmID}
+		}
+
+		// Проверяем, нужно ли продолжать пагинацию
+		if len(response.Cards) < limit || response.Cursor.Total < limit {
+			fmt.Printf("Получены все карточки. Всего: %d\n", totalProcessed)
+			break
+		}
+
+		// Обновляем курсор для следующего запроса
+		cursorUpdatedAt = response.Cursor.UpdatedAt
+		cursorNmID = response.Cursor.NmID
+
+		// Небольшая задержка между запросами, чтобы не превысить лимиты
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return allCards, last, nil
+}
+
+// ListTrashNmIDs возвращает nmID карточек в корзине WB
+func (p *Provider) ListTrashNmIDs() ([]int, error) {
+	var nmIDs []int
+	var cursor wb.TrashCursor
+
+	limit := 100
+	for {
+		var request wb.TrashRequest
+		request.Settings.Cursor = wb.TrashCursor{Limit: limit, TrashedAt: cursor.TrashedAt, NmID: cursor.NmID}
+
+		var response wb.TrashResponse
+		if err := p.postContent(wb.CardsTrash, request, &response); err != nil {
+			return nil, err
+		}
+
+		for _, card := range response.Cards {
+			nmIDs = append(nmIDs, card.NmID)
+		}
+
+		if len(response.Cards) < limit || response.Cursor.Total < limit {
+			break
+		}
+
+		cursor = response.Cursor
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return nmIDs, nil
+}
+
+// SubjectCharacteristics возвращает характеристики предмета (обязательные, лимит значений, тип)
+func (p *Provider) SubjectCharacteristics(subjectID int) ([]wb.SubjectCharacteristic, error) {
+	var response wb.SubjectCharacteristicsResponse
+	url := wb.URLFor(wb.SubjectCharcs) + "/" + strconv.Itoa(subjectID)
+	if err := p.contentRequest(http.MethodGet, url, nil, &response); err != nil {
+		return nil, err
+	}
+	if response.Error {
+		return nil, fmt.Errorf("WB API error: %s", response.ErrorText)
+	}
+	return response.Data, nil
+}
+
+// UpdateCards отправляет карточки на изменение. WB обрабатывает их асинхронно:
+// ошибки по отдельным карточкам появляются позже в CardErrors
+func (p *Provider) UpdateCards(cards []wb.CardUpdate) error {
+	var response wb.ContentResponse
+	if err := p.contentRequest(http.MethodPost, wb.URLFor(wb.CardsUpdate), cards, &response); err != nil {
+		return err
+	}
+	if response.Error {
+		return fmt.Errorf("WB API error: %s", response.ErrorText)
+	}
+	return nil
+}
+
+// CardErrors возвращает карточки, которые WB не смог создать или изменить
+func (p *Provider) CardErrors() ([]wb.CardError, error) {
+	var response wb.CardErrorsResponse
+	if err := p.contentRequest(http.MethodGet, wb.URLFor(wb.CardsErrors), nil, &response); err != nil {
+		return nil, err
+	}
+	if response.Error {
+		return nil, fmt.Errorf("WB API error: %s", response.ErrorText)
+	}
+	return response.Data, nil
+}
+
+// postContent отправляет POST-запрос к API контента и разбирает JSON-ответ
+func (p *Provider) postContent(endpoint wb.Endpoint, request interface{}, response interface{}) error {
+	return p.contentRequest(http.MethodPost, wb.URLFor(endpoint), request, response)
+}
+
+// contentRequest выполняет запрос к API контента (request = nil - без тела) и разбирает JSON-ответ.
+// При ошибке в текст добавляется errorText из ответа WB
+func (p *Provider) contentRequest(method, url string, request interface{}, response interface{}) error {
+	var reqBody io.Reader
+	if request != nil {
+		jsonBody, err := json.Marshal(request)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		reqBody = strings.NewReader(string(jsonBody))
+	}
+
+	req, err := http.NewRequest(method, url, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", p.client.Token)
+	if request != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.client.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != 200 {
+		fmt.Printf("Response body: %s\n", string(body))
+		var errResponse wb.ContentResponse
+		if json.Unmarshal(body, &errResponse) == nil && errResponse.ErrorText != "" {
+			return fmt.Errorf("WB API error: status %d: %s", resp.StatusCode, errResponse.ErrorText)
+		}
+		return fmt.Errorf("WB API error: status %d", resp.StatusCode)
+	}
+
+	// Парсим ответ
+	if err := json.Unmarshal(body, response); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return nil
+}
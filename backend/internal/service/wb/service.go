@@ -1,36 +1,57 @@
 package wb
 
 import (
+	"database/sql"
+	"fmt"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/marketplace"
+	"wbrost-go/internal/repository/account"
 	"wbrost-go/internal/repository/article"
+	"wbrost-go/internal/repository/operation"
 	"wbrost-go/internal/repository/stat"
 	"wbrost-go/internal/repository/user"
 )
 
+// statBatchSize - строк отчета в одном COPY
+const statBatchSize = 5000
+
+// statPartitionsAhead - на сколько месяцев вперед заранее создаются секции wb_stats
+const statPartitionsAhead = 3
+
 type WBService struct {
 	userRepo        *user.UserRepository
+	accountRepo     *account.SellerAccountRepository
 	statsGetRepo    *stat.WBStatsGetRepository
 	statRepo        *stat.StatRepository
 	articlesGetRepo *article.WBArticlesGetRepository
 	articleRepo     *article.WBArticlesRepository
+	cardUpdateRepo  *article.WBCardUpdateRepository
+	operationRepo   *operation.OperationRepository
 	rateLimiter     *WBRateLimiter
 }
 
 func NewWBService(
 	userRepo *user.UserRepository,
+	accountRepo *account.SellerAccountRepository,
 	statsGetRepo *stat.WBStatsGetRepository,
 	statRepo *stat.StatRepository,
 	articlesGetRepo *article.WBArticlesGetRepository,
 	articleRepo *article.WBArticlesRepository,
+	cardUpdateRepo *article.WBCardUpdateRepository,
+	operationRepo *operation.OperationRepository,
 ) *WBService {
 	// Используем новый rate limiter с поддержкой WB API
 	rateLimiter := NewWBRateLimiter()
 
 	return &WBService{
 		userRepo:        userRepo,
+		accountRepo:     accountRepo,
 		statsGetRepo:    statsGetRepo,
 		statRepo:        statRepo,
 		articlesGetRepo: articlesGetRepo,
 		articleRepo:     articleRepo,
+		cardUpdateRepo:  cardUpdateRepo,
+		operationRepo:   operationRepo,
 		rateLimiter:     rateLimiter,
 	}
 }
@@ -39,3 +60,44 @@ func NewWBService(
 func (s *WBService) GetLimiterStats() map[string]interface{} {
 	return s.rateLimiter.GetStats()
 }
+
+// applyAccountKey подставляет пользователю ключ кабинета, указанного в задании.
+// Задания без кабинета (созданные до появления кабинетов) работают с ключом из профиля
+func (s *WBService) applyAccountKey(user *entity.Users, accountID sql.NullInt64) error {
+	if !accountID.Valid || accountID.Int64 == 0 {
+		return nil
+	}
+
+	sellerAccount, err := s.accountRepo.GetByID(int(accountID.Int64))
+	if err != nil {
+		return err
+	}
+
+	if sellerAccount.UserID != user.ID {
+		return fmt.Errorf("seller account %d does not belong to user %d", sellerAccount.ID, user.ID)
+	}
+
+	if sellerAccount.Marketplace != entity.MarketplaceWB {
+		return fmt.Errorf("seller account %d is not a Wildberries account", sellerAccount.ID)
+	}
+
+	user.WbKey = sellerAccount.APIKey
+	return nil
+}
+
+// saveOperations сохраняет нормализованные операции одной пачкой. Ошибки не прерывают задание:
+// данные WB уже сохранены в wb_stats
+func (s *WBService) saveOperations(marketplaceCode string, operations []marketplace.Operation, userID int, accountID sql.NullInt64) {
+	entities := make([]*entity.MarketplaceOperation, len(operations))
+	for i := range operations {
+		entities[i] = operations[i].Entity(userID, accountID, marketplaceCode)
+	}
+
+	countSaved, err := s.operationRepo.CreateBatch(entities)
+	if err != nil {
+		fmt.Printf("Error saving operations %s: %v\n", marketplaceCode, err)
+		return
+	}
+
+	fmt.Printf("📒 Операции %s: всего %d, новых %d\n", marketplaceCode, len(operations), countSaved)
+}
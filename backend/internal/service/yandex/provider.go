@@ -0,0 +1,338 @@
+package yandex
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	yandexapi "wbrost-go/internal/api/yandex"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/marketplace"
+)
+
+// pageDelay - пауза между запросами к API Яндекс Маркета
+const pageDelay = 500 * time.Millisecond
+
+// Статусы заказа, по которым товар дошел до покупателя
+var deliveredStatuses = map[string]bool{
+	"DELIVERED":          true,
+	"PARTIALLY_RETURNED": true,
+	"RETURNED":           true,
+}
+
+// Provider - реализация marketplace.Provider для API Яндекс Маркета.
+// Ключ выдается на кабинет (businessId), отчеты строятся по магазину (campaignId)
+type Provider struct {
+	client     *yandexapi.Client
+	businessID int64
+	campaignID int64
+}
+
+var _ marketplace.Provider = (*Provider)(nil)
+
+// NewProvider создает провайдера Яндекс Маркета по ключу и идентификаторам кабинета и магазина
+func NewProvider(apiKey string, businessID, campaignID int64) *Provider {
+	return &Provider{
+		client:     yandexapi.NewYandexClient(apiKey),
+		businessID: businessID,
+		campaignID: campaignID,
+	}
+}
+
+func (p *Provider) Marketplace() string {
+	return entity.MarketplaceYandex
+}
+
+// ValidateCredentials проверяет ключ запросом информации о магазине
+func (p *Provider) ValidateCredentials() error {
+	isValid, err := p.client.CheckKey(p.campaignID)
+	if err != nil {
+		return err
+	}
+	if !isValid {
+		return marketplace.ErrInvalidCredentials
+	}
+	return nil
+}
+
+// ListProducts загружает товары кабинета (включая архивные)
+func (p *Provider) ListProducts() ([]marketplace.Product, error) {
+	var products []marketplace.Product
+	pageToken := ""
+
+	for {
+		resp, err := p.client.OfferMappings(p.businessID, pageToken)
+		if err != nil {
+			return nil, mapError(err)
+		}
+
+		for _, item := range resp.Result.OfferMappings {
+			product := marketplace.Product{
+				OfferID:  item.Offer.OfferID,
+				Name:     item.Offer.Name,
+				Archived: item.Offer.Archived,
+				Raw:      item,
+			}
+			if item.Mapping.MarketSku != 0 {
+				product.ExternalID = strconv.FormatInt(item.Mapping.MarketSku, 10)
+			}
+			if len(item.Offer.Barcodes) > 0 {
+				product.Barcode = item.Offer.Barcodes[0]
+			}
+			if len(item.Offer.Pictures) > 0 {
+				product.Photo = item.Offer.Pictures[0]
+			}
+			if item.Offer.BasicPrice != nil {
+				product.Price = item.Offer.BasicPrice.Value
+			}
+			products = append(products, product)
+		}
+
+		if resp.Result.Paging.NextPageToken == "" {
+			break
+		}
+		pageToken = resp.Result.Paging.NextPageToken
+		time.Sleep(pageDelay)
+	}
+
+	return products, nil
+}
+
+// FetchOperations загружает отчет по заказам магазина за период и раскладывает его
+// на нормализованные операции: продажи и возвраты по товарам, комиссии и услуги Маркета
+// по заказам. Идентификатор операции строится из номера заказа, чтобы повторная
+// загрузка того же периода не создавала дублей
+func (p *Provider) FetchOperations(dateFrom, dateTo time.Time) ([]marketplace.Operation, error) {
+	orders, err := p.fetchStatsOrders(dateFrom, dateTo)
+	if err != nil {
+		return nil, err
+	}
+
+	var operations []marketplace.Operation
+	for _, order := range orders {
+		operations = append(operations, mapOrderOperations(order)...)
+	}
+
+	return operations, nil
+}
+
+// FetchOrders загружает заказы магазина за период (по строке на товар заказа)
+func (p *Provider) FetchOrders(dateFrom, dateTo time.Time) ([]marketplace.Order, error) {
+	orders, err := p.fetchStatsOrders(dateFrom, dateTo)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []marketplace.Order
+	for _, order := range orders {
+		region := ""
+		if order.DeliveryRegion != nil {
+			region = order.DeliveryRegion.Name
+		}
+
+		for _, item := range order.Items {
+			o := marketplace.Order{
+				ExternalID: fmt.Sprintf("%d:%s", order.ID, item.ShopSku),
+				Date:       parseTime(order.CreationDate),
+				OfferID:    item.ShopSku,
+				Quantity:   item.Count,
+				Price:      itemTotal(item),
+				Region:     region,
+				Cancelled:  strings.HasPrefix(order.Status, "CANCELLED"),
+				Raw:        order,
+			}
+			if item.MarketSku != 0 {
+				o.ProductID = strconv.FormatInt(item.MarketSku, 10)
+			}
+			if item.Warehouse != nil {
+				o.Warehouse = item.Warehouse.Name
+			}
+			result = append(result, o)
+		}
+	}
+
+	return result, nil
+}
+
+// fetchStatsOrders загружает все страницы отчета по заказам за период
+func (p *Provider) fetchStatsOrders(dateFrom, dateTo time.Time) ([]yandexapi.StatsOrder, error) {
+	var orders []yandexapi.StatsOrder
+	pageToken := ""
+
+	fmt.Printf("📅 Яндекс Маркет: заказы %s - %s\n", dateFrom.Format("2006-01-02"), dateTo.Format("2006-01-02"))
+
+	for {
+		resp, err := p.client.StatsOrders(p.campaignID, dateFrom, dateTo, pageToken)
+		if err != nil {
+			return nil, mapError(err)
+		}
+
+		orders = append(orders, resp.Result.Orders...)
+
+		if resp.Result.Paging.NextPageToken == "" {
+			break
+		}
+		pageToken = resp.Result.Paging.NextPageToken
+		time.Sleep(pageDelay)
+	}
+
+	return orders, nil
+}
+
+// mapOrderOperations раскладывает заказ на операции.
+// Продажа - выручка по доставленному товару (цена покупателя + скидки за счет Маркета),
+// возврат - та же выручка с минусом на возвращенное количество.
+// Комиссии и услуги не делятся по товарам: Маркет отдает их суммой на заказ
+func mapOrderOperations(order yandexapi.StatsOrder) []marketplace.Operation {
+	var operations []marketplace.Operation
+	orderDate := parseTime(order.StatusUpdateDate)
+	if orderDate.IsZero() {
+		orderDate = parseTime(order.CreationDate)
+	}
+
+	if deliveredStatuses[order.Status] {
+		for _, item := range order.Items {
+			if item.Count == 0 {
+				continue
+			}
+
+			sale := marketplace.Operation{
+				ExternalID:  fmt.Sprintf("%d:%s:sale", order.ID, item.ShopSku),
+				Type:        entity.OperationSale,
+				Name:        "Продажа",
+				Date:        orderDate,
+				OfferID:     item.ShopSku,
+				ProductName: item.OfferName,
+				Quantity:    item.Count,
+				Revenue:     itemTotal(item),
+				Raw:         order,
+			}
+			if item.MarketSku != 0 {
+				sale.ProductID = strconv.FormatInt(item.MarketSku, 10)
+			}
+			sale.Payout = sale.Revenue
+			operations = append(operations, sale)
+
+			returned, returnDate := returnedCount(order, item)
+			if returned == 0 {
+				continue
+			}
+			if returnDate.IsZero() {
+				returnDate = orderDate
+			}
+
+			ret := sale
+			ret.ExternalID = fmt.Sprintf("%d:%s:return", order.ID, item.ShopSku)
+			ret.Type = entity.OperationReturn
+			ret.Name = "Возврат"
+			ret.Date = returnDate
+			ret.Quantity = returned
+			ret.Revenue = -sale.Revenue * float64(returned) / float64(item.Count)
+			ret.Payout = ret.Revenue
+			operations = append(operations, ret)
+		}
+	}
+
+	for _, commission := range order.Commissions {
+		if commission.Actual == 0 {
+			continue
+		}
+
+		op := marketplace.Operation{
+			ExternalID: fmt.Sprintf("%d:commission:%s", order.ID, commission.Type),
+			Type:       commissionType(commission.Type),
+			Name:       commission.Type,
+			Date:       orderDate,
+			Raw:        order,
+		}
+		if len(order.Items) > 0 {
+			op.OfferID = order.Items[0].ShopSku
+			op.ProductName = order.Items[0].OfferName
+			if order.Items[0].MarketSku != 0 {
+				op.ProductID = strconv.FormatInt(order.Items[0].MarketSku, 10)
+			}
+		}
+
+		switch op.Type {
+		case entity.OperationLogistics:
+			op.Logistics = commission.Actual
+		case entity.OperationStorage:
+			op.Storage = commission.Actual
+		default:
+			op.Commission = commission.Actual
+			op.Payout = -commission.Actual
+		}
+		operations = append(operations, op)
+	}
+
+	return operations
+}
+
+// commissionType - тип нормализованной операции по типу комиссии Маркета
+func commissionType(yandexType string) string {
+	switch yandexType {
+	case "DELIVERY_TO_CUSTOMER", "EXPRESS_DELIVERY_TO_CUSTOMER", "MIDDLE_MILE",
+		"CROSSREGIONAL_DELIVERY", "SORTING", "RETURN_PROCESSING":
+		return entity.OperationLogistics
+	case "RETURNED_ORDERS_STORAGE":
+		return entity.OperationStorage
+	default:
+		return entity.OperationCommission
+	}
+}
+
+// returnedCount - количество возвращенных единиц товара и дата возврата.
+// Для заказа в статусе RETURNED без детализации возвращенным считается весь товар
+func returnedCount(order yandexapi.StatsOrder, item yandexapi.StatsOrderItem) (int, time.Time) {
+	count := 0
+	var date time.Time
+	for _, detail := range item.Details {
+		if detail.ItemStatus == "RETURNED" {
+			count += detail.ItemCount
+			if d := parseTime(detail.UpdateDate); d.After(date) {
+				date = d
+			}
+		}
+	}
+
+	if count == 0 && order.Status == "RETURNED" {
+		count = item.Count
+	}
+	if count > item.Count {
+		count = item.Count
+	}
+
+	return count, date
+}
+
+// itemTotal - выручка по товару: цена покупателя и скидки, которые компенсирует Маркет
+func itemTotal(item yandexapi.StatsOrderItem) float64 {
+	total := 0.0
+	for _, price := range item.Prices {
+		total += price.Total
+	}
+	return total
+}
+
+// parseTime разбирает дату отчета: RFC3339 или только дату
+func parseTime(value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
+// mapError переводит ошибки клиента в ошибки провайдера
+func mapError(err error) error {
+	if errors.Is(err, yandexapi.ErrRateLimited) {
+		return marketplace.ErrRateLimited
+	}
+	return err
+}
@@ -0,0 +1,187 @@
+package yandex
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"wbrost-go/internal/entity"
+	"wbrost-go/internal/marketplace"
+	"wbrost-go/internal/repository/account"
+	"wbrost-go/internal/repository/article"
+	"wbrost-go/internal/repository/operation"
+	yandexrepo "wbrost-go/internal/repository/yandex"
+)
+
+type YandexService struct {
+	accountRepo   *account.SellerAccountRepository
+	jobRepo       *yandexrepo.YandexGetRepository
+	articleRepo   *article.WBArticlesRepository
+	operationRepo *operation.OperationRepository
+}
+
+func NewYandexService(
+	accountRepo *account.SellerAccountRepository,
+	jobRepo *yandexrepo.YandexGetRepository,
+	articleRepo *article.WBArticlesRepository,
+	operationRepo *operation.OperationRepository,
+) *YandexService {
+	return &YandexService{
+		accountRepo:   accountRepo,
+		jobRepo:       jobRepo,
+		articleRepo:   articleRepo,
+		operationRepo: operationRepo,
+	}
+}
+
+// ProcessPendingJobs обрабатывает задания yandex_get со статусом 0
+func (s *YandexService) ProcessPendingJobs() error {
+	jobs, err := s.jobRepo.GetPendingJobs()
+	if err != nil {
+		return err
+	}
+
+	if len(jobs) == 0 {
+		fmt.Println("No pending yandex jobs found")
+		return nil
+	}
+
+	for _, job := range jobs {
+		fmt.Printf("Processing yandex job ID: %d (%s) for user %d, account %d\n", job.ID, job.Type, job.UserID, job.AccountID)
+
+		sellerAccount, err := s.accountRepo.GetByID(job.AccountID)
+		if err != nil || sellerAccount.UserID != job.UserID || sellerAccount.Marketplace != entity.MarketplaceYandex {
+			s.updateJobStatus(job.ID, entity.StatusError, "Yandex Market account not found")
+			continue
+		}
+
+		if !sellerAccount.APIKey.Valid || sellerAccount.APIKey.String == "" || !sellerAccount.BusinessID.Valid || !sellerAccount.CampaignID.Valid {
+			s.updateJobStatus(job.ID, entity.StatusError, "Yandex Market key, business_id or campaign_id not set")
+			continue
+		}
+
+		provider := NewProvider(sellerAccount.APIKey.String, sellerAccount.BusinessID.Int64, sellerAccount.CampaignID.Int64)
+
+		// Сначала проверяем ключ, чтобы не гонять задание с отозванным ключом
+		if err := provider.ValidateCredentials(); err != nil {
+			if errors.Is(err, marketplace.ErrInvalidCredentials) {
+				s.updateJobStatus(job.ID, entity.StatusError, "Ключ Яндекс Маркета недействителен или нет доступа к магазину")
+				continue
+			}
+			// Сетевая ошибка или сбой API - оставляем задание на следующий запуск
+			s.updateJobStatus(job.ID, entity.StatusWait, err.Error())
+			continue
+		}
+
+		var result string
+		switch job.Type {
+		case entity.YandexJobOperations:
+			result, err = s.loadOperations(provider, &job)
+		case entity.YandexJobProducts:
+			result, err = s.loadProducts(provider, &job)
+		default:
+			err = fmt.Errorf("unknown yandex job type: %s", job.Type)
+		}
+
+		if err != nil {
+			if errors.Is(err, marketplace.ErrRateLimited) {
+				// Лимит запросов - повторим на следующем запуске
+				s.updateJobStatus(job.ID, entity.StatusWait, err.Error())
+				continue
+			}
+			s.updateJobStatus(job.ID, entity.StatusError, err.Error())
+			continue
+		}
+
+		s.updateJobStatus(job.ID, entity.StatusSuccess, result)
+	}
+
+	return nil
+}
+
+// loadOperations загружает заказы с комиссиями и услугами за период задания
+// в нормализованные операции кабинета
+func (s *YandexService) loadOperations(provider *Provider, job *entity.YandexGet) (string, error) {
+	dateFrom, err := time.Parse("2006-01-02", job.DateFrom.String)
+	if err != nil {
+		return "", fmt.Errorf("invalid date_from format: %w", err)
+	}
+
+	dateTo, err := time.Parse("2006-01-02", job.DateTo.String)
+	if err != nil {
+		return "", fmt.Errorf("invalid date_to format: %w", err)
+	}
+
+	operations, err := provider.FetchOperations(dateFrom, dateTo)
+	if err != nil {
+		return "", err
+	}
+
+	accountID := sql.NullInt64{Int64: int64(job.AccountID), Valid: true}
+	inserted, skipped := 0, 0
+	for i := range operations {
+		saved, err := s.operationRepo.Create(operations[i].Entity(job.UserID, accountID, provider.Marketplace()))
+		if err != nil {
+			return "", err
+		}
+		if saved {
+			inserted++
+		} else {
+			skipped++
+		}
+	}
+
+	fmt.Printf("✅ Яндекс Маркет: сохранено %d операций, пропущено дублей %d\n", inserted, skipped)
+	return "", nil
+}
+
+// loadProducts загружает товары кабинета в общий каталог (wb_articles, marketplace = yandex).
+// Товары без карточки на Маркете (нет marketSku) пропускаются - по ним нет продаж
+func (s *YandexService) loadProducts(provider *Provider, job *entity.YandexGet) (string, error) {
+	products, err := provider.ListProducts()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	saved, skipped := 0, 0
+	for _, product := range products {
+		if product.ExternalID == "" {
+			skipped++
+			continue
+		}
+
+		article := &entity.WBArticles{
+			UserID:      job.UserID,
+			AccountID:   sql.NullInt64{Int64: int64(job.AccountID), Valid: true},
+			Marketplace: entity.MarketplaceYandex,
+			Articule:    product.ExternalID,
+			Name:        nullString(product.Name),
+			Photo:       nullString(product.Photo),
+			Barcode:     nullString(product.Barcode),
+			InternalID:  nullString(product.OfferID),
+			Created:     sql.NullTime{Time: now, Valid: true},
+			Updated:     sql.NullTime{Time: now, Valid: true},
+			UpdatedAt:   sql.NullTime{Time: now, Valid: true},
+		}
+
+		if err := s.articleRepo.CreateOrUpdate(article); err != nil {
+			return "", err
+		}
+		saved++
+	}
+
+	fmt.Printf("✅ Яндекс Маркет: сохранено %d товаров, без карточки на Маркете %d\n", saved, skipped)
+	return "", nil
+}
+
+func (s *YandexService) updateJobStatus(jobID int, status int, errorMsg string) {
+	err := s.jobRepo.UpdateStatus(jobID, status, errorMsg)
+	if err != nil {
+		fmt.Printf("Failed to update yandex job %d status: %v\n", jobID, err)
+	}
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
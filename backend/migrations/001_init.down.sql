@@ -1,6 +0,0 @@
--- Удаляем все таблицы в обратном порядке
-DROP TABLE IF EXISTS wb_stats_get;
-DROP TABLE IF EXISTS wb_articles_get;
-DROP TABLE IF EXISTS wb_articles;
-DROP TABLE IF EXISTS wb_stats;
-DROP TABLE IF EXISTS users;
\ No newline at end of file
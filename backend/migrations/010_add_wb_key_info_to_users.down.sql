@@ -0,0 +1,4 @@
+ALTER TABLE users DROP COLUMN IF EXISTS wb_seller_uuid;
+ALTER TABLE users DROP COLUMN IF EXISTS wb_seller_id;
+ALTER TABLE users DROP COLUMN IF EXISTS wb_key_scopes;
+ALTER TABLE users DROP COLUMN IF EXISTS wb_key_expires_at;
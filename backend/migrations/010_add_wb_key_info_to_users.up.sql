@@ -0,0 +1,10 @@
+-- Данные, извлеченные из payload токена WB
+ALTER TABLE users ADD COLUMN IF NOT EXISTS wb_key_expires_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS wb_key_scopes BIGINT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS wb_seller_id BIGINT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS wb_seller_uuid VARCHAR(64);
+
+COMMENT ON COLUMN users.wb_key_expires_at IS 'Срок действия API ключа WB (exp)';
+COMMENT ON COLUMN users.wb_key_scopes IS 'Битовая маска категорий API ключа WB (s)';
+COMMENT ON COLUMN users.wb_seller_id IS 'ID продавца WB из API ключа (oid)';
+COMMENT ON COLUMN users.wb_seller_uuid IS 'UUID продавца WB из API ключа (sid)';
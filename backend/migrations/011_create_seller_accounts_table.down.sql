@@ -0,0 +1,8 @@
+-- Ключи кабинетов по умолчанию остаются в профиле пользователя (users.wb_key),
+-- ключи дополнительных кабинетов теряются вместе с таблицей
+ALTER TABLE wb_articles_get DROP COLUMN IF EXISTS account_id;
+ALTER TABLE wb_stats_get DROP COLUMN IF EXISTS account_id;
+ALTER TABLE wb_articles DROP COLUMN IF EXISTS account_id;
+ALTER TABLE wb_stats DROP COLUMN IF EXISTS account_id;
+
+DROP TABLE IF EXISTS seller_accounts;
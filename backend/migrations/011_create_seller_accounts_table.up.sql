@@ -0,0 +1,57 @@
+-- Кабинеты продавца: у пользователя может быть несколько API ключей (кабинетов) маркетплейса
+CREATE TABLE IF NOT EXISTS seller_accounts (
+    id SERIAL PRIMARY KEY,
+    id_user INT NOT NULL,
+    marketplace VARCHAR(20) NOT NULL DEFAULT 'wb',
+    name VARCHAR(255) NOT NULL,
+    api_key TEXT,
+    key_expires_at TIMESTAMP,
+    key_scopes BIGINT,
+    seller_id BIGINT,
+    seller_uuid VARCHAR(64),
+    is_default SMALLINT NOT NULL DEFAULT 0,
+    del INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_seller_accounts_id_user ON seller_accounts(id_user);
+CREATE INDEX idx_seller_accounts_marketplace ON seller_accounts(marketplace);
+
+COMMENT ON COLUMN seller_accounts.api_key IS 'API ключ кабинета (зашифрован, как users.wb_key)';
+COMMENT ON COLUMN seller_accounts.is_default IS 'Кабинет по умолчанию (ключ из профиля пользователя)';
+
+-- Переносим ключи из профиля пользователей в кабинеты по умолчанию
+INSERT INTO seller_accounts (
+    id_user, marketplace, name, api_key, key_expires_at, key_scopes, seller_id, seller_uuid, is_default
+)
+SELECT id_user, 'wb', 'Основной кабинет', wb_key, wb_key_expires_at, wb_key_scopes, wb_seller_id, wb_seller_uuid, 1
+FROM users
+WHERE COALESCE(wb_key, '') != '';
+
+-- Кабинет, к которому относятся данные и задания
+ALTER TABLE wb_stats ADD COLUMN IF NOT EXISTS account_id INT;
+ALTER TABLE wb_articles ADD COLUMN IF NOT EXISTS account_id INT;
+ALTER TABLE wb_stats_get ADD COLUMN IF NOT EXISTS account_id INT;
+ALTER TABLE wb_articles_get ADD COLUMN IF NOT EXISTS account_id INT;
+
+UPDATE wb_stats s SET account_id = a.id
+FROM seller_accounts a
+WHERE a.id_user = s.user_id AND a.is_default = 1 AND s.account_id IS NULL;
+
+UPDATE wb_articles wa SET account_id = a.id
+FROM seller_accounts a
+WHERE a.id_user = wa.id_user AND a.is_default = 1 AND wa.account_id IS NULL;
+
+UPDATE wb_stats_get g SET account_id = a.id
+FROM seller_accounts a
+WHERE a.id_user = g.id_user AND a.is_default = 1 AND g.account_id IS NULL;
+
+UPDATE wb_articles_get g SET account_id = a.id
+FROM seller_accounts a
+WHERE a.id_user = g.id_user AND a.is_default = 1 AND g.account_id IS NULL;
+
+CREATE INDEX idx_wb_stats_account_id ON wb_stats(account_id);
+CREATE INDEX idx_wb_articles_account_id ON wb_articles(account_id);
+CREATE INDEX idx_wb_stats_get_account_id ON wb_stats_get(account_id);
+CREATE INDEX idx_wb_articles_get_account_id ON wb_articles_get(account_id);
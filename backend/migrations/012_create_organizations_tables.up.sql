@@ -0,0 +1,53 @@
+-- Организации: владеют кабинетами продавца и данными (данные хранятся под id_user владельца)
+CREATE TABLE IF NOT EXISTS organizations (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    owner_id INT NOT NULL,
+    del INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- У пользователя одна собственная организация: ее данные - данные владельца
+CREATE UNIQUE INDEX idx_organizations_owner_id ON organizations(owner_id);
+
+-- Участники организации и их роли: owner, manager, analyst, viewer
+CREATE TABLE IF NOT EXISTS organization_members (
+    id SERIAL PRIMARY KEY,
+    organization_id INT NOT NULL REFERENCES organizations(id),
+    id_user INT NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_organization_members_org_user ON organization_members(organization_id, id_user);
+CREATE INDEX idx_organization_members_id_user ON organization_members(id_user);
+
+-- Приглашения по email или username
+CREATE TABLE IF NOT EXISTS organization_invites (
+    id SERIAL PRIMARY KEY,
+    organization_id INT NOT NULL REFERENCES organizations(id),
+    email VARCHAR(255),
+    username VARCHAR(255),
+    role VARCHAR(20) NOT NULL,
+    invited_by INT NOT NULL,
+    status SMALLINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_organization_invites_organization_id ON organization_invites(organization_id);
+CREATE INDEX idx_organization_invites_email ON organization_invites(LOWER(email));
+CREATE INDEX idx_organization_invites_username ON organization_invites(LOWER(username));
+
+COMMENT ON COLUMN organization_invites.status IS '0 - ожидает, 1 - принято, 2 - отклонено, 3 - отозвано';
+
+-- Собственная организация для каждого существующего пользователя
+INSERT INTO organizations (name, owner_id)
+SELECT COALESCE(NULLIF(name, ''), username), id_user
+FROM users;
+
+INSERT INTO organization_members (organization_id, id_user, role)
+SELECT id, owner_id, 'owner'
+FROM organizations;
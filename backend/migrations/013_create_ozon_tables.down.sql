@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS ozon_products;
+DROP TABLE IF EXISTS ozon_transactions;
+DROP TABLE IF EXISTS ozon_get;
+
+COMMENT ON COLUMN users.ozon_status IS NULL;
+ALTER TABLE users DROP COLUMN IF EXISTS ozon_client_id;
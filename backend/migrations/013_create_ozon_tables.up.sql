@@ -0,0 +1,77 @@
+-- Ozon Seller API: Client-Id к ключу users.ozon_key
+ALTER TABLE users ADD COLUMN IF NOT EXISTS ozon_client_id VARCHAR(64);
+
+COMMENT ON COLUMN users.ozon_client_id IS 'Client-Id Ozon Seller API (пара к ozon_key)';
+COMMENT ON COLUMN users.ozon_status IS 'Статус ключа Ozon: 0 - не проверен, 1 - активен, 2 - недействителен';
+
+-- Задания на загрузку данных Ozon (аналог wb_stats_get / wb_articles_get)
+CREATE TABLE IF NOT EXISTS ozon_get (
+    id SERIAL PRIMARY KEY,
+    id_user INT NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    status INT,
+    date_from VARCHAR(255),
+    date_to VARCHAR(255),
+    created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_error VARCHAR(1000)
+);
+
+CREATE INDEX idx_ozon_get_id_user ON ozon_get(id_user);
+CREATE INDEX idx_ozon_get_status ON ozon_get(status);
+
+COMMENT ON COLUMN ozon_get.type IS 'transactions - финансовые операции за период, products - список товаров';
+
+-- Финансовые операции Ozon (/v3/finance/transaction/list)
+CREATE TABLE IF NOT EXISTS ozon_transactions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    operation_id BIGINT NOT NULL,
+    operation_type VARCHAR(255),
+    operation_type_name VARCHAR(255),
+    operation_date TIMESTAMP,
+    type VARCHAR(50),
+    posting_number VARCHAR(100),
+    delivery_schema VARCHAR(20),
+    order_date TIMESTAMP,
+    warehouse_id BIGINT,
+    sku BIGINT,
+    item_name VARCHAR(500),
+    items_count INT NOT NULL DEFAULT 0,
+    accruals_for_sale NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    sale_commission NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    delivery_charge NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    return_delivery_charge NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    services_amount NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    amount NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    items JSONB,
+    services JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_ozon_transactions_user_operation ON ozon_transactions(user_id, operation_id);
+CREATE INDEX idx_ozon_transactions_user_date ON ozon_transactions(user_id, operation_date);
+CREATE INDEX idx_ozon_transactions_sku ON ozon_transactions(sku);
+
+COMMENT ON COLUMN ozon_transactions.sku IS 'SKU первого товара операции (для группировки по товару)';
+COMMENT ON COLUMN ozon_transactions.services_amount IS 'Сумма услуг Ozon по операции (services[].price)';
+
+-- Товары Ozon (/v3/product/list + /v3/product/info/list)
+CREATE TABLE IF NOT EXISTS ozon_products (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    product_id BIGINT NOT NULL,
+    offer_id VARCHAR(255),
+    sku BIGINT,
+    name VARCHAR(500),
+    barcode VARCHAR(255),
+    photo VARCHAR(500),
+    price NUMERIC(14, 2),
+    archived SMALLINT NOT NULL DEFAULT 0,
+    cost_price NUMERIC(15, 2),
+    created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_ozon_products_user_product ON ozon_products(user_id, product_id);
+CREATE INDEX idx_ozon_products_sku ON ozon_products(sku);
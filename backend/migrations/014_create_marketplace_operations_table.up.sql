@@ -0,0 +1,35 @@
+-- Нормализованные финансовые операции всех маркетплейсов (WB, Ozon, ...).
+-- Знаки: revenue и payout положительные у продаж и отрицательные у возвратов,
+-- расходы (commission, logistics, storage, penalty, other_charges) положительные.
+-- Итог операции для продавца: payout - logistics - storage - penalty - other_charges
+CREATE TABLE IF NOT EXISTS marketplace_operations (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    account_id INT,
+    marketplace VARCHAR(20) NOT NULL,
+    external_id VARCHAR(255) NOT NULL,
+    operation_type VARCHAR(30) NOT NULL,
+    operation_name VARCHAR(255),
+    operation_date TIMESTAMP,
+    product_id VARCHAR(100),
+    offer_id VARCHAR(255),
+    product_name VARCHAR(500),
+    quantity INT NOT NULL DEFAULT 0,
+    revenue NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    payout NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    commission NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    logistics NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    storage NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    penalty NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    other_charges NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_marketplace_operations_external ON marketplace_operations(user_id, marketplace, external_id);
+CREATE INDEX idx_marketplace_operations_user_date ON marketplace_operations(user_id, operation_date);
+CREATE INDEX idx_marketplace_operations_account ON marketplace_operations(account_id);
+
+COMMENT ON COLUMN marketplace_operations.external_id IS 'Идентификатор операции на маркетплейсе (WB - rrd_id, Ozon - operation_id)';
+COMMENT ON COLUMN marketplace_operations.operation_type IS 'sale, return, logistics, storage, penalty, compensation, other';
+COMMENT ON COLUMN marketplace_operations.product_id IS 'Товар на маркетплейсе (WB - nm_id, Ozon - sku)';
+COMMENT ON COLUMN marketplace_operations.offer_id IS 'Артикул продавца';
@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS yandex_get;
+
+COMMENT ON COLUMN marketplace_operations.operation_type IS 'sale, return, logistics, storage, penalty, compensation, other';
+
+-- Каталог снова только WB: товары Яндекс Маркета удаляются
+DELETE FROM wb_articles WHERE marketplace <> 'wb';
+DROP INDEX IF EXISTS idx_wb_articles_user_marketplace;
+ALTER TABLE wb_articles DROP COLUMN IF EXISTS marketplace;
+ALTER TABLE wb_articles ALTER COLUMN articule TYPE INT;
+COMMENT ON COLUMN wb_articles.articule IS NULL;
+
+ALTER TABLE seller_accounts DROP COLUMN IF EXISTS campaign_id;
+ALTER TABLE seller_accounts DROP COLUMN IF EXISTS business_id;
+COMMENT ON COLUMN seller_accounts.marketplace IS NULL;
@@ -0,0 +1,37 @@
+-- Яндекс Маркет: ключ API хранится в кабинете (seller_accounts.api_key),
+-- для запросов нужны также идентификаторы кабинета (business) и магазина (campaign)
+ALTER TABLE seller_accounts ADD COLUMN IF NOT EXISTS business_id BIGINT;
+ALTER TABLE seller_accounts ADD COLUMN IF NOT EXISTS campaign_id BIGINT;
+
+COMMENT ON COLUMN seller_accounts.marketplace IS 'wb - Wildberries, yandex - Яндекс Маркет';
+COMMENT ON COLUMN seller_accounts.business_id IS 'Яндекс Маркет: идентификатор кабинета (businessId)';
+COMMENT ON COLUMN seller_accounts.campaign_id IS 'Яндекс Маркет: идентификатор магазина (campaignId)';
+
+-- Каталог товаров общий для маркетплейсов, которые синхронизируются в wb_articles
+ALTER TABLE wb_articles ADD COLUMN IF NOT EXISTS marketplace VARCHAR(20) NOT NULL DEFAULT 'wb';
+-- marketSku Яндекс Маркета (11-12 цифр) не помещается в INT
+ALTER TABLE wb_articles ALTER COLUMN articule TYPE BIGINT;
+CREATE INDEX IF NOT EXISTS idx_wb_articles_user_marketplace ON wb_articles(id_user, marketplace, articule);
+
+COMMENT ON COLUMN wb_articles.articule IS 'WB - nm_id, Яндекс Маркет - marketSku';
+
+COMMENT ON COLUMN marketplace_operations.operation_type IS 'sale, return, commission, logistics, storage, penalty, compensation, other';
+
+-- Задания на загрузку данных Яндекс Маркета (по кабинету)
+CREATE TABLE IF NOT EXISTS yandex_get (
+    id SERIAL PRIMARY KEY,
+    id_user INT NOT NULL,
+    account_id INT NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    status INT,
+    date_from VARCHAR(255),
+    date_to VARCHAR(255),
+    created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_error VARCHAR(1000)
+);
+
+CREATE INDEX idx_yandex_get_id_user ON yandex_get(id_user);
+CREATE INDEX idx_yandex_get_status ON yandex_get(status);
+
+COMMENT ON COLUMN yandex_get.type IS 'operations - заказы с комиссиями и услугами за период, products - каталог товаров';
@@ -0,0 +1,37 @@
+-- Обратно в строки. Значения, которые не удалось разобрать при переводе в NUMERIC,
+-- восстанавливаются из money_conversion_errors
+ALTER TABLE wb_articles ALTER COLUMN cost_price DROP DEFAULT;
+ALTER TABLE wb_articles ALTER COLUMN self_ransom_price DROP DEFAULT;
+
+ALTER TABLE wb_articles
+    ALTER COLUMN cost_price TYPE VARCHAR(255) USING cost_price::TEXT,
+    ALTER COLUMN self_ransom_price TYPE VARCHAR(255) USING self_ransom_price::TEXT;
+
+ALTER TABLE wb_articles ALTER COLUMN cost_price SET DEFAULT '0';
+ALTER TABLE wb_articles ALTER COLUMN self_ransom_price SET DEFAULT '0';
+
+ALTER TABLE wb_stats ALTER COLUMN rebill_logistic_cost DROP DEFAULT;
+
+ALTER TABLE wb_stats
+    ALTER COLUMN ppvz_for_pay TYPE VARCHAR(500) USING ppvz_for_pay::TEXT,
+    ALTER COLUMN rebill_logistic_cost TYPE VARCHAR(500) USING rebill_logistic_cost::TEXT,
+    ALTER COLUMN ppvz_spp_prc TYPE VARCHAR(500) USING ppvz_spp_prc::TEXT,
+    ALTER COLUMN ppvz_kvw_prc_base TYPE VARCHAR(500) USING ppvz_kvw_prc_base::TEXT,
+    ALTER COLUMN ppvz_kvw_prc TYPE VARCHAR(500) USING ppvz_kvw_prc::TEXT,
+    ALTER COLUMN ppvz_vw_nds TYPE VARCHAR(500) USING ppvz_vw_nds::TEXT,
+    ALTER COLUMN ppvz_vw TYPE VARCHAR(500) USING ppvz_vw::TEXT;
+
+ALTER TABLE wb_stats ALTER COLUMN rebill_logistic_cost SET DEFAULT '0.00';
+
+DO $$
+DECLARE
+    err RECORD;
+BEGIN
+    FOR err IN SELECT * FROM money_conversion_errors LOOP
+        EXECUTE format('UPDATE %I SET %I = %L WHERE id = %s',
+            err.table_name, err.column_name, err.raw_value, err.row_id);
+    END LOOP;
+END $$;
+
+DROP TABLE IF EXISTS money_conversion_errors;
+DROP FUNCTION IF EXISTS parse_money(TEXT);
@@ -0,0 +1,85 @@
+-- Денежные и процентные поля отчета WB и себестоимость хранились строками,
+-- запросы разбирали их регуляркой '^[0-9]+\.?[0-9]*$' и отрицательные суммы
+-- (корректировки, возвраты) превращались в 0. Переводим колонки в NUMERIC
+
+-- Разбор строки в число: пробелы убираются, запятая - десятичный разделитель,
+-- если в строке нет точки (иначе запятая - разделитель тысяч). Неразобранное - NULL
+CREATE OR REPLACE FUNCTION parse_money(value TEXT) RETURNS NUMERIC AS $$
+    SELECT CASE
+        WHEN v ~ '^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)$' THEN v::NUMERIC
+    END
+    FROM (
+        SELECT CASE
+            WHEN position('.' IN s) > 0 THEN replace(s, ',', '')
+            ELSE replace(s, ',', '.')
+        END AS v
+        FROM (SELECT regexp_replace(value, '\s', '', 'g') AS s) cleaned
+    ) normalized
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- Отчет о значениях, которые не удалось преобразовать (в колонке после миграции будет NULL)
+CREATE TABLE IF NOT EXISTS money_conversion_errors (
+    id SERIAL PRIMARY KEY,
+    table_name VARCHAR(50) NOT NULL,
+    row_id BIGINT NOT NULL,
+    column_name VARCHAR(50) NOT NULL,
+    raw_value VARCHAR(500),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+DO $$
+DECLARE
+    col RECORD;
+    bad INT;
+BEGIN
+    FOR col IN
+        SELECT * FROM (VALUES
+            ('wb_stats', 'ppvz_for_pay'),
+            ('wb_stats', 'rebill_logistic_cost'),
+            ('wb_stats', 'ppvz_spp_prc'),
+            ('wb_stats', 'ppvz_kvw_prc_base'),
+            ('wb_stats', 'ppvz_kvw_prc'),
+            ('wb_stats', 'ppvz_vw_nds'),
+            ('wb_stats', 'ppvz_vw'),
+            ('wb_articles', 'cost_price'),
+            ('wb_articles', 'self_ransom_price')
+        ) AS c(table_name, column_name)
+    LOOP
+        EXECUTE format(
+            'INSERT INTO money_conversion_errors (table_name, row_id, column_name, raw_value)
+             SELECT %L, id, %L, %I FROM %I
+             WHERE btrim(COALESCE(%I, '''')) <> '''' AND parse_money(%I) IS NULL',
+            col.table_name, col.column_name, col.column_name, col.table_name, col.column_name, col.column_name
+        );
+        GET DIAGNOSTICS bad = ROW_COUNT;
+        IF bad > 0 THEN
+            RAISE WARNING '%.%: не удалось преобразовать % значений, подробности в money_conversion_errors',
+                col.table_name, col.column_name, bad;
+        END IF;
+    END LOOP;
+END $$;
+
+ALTER TABLE wb_stats ALTER COLUMN rebill_logistic_cost DROP DEFAULT;
+
+ALTER TABLE wb_stats
+    ALTER COLUMN ppvz_for_pay TYPE NUMERIC(15,2) USING parse_money(ppvz_for_pay),
+    ALTER COLUMN rebill_logistic_cost TYPE NUMERIC(15,2) USING parse_money(rebill_logistic_cost),
+    ALTER COLUMN ppvz_spp_prc TYPE NUMERIC(10,4) USING parse_money(ppvz_spp_prc),
+    ALTER COLUMN ppvz_kvw_prc_base TYPE NUMERIC(10,4) USING parse_money(ppvz_kvw_prc_base),
+    ALTER COLUMN ppvz_kvw_prc TYPE NUMERIC(10,4) USING parse_money(ppvz_kvw_prc),
+    ALTER COLUMN ppvz_vw_nds TYPE NUMERIC(15,2) USING parse_money(ppvz_vw_nds),
+    ALTER COLUMN ppvz_vw TYPE NUMERIC(15,2) USING parse_money(ppvz_vw);
+
+ALTER TABLE wb_stats ALTER COLUMN rebill_logistic_cost SET DEFAULT 0;
+
+ALTER TABLE wb_articles ALTER COLUMN cost_price DROP DEFAULT;
+ALTER TABLE wb_articles ALTER COLUMN self_ransom_price DROP DEFAULT;
+
+ALTER TABLE wb_articles
+    ALTER COLUMN cost_price TYPE NUMERIC(15,2) USING parse_money(cost_price),
+    ALTER COLUMN self_ransom_price TYPE NUMERIC(15,2) USING parse_money(self_ransom_price);
+
+ALTER TABLE wb_articles ALTER COLUMN cost_price SET DEFAULT 0;
+ALTER TABLE wb_articles ALTER COLUMN self_ransom_price SET DEFAULT 0;
+
+COMMENT ON TABLE money_conversion_errors IS 'Значения денежных колонок, которые не удалось перевести в NUMERIC (миграция 016)';
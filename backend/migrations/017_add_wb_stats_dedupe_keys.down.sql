@@ -0,0 +1,5 @@
+-- Удаленные при переходе дубли не восстанавливаются
+DROP INDEX IF EXISTS uq_wb_stats_hash_info;
+DROP INDEX IF EXISTS uq_wb_stats_rrd_id;
+
+ALTER TABLE wb_stats DROP COLUMN IF EXISTS rrd_id;
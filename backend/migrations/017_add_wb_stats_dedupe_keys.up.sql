@@ -0,0 +1,30 @@
+-- Дедупликация строк отчета WB ограничениями вместо SELECT по hash_info на каждую строку.
+-- Естественный ключ строки отчета - rrd_id (уникален в кабинете продавца),
+-- для строк без rrd_id (загруженных до этой миграции) - хеш строки
+ALTER TABLE wb_stats ADD COLUMN IF NOT EXISTS rrd_id BIGINT;
+
+COMMENT ON COLUMN wb_stats.rrd_id IS 'Номер строки отчета реализации WB';
+
+-- Перед уникальным индексом убираем уже накопившиеся дубли (оставляем первую загруженную строку)
+DO $$
+DECLARE
+    removed INT;
+BEGIN
+    DELETE FROM wb_stats a
+    USING wb_stats b
+    WHERE a.user_id = b.user_id
+      AND a.hash_info = b.hash_info
+      AND a.id > b.id;
+    GET DIAGNOSTICS removed = ROW_COUNT;
+    IF removed > 0 THEN
+        RAISE NOTICE 'wb_stats: удалено % дублей по hash_info', removed;
+    END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_wb_stats_rrd_id
+    ON wb_stats(user_id, (COALESCE(account_id, 0)), rrd_id)
+    WHERE rrd_id IS NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_wb_stats_hash_info
+    ON wb_stats(user_id, hash_info)
+    WHERE rrd_id IS NULL;
@@ -0,0 +1,55 @@
+-- Обратно в обычную таблицу с индексами из 006, 011 и 017
+ALTER TABLE wb_stats RENAME TO wb_stats_partitioned;
+
+CREATE TABLE wb_stats (LIKE wb_stats_partitioned INCLUDING DEFAULTS);
+
+ALTER SEQUENCE wb_stats_id_seq OWNED BY wb_stats.id;
+
+INSERT INTO wb_stats SELECT * FROM wb_stats_partitioned;
+
+-- Вместе с родительской таблицей удаляются все секции и их индексы
+DROP TABLE wb_stats_partitioned;
+
+DROP FUNCTION IF EXISTS create_wb_stats_partitions(DATE, DATE);
+DROP FUNCTION IF EXISTS create_wb_stats_partition(DATE);
+
+ALTER TABLE wb_stats ADD PRIMARY KEY (id);
+
+CREATE INDEX idx_wb_stats_user_id ON wb_stats(user_id);
+CREATE INDEX idx_wb_stats_nm_id ON wb_stats(nm_id);
+CREATE INDEX idx_wb_stats_ppvz_for_pay ON wb_stats(ppvz_for_pay);
+CREATE INDEX idx_wb_stats_quantity ON wb_stats(quantity);
+CREATE INDEX idx_wb_stats_realizationreport_id ON wb_stats(realizationreport_id);
+CREATE INDEX idx_wb_stats_rebill_logistic_cost ON wb_stats(rebill_logistic_cost);
+CREATE INDEX idx_wb_stats_report_type ON wb_stats(report_type);
+CREATE INDEX idx_wb_stats_return_amount ON wb_stats(return_amount);
+CREATE INDEX idx_wb_stats_rid ON wb_stats(rid);
+CREATE INDEX idx_wb_stats_supplier_oper_name ON wb_stats(supplier_oper_name);
+CREATE INDEX idx_wb_stats_rr_dt ON wb_stats(rr_dt);
+CREATE INDEX idx_wb_stats_account_id ON wb_stats(account_id);
+
+-- Уникальные ключи без sale_dt: строка с тем же ключом, но другой датой продажи считается дублем
+DELETE FROM wb_stats a
+USING wb_stats b
+WHERE a.user_id = b.user_id
+  AND COALESCE(a.account_id, 0) = COALESCE(b.account_id, 0)
+  AND a.rrd_id = b.rrd_id
+  AND a.id > b.id;
+
+DELETE FROM wb_stats a
+USING wb_stats b
+WHERE a.user_id = b.user_id
+  AND a.hash_info = b.hash_info
+  AND a.rrd_id IS NULL
+  AND b.rrd_id IS NULL
+  AND a.id > b.id;
+
+CREATE UNIQUE INDEX uq_wb_stats_rrd_id
+    ON wb_stats(user_id, (COALESCE(account_id, 0)), rrd_id)
+    WHERE rrd_id IS NOT NULL;
+
+CREATE UNIQUE INDEX uq_wb_stats_hash_info
+    ON wb_stats(user_id, hash_info)
+    WHERE rrd_id IS NULL;
+
+COMMENT ON COLUMN wb_stats.rrd_id IS 'Номер строки отчета реализации WB';
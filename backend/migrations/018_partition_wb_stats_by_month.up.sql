@@ -0,0 +1,108 @@
+-- Помесячное секционирование wb_stats по sale_dt.
+-- Аналитика всегда фильтрует user_id + диапазон sale_dt, поэтому секции по месяцу
+-- и составные индексы (user_id, sale_dt) вместо набора одиночных индексов.
+-- Строки без sale_dt попадают в секцию wb_stats_default
+
+-- Создание секции месяца. Строки этого месяца, успевшие попасть в default-секцию,
+-- переносятся в новую секцию (иначе PostgreSQL не даст ее создать)
+CREATE OR REPLACE FUNCTION create_wb_stats_partition(p_month DATE) RETURNS TEXT AS $$
+DECLARE
+    start_dt DATE := date_trunc('month', p_month)::DATE;
+    end_dt DATE := (date_trunc('month', p_month) + INTERVAL '1 month')::DATE;
+    part_name TEXT := 'wb_stats_' || to_char(date_trunc('month', p_month), 'YYYY_MM');
+    moved INT := 0;
+BEGIN
+    -- Секции могут создавать одновременно несколько воркеров
+    PERFORM pg_advisory_xact_lock(hashtext('wb_stats_partitions'));
+
+    IF to_regclass(part_name) IS NOT NULL THEN
+        RETURN part_name;
+    END IF;
+
+    IF to_regclass('wb_stats_default') IS NOT NULL THEN
+        EXECUTE format(
+            'CREATE TEMP TABLE wb_stats_partition_move ON COMMIT DROP AS
+             SELECT * FROM wb_stats_default WHERE sale_dt >= %L AND sale_dt < %L',
+            start_dt, end_dt);
+        EXECUTE format('DELETE FROM wb_stats_default WHERE sale_dt >= %L AND sale_dt < %L', start_dt, end_dt);
+        GET DIAGNOSTICS moved = ROW_COUNT;
+    END IF;
+
+    EXECUTE format(
+        'CREATE TABLE %I PARTITION OF wb_stats FOR VALUES FROM (%L) TO (%L)',
+        part_name, start_dt, end_dt);
+
+    IF to_regclass('wb_stats_default') IS NOT NULL THEN
+        EXECUTE 'INSERT INTO wb_stats SELECT * FROM wb_stats_partition_move';
+        EXECUTE 'DROP TABLE wb_stats_partition_move';
+    END IF;
+
+    IF moved > 0 THEN
+        RAISE NOTICE 'wb_stats: в секцию % перенесено % строк из default', part_name, moved;
+    END IF;
+
+    RETURN part_name;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Создание секций всех месяцев диапазона (включительно). Возвращает количество месяцев
+CREATE OR REPLACE FUNCTION create_wb_stats_partitions(p_from DATE, p_to DATE) RETURNS INT AS $$
+DECLARE
+    month_dt DATE := date_trunc('month', p_from)::DATE;
+    months INT := 0;
+BEGIN
+    WHILE month_dt <= p_to LOOP
+        PERFORM create_wb_stats_partition(month_dt);
+        month_dt := (month_dt + INTERVAL '1 month')::DATE;
+        months := months + 1;
+    END LOOP;
+    RETURN months;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Переносим данные: старая таблица -> секционированная с той же структурой
+ALTER TABLE wb_stats RENAME TO wb_stats_old;
+
+CREATE TABLE wb_stats (LIKE wb_stats_old INCLUDING DEFAULTS) PARTITION BY RANGE (sale_dt);
+
+-- Последовательность id остается прежней, чтобы не пересекались идентификаторы
+ALTER SEQUENCE wb_stats_id_seq OWNED BY wb_stats.id;
+
+CREATE TABLE wb_stats_default PARTITION OF wb_stats DEFAULT;
+
+DO $$
+DECLARE
+    first_dt DATE;
+    months INT;
+BEGIN
+    SELECT COALESCE(MIN(sale_dt), CURRENT_DATE)::DATE INTO first_dt FROM wb_stats_old;
+    months := create_wb_stats_partitions(first_dt, (CURRENT_DATE + INTERVAL '3 months')::DATE);
+    RAISE NOTICE 'wb_stats: создано % помесячных секций начиная с %', months, first_dt;
+END $$;
+
+INSERT INTO wb_stats SELECT * FROM wb_stats_old;
+
+DROP TABLE wb_stats_old;
+
+-- Ключ строки: первичный ключ секционированной таблицы обязан включать sale_dt, а он бывает NULL,
+-- поэтому вместо PRIMARY KEY - UNIQUE (id, sale_dt), строки без даты продажи тоже не дублируются по id
+ALTER TABLE wb_stats ADD CONSTRAINT wb_stats_id_sale_dt_key UNIQUE NULLS NOT DISTINCT (id, sale_dt);
+
+-- Индексы создаются на родительской таблице и наследуются всеми секциями
+CREATE INDEX idx_wb_stats_user_sale_dt ON wb_stats(user_id, sale_dt);
+CREATE INDEX idx_wb_stats_user_account_sale_dt ON wb_stats(user_id, account_id, sale_dt);
+CREATE INDEX idx_wb_stats_user_nm_sale_dt ON wb_stats(user_id, nm_id, sale_dt);
+CREATE INDEX idx_wb_stats_user_realizationreport_id ON wb_stats(user_id, realizationreport_id);
+CREATE INDEX idx_wb_stats_user_rr_dt ON wb_stats(user_id, rr_dt);
+
+-- Ключи дедупликации из 017: уникальный индекс секционированной таблицы включает ключ секционирования
+CREATE UNIQUE INDEX uq_wb_stats_rrd_id
+    ON wb_stats(user_id, (COALESCE(account_id, 0)), rrd_id, sale_dt) NULLS NOT DISTINCT
+    WHERE rrd_id IS NOT NULL;
+
+CREATE UNIQUE INDEX uq_wb_stats_hash_info
+    ON wb_stats(user_id, hash_info, sale_dt) NULLS NOT DISTINCT
+    WHERE rrd_id IS NULL;
+
+COMMENT ON TABLE wb_stats IS 'Строки отчета реализации WB, секции по месяцу sale_dt';
+COMMENT ON COLUMN wb_stats.rrd_id IS 'Номер строки отчета реализации WB';
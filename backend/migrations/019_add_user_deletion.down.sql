@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_delete_after;
+
+ALTER TABLE users DROP COLUMN IF EXISTS delete_after;
+ALTER TABLE users DROP COLUMN IF EXISTS delete_requested_at;
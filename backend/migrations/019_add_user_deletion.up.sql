@@ -0,0 +1,9 @@
+-- Удаление аккаунта по запросу пользователя: после льготного периода
+-- воркер purge удаляет пользователя вместе со всеми его данными
+ALTER TABLE users ADD COLUMN IF NOT EXISTS delete_requested_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS delete_after TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_users_delete_after ON users(delete_after) WHERE delete_after IS NOT NULL;
+
+COMMENT ON COLUMN users.delete_requested_at IS 'Когда пользователь запросил удаление аккаунта';
+COMMENT ON COLUMN users.delete_after IS 'Дата полного удаления аккаунта и данных (NULL - удаление не запрошено)';
@@ -0,0 +1,24 @@
+-- Журнал действий: админские изменения пользователей, изменения профиля и ключей,
+-- правки себестоимости и создание заданий. Секреты в before/after хранятся только маской
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id INT,
+    target_user_id INT,
+    target_type VARCHAR(50) NOT NULL,
+    target_id VARCHAR(100),
+    action VARCHAR(100) NOT NULL,
+    before JSONB,
+    after JSONB,
+    ip VARCHAR(64),
+    user_agent VARCHAR(500),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_log_created_at ON audit_log(created_at);
+CREATE INDEX idx_audit_log_actor ON audit_log(actor_id, created_at);
+CREATE INDEX idx_audit_log_target_user ON audit_log(target_user_id, created_at);
+CREATE INDEX idx_audit_log_action ON audit_log(action, created_at);
+
+COMMENT ON COLUMN audit_log.actor_id IS 'Кто выполнил действие (users.id_user)';
+COMMENT ON COLUMN audit_log.target_user_id IS 'Чьи данные изменены (владелец данных или пользователь из админки)';
+COMMENT ON COLUMN audit_log.target_type IS 'user, article, wb_stats_get, wb_articles_get, ozon_get, yandex_get';
@@ -0,0 +1,27 @@
+-- История себестоимости: каждое значение действует с valid_from до следующей записи.
+-- Аналитика берет себестоимость, действовавшую на sale_dt строки отчета,
+-- поэтому изменение цены поставщика не пересчитывает прошлые периоды.
+-- wb_articles.cost_price остается текущим значением (на сегодня)
+CREATE TABLE IF NOT EXISTS cost_price_history (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    articule VARCHAR(255) NOT NULL,
+    cost_price NUMERIC(15,2) NOT NULL,
+    valid_from DATE NOT NULL,
+    created_by INT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Одно значение на дату; индекс же используется для поиска действующей записи (valid_from <= sale_dt)
+CREATE UNIQUE INDEX uq_cost_price_history ON cost_price_history(user_id, articule, valid_from);
+
+-- Текущая себестоимость переносится как действующая с начала истории
+INSERT INTO cost_price_history (user_id, articule, cost_price, valid_from)
+SELECT DISTINCT ON (id_user, articule) id_user, articule::text, cost_price, DATE '1970-01-01'
+FROM wb_articles
+WHERE COALESCE(cost_price, 0) <> 0
+ORDER BY id_user, articule, updated_at DESC NULLS LAST, id DESC;
+
+COMMENT ON TABLE cost_price_history IS 'Себестоимость товаров с датой начала действия';
+COMMENT ON COLUMN cost_price_history.valid_from IS 'Дата, с которой действует себестоимость (включительно)';
+COMMENT ON COLUMN cost_price_history.created_by IS 'Кто внес значение (users.id_user)';
@@ -0,0 +1,8 @@
+DELETE FROM cost_price_history WHERE chrt_id <> 0;
+
+DROP INDEX IF EXISTS uq_cost_price_history;
+ALTER TABLE cost_price_history DROP COLUMN IF EXISTS chrt_id;
+CREATE UNIQUE INDEX uq_cost_price_history ON cost_price_history(user_id, articule, valid_from);
+
+DROP TABLE IF EXISTS wb_article_barcodes;
+DROP TABLE IF EXISTS wb_article_sizes;
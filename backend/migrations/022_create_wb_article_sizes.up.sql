@@ -0,0 +1,55 @@
+-- Размеры карточек WB: у одной карточки (nm_id) несколько размеров (chrtID),
+-- у каждого размера свои баркоды. Раньше в wb_articles сохранялся только первый размер,
+-- а его баркоды склеивались в строку
+CREATE TABLE IF NOT EXISTS wb_article_sizes (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    nm_id BIGINT NOT NULL,
+    chrt_id BIGINT NOT NULL,
+    tech_size VARCHAR(100) NOT NULL DEFAULT '',
+    wb_size VARCHAR(100) NOT NULL DEFAULT '',
+    cost_price NUMERIC(15,2),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX uq_wb_article_sizes ON wb_article_sizes(user_id, nm_id, chrt_id);
+CREATE INDEX idx_wb_article_sizes_tech_size ON wb_article_sizes(user_id, nm_id, tech_size);
+
+-- Баркоды размера (skus) - по одной строке на баркод
+CREATE TABLE IF NOT EXISTS wb_article_barcodes (
+    id SERIAL PRIMARY KEY,
+    size_id INT NOT NULL REFERENCES wb_article_sizes(id) ON DELETE CASCADE,
+    user_id INT NOT NULL,
+    barcode VARCHAR(100) NOT NULL
+);
+
+CREATE UNIQUE INDEX uq_wb_article_barcodes ON wb_article_barcodes(size_id, barcode);
+CREATE INDEX idx_wb_article_barcodes_user_barcode ON wb_article_barcodes(user_id, barcode);
+
+-- Переносим сохраненный первый размер; остальные появятся при следующем обновлении карточек
+INSERT INTO wb_article_sizes (user_id, nm_id, chrt_id, tech_size, wb_size)
+SELECT DISTINCT ON (id_user, articule, chrt_id)
+    id_user, articule, chrt_id, COALESCE(eu_size, ''), COALESCE(rus_size, '')
+FROM wb_articles
+WHERE marketplace = 'wb' AND COALESCE(chrt_id, 0) <> 0
+ORDER BY id_user, articule, chrt_id, id DESC;
+
+INSERT INTO wb_article_barcodes (size_id, user_id, barcode)
+SELECT DISTINCT sz.id, sz.user_id, TRIM(code)
+FROM wb_articles wa
+JOIN wb_article_sizes sz ON sz.user_id = wa.id_user AND sz.nm_id = wa.articule AND sz.chrt_id = wa.chrt_id
+CROSS JOIN LATERAL unnest(string_to_array(wa.barcode, ',')) AS code
+WHERE wa.marketplace = 'wb' AND TRIM(code) <> '';
+
+-- Себестоимость размера: chrt_id = 0 - себестоимость всей карточки
+ALTER TABLE cost_price_history ADD COLUMN chrt_id BIGINT NOT NULL DEFAULT 0;
+
+DROP INDEX uq_cost_price_history;
+CREATE UNIQUE INDEX uq_cost_price_history ON cost_price_history(user_id, articule, chrt_id, valid_from);
+
+COMMENT ON TABLE wb_article_sizes IS 'Размеры карточек WB (sizes[] из API контента)';
+COMMENT ON COLUMN wb_article_sizes.tech_size IS 'techSize - размер продавца, совпадает с wb_stats.ts_name';
+COMMENT ON COLUMN wb_article_sizes.wb_size IS 'wbSize - российский размер';
+COMMENT ON COLUMN wb_article_sizes.cost_price IS 'Текущая себестоимость размера (NULL - используется себестоимость карточки)';
+COMMENT ON COLUMN cost_price_history.chrt_id IS 'Размер (chrtID), 0 - вся карточка';
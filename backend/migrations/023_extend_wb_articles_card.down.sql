@@ -0,0 +1,27 @@
+DROP TABLE IF EXISTS wb_article_characteristics;
+DROP TABLE IF EXISTS wb_article_photos;
+
+DROP INDEX IF EXISTS idx_wb_articles_user_imt;
+DROP INDEX IF EXISTS idx_wb_articles_user_internal_id;
+DROP INDEX IF EXISTS idx_wb_articles_user_subject;
+DROP INDEX IF EXISTS idx_wb_articles_user_brand;
+
+-- Возвращаем прежнее содержимое internal_id, если артикул продавца еще не загружен
+UPDATE wb_articles SET internal_id = nm_uuid WHERE internal_id IS NULL AND nm_uuid IS NOT NULL;
+
+ALTER TABLE wb_articles
+    DROP COLUMN IF EXISTS brand,
+    DROP COLUMN IF EXISTS subject_id,
+    DROP COLUMN IF EXISTS subject_name,
+    DROP COLUMN IF EXISTS imt_id,
+    DROP COLUMN IF EXISTS nm_uuid,
+    DROP COLUMN IF EXISTS description,
+    DROP COLUMN IF EXISTS length,
+    DROP COLUMN IF EXISTS width,
+    DROP COLUMN IF EXISTS height,
+    DROP COLUMN IF EXISTS weight_brutto,
+    DROP COLUMN IF EXISTS dimensions_valid,
+    DROP COLUMN IF EXISTS card_created_at,
+    DROP COLUMN IF EXISTS card_updated_at;
+
+COMMENT ON COLUMN wb_articles.internal_id IS 'nmUUID';
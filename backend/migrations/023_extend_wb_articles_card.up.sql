@@ -0,0 +1,62 @@
+-- Полная карточка товара: бренд, предмет, склейка, описание, габариты упаковки.
+-- internal_id - артикул продавца (vendorCode WB, offerId Маркета). Раньше для WB он
+-- перезаписывался nmUUID, поэтому такие значения переносятся в nm_uuid
+ALTER TABLE wb_articles
+    ADD COLUMN IF NOT EXISTS brand VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS subject_id INT,
+    ADD COLUMN IF NOT EXISTS subject_name VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS imt_id BIGINT,
+    ADD COLUMN IF NOT EXISTS nm_uuid VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS description TEXT,
+    ADD COLUMN IF NOT EXISTS length NUMERIC(10,2),
+    ADD COLUMN IF NOT EXISTS width NUMERIC(10,2),
+    ADD COLUMN IF NOT EXISTS height NUMERIC(10,2),
+    ADD COLUMN IF NOT EXISTS weight_brutto NUMERIC(10,3),
+    ADD COLUMN IF NOT EXISTS dimensions_valid BOOLEAN,
+    ADD COLUMN IF NOT EXISTS card_created_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS card_updated_at TIMESTAMP;
+
+UPDATE wb_articles
+SET nm_uuid = internal_id, internal_id = NULL
+WHERE marketplace = 'wb'
+  AND internal_id ~* '^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$';
+
+CREATE INDEX IF NOT EXISTS idx_wb_articles_user_brand ON wb_articles(id_user, brand);
+CREATE INDEX IF NOT EXISTS idx_wb_articles_user_subject ON wb_articles(id_user, subject_id);
+CREATE INDEX IF NOT EXISTS idx_wb_articles_user_internal_id ON wb_articles(id_user, internal_id);
+CREATE INDEX IF NOT EXISTS idx_wb_articles_user_imt ON wb_articles(id_user, imt_id);
+
+-- Все фотографии карточки во всех размерах, position - порядок в карточке
+CREATE TABLE IF NOT EXISTS wb_article_photos (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    nm_id BIGINT NOT NULL,
+    position INT NOT NULL,
+    big VARCHAR(1000),
+    c246x328 VARCHAR(1000),
+    c516x688 VARCHAR(1000),
+    square VARCHAR(1000),
+    tm VARCHAR(1000)
+);
+
+CREATE UNIQUE INDEX uq_wb_article_photos ON wb_article_photos(user_id, nm_id, position);
+
+-- Характеристики карточки. value - как в API: строка, число или массив значений
+CREATE TABLE IF NOT EXISTS wb_article_characteristics (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    nm_id BIGINT NOT NULL,
+    characteristic_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    value JSONB
+);
+
+CREATE UNIQUE INDEX uq_wb_article_characteristics ON wb_article_characteristics(user_id, nm_id, characteristic_id);
+CREATE INDEX idx_wb_article_characteristics_name ON wb_article_characteristics(user_id, name);
+
+COMMENT ON COLUMN wb_articles.internal_id IS 'Артикул продавца: vendorCode WB, offerId Маркета';
+COMMENT ON COLUMN wb_articles.nm_uuid IS 'nmUUID';
+COMMENT ON COLUMN wb_articles.imt_id IS 'imtID - склейка карточек';
+COMMENT ON COLUMN wb_articles.length IS 'Габариты упаковки, см';
+COMMENT ON COLUMN wb_articles.weight_brutto IS 'Вес с упаковкой, кг';
+COMMENT ON COLUMN wb_articles.card_updated_at IS 'updatedAt карточки в WB';
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS wb_article_changes;
+
+ALTER TABLE wb_articles_get DROP COLUMN IF EXISTS full_sync;
+
+DROP INDEX IF EXISTS idx_wb_articles_user_archived;
+ALTER TABLE wb_articles
+    DROP COLUMN IF EXISTS archived_at,
+    DROP COLUMN IF EXISTS archive_reason;
+
+DROP TABLE IF EXISTS wb_article_sync;
@@ -0,0 +1,44 @@
+-- Инкрементальная синхронизация карточек WB: курсор последней загрузки по кабинету,
+-- пометка карточек, удаленных или перенесенных в корзину, и журнал изменений карточек
+
+-- Курсор (updatedAt + nmID последней полученной карточки) и время последней полной сверки.
+-- account_id = 0 - основной кабинет пользователя (ключ из профиля)
+CREATE TABLE IF NOT EXISTS wb_article_sync (
+    user_id INT NOT NULL,
+    account_id INT NOT NULL DEFAULT 0,
+    cursor_updated_at VARCHAR(50),
+    cursor_nm_id BIGINT,
+    last_sync_at TIMESTAMP,
+    last_full_sync_at TIMESTAMP,
+    PRIMARY KEY (user_id, account_id)
+);
+
+-- Карточка в архиве: trash - в корзине WB, deleted - отсутствует при полной сверке
+ALTER TABLE wb_articles
+    ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS archive_reason VARCHAR(20);
+
+CREATE INDEX IF NOT EXISTS idx_wb_articles_user_archived ON wb_articles(id_user, archived_at);
+
+-- Запрос полной сверки вместо инкрементальной загрузки
+ALTER TABLE wb_articles_get ADD COLUMN IF NOT EXISTS full_sync BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Изменения карточек: название, фото, размеры, баркоды, статус
+CREATE TABLE IF NOT EXISTS wb_article_changes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    account_id INT,
+    nm_id BIGINT NOT NULL,
+    field VARCHAR(50) NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    card_updated_at TIMESTAMP,
+    detected_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_wb_article_changes_user_nm ON wb_article_changes(user_id, nm_id, detected_at);
+CREATE INDEX idx_wb_article_changes_user_detected ON wb_article_changes(user_id, detected_at);
+
+COMMENT ON COLUMN wb_articles.archive_reason IS 'trash - в корзине WB, deleted - удалена (нет в списке карточек)';
+COMMENT ON COLUMN wb_article_changes.field IS 'title, photos, sizes, barcodes, vendor_code, brand, subject, status';
+COMMENT ON COLUMN wb_article_changes.card_updated_at IS 'updatedAt карточки в WB на момент изменения';
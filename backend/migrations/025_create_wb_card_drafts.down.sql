@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS wb_subject_characteristics;
+DROP TABLE IF EXISTS wb_card_drafts;
+DROP TABLE IF EXISTS wb_card_updates;
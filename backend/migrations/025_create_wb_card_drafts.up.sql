@@ -0,0 +1,62 @@
+-- Редактирование карточек WB из приложения: черновики изменений, задания отправки
+-- в API контента и справочник характеристик предметов для проверки черновиков
+
+-- Задание отправки черновиков (статусы как у wb_articles_get + 3 - ожидает результата WB)
+CREATE TABLE IF NOT EXISTS wb_card_updates (
+    id SERIAL PRIMARY KEY,
+    id_user INT NOT NULL,
+    account_id INT,
+    status INT,
+    created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP,
+    last_error VARCHAR(1000)
+);
+
+CREATE INDEX idx_wb_card_updates_status ON wb_card_updates(status, created);
+CREATE INDEX idx_wb_card_updates_user ON wb_card_updates(id_user, created);
+
+-- Черновик изменений карточки: NULL в поле - поле не меняется.
+-- characteristics - измененные характеристики [{id, value}], value = null - удалить значение
+CREATE TABLE IF NOT EXISTS wb_card_drafts (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    account_id INT,
+    nm_id BIGINT NOT NULL,
+    title VARCHAR(255),
+    description TEXT,
+    vendor_code VARCHAR(100),
+    characteristics JSONB,
+    length NUMERIC(10, 2),
+    width NUMERIC(10, 2),
+    height NUMERIC(10, 2),
+    weight_brutto NUMERIC(10, 3),
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    errors TEXT[] NOT NULL DEFAULT '{}',
+    update_id INT REFERENCES wb_card_updates(id) ON DELETE SET NULL,
+    created_by INT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, nm_id)
+);
+
+CREATE INDEX idx_wb_card_drafts_update ON wb_card_drafts(update_id);
+CREATE INDEX idx_wb_card_drafts_user_status ON wb_card_drafts(user_id, status);
+
+-- Характеристики предметов WB (общий справочник, обновляется при загрузке карточек)
+CREATE TABLE IF NOT EXISTS wb_subject_characteristics (
+    subject_id INT NOT NULL,
+    charc_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    unit_name VARCHAR(50),
+    max_count INT NOT NULL DEFAULT 0,
+    charc_type INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (subject_id, charc_id)
+);
+
+COMMENT ON COLUMN wb_card_drafts.status IS 'draft - черновик, queued - в задании, sent - отправлен в WB, applied - принят, error - отклонен WB';
+COMMENT ON COLUMN wb_card_drafts.errors IS 'Ошибки проверки черновика или ошибки WB по карточке';
+COMMENT ON COLUMN wb_subject_characteristics.charc_type IS '1 - массив строк, 4 - число (как в API WB)';
+COMMENT ON COLUMN wb_subject_characteristics.max_count IS 'Максимум значений (0 - без ограничения)';
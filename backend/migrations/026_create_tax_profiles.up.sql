@@ -0,0 +1,33 @@
+-- Налоговый профиль продавца: режим, ставка, база и НДС с датой начала действия.
+-- Профиль действует с valid_from до следующей записи; аналитика берет профиль,
+-- действовавший на sale_dt строки отчета (operation_date операции).
+-- users.taxes остается текущей ставкой: без профиля налог считается по ней
+CREATE TABLE IF NOT EXISTS tax_profiles (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    regime VARCHAR(30) NOT NULL,
+    rate NUMERIC(5,2) NOT NULL,
+    base VARCHAR(20) NOT NULL DEFAULT 'payout',
+    vat_rate NUMERIC(5,2) NOT NULL DEFAULT 0,
+    valid_from DATE NOT NULL,
+    created_by INT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Один профиль на дату; индекс же используется для поиска действующего профиля (valid_from <= sale_dt)
+CREATE UNIQUE INDEX uq_tax_profiles ON tax_profiles(user_id, valid_from);
+
+-- Текущая ставка переносится как действующая с начала истории. Прежний расчет -
+-- процент от суммы к перечислению за вычетом расходов
+INSERT INTO tax_profiles (user_id, regime, rate, base, valid_from)
+SELECT id_user, 'usn_income_expense', taxes, 'payout', DATE '1970-01-01'
+FROM users
+WHERE COALESCE(taxes, 0) > 0;
+
+COMMENT ON TABLE tax_profiles IS 'Налоговые профили продавцов с датой начала действия';
+COMMENT ON COLUMN tax_profiles.regime IS 'usn_income - УСН Доходы, usn_income_expense - УСН Доходы минус расходы, osno - ОСНО, self_employed - НПД';
+COMMENT ON COLUMN tax_profiles.rate IS 'Ставка налога, %';
+COMMENT ON COLUMN tax_profiles.base IS 'Доход: retail - цена продажи покупателю, payout - сумма к перечислению от маркетплейса';
+COMMENT ON COLUMN tax_profiles.vat_rate IS 'Ставка НДС, % (0 - без НДС). НДС выделяется из дохода';
+COMMENT ON COLUMN tax_profiles.valid_from IS 'Дата, с которой действует профиль (включительно)';
+COMMENT ON COLUMN tax_profiles.created_by IS 'Кто внес профиль (users.id_user)';
@@ -0,0 +1,15 @@
+-- Сверка еженедельных отчетов реализации WB: сумма "Итого к оплате" из отчета в кабинете
+-- продавца (или из выплаты) сравнивается с суммой, рассчитанной по строкам wb_stats
+CREATE TABLE IF NOT EXISTS wb_realization_reports (
+    user_id INT NOT NULL,
+    realizationreport_id BIGINT NOT NULL,
+    expected_payout NUMERIC(15,2),
+    comment TEXT,
+    updated_by INT,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, realizationreport_id)
+);
+
+COMMENT ON TABLE wb_realization_reports IS 'Сверка отчетов реализации WB';
+COMMENT ON COLUMN wb_realization_reports.expected_payout IS 'Итого к оплате по данным WB';
+COMMENT ON COLUMN wb_realization_reports.updated_by IS 'Кто внес сумму (users.id_user)';
@@ -0,0 +1,25 @@
+-- Снимок остатков на складах WB (statistics API /api/v1/supplier/stocks).
+-- Перезаписывается целиком для кабинета при каждой загрузке карточек
+CREATE TABLE IF NOT EXISTS wb_stocks (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    account_id INT,
+    nm_id BIGINT NOT NULL,
+    barcode VARCHAR(64) NOT NULL DEFAULT '',
+    tech_size VARCHAR(64) NOT NULL DEFAULT '',
+    warehouse_name VARCHAR(255) NOT NULL DEFAULT '',
+    quantity INT NOT NULL DEFAULT 0,
+    in_way_to_client INT NOT NULL DEFAULT 0,
+    in_way_from_client INT NOT NULL DEFAULT 0,
+    quantity_full INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_wb_stocks_user_nm ON wb_stocks(user_id, nm_id);
+CREATE INDEX IF NOT EXISTS idx_wb_stocks_user_account ON wb_stocks(user_id, account_id);
+
+COMMENT ON TABLE wb_stocks IS 'Остатки на складах WB';
+COMMENT ON COLUMN wb_stocks.quantity IS 'Доступно для продажи';
+COMMENT ON COLUMN wb_stocks.in_way_to_client IS 'В пути к клиенту';
+COMMENT ON COLUMN wb_stocks.in_way_from_client IS 'В пути от клиента (возвраты)';
+COMMENT ON COLUMN wb_stocks.quantity_full IS 'Полное количество с товаром в пути';